@@ -0,0 +1,60 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APIKeysEnv lists the API keys, separated by commas, accepted by the
+// rendering endpoints wrapped with RequireAPIKey. When it is unset or empty
+// those endpoints stay open.
+const APIKeysEnv = "MINDMAP_API_KEYS"
+
+// apiKeys 返回 MINDMAP_API_KEYS 中配置的密钥，忽略空白与空项
+func apiKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(os.Getenv(APIKeysEnv), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// RequireAPIKey wraps h so that, when MINDMAP_API_KEYS is set, requests must
+// carry one of the keys as "Authorization: Bearer <key>" or "X-API-Key: <key>";
+// other requests get 401. Without configured keys h is called as is.
+func RequireAPIKey(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys := apiKeys()
+		if len(keys) == 0 {
+			h(w, r)
+			return
+		}
+		given := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && given == "" {
+			given = bearer
+		}
+		if given == "" || !matchAPIKey(given, keys) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, "Invalid or missing API key")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// matchAPIKey 以常量时间比较 given 与每个密钥：比较 SHA-256 摘要以免泄露密钥长度，
+// 且不在匹配后提前返回，耗时与匹配到哪个密钥无关
+func matchAPIKey(given string, keys []string) bool {
+	sum := sha256.Sum256([]byte(given))
+	match := 0
+	for _, key := range keys {
+		want := sha256.Sum256([]byte(key))
+		match |= subtle.ConstantTimeCompare(sum[:], want[:])
+	}
+	return match == 1
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	var called int
+	h := RequireAPIKey(func(w http.ResponseWriter, r *http.Request) { called++ })
+	serve := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec.Code
+	}
+
+	// 未配置密钥时保持开放
+	t.Setenv(APIKeysEnv, "")
+	if code := serve("", ""); code != http.StatusOK || called != 1 {
+		t.Fatalf("expected open access without keys, got %d", code)
+	}
+
+	t.Setenv(APIKeysEnv, " alpha-key , ,beta-key")
+	called = 0
+	for _, tc := range []struct {
+		header, value string
+		want          int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"Authorization", "Bearer alpha-key", http.StatusOK},
+		{"X-API-Key", "beta-key", http.StatusOK},
+		{"Authorization", "Bearer wrong", http.StatusUnauthorized},
+		{"Authorization", "alpha-key", http.StatusUnauthorized},
+		{"X-API-Key", "alpha", http.StatusUnauthorized},
+		{"X-API-Key", " ", http.StatusUnauthorized},
+	} {
+		if code := serve(tc.header, tc.value); code != tc.want {
+			t.Errorf("%s: %q: expected %d, got %d", tc.header, tc.value, tc.want, code)
+		}
+	}
+	if called != 2 {
+		t.Errorf("expected the handler to run only for valid keys, ran %d times", called)
+	}
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodPost, "/api/gen", nil))
+	if rec.Header().Get("WWW-Authenticate") != "Bearer" || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected a JSON 401 with WWW-Authenticate, got %v", rec.Header())
+	}
+}
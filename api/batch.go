@@ -0,0 +1,222 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/internal/drawer"
+	"github.com/hellodeveye/mindmapgen/internal/parser"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+)
+
+// 批量生成的限制：单次请求的条目数与请求体大小，单个条目的内容沿用 maxMindmapInputBytes
+const (
+	maxBatchItems     = 50
+	maxBatchBodyBytes = 8 << 20 // 8 MiB
+)
+
+// batchItem 批量请求中的一个导图
+type batchItem struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	Theme   string `json:"theme"`
+	Layout  string `json:"layout"`
+}
+
+// batchItemError 描述某个条目失败的原因
+type batchItemError struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+type batchErrorResponse struct {
+	Error string           `json:"error"`
+	Items []batchItemError `json:"items"`
+}
+
+// batchResult 已渲染的条目：ZIP 中的文件名与 PNG 数据
+type batchResult struct {
+	name string
+	data []byte
+}
+
+// BatchHandler renders a JSON array of {name, content, theme, layout} outlines
+// and returns a ZIP archive with one PNG per item, named after the item's
+// name. With media=url the PNGs are uploaded instead and a JSON array of
+// {name, url} objects is returned. Every item is validated and rendered
+// before anything is returned; if any item fails, the whole batch fails with
+// a 400 listing the error of each failed item.
+func BatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed; use POST")
+		return
+	}
+	media := r.URL.Query().Get("media")
+	if media != "" && media != "raw" && media != "url" {
+		writeAPIError(w, http.StatusBadRequest, "Invalid media: must be raw or url")
+		return
+	}
+	if media == "url" && imageStore == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "Storage not configured. Set R2_*, S3_* or LOCAL_STORAGE_DIR environment variables and restart the server.")
+		return
+	}
+
+	var items []batchItem
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, "Input too large")
+			return
+		}
+		writeAPIError(w, http.StatusBadRequest, "Invalid batch: expected a JSON array of {name, content, theme, layout} objects")
+		return
+	}
+	if len(items) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "Empty batch")
+		return
+	}
+	if len(items) > maxBatchItems {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Too many items: at most %d per batch", maxBatchItems))
+		return
+	}
+
+	// 逐个渲染，收集所有条目的错误而不是遇到第一个错误就中止
+	var failures []batchItemError
+	results := make([]batchResult, 0, len(items))
+	seen := make(map[string]bool)
+	for i, item := range items {
+		result, err := renderBatchItem(r.Context(), item)
+		if ctxErr := r.Context().Err(); ctxErr != nil {
+			writeDrawError(w, ctxErr, writeAPIError)
+			return
+		}
+		if err == nil && seen[result.name] {
+			err = fmt.Errorf("duplicate name %q", result.name)
+		}
+		if err != nil {
+			failures = append(failures, batchItemError{Index: i, Name: item.Name, Error: err.Error()})
+			continue
+		}
+		seen[result.name] = true
+		results = append(results, result)
+	}
+	if len(failures) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(batchErrorResponse{
+			Error: fmt.Sprintf("%d of %d items failed", len(failures), len(items)),
+			Items: failures,
+		})
+		return
+	}
+
+	if media == "url" {
+		type uploaded struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		}
+		urls := make([]uploaded, 0, len(results))
+		for _, res := range results {
+			url, err := imageStore.UploadImage(r.Context(), res.data, "image/png")
+			if err != nil {
+				log.Println("Error uploading mindmap:", err)
+				writeAPIError(w, http.StatusInternalServerError, "Failed to upload mindmap "+res.name)
+				return
+			}
+			urls = append(urls, uploaded{Name: res.name, URL: url})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(urls)
+		return
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, res := range results {
+		// PNG 已经压缩，直接存储
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: res.name, Method: zip.Store})
+		if err == nil {
+			_, err = f.Write(res.data)
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "Failed to create archive")
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "Failed to create archive")
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="mindmaps.zip"`)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// renderBatchItem 校验并渲染单个条目，返回 ZIP 中使用的文件名与 PNG 数据
+func renderBatchItem(ctx context.Context, item batchItem) (batchResult, error) {
+	name, err := batchFileName(item.Name)
+	if err != nil {
+		return batchResult{}, err
+	}
+	if len(item.Content) > maxMindmapInputBytes {
+		return batchResult{}, fmt.Errorf("content exceeds %d bytes", maxMindmapInputBytes)
+	}
+	if strings.TrimSpace(item.Content) == "" {
+		return batchResult{}, errors.New("empty content")
+	}
+
+	themeName := item.Theme
+	if themeName == "" {
+		themeName = "default"
+	}
+	if _, err := theme.GetManager().GetThemeStrict(themeName); err != nil {
+		return batchResult{}, fmt.Errorf("unknown theme %q", themeName)
+	}
+	layout := item.Layout
+	if layout == "" {
+		layout = "right"
+	}
+	if !drawer.IsLayout(layout) {
+		return batchResult{}, fmt.Errorf("unknown layout %q", layout)
+	}
+
+	root, err := parser.Parse(item.Content)
+	if err != nil {
+		return batchResult{}, fmt.Errorf("failed to parse content: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := drawer.DrawContext(ctx, root, &buf, drawer.WithTheme(themeName), drawer.WithLayout(layout)); err != nil {
+		if errors.Is(err, drawer.ErrCanvasTooLarge) {
+			return batchResult{}, err
+		}
+		log.Printf("Error generating batch item %q: %v", item.Name, err)
+		return batchResult{}, errors.New("failed to generate mindmap")
+	}
+	return batchResult{name: name, data: buf.Bytes()}, nil
+}
+
+// batchFileName 将条目名转换为 ZIP 中的文件名：不允许路径，自动补全 .png 扩展名
+func batchFileName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("missing name")
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid name %q: must not contain path separators", name)
+	}
+	if !strings.EqualFold(path.Ext(name), ".png") {
+		name += ".png"
+	}
+	return name, nil
+}
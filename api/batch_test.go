@@ -0,0 +1,134 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func postBatch(t *testing.T, target string, items any) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(items)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	BatchHandler(rec, httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body)))
+	return rec
+}
+
+func TestBatchHandler_Zip(t *testing.T) {
+	rec := postBatch(t, "/api/batch", []batchItem{
+		{Name: "first", Content: "root\n  child"},
+		{Name: "second.png", Content: "other\n  leaf", Theme: "dark", Layout: "both"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+		t.Fatalf("expected Content-Type application/zip, got %q", got)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("invalid ZIP: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		header := make([]byte, 8)
+		_, _ = rc.Read(header)
+		rc.Close()
+		if !bytes.Equal(header, []byte("\x89PNG\r\n\x1a\n")) {
+			t.Errorf("%s is not a PNG", f.Name)
+		}
+	}
+	if strings.Join(names, ",") != "first.png,second.png" {
+		t.Fatalf("unexpected archive entries %v", names)
+	}
+}
+
+func TestBatchHandler_ItemErrors(t *testing.T) {
+	rec := postBatch(t, "/api/batch", []batchItem{
+		{Name: "ok", Content: "root\n  child"},
+		{Name: "", Content: "root"},
+		{Name: "bad-theme", Content: "root", Theme: "nope"},
+		{Name: "../escape", Content: "root"},
+		{Name: "empty", Content: "  "},
+		{Name: "ok.png", Content: "root"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	var resp batchErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var indexes []int
+	for _, item := range resp.Items {
+		indexes = append(indexes, item.Index)
+	}
+	if fmt.Sprint(indexes) != "[1 2 3 4 5]" {
+		t.Fatalf("expected every bad item to be reported, got %+v", resp.Items)
+	}
+	if !strings.Contains(resp.Items[4].Error, "duplicate") {
+		t.Errorf("expected a duplicate name error, got %q", resp.Items[4].Error)
+	}
+}
+
+func TestBatchHandler_Limits(t *testing.T) {
+	items := make([]batchItem, maxBatchItems+1)
+	for i := range items {
+		items[i] = batchItem{Name: fmt.Sprint(i), Content: "root"}
+	}
+	if rec := postBatch(t, "/api/batch", items); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d for too many items, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+
+	big := []batchItem{{Name: "big", Content: strings.Repeat("a", maxMindmapInputBytes+1)}}
+	rec := postBatch(t, "/api/batch", big)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "exceeds") {
+		t.Fatalf("expected a per-item size error, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, "/api/batch", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/batch", "{}", http.StatusBadRequest},
+		{http.MethodPost, "/api/batch", "[]", http.StatusBadRequest},
+		{http.MethodPost, "/api/batch?media=url", "[]", http.StatusServiceUnavailable},
+	} {
+		rec := httptest.NewRecorder()
+		BatchHandler(rec, httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body)))
+		if rec.Code != tc.status {
+			t.Errorf("%s %s %q: expected status %d, got %d", tc.method, tc.target, tc.body, tc.status, rec.Code)
+		}
+	}
+}
+
+func TestBatchHandler_Timeout(t *testing.T) {
+	body, err := json.Marshal([]batchItem{{Name: "first", Content: "Plan\n  Step"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	BatchHandler(rec, httptest.NewRequest(http.MethodPost, "/api/batch", bytes.NewReader(body)).WithContext(ctx))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d after the deadline, got %d: %s", http.StatusServiceUnavailable, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+)
+
+// 渲染结果缓存的容量：条目数与单个结果的大小上限，超过上限的结果不缓存
+const (
+	renderCacheEntries  = 64
+	renderCacheMaxBytes = 2 << 20 // 2 MiB
+)
+
+// renders 最近直接返回的渲染结果，按 renderKey 索引
+var renders = newRenderCache(renderCacheEntries)
+
+// renderKey 计算输入内容与影响输出的参数的哈希，同时用作强 ETag 与缓存键
+func renderKey(content string, params ...string) string {
+	h := sha256.New()
+	for _, p := range params {
+		io.WriteString(h, p)
+		h.Write([]byte{0})
+	}
+	io.WriteString(h, content)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// etagMatches 判断 If-None-Match 头是否包含 etag。按 RFC 9110 使用弱比较，
+// 忽略 W/ 前缀；"*" 匹配任意表示。
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCache 固定容量的 LRU 缓存，可并发使用
+type renderCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // 最近使用的条目在前
+	entries  map[string]*list.Element
+}
+
+type renderCacheEntry struct {
+	key  string
+	data []byte
+}
+
+func newRenderCache(capacity int) *renderCache {
+	return &renderCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *renderCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*renderCacheEntry).data, true
+}
+
+func (c *renderCache) add(key string, data []byte) {
+	if len(data) > renderCacheMaxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*renderCacheEntry).data = data
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&renderCacheEntry{key: key, data: data})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*renderCacheEntry).key)
+	}
+}
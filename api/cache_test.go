@@ -0,0 +1,43 @@
+package api
+
+import "testing"
+
+func TestRenderCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newRenderCache(2)
+	c.add("a", []byte("A"))
+	c.add("b", []byte("B"))
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	c.add("c", []byte("C"))
+
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b to be evicted as the least recently used entry")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("expected %s to stay cached", key)
+		}
+	}
+
+	c.add("big", make([]byte, renderCacheMaxBytes+1))
+	if _, ok := c.get("big"); ok {
+		t.Error("expected oversized results not to be cached")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+	for header, want := range map[string]bool{
+		`"abc"`:      true,
+		`W/"abc"`:    true,
+		`"x", "abc"`: true,
+		`*`:          true,
+		`"abd"`:      false,
+		``:           false,
+	} {
+		if got := etagMatches(header, etag); got != want {
+			t.Errorf("etagMatches(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -2,23 +2,49 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/hellodeveye/mindmapgen/internal/bundle"
 	"github.com/hellodeveye/mindmapgen/internal/drawer"
 	"github.com/hellodeveye/mindmapgen/internal/parser"
 	"github.com/hellodeveye/mindmapgen/internal/storage"
 	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+	"gopkg.in/yaml.v3"
 )
 
-var r2Client *storage.R2Client
+// imageStore media=url 模式上传图片的存储：S3 兼容的对象存储或本地目录，未配置时为 nil
+var imageStore storage.ImageStore
+
+// localStore 使用本地目录存储时的配置，由服务端挂载静态文件路由
+var localStore *storage.LocalStore
+
+// r2InitErr 记录 R2 已配置但客户端初始化失败的原因，供就绪探针报告
+var r2InitErr error
 
 const maxMindmapInputBytes = 1 << 20 // 1 MiB
 
+const maxThemeInputBytes = 64 << 10 // 64 KiB
+
+// scale 查询参数允许的范围
+const (
+	minScaleParam = 0.5
+	maxScaleParam = 8.0
+)
+
 type apiErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -29,18 +55,86 @@ func writeAPIError(w http.ResponseWriter, status int, message string) {
 	_ = json.NewEncoder(w).Encode(apiErrorResponse{Error: message})
 }
 
+// writeErrorImage 以 PNG 图片返回错误信息，状态码与 JSON 错误响应一致
+func writeErrorImage(w http.ResponseWriter, status int, message string) {
+	var buf bytes.Buffer
+	if err := drawer.DrawMessage(&buf, message); err != nil {
+		log.Println("Error rendering error image:", err)
+		writeAPIError(w, status, message)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
 func InitR2Client(cfg storage.R2Config) error {
-	var err error
-	r2Client, err = storage.NewR2Client(cfg)
-	return err
+	return InitS3Client(cfg.S3Config())
+}
+
+// InitS3Client configures the object storage used by media=url from any
+// S3-compatible configuration (AWS S3, MinIO, Backblaze, R2).
+func InitS3Client(cfg storage.S3Config) error {
+	client, err := storage.NewS3Client(cfg)
+	r2InitErr = err
+	if err != nil {
+		return err
+	}
+	imageStore = client
+	return nil
+}
+
+// SetImageStore sets the store media=url uploads to, e.g. a fake in tests;
+// nil disables media=url. Call it before serving requests.
+func SetImageStore(store storage.ImageStore) {
+	imageStore = store
+	localStore, _ = store.(*storage.LocalStore)
+}
+
+// InitLocalStore makes media=url save images to a local directory instead of
+// object storage. The files must be served under store.PublicPrefix, see
+// LocalFileStore.
+func InitLocalStore(store *storage.LocalStore) {
+	SetImageStore(store)
+}
+
+// LocalFileStore returns the local store configured by InitLocalStore, or nil
+// when uploads go to object storage or are not configured.
+func LocalFileStore() *storage.LocalStore {
+	return localStore
+}
+
+// R2InitError reports why the configured R2 client failed to initialize, or
+// nil when R2 is ready or was never configured.
+func R2InitError() error {
+	return r2InitErr
 }
 
 func GenerateMindmapHandler(w http.ResponseWriter, r *http.Request) {
 	// 获取参数
 	media := r.URL.Query().Get("media")
+	format := r.URL.Query().Get("format")
 	themeName := r.URL.Query().Get("theme")
 	layout := r.URL.Query().Get("layout")
 
+	// 未显式指定 format 或 media 时按 Accept 头协商输出
+	if format == "" || media == "" {
+		w.Header().Add("Vary", "Accept")
+		acceptFormat, acceptMedia := negotiateAccept(r.Header.Get("Accept"))
+		if format == "" {
+			format = acceptFormat
+		}
+		if media == "" {
+			media = acceptMedia
+		}
+	}
+
+	// 直接返回图片时可选择以 PNG 图片返回错误，便于 <img> 标签的调用方展示
+	writeError := writeAPIError
+	if media != "url" && r.URL.Query().Get("errorImage") == "true" {
+		writeError = writeErrorImage
+	}
+
 	// 如果没有指定主题，使用默认主题
 	if themeName == "" {
 		themeName = "default"
@@ -49,65 +143,280 @@ func GenerateMindmapHandler(w http.ResponseWriter, r *http.Request) {
 		layout = "right"
 	}
 
-	// 读取请求内容
-	var content string
-	r.Body = http.MaxBytesReader(w, r.Body, maxMindmapInputBytes)
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		var maxErr *http.MaxBytesError
-		if errors.As(err, &maxErr) {
-			writeAPIError(w, http.StatusRequestEntityTooLarge, "Input too large")
+	if !drawer.IsLayout(layout) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown layout %q; available layouts: %s", layout, strings.Join(drawer.Layouts(), ", ")))
+		return
+	}
+
+	// 主题名拼写错误时直接报错，避免静默使用默认主题
+	manager := theme.GetManager()
+	if _, err := manager.GetThemeStrict(themeName); err != nil {
+		themes := manager.ListThemes()
+		sort.Strings(themes)
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown theme %q; available themes: %s", themeName, strings.Join(themes, ", ")))
+		return
+	}
+
+	align := r.URL.Query().Get("align")
+	if align != "" && !drawer.IsAlignment(align) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown align %q; available alignments: %s", align, strings.Join(drawer.Alignments(), ", ")))
+		return
+	}
+	drawOpts := []drawer.Option{drawer.WithTheme(themeName), drawer.WithLayout(layout), drawer.WithAlignment(align)}
+	var scale float64
+	if rawScale := r.URL.Query().Get("scale"); rawScale != "" {
+		var err error
+		scale, err = strconv.ParseFloat(rawScale, 64)
+		if err != nil || scale < minScaleParam || scale > maxScaleParam {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid scale: must be a number between %g and %g", minScaleParam, maxScaleParam))
 			return
 		}
-		writeAPIError(w, http.StatusInternalServerError, "Failed to read request body")
+		drawOpts = append(drawOpts, drawer.WithScale(scale))
+	}
+
+	var maxDepth *int
+	if rawDepth := r.URL.Query().Get("maxDepth"); rawDepth != "" {
+		depth, err := strconv.Atoi(rawDepth)
+		if err != nil || depth < 0 {
+			writeError(w, http.StatusBadRequest, "Invalid maxDepth: must be a non-negative integer")
+			return
+		}
+		maxDepth = &depth
+		drawOpts = append(drawOpts, drawer.WithMaxDepth(depth))
+	}
+
+	// quality 为预设名（draft、normal、high）时选择质量预设，为数字时作为 JPEG 质量
+	rawQuality, qualityPreset := r.URL.Query().Get("quality"), ""
+	if drawer.IsQualityPreset(rawQuality) {
+		drawOpts = append(drawOpts, drawer.WithQuality(rawQuality))
+		qualityPreset, rawQuality = rawQuality, ""
+	} else if _, err := strconv.Atoi(rawQuality); rawQuality != "" && err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid quality: must be draft, normal, high or an integer between 1 and 100")
 		return
 	}
-	content = string(body)
+
+	if rawMeta := r.URL.Query().Get("meta"); rawMeta != "" {
+		drawOpts = append(drawOpts, drawer.WithMetaKeys(strings.Split(rawMeta, ",")...))
+	}
+
+	// 画布顶部的标题与底部的说明，为空时不绘制
+	if title := r.URL.Query().Get("title"); title != "" {
+		drawOpts = append(drawOpts, drawer.WithTitle(title))
+	}
+	if caption := r.URL.Query().Get("caption"); caption != "" {
+		drawOpts = append(drawOpts, drawer.WithCaption(caption))
+	}
+
+	// rtl=true 时从右到左排列导图与文本
+	if r.URL.Query().Get("rtl") == "true" {
+		drawOpts = append(drawOpts, drawer.WithRTL(true))
+	}
+
+	// iconLegend=true 时在右下角绘制图标图例
+	if r.URL.Query().Get("iconLegend") == "true" {
+		drawOpts = append(drawOpts, drawer.WithIconLegend(true))
+	}
+
+	// focus 只绘制按节点文本或点号路径选中的子树
+	if focus := r.URL.Query().Get("focus"); focus != "" {
+		drawOpts = append(drawOpts, drawer.WithFocus(focus))
+	}
+
+	// highlight 以高亮样式绘制匹配的节点，dim=true 时淡化其余节点
+	if highlight := r.URL.Query().Get("highlight"); highlight != "" {
+		drawOpts = append(drawOpts, drawer.WithHighlight(highlight), drawer.WithDimUnmatched(r.URL.Query().Get("dim") == "true"))
+	}
+
+	switch r.URL.Query().Get("background") {
+	case "", "theme":
+	case "transparent":
+		drawOpts = append(drawOpts, drawer.WithTransparentBackground())
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid background: must be theme or transparent")
+		return
+	}
+
+	// 读取请求内容：GET 请求从查询参数读取，便于直接用作链接或 <img src>
+	var body []byte
+	if r.Method == http.MethodGet {
+		var err error
+		body, err = contentFromQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(body) > maxMindmapInputBytes {
+			writeError(w, http.StatusRequestEntityTooLarge, "Input too large")
+			return
+		}
+	} else {
+		r.Body = http.MaxBytesReader(w, r.Body, maxMindmapInputBytes)
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(w, http.StatusRequestEntityTooLarge, "Input too large")
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "Failed to read request body")
+			return
+		}
+	}
+	content := string(body)
 	if strings.TrimSpace(content) == "" {
-		writeAPIError(w, http.StatusBadRequest, "Empty input content")
+		writeError(w, http.StatusBadRequest, "Empty input content")
 		return
 	}
 
-	// 解析内容
-	root, err := parser.Parse(content)
+	// 解析内容；bareUrls=true 时文本中的 http(s) 地址也作为节点链接，comments=true 时跳过注释
+	var parseOpts []parser.Option
+	if r.URL.Query().Get("bareUrls") == "true" {
+		parseOpts = append(parseOpts, parser.ParseBareURLs())
+	}
+	if r.URL.Query().Get("comments") == "true" {
+		parseOpts = append(parseOpts, parser.ParseComments())
+	}
+	root, err := parser.Parse(content, parseOpts...)
 	if err != nil {
 		log.Printf("Failed to parse input: %v", err)
-		writeAPIError(w, http.StatusBadRequest, "Failed to parse input content")
+		renderMetrics.parseError()
+		var parseErr *parser.ParseError
+		var rootsErr *parser.MultipleRootsError
+		if errors.As(err, &parseErr) || errors.As(err, &rootsErr) {
+			writeError(w, http.StatusBadRequest, "Failed to parse input content: "+err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, "Failed to parse input content")
 		return
 	}
 
-	switch media {
-	case "raw":
-		// 设置响应头，返回图像
-		w.Header().Set("Content-Type", "image/png")
+	// filter 只保留文本或备注包含该词（不区分大小写）的节点及其祖先
+	filter := strings.TrimSpace(r.URL.Query().Get("filter"))
+	if root = root.FilterText(filter); root == nil {
+		writeError(w, http.StatusBadRequest, "No nodes match filter")
+		return
+	}
+
+	// 导出所有节点链接，不生成图片
+	if format == "links" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Links []types.NodeLink `json:"links"`
+		}{Links: types.CollectLinks(root)})
+		return
+	}
 
-		// 使用指定主题生成思维导图
-		err = drawer.Draw(root, w, drawer.WithTheme(themeName), drawer.WithLayout(layout))
+	// 只返回布局计算得到的节点坐标、尺寸与换行结果，不生成图片，供客户端自行绘制
+	if media == "layout" {
+		// subtreeImages=true 时为 maxDepth 隐藏的每个子树上传完整图片，地址写入该节点的 subtreeImage
+		var uploadErr error
+		if r.URL.Query().Get("subtreeImages") == "true" {
+			if imageStore == nil {
+				writeError(w, http.StatusServiceUnavailable, "Storage not configured. Set R2_*, S3_* or LOCAL_STORAGE_DIR environment variables and restart the server.")
+				return
+			}
+			drawOpts = append(drawOpts, drawer.WithSubtreeImages(func(data []byte, contentType string) (string, error) {
+				url, err := imageStore.UploadImage(r.Context(), data, contentType)
+				if err != nil {
+					uploadErr = err
+				}
+				return url, err
+			}))
+		}
+		l, err := drawer.ComputeLayout(root, drawOpts...)
+		if uploadErr != nil {
+			log.Println("Error uploading subtree image:", uploadErr)
+			writeError(w, http.StatusInternalServerError, "Failed to upload subtree image")
+			return
+		}
 		if err != nil {
-			log.Println("Error generating mindmap:", err)
-			writeAPIError(w, http.StatusInternalServerError, "Failed to generate mindmap")
+			writeDrawError(w, err, writeError)
 			return
 		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(l)
+		return
+	}
+
+	// 选择输出格式，默认 PNG；位图在测量、布局与绘制之间响应请求取消或超时
+	draw, contentType := drawWithContext(r.Context()), "image/png"
+	switch format {
+	case "", "png":
+	case "pdf":
+		draw, contentType = drawer.DrawPDF, "application/pdf"
+	case "svg":
+		draw, contentType = drawer.DrawSVG, "image/svg+xml"
+	case "gif":
+		draw, contentType = drawer.DrawReveal, "image/gif"
+	case "jpeg", "jpg":
+		contentType = "image/jpeg"
+		drawOpts = append(drawOpts, drawer.WithFormat("jpeg"))
+		if rawQuality != "" {
+			quality, err := strconv.Atoi(rawQuality)
+			if err != nil || quality < 1 || quality > 100 {
+				writeError(w, http.StatusBadRequest, "Invalid quality: must be an integer between 1 and 100")
+				return
+			}
+			drawOpts = append(drawOpts, drawer.WithJPEGQuality(quality))
+		}
+	case "bundle":
+		// 打包源文本、解析后的主题与渲染结果，记录影响输出的全部参数，便于归档与重新渲染
+		contentType = bundle.ContentType
+		query := r.URL.Query()
+		settings := bundle.Settings{
+			Theme: themeName, Layout: layout, Align: align, Scale: scale,
+			BareURLs: query.Get("bareUrls") == "true", Comments: query.Get("comments") == "true",
+			Filter: filter, Focus: query.Get("focus"), MaxDepth: maxDepth,
+			Transparent: query.Get("background") == "transparent", RTL: query.Get("rtl") == "true",
+			Title: query.Get("title"), Caption: query.Get("caption"), Quality: qualityPreset,
+			IconLegend: query.Get("iconLegend") == "true",
+		}
+		if highlight := query.Get("highlight"); highlight != "" {
+			settings.Highlight, settings.Dim = highlight, query.Get("dim") == "true"
+		}
+		if rawMeta := query.Get("meta"); rawMeta != "" {
+			settings.Meta = strings.Split(rawMeta, ",")
+		}
+		draw = func(_ *types.Node, w io.Writer, _ ...drawer.Option) error {
+			b, err := bundle.New(body, "text", settings)
+			if err != nil {
+				return err
+			}
+			return b.Write(w)
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "Unsupported format: "+format)
+		return
+	}
+
+	// 大型导图的目录：列出一级与二级分支及其编号的小尺寸 PNG
+	if media == "toc" {
+		draw, contentType = drawer.DrawTOC, "image/png"
+	}
+	// 其他格式不支持中途取消，至少在开始绘制前检查请求是否已结束
+	draw = checkContext(r.Context(), draw)
+	// 启用指标时记录每次实际绘制（命中缓存的请求不计）
+	draw = renderMetrics.instrument(themeName, layout, draw)
 
-	case "url":
-		if r2Client == nil {
-			writeAPIError(w, http.StatusServiceUnavailable, "R2 client not configured. Set R2_* environment variables and restart the server.")
+	if media == "url" {
+		if imageStore == nil {
+			writeError(w, http.StatusServiceUnavailable, "Storage not configured. Set R2_*, S3_* or LOCAL_STORAGE_DIR environment variables and restart the server.")
 			return
 		}
 		// Generate mindmap to buffer
 		var buf bytes.Buffer
-		err = drawer.Draw(root, &buf, drawer.WithTheme(themeName), drawer.WithLayout(layout))
+		err = draw(root, &buf, drawOpts...)
 		if err != nil {
-			log.Println("Error generating mindmap:", err)
-			writeAPIError(w, http.StatusInternalServerError, "Failed to generate mindmap")
+			writeDrawError(w, err, writeError)
 			return
 		}
 
 		// 上传图片
-		url, err := r2Client.UploadImage(r.Context(), buf.Bytes(), "image/png")
+		url, err := imageStore.UploadImage(r.Context(), buf.Bytes(), contentType)
 		if err != nil {
-			log.Println("Error uploading to R2:", err)
-			writeAPIError(w, http.StatusInternalServerError, "Failed to upload mindmap")
+			log.Println("Error uploading mindmap:", err)
+			writeError(w, http.StatusInternalServerError, "Failed to upload mindmap")
 			return
 		}
 
@@ -115,17 +424,296 @@ func GenerateMindmapHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(struct {
 			URL string `json:"url"`
 		}{URL: url})
+		return
+	}
 
+	// 直接返回图片（media=raw、toc 或默认）：相同的内容与参数得到相同的 ETag，
+	// 客户端已缓存时返回 304，最近渲染过的结果直接从内存返回。
+	// 主题表版本号纳入缓存键，重新加载主题后旧结果不再命中
+	key := renderKey(content, media, format, themeName, strconv.FormatUint(manager.Generation(), 10), layout, align,
+		r.URL.Query().Get("scale"), r.URL.Query().Get("maxDepth"),
+		r.URL.Query().Get("background"), r.URL.Query().Get("quality"), r.URL.Query().Get("meta"),
+		r.URL.Query().Get("bareUrls"), r.URL.Query().Get("comments"),
+		r.URL.Query().Get("title"), r.URL.Query().Get("caption"), r.URL.Query().Get("rtl"),
+		r.URL.Query().Get("filter"), r.URL.Query().Get("highlight"), r.URL.Query().Get("dim"),
+		r.URL.Query().Get("focus"), r.URL.Query().Get("iconLegend"))
+	etag := `"` + key + `"`
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, ok := renders.get(key)
+	if !ok {
+		var buf bytes.Buffer
+		if err := draw(root, &buf, drawOpts...); err != nil {
+			writeDrawError(w, err, writeError)
+			return
+		}
+		data = buf.Bytes()
+		renders.add(key, data)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write(data)
+}
+
+// acceptTypes Accept 头中可识别的媒体类型及其对应的 format 或 media 参数。
+// 通配类型按默认的 PNG 处理。
+var acceptTypes = map[string]struct{ format, media string }{
+	"*/*":              {format: "png"},
+	"image/*":          {format: "png"},
+	"image/png":        {format: "png"},
+	"image/svg+xml":    {format: "svg"},
+	"application/pdf":  {format: "pdf"},
+	"image/jpeg":       {format: "jpeg"},
+	"image/gif":        {format: "gif"},
+	"application/json": {media: "url"},
+}
+
+// negotiateAccept 返回 Accept 头中优先级最高的可识别类型对应的 format 与 media，
+// 都无法识别时返回空值，由调用方使用默认的 PNG。
+func negotiateAccept(accept string) (format, media string) {
+	bestQ := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		target, ok := acceptTypes[strings.ToLower(strings.TrimSpace(mediaType))]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		// 同等优先级时优先默认的 PNG（浏览器的 <img> 请求通常同时接受 SVG 与 image/*），其次取先出现的类型
+		if q > bestQ || (q > 0 && q == bestQ && target.format == "png") {
+			bestQ = q
+			format, media = target.format, target.media
+		}
+	}
+	return format, media
+}
+
+// contentFromQuery 读取 GET 请求的大纲：content 为 URL 编码的文本，content_b64 为 base64 编码的文本
+func contentFromQuery(query url.Values) ([]byte, error) {
+	if query.Has("content") {
+		return []byte(query.Get("content")), nil
+	}
+	if !query.Has("content_b64") {
+		return nil, errors.New("Missing content: provide the content or content_b64 query parameter")
+	}
+	// 同时接受标准与 URL 安全的 base64，后者在链接中无需再转义
+	encoded := strings.TrimRight(query.Get("content_b64"), "=")
+	decoded, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		decoded, err = base64.RawURLEncoding.DecodeString(encoded)
+	}
+	if err != nil {
+		return nil, errors.New("Invalid content_b64: must be base64-encoded text")
+	}
+	return decoded, nil
+}
+
+// drawFunc 绘制导图并写出编码结果的函数，如 drawer.Draw、drawer.DrawSVG
+type drawFunc func(root *types.Node, w io.Writer, opts ...drawer.Option) error
+
+// drawWithContext 返回以 ctx 绘制 PNG 或 JPEG 的 drawFunc
+func drawWithContext(ctx context.Context) drawFunc {
+	return func(root *types.Node, w io.Writer, opts ...drawer.Option) error {
+		return drawer.DrawContext(ctx, root, w, opts...)
+	}
+}
+
+// checkContext 在 ctx 已结束时不调用 draw，直接返回 ctx.Err()
+func checkContext(ctx context.Context, draw drawFunc) drawFunc {
+	return func(root *types.Node, w io.Writer, opts ...drawer.Option) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return draw(root, w, opts...)
+	}
+}
+
+// statusClientClosedRequest 客户端在响应前断开连接时记录的状态码（沿用 nginx 的 499）
+const statusClientClosedRequest = 499
+
+// writeDrawError 将绘制错误转换为 API 错误响应
+func writeDrawError(w http.ResponseWriter, err error, writeError func(http.ResponseWriter, int, string)) {
+	log.Println("Error generating mindmap:", err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeError(w, http.StatusServiceUnavailable, "Rendering timed out")
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		writeError(w, statusClientClosedRequest, "Request canceled")
+		return
+	}
+	if errors.Is(err, drawer.ErrCanvasTooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "Mind map too large to render: "+err.Error())
+		return
+	}
+	if errors.Is(err, drawer.ErrFocusNotFound) {
+		writeError(w, http.StatusNotFound, "Focus node not found: "+err.Error())
+		return
+	}
+	if errors.Is(err, drawer.ErrTransparentBackground) {
+		writeError(w, http.StatusBadRequest, "Invalid background: "+err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "Failed to generate mindmap")
+}
+
+// AdminTokenEnv 管理接口的访问令牌；未设置时管理接口不可用
+const AdminTokenEnv = "MINDMAP_ADMIN_TOKEN"
+
+// authorizeAdmin 校验 Authorization: Bearer <token>，失败时写入错误响应并返回 false
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token := os.Getenv(AdminTokenEnv)
+	if token == "" {
+		writeAPIError(w, http.StatusForbidden, "Admin endpoints are disabled; set "+AdminTokenEnv+" to enable them")
+		return false
+	}
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeAPIError(w, http.StatusUnauthorized, "Invalid or missing admin token")
+		return false
+	}
+	return true
+}
+
+// ReloadThemesHandler re-reads the embedded and external themes without a
+// restart (POST /api/themes/reload). It requires the admin bearer token from
+// MINDMAP_ADMIN_TOKEN and responds with the themes now available.
+func ReloadThemesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed; use POST")
+		return
+	}
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	manager := theme.GetManager()
+	if err := manager.Reload(); err != nil {
+		log.Println("Error reloading themes:", err)
+		writeAPIError(w, http.StatusInternalServerError, "Failed to reload themes")
+		return
+	}
+	themes := manager.ListThemes()
+	sort.Strings(themes)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Themes []string `json:"themes"`
+	}{Themes: themes})
+}
+
+// ValidateThemeHandler checks a YAML theme sent as the request body
+// (POST /api/themes/validate) without loading it. It responds with
+// {"valid": bool, "problems": [...]}, listing unknown fields as well as
+// everything ThemeConfig.Validate reports. Bodies that are not YAML get 400.
+func ValidateThemeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed; use POST")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxThemeInputBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, "Theme too large")
+			return
+		}
+		writeAPIError(w, http.StatusInternalServerError, "Failed to read request body")
+		return
+	}
+	if strings.TrimSpace(string(body)) == "" {
+		writeAPIError(w, http.StatusBadRequest, "Empty theme")
+		return
+	}
+
+	// 未知字段（如拼错的键名）记为问题，其余字段仍照常解析并校验
+	var cfg theme.ThemeConfig
+	problems := []string{}
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			writeAPIError(w, http.StatusBadRequest, "Invalid YAML: "+err.Error())
+			return
+		}
+		problems = append(problems, typeErr.Errors...)
+	}
+	var verr *theme.ValidationError
+	if errors.As(cfg.Validate(), &verr) {
+		problems = append(problems, verr.Problems...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Valid    bool     `json:"valid"`
+		Problems []string `json:"problems"`
+	}{Valid: len(problems) == 0, Problems: problems})
+}
+
+// ThemePreviewHandler renders drawer.SampleTree with one theme
+// (GET /api/themes/{name}/preview) so UIs can show a thumbnail per theme.
+// media selects png (the default) or svg; unknown themes get 404.
+func ThemePreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed; use GET")
+		return
+	}
+	name := r.PathValue("name")
+	manager := theme.GetManager()
+	if _, err := manager.GetThemeStrict(name); err != nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Sprintf("Unknown theme %q", name))
+		return
+	}
+
+	media := r.URL.Query().Get("media")
+	draw, contentType := drawWithContext(r.Context()), "image/png"
+	switch media {
+	case "", "png":
+	case "svg":
+		draw, contentType = checkContext(r.Context(), drawer.DrawSVG), "image/svg+xml"
 	default:
-		// 默认返回原始图片
-		w.Header().Set("Content-Type", "image/png")
-		err = drawer.Draw(root, w, drawer.WithTheme(themeName), drawer.WithLayout(layout))
-		if err != nil {
-			log.Println("Error generating mindmap:", err)
-			writeAPIError(w, http.StatusInternalServerError, "Failed to generate mindmap")
+		writeAPIError(w, http.StatusBadRequest, "Unsupported media: "+media+"; use png or svg")
+		return
+	}
+
+	// 示例导图固定不变，预览只随主题与主题表版本变化
+	key := renderKey("", "preview", media, name, strconv.FormatUint(manager.Generation(), 10))
+	etag := `"` + key + `"`
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	data, ok := renders.get(key)
+	if !ok {
+		var buf bytes.Buffer
+		if err := draw(drawer.SampleTree(), &buf, drawer.WithTheme(name), drawer.WithScale(1)); err != nil {
+			writeDrawError(w, err, writeAPIError)
 			return
 		}
+		data = buf.Bytes()
+		renders.add(key, data)
 	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write(data)
 }
 
 // ListThemesHandler 列出所有可用主题
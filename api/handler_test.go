@@ -1,18 +1,86 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/hellodeveye/mindmapgen/internal/bundle"
+	"github.com/hellodeveye/mindmapgen/internal/storage"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+	"gopkg.in/yaml.v3"
 )
 
+// validThemeYAML 返回以 default 主题为基础、名称为 name 的完整主题 YAML
+func validThemeYAML(t *testing.T, name string) []byte {
+	t.Helper()
+	cfg, err := theme.GetManager().GetThemeStrict("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Name = name
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGenerateMindmapHandler_URLWithLocalStore(t *testing.T) {
+	prevStore, prevLocal := imageStore, localStore
+	t.Cleanup(func() {
+		imageStore, localStore = prevStore, prevLocal
+	})
+	local, err := storage.NewLocalStore(t.TempDir(), "/files/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	InitLocalStore(local)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=url", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resp.URL, "/files/") || !strings.HasSuffix(resp.URL, ".png") {
+		t.Fatalf("expected a relative URL below /files/, got %q", resp.URL)
+	}
+
+	// 返回的链接可由本地存储的文件服务访问
+	rec = httptest.NewRecorder()
+	local.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, resp.URL, nil))
+	if rec.Code != http.StatusOK || !bytes.HasPrefix(rec.Body.Bytes(), []byte("\x89PNG")) {
+		t.Fatalf("expected the stored PNG to be served, got %d", rec.Code)
+	}
+}
+
 func TestGenerateMindmapHandler_URLWithoutR2Client(t *testing.T) {
-	prevClient := r2Client
-	r2Client = nil
+	prevClient := imageStore
+	imageStore = nil
 	t.Cleanup(func() {
-		r2Client = prevClient
+		imageStore = prevClient
 	})
 
 	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=url", bytes.NewBufferString("root\n  child"))
@@ -24,8 +92,8 @@ func TestGenerateMindmapHandler_URLWithoutR2Client(t *testing.T) {
 		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
 	}
 
-	if !strings.Contains(rec.Body.String(), "R2 client not configured") {
-		t.Fatalf("expected error message to mention R2 client not configured, got %q", rec.Body.String())
+	if !strings.Contains(rec.Body.String(), "Storage not configured") {
+		t.Fatalf("expected error message to mention storage not configured, got %q", rec.Body.String())
 	}
 }
 
@@ -68,6 +136,8 @@ func TestGenerateMindmapHandler_LayoutParam(t *testing.T) {
 		{name: "both", layout: "both"},
 		{name: "left", layout: "left"},
 		{name: "right", layout: "right"},
+		{name: "down", layout: "down"},
+		{name: "up", layout: "up"},
 	}
 
 	for _, tt := range tests {
@@ -96,3 +166,995 @@ func TestGenerateMindmapHandler_LayoutParam(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateMindmapHandler_AlignParam(t *testing.T) {
+	for align, want := range map[string]int{"top": http.StatusOK, "justify": http.StatusOK, "topp": http.StatusBadRequest} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?format=svg&align="+align, bytes.NewBufferString("root\n  child"))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != want {
+			t.Errorf("align=%s: expected status %d, got %d", align, want, rec.Code)
+		}
+		if want == http.StatusBadRequest && !strings.Contains(rec.Body.String(), "center, top, justify") {
+			t.Errorf("expected the error to list the alignments, got %s", rec.Body.String())
+		}
+	}
+}
+
+func TestGenerateMindmapHandler_IconLegend(t *testing.T) {
+	render := func(query string) string {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?format=svg"+query, bytes.NewBufferString("root\n  Launch\n  ::icon(fa fa-star)"))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+	// 图例中再次列出使用该图标的节点
+	if plain, legend := strings.Count(render(""), "Launch"), strings.Count(render("&iconLegend=true"), "Launch"); legend != plain+1 {
+		t.Fatalf("expected the legend to list the icon's node once more, got %d occurrences (%d without)", legend, plain)
+	}
+}
+
+func TestGenerateMindmapHandler_ParseErrorReportsLine(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen", bytes.NewBufferString("root\n   child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	if !strings.Contains(rec.Body.String(), "line 2") {
+		t.Fatalf("expected error to mention the offending line, got %q", rec.Body.String())
+	}
+}
+
+func TestGenerateMindmapHandler_MultipleRoots(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen", bytes.NewBufferString("first\n  child\nsecond"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "2 root nodes") || !strings.Contains(rec.Body.String(), "line 3") {
+		t.Fatalf("expected error to list the root lines, got %q", rec.Body.String())
+	}
+}
+
+func TestGenerateMindmapHandler_ErrorImage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&errorImage=true", bytes.NewBufferString("root\n   child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Fatalf("expected Content-Type image/png, got %q", got)
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+
+	// 卡片内部应包含深色的文字像素
+	b := img.Bounds()
+	dark := 0
+	for y := b.Min.Y + 10; y < b.Max.Y-10; y++ {
+		for x := b.Min.X + 10; x < b.Max.X-10; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			if r < 0x8000 && g < 0x8000 && bl < 0x8000 {
+				dark++
+			}
+		}
+	}
+	if dark == 0 {
+		t.Fatal("expected the error image to contain text")
+	}
+}
+
+func TestGenerateMindmapHandler_LinksFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?format=links", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected JSON content type, got %q", got)
+	}
+	if !strings.Contains(rec.Body.String(), `"links":[]`) {
+		t.Fatalf("expected empty links list, got %q", rec.Body.String())
+	}
+}
+
+func TestGenerateMindmapHandler_LinksFormatPaths(t *testing.T) {
+	input := "Project\n  Plan\n    [Docs](https://example.com/docs)\n  Site https://example.com/site"
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?format=links&bareUrls=true", bytes.NewBufferString(input))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Links []types.NodeLink `json:"links"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	want := []types.NodeLink{
+		{Path: []string{"Project", "Plan", "Docs"}, URL: "https://example.com/docs"},
+		{Path: []string{"Project", "Site https://example.com/site"}, URL: "https://example.com/site"},
+	}
+	if !reflect.DeepEqual(resp.Links, want) {
+		t.Fatalf("expected links %+v, got %+v", want, resp.Links)
+	}
+}
+
+func TestGenerateMindmapHandler_PDFFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&format=pdf", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Fatalf("expected Content-Type application/pdf, got %q", got)
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("response is not PDF data")
+	}
+}
+
+func TestGenerateMindmapHandler_SVGFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&format=svg", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/svg+xml" {
+		t.Fatalf("expected Content-Type image/svg+xml, got %q", got)
+	}
+	if !strings.Contains(rec.Body.String(), "<svg ") {
+		t.Fatalf("response is not SVG data")
+	}
+}
+
+func TestGenerateMindmapHandler_JPEGFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&format=jpeg&quality=70&theme=dark", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/jpeg" {
+		t.Fatalf("expected Content-Type image/jpeg, got %q", got)
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte{0xff, 0xd8}) {
+		t.Fatalf("response is not JPEG data")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/gen?format=jpeg&quality=0", bytes.NewBufferString("root\n  child"))
+	rec = httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for invalid quality, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestGenerateMindmapHandler_QualityPreset(t *testing.T) {
+	sizes := make(map[string]int)
+	for _, preset := range []string{"draft", "high"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&quality="+preset, bytes.NewBufferString("root\n  child"))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", preset, http.StatusOK, rec.Code)
+		}
+		sizes[preset] = rec.Body.Len()
+	}
+	if sizes["draft"] >= sizes["high"] {
+		t.Fatalf("expected the draft PNG to be smaller than high: %v", sizes)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&quality=ultra", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for an unknown preset, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestGenerateMindmapHandler_TransparentBackground(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&background=transparent&scale=1", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("response is not PNG: %v", err)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Fatalf("expected transparent background, got alpha %d", a)
+	}
+
+	for _, query := range []string{"format=jpeg&background=transparent", "background=clear"} {
+		req = httptest.NewRequest(http.MethodPost, "/api/gen?"+query, bytes.NewBufferString("root\n  child"))
+		rec = httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "background") {
+			t.Errorf("%s: expected a background error, got %q", query, rec.Body.String())
+		}
+	}
+}
+
+func TestGenerateMindmapHandler_MaxDepthParam(t *testing.T) {
+	for query, want := range map[string]int{"maxDepth=0": http.StatusOK, "maxDepth=-1": http.StatusBadRequest, "maxDepth=x": http.StatusBadRequest} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&"+query, bytes.NewBufferString("root\n  child\n    grandchild"))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: expected status %d, got %d", query, want, rec.Code)
+		}
+	}
+}
+
+func TestGenerateMindmapHandler_BundleFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&format=bundle&theme=dark&layout=both", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+		t.Fatalf("expected Content-Type application/zip, got %q", got)
+	}
+	b, err := bundle.Read(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("response is not a bundle: %v", err)
+	}
+	if b.Manifest.Theme != "dark" || b.Manifest.Layout != "both" || string(b.Source) != "root\n  child" {
+		t.Fatalf("unexpected bundle manifest %+v", b.Manifest)
+	}
+}
+
+func TestGenerateMindmapHandler_BundleRecordsPipeline(t *testing.T) {
+	input := "Plan\n  Design\n    Mockups\n      Icons\n  Build\n    API"
+	params := "theme=dark&maxDepth=1&filter=mock&title=Q3&highlight=design&dim=true&background=transparent"
+	gen := func(format string) []byte {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw&format="+format+"&"+params, bytes.NewBufferString(input))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("format=%s: expected status %d, got %d: %s", format, http.StatusOK, rec.Code, rec.Body.String())
+		}
+		return rec.Body.Bytes()
+	}
+	archive := gen("bundle")
+	b, err := bundle.Read(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("response is not a bundle: %v", err)
+	}
+	m := b.Manifest
+	if m.Filter != "mock" || m.MaxDepth == nil || *m.MaxDepth != 1 || m.Title != "Q3" ||
+		m.Highlight != "design" || !m.Dim || !m.Transparent {
+		t.Fatalf("expected the request parameters in the manifest, got %+v", m.Settings)
+	}
+
+	// 包内的 PNG 与以相同参数直接生成的 PNG 一致，重新渲染同样一致
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("map.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundled, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bundled, gen("png")) {
+		t.Fatal("expected the bundled PNG to match the PNG rendered with the same parameters")
+	}
+	var rerendered bytes.Buffer
+	if err := b.Render(&rerendered); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rerendered.Bytes(), bundled) {
+		t.Fatal("expected re-rendering the bundle to reproduce its PNG")
+	}
+}
+
+func TestGenerateMindmapHandler_UnknownTheme(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?theme=drak", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "drak") || !strings.Contains(body, "dark") || !strings.Contains(body, "default") {
+		t.Fatalf("expected error to name the theme and list available themes, got %q", body)
+	}
+}
+
+func TestGenerateMindmapHandler_UnknownFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?format=bmp", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestGenerateMindmapHandler_ScaleParam(t *testing.T) {
+	decodeWidth := func(query string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw"+query, bytes.NewBufferString("root\n  child"))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		cfg, err := png.DecodeConfig(rec.Body)
+		if err != nil {
+			t.Fatalf("decode PNG: %v", err)
+		}
+		return cfg.Width
+	}
+
+	if small, large := decodeWidth("&scale=1"), decodeWidth("&scale=2"); large <= small {
+		t.Fatalf("expected scale=2 to be wider than scale=1, got %d and %d", large, small)
+	}
+
+	for _, scale := range []string{"0.1", "9", "abc"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?scale="+scale, bytes.NewBufferString("root\n  child"))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("scale=%s: expected status %d, got %d", scale, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func TestGenerateMindmapHandler_CanvasTooLarge(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("root\n")
+	for i := 0; i < 600; i++ {
+		fmt.Fprintf(&input, "  child %d\n", i)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=raw", strings.NewReader(input.String()))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "too large") {
+		t.Fatalf("expected readable error, got %q", rec.Body.String())
+	}
+}
+
+func TestGenerateMindmapHandler_GetContent(t *testing.T) {
+	outline := "root\n  child"
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "url-encoded", query: "content=" + url.QueryEscape(outline)},
+		{name: "base64", query: "content_b64=" + url.QueryEscape(base64.StdEncoding.EncodeToString([]byte(outline)))},
+		{name: "url-safe base64", query: "content_b64=" + base64.RawURLEncoding.EncodeToString([]byte(outline))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/gen?media=raw&theme=dark&"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			GenerateMindmapHandler(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+			}
+			if _, err := png.Decode(rec.Body); err != nil {
+				t.Fatalf("expected a PNG response: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateMindmapHandler_GetContentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   string
+	}{
+		{name: "missing", query: "theme=dark", status: http.StatusBadRequest, want: "Missing content"},
+		{name: "empty", query: "content=%20%0A", status: http.StatusBadRequest, want: "Empty input content"},
+		{name: "invalid base64", query: "content_b64=%21%21", status: http.StatusBadRequest, want: "Invalid content_b64"},
+		{
+			name:   "too large",
+			query:  "content=" + strings.Repeat("a", maxMindmapInputBytes+1),
+			status: http.StatusRequestEntityTooLarge,
+			want:   "Input too large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/gen?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			GenerateMindmapHandler(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Fatalf("expected error to mention %q, got %q", tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGenerateMindmapHandler_TOCMedia(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=toc", bytes.NewBufferString("root\n  plan\n    goals\n  build"))
+	rec := httptest.NewRecorder()
+
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Fatalf("expected image/png, got %q", ct)
+	}
+	if _, err := png.Decode(rec.Body); err != nil {
+		t.Fatalf("expected a PNG response: %v", err)
+	}
+}
+
+func TestGenerateMindmapHandler_AcceptHeader(t *testing.T) {
+	prevClient := imageStore
+	imageStore = nil
+	t.Cleanup(func() {
+		imageStore = prevClient
+	})
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{name: "svg", accept: "image/svg+xml", status: http.StatusOK, contentType: "image/svg+xml"},
+		{name: "pdf", accept: "application/pdf", status: http.StatusOK, contentType: "application/pdf"},
+		{name: "png", accept: "image/png", status: http.StatusOK, contentType: "image/png"},
+		{name: "no accept", status: http.StatusOK, contentType: "image/png"},
+		{name: "unrecognized", accept: "text/html", status: http.StatusOK, contentType: "image/png"},
+		{name: "quality values", accept: "image/png;q=0.5, application/pdf", status: http.StatusOK, contentType: "application/pdf"},
+		{name: "browser image request", accept: "image/avif,image/webp,image/svg+xml,image/*,*/*;q=0.8", status: http.StatusOK, contentType: "image/png"},
+		// 未配置 R2 时 URL 模式返回 JSON 错误，说明 Accept 选择了 media=url
+		{name: "json selects url media", accept: "application/json", status: http.StatusServiceUnavailable, contentType: "application/json"},
+		{name: "format param wins", query: "format=png", accept: "image/svg+xml", status: http.StatusOK, contentType: "image/png"},
+		{name: "media param wins", query: "media=raw", accept: "application/json", status: http.StatusOK, contentType: "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/gen?"+tt.query, bytes.NewBufferString("root\n  child"))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			GenerateMindmapHandler(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("expected Content-Type %q, got %q", tt.contentType, ct)
+			}
+		})
+	}
+}
+
+func TestGenerateMindmapHandler_ETag(t *testing.T) {
+	newRequest := func(query string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/api/gen?"+query, bytes.NewBufferString("root\n  child"))
+	}
+
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, newRequest("theme=dark"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	etag := rec.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		t.Fatalf("expected a strong quoted ETag, got %q", etag)
+	}
+
+	// 相同请求得到相同的 ETag 与内容
+	again := httptest.NewRecorder()
+	GenerateMindmapHandler(again, newRequest("theme=dark"))
+	if again.Header().Get("ETag") != etag || !bytes.Equal(again.Body.Bytes(), rec.Body.Bytes()) {
+		t.Fatalf("expected identical requests to return the same ETag and body")
+	}
+
+	req := newRequest("theme=dark")
+	req.Header.Set("If-None-Match", etag)
+	notModified := httptest.NewRecorder()
+	GenerateMindmapHandler(notModified, req)
+	if notModified.Code != http.StatusNotModified {
+		t.Fatalf("expected status %d, got %d", http.StatusNotModified, notModified.Code)
+	}
+	if notModified.Body.Len() != 0 {
+		t.Fatalf("expected an empty 304 body, got %d bytes", notModified.Body.Len())
+	}
+
+	// 改变影响输出的参数后 ETag 随之改变
+	for _, query := range []string{"theme=default", "theme=dark&layout=both", "theme=dark&scale=1", "theme=dark&format=svg"} {
+		other := httptest.NewRecorder()
+		GenerateMindmapHandler(other, newRequest(query))
+		if other.Header().Get("ETag") == etag {
+			t.Errorf("%s: expected a different ETag", query)
+		}
+	}
+}
+
+func TestReloadThemesHandler(t *testing.T) {
+	reload := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/themes/reload", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		ReloadThemesHandler(rec, req)
+		return rec
+	}
+
+	t.Setenv(AdminTokenEnv, "")
+	if rec := reload("anything"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d without a configured token, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	t.Setenv(AdminTokenEnv, "s3cret")
+	if rec := reload(""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d without credentials, got %d", http.StatusUnauthorized, rec.Code)
+	}
+	if rec := reload("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d for a wrong token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	dir := t.TempDir()
+	if err := theme.GetManager().LoadThemesFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "reload-test.yaml"), validThemeYAML(t, "Reload Test"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec := reload("s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"reload-test"`) {
+		t.Fatalf("expected the new theme in the response, got %s", rec.Body.String())
+	}
+	if _, err := theme.GetManager().GetThemeStrict("reload-test"); err != nil {
+		t.Fatalf("expected the reloaded theme to be available: %v", err)
+	}
+}
+
+func TestValidateThemeHandler(t *testing.T) {
+	validate := func(method string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/themes/validate", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		ValidateThemeHandler(rec, req)
+		return rec
+	}
+	type result struct {
+		Valid    bool     `json:"valid"`
+		Problems []string `json:"problems"`
+	}
+	decode := func(rec *httptest.ResponseRecorder) result {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var res result
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if res := decode(validate(http.MethodPost, validThemeYAML(t, "Good"))); !res.Valid || len(res.Problems) != 0 {
+		t.Errorf("expected a valid theme, got %+v", res)
+	}
+
+	bad := bytes.Replace(validThemeYAML(t, "Bad"), []byte("background: '#FFFFFF'"), []byte("background: white"), 1)
+	bad = bytes.Replace(bad, []byte("scale: 3"), []byte("scale: 0"), 1)
+	bad = append(bad, []byte("fontsize: 12\n")...)
+	res := decode(validate(http.MethodPost, bad))
+	if res.Valid || len(res.Problems) != 3 {
+		t.Fatalf("expected three problems, got %+v", res)
+	}
+	for i, want := range []string{"fontsize", "colors.background", "layout.scale"} {
+		if !strings.Contains(res.Problems[i], want) {
+			t.Errorf("problem %d = %q, want it to mention %q", i, res.Problems[i], want)
+		}
+	}
+
+	if rec := validate(http.MethodPost, []byte("name: [unterminated\n")); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid YAML, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if rec := validate(http.MethodPost, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an empty body, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if rec := validate(http.MethodGet, nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d for GET, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestThemePreviewHandler(t *testing.T) {
+	preview := func(name, query string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/themes/"+name+"/preview"+query, nil)
+		req.SetPathValue("name", name)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		ThemePreviewHandler(rec, req)
+		return rec
+	}
+
+	rec := preview("dark", "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a PNG, got %d %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	if _, err := png.Decode(rec.Body); err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	etag := rec.Header().Get("ETag")
+	if again := preview("dark", "", http.Header{"If-None-Match": {etag}}); again.Code != http.StatusNotModified {
+		t.Errorf("expected status %d for a matching ETag, got %d", http.StatusNotModified, again.Code)
+	}
+	if other := preview("default", "", nil); other.Header().Get("ETag") == etag {
+		t.Error("expected previews of different themes to have different ETags")
+	}
+
+	svg := preview("dark", "?media=svg", nil)
+	if svg.Code != http.StatusOK || !strings.Contains(svg.Body.String(), "Project") {
+		t.Fatalf("expected an SVG of the sample map, got %d: %s", svg.Code, svg.Body.String())
+	}
+	if rec := preview("dark", "?media=gif", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unsupported media, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if rec := preview("no-such-theme", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown theme, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestGenerateMindmapHandler_Canceled(t *testing.T) {
+	generate := func(ctx context.Context, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/gen?content=Plan%0A%20%20Step&"+query, nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		return rec
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithTimeout(context.Background(), -time.Second)
+	defer cancelExpired()
+	for _, query := range []string{"format=png", "format=svg"} {
+		if rec := generate(canceled, query); rec.Code != statusClientClosedRequest {
+			t.Errorf("%s: expected status %d for a canceled request, got %d", query, statusClientClosedRequest, rec.Code)
+		}
+		if rec := generate(expired, query); rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status %d after the deadline, got %d", query, http.StatusServiceUnavailable, rec.Code)
+		}
+	}
+	// 取消的渲染不写入缓存，之后的正常请求仍然成功
+	if rec := generate(context.Background(), "format=png"); rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+}
+
+// fakeStore 记录上传内容的 ImageStore
+type fakeStore struct {
+	data        []byte
+	contentType string
+	uploads     int
+}
+
+func (f *fakeStore) UploadImage(_ context.Context, data []byte, contentType string) (string, error) {
+	f.data, f.contentType = data, contentType
+	f.uploads++
+	return "https://cdn.example.com/mindmap.png", nil
+}
+
+func TestGenerateMindmapHandler_URLWithFakeStore(t *testing.T) {
+	prevStore, prevLocal := imageStore, localStore
+	t.Cleanup(func() {
+		imageStore, localStore = prevStore, prevLocal
+	})
+	fake := &fakeStore{}
+	SetImageStore(fake)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=url", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "https://cdn.example.com/mindmap.png") {
+		t.Fatalf("expected the store's URL in the response, got %s", rec.Body.String())
+	}
+	if fake.contentType != "image/png" || !bytes.HasPrefix(fake.data, []byte("\x89PNG")) {
+		t.Fatalf("expected PNG bytes to be uploaded, got %q with %d bytes", fake.contentType, len(fake.data))
+	}
+}
+
+func TestGenerateMindmapHandler_LayoutSubtreeImages(t *testing.T) {
+	prevStore, prevLocal := imageStore, localStore
+	t.Cleanup(func() {
+		imageStore, localStore = prevStore, prevLocal
+	})
+
+	// 未配置存储时无法上传子树图片
+	SetImageStore(nil)
+	content := "Plan\n  collapsed\n    hidden\n      deeper\n  leaf"
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=layout&maxDepth=1&subtreeImages=true", bytes.NewBufferString(content))
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d without storage, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	fake := &fakeStore{}
+	SetImageStore(fake)
+	req = httptest.NewRequest(http.MethodPost, "/api/gen?media=layout&maxDepth=1&subtreeImages=true", bytes.NewBufferString(content))
+	rec = httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Nodes []struct {
+			Text         string `json:"text"`
+			SubtreeImage string `json:"subtreeImage"`
+		} `json:"nodes"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	images := map[string]string{}
+	for _, n := range resp.Nodes {
+		images[n.Text] = n.SubtreeImage
+	}
+	want := map[string]string{"Plan": "", "collapsed": "https://cdn.example.com/mindmap.png", "leaf": ""}
+	if !reflect.DeepEqual(images, want) {
+		t.Fatalf("subtree images = %v, want %v", images, want)
+	}
+	if fake.uploads != 1 || fake.contentType != "image/png" || !bytes.HasPrefix(fake.data, []byte("\x89PNG")) {
+		t.Fatalf("expected one PNG upload for the collapsed subtree, got %d uploads of %q", fake.uploads, fake.contentType)
+	}
+}
+
+func TestGenerateMindmapHandler_BareURLs(t *testing.T) {
+	content := "root\n  [Docs](https://example.com/docs)\n  Site https://example.com"
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{"format=links", 1},
+		{"format=links&bareUrls=true", 2},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?"+tt.query, bytes.NewBufferString(content))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		var resp struct {
+			Links []struct {
+				URL string `json:"url"`
+			} `json:"links"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Links) != tt.want {
+			t.Errorf("%s: expected %d links, got %+v", tt.query, tt.want, resp.Links)
+		}
+	}
+}
+
+func TestGenerateMindmapHandler_Comments(t *testing.T) {
+	content := "# notes\nroot\n  child // note"
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{"", http.StatusBadRequest},
+		{"?comments=true", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen"+tt.query, bytes.NewBufferString(content))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%q: expected status %d, got %d: %s", tt.query, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestGenerateMindmapHandler_UnknownLayout(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?layout=diagonal", bytes.NewBufferString("root\n  child"))
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "available layouts: right") {
+		t.Fatalf("expected 400 listing the layouts, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGenerateMindmapHandler_Filter(t *testing.T) {
+	content := "root\n  [Design docs](https://example.com/design)\n  Build\n    [API](https://example.com/api)"
+	for _, tt := range []struct {
+		query string
+		code  int
+		links int
+	}{
+		{"format=links", http.StatusOK, 2},
+		{"format=links&filter=DESIGN", http.StatusOK, 1},
+		{"format=links&filter=api", http.StatusOK, 1},
+		{"format=links&filter=missing", http.StatusBadRequest, 0},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?"+tt.query, bytes.NewBufferString(content))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != tt.code {
+			t.Fatalf("%s: expected status %d, got %d: %s", tt.query, tt.code, rec.Code, rec.Body.String())
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			Links []struct {
+				URL string `json:"url"`
+			} `json:"links"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Links) != tt.links {
+			t.Errorf("%s: expected %d links, got %+v", tt.query, tt.links, resp.Links)
+		}
+	}
+}
+
+func TestGenerateMindmapHandler_Highlight(t *testing.T) {
+	get := func(query string) string {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?format=svg"+query, bytes.NewBufferString("root\n  设计评审\n  开发"))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected status %d, got %d: %s", query, http.StatusOK, rec.Code, rec.Body.String())
+		}
+		return rec.Body.String()
+	}
+	plain := get("")
+	if highlighted := get("&highlight=" + url.QueryEscape("设计")); highlighted == plain {
+		t.Error("expected highlight to change the output")
+	}
+	if dimmed, highlighted := get("&highlight=x&dim=true"), get("&highlight=x"); dimmed == highlighted {
+		t.Error("expected dim=true to change the output")
+	}
+}
+
+func TestGenerateMindmapHandler_LayoutMedia(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?media=layout&layout=down", bytes.NewBufferString("Plan\n  child one\n  child two"))
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected JSON content type, got %q", got)
+	}
+	var resp struct {
+		Width float64 `json:"width"`
+		Nodes []struct {
+			Text   string   `json:"text"`
+			Parent int      `json:"parent"`
+			X      float64  `json:"x"`
+			Y      float64  `json:"y"`
+			Width  float64  `json:"width"`
+			Lines  []string `json:"lines"`
+		} `json:"nodes"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Nodes) != 3 || resp.Nodes[0].Text != "Plan" || resp.Nodes[0].Parent != -1 || resp.Nodes[2].Parent != 0 {
+		t.Fatalf("unexpected nodes: %+v", resp.Nodes)
+	}
+	// 向下布局中子节点位于根节点下方
+	if resp.Nodes[1].Y <= resp.Nodes[0].Y || resp.Width <= 0 || len(resp.Nodes[1].Lines) == 0 {
+		t.Errorf("unexpected geometry: %+v", resp)
+	}
+}
+
+func TestGenerateMindmapHandler_Focus(t *testing.T) {
+	content := "Plan\n  Design\n    Mockups\n  Build"
+	for _, tt := range []struct {
+		query string
+		code  int
+		nodes int
+	}{
+		{"", http.StatusOK, 4},
+		{"&focus=design", http.StatusOK, 2},
+		{"&focus=Plan.Build", http.StatusOK, 1},
+		{"&focus=Missing", http.StatusNotFound, 0},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/gen?media=layout"+tt.query, bytes.NewBufferString(content))
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, req)
+		if rec.Code != tt.code {
+			t.Fatalf("%q: expected status %d, got %d: %s", tt.query, tt.code, rec.Code, rec.Body.String())
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			Nodes []json.RawMessage `json:"nodes"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Nodes) != tt.nodes {
+			t.Errorf("%q: expected %d nodes, got %d", tt.query, tt.nodes, len(resp.Nodes))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gen?focus=Missing", bytes.NewBufferString(content))
+	rec := httptest.NewRecorder()
+	GenerateMindmapHandler(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected a PNG request to return %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
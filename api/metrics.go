@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hellodeveye/mindmapgen/internal/drawer"
+	"github.com/hellodeveye/mindmapgen/internal/metrics"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// renderMetrics 生成接口的渲染与解析指标；未调用 EnableMetrics 时为 nil，不做任何记录
+var renderMetrics *apiMetrics
+
+// renderDurationBuckets 渲染耗时直方图的桶上界（秒），覆盖缓存外的小图到接近超时的大图
+var renderDurationBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type apiMetrics struct {
+	registry    *metrics.Registry
+	renders     *metrics.CounterVec
+	duration    *metrics.Histogram
+	bytes       *metrics.Summary
+	parseErrors *metrics.Counter
+}
+
+func newAPIMetrics() *apiMetrics {
+	reg := metrics.NewRegistry()
+	return &apiMetrics{
+		registry:    reg,
+		renders:     reg.NewCounterVec("mindmap_render_total", "Mind map renders by theme, layout and outcome (ok, error, timeout, canceled).", "theme", "layout", "status"),
+		duration:    reg.NewHistogram("mindmap_render_duration_seconds", "Time spent rendering a mind map.", renderDurationBuckets),
+		bytes:       reg.NewSummary("mindmap_render_bytes", "Size of successfully rendered output in bytes."),
+		parseErrors: reg.NewCounter("mindmap_parse_errors_total", "Inputs rejected because they could not be parsed."),
+	}
+}
+
+// EnableMetrics starts recording render and parse metrics in
+// GenerateMindmapHandler and returns the handler that serves them in the
+// Prometheus text format. Until it is called the handler records nothing.
+// Call it before serving requests.
+func EnableMetrics() http.Handler {
+	if renderMetrics == nil {
+		renderMetrics = newAPIMetrics()
+	}
+	return renderMetrics.registry
+}
+
+// MetricsHandler returns the handler returned by EnableMetrics, or nil when
+// metrics are disabled.
+func MetricsHandler() http.Handler {
+	if renderMetrics == nil {
+		return nil
+	}
+	return renderMetrics.registry
+}
+
+// parseError 记录一次解析失败
+func (m *apiMetrics) parseError() {
+	if m != nil {
+		m.parseErrors.Inc()
+	}
+}
+
+// instrument 包装 draw，记录每次绘制的结果、耗时与成功时的输出字节数；未启用指标时原样返回 draw
+func (m *apiMetrics) instrument(themeName, layout string, draw drawFunc) drawFunc {
+	if m == nil {
+		return draw
+	}
+	return func(root *types.Node, w io.Writer, opts ...drawer.Option) error {
+		cw := &countingWriter{w: w}
+		start := time.Now()
+		err := draw(root, cw, opts...)
+		m.duration.Observe(time.Since(start).Seconds())
+		m.renders.Inc(themeName, layout, renderStatus(err))
+		if err == nil {
+			m.bytes.Observe(float64(cw.n))
+		}
+		return err
+	}
+}
+
+// renderStatus 返回绘制结果对应的 status 标签值
+func renderStatus(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// enableTestMetrics 为测试启用新的指标集，结束后恢复为未启用
+func enableTestMetrics(t *testing.T) http.Handler {
+	t.Helper()
+	prev := renderMetrics
+	renderMetrics = nil
+	t.Cleanup(func() { renderMetrics = prev })
+	return EnableMetrics()
+}
+
+func TestMetricsDisabledByDefault(t *testing.T) {
+	prev := renderMetrics
+	renderMetrics = nil
+	t.Cleanup(func() { renderMetrics = prev })
+
+	if MetricsHandler() != nil {
+		t.Fatal("expected no metrics handler before EnableMetrics")
+	}
+	var m *apiMetrics
+	draw := drawWithContext(context.Background())
+	if got := m.instrument("default", "right", draw); got == nil {
+		t.Fatal("expected the draw function back")
+	}
+	m.parseError() // 未启用时不记录，也不 panic
+}
+
+func TestGenerateMindmapHandler_Metrics(t *testing.T) {
+	handler := enableTestMetrics(t)
+	if MetricsHandler() != handler {
+		t.Fatal("expected MetricsHandler to return the enabled handler")
+	}
+
+	gen := func(query, body string) int {
+		rec := httptest.NewRecorder()
+		GenerateMindmapHandler(rec, httptest.NewRequest(http.MethodPost, "/api/gen?"+query, bytes.NewBufferString(body)))
+		return rec.Code
+	}
+	if code := gen("theme=dark&layout=both&format=svg", "Metrics plan\n  Measure"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	// 相同请求命中渲染缓存，不计为新的绘制
+	if code := gen("theme=dark&layout=both&format=svg", "Metrics plan\n  Measure"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := gen("focus=missing", "Metrics plan\n  Focus"); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+	if code := gen("", "Metrics plan\n   bad indent"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", code)
+	}
+
+	m := renderMetrics
+	if got := m.renders.Value("dark", "both", "ok"); got != 1 {
+		t.Errorf("expected 1 successful render, got %v", got)
+	}
+	if got := m.renders.Value("default", "right", "error"); got != 1 {
+		t.Errorf("expected 1 failed render, got %v", got)
+	}
+	if got := m.duration.Count(); got != 2 {
+		t.Errorf("expected 2 timed renders, got %d", got)
+	}
+	if got := m.bytes.Sum(); got <= 0 {
+		t.Errorf("expected the SVG size to be recorded, got %v", got)
+	}
+	if got := m.parseErrors.Value(); got != 1 {
+		t.Errorf("expected 1 parse error, got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`mindmap_render_total{theme="dark",layout="both",status="ok"} 1`,
+		"# TYPE mindmap_render_duration_seconds histogram",
+		"mindmap_render_bytes_count 1",
+		"mindmap_parse_errors_total 1",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected %q in the exposition, got:\n%s", want, rec.Body.String())
+		}
+	}
+}
+
+func TestRenderStatus(t *testing.T) {
+	for err, want := range map[error]string{
+		nil:                      "ok",
+		context.DeadlineExceeded: "timeout",
+		context.Canceled:         "canceled",
+		errors.New("boom"):       "error",
+	} {
+		if got := renderStatus(err); got != want {
+			t.Errorf("renderStatus(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/hellodeveye/mindmapgen/internal/drawer"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
 	mindmapmcp "github.com/hellodeveye/mindmapgen/pkg/mcp"
 	sdk "github.com/mark3labs/mcp-go/server"
 )
@@ -24,6 +26,14 @@ func main() {
 
 	flag.Parse()
 
+	if err := theme.LoadThemesFromEnv(); err != nil {
+		log.Printf("failed to load user themes: %v", err)
+	}
+	// 启动时预先解析字体与主题，避免首个请求承担冷启动开销
+	if err := drawer.Warmup(); err != nil {
+		log.Printf("failed to warm up renderer: %v", err)
+	}
+
 	mcpServer := mindmapmcp.NewMindmapServer()
 
 	var opts []sdk.StreamableHTTPOption
@@ -7,6 +7,8 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/hellodeveye/mindmapgen/internal/drawer"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
 	mindmapmcp "github.com/hellodeveye/mindmapgen/pkg/mcp"
 	sdk "github.com/mark3labs/mcp-go/server"
 )
@@ -15,6 +17,14 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	if err := theme.LoadThemesFromEnv(); err != nil {
+		log.Printf("failed to load user themes: %v", err)
+	}
+	// 启动时预先解析字体与主题，避免首个请求承担冷启动开销
+	if err := drawer.Warmup(); err != nil {
+		log.Printf("failed to warm up renderer: %v", err)
+	}
+
 	mcpServer := mindmapmcp.NewMindmapServer()
 
 	stdioServer := sdk.NewStdioServer(mcpServer)
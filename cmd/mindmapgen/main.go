@@ -1,88 +1,566 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"flag"
 	"fmt"
-
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode"
 
+	"github.com/hellodeveye/mindmapgen/internal/bundle"
 	"github.com/hellodeveye/mindmapgen/internal/drawer"
 	"github.com/hellodeveye/mindmapgen/internal/parser"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
 )
 
 func main() {
 	// Define command-line flags
-	inputFile := flag.String("i", "", "Path to the input text file (e.g., -i input.md)")
-	outputFile := flag.String("o", "output.png", "Path for the output PNG image (e.g., -o mindmap.png)")
+	inputFile := flag.String("i", "", "Path to the input text file (e.g., -i input.md), or - to read standard input")
+	outputFile := flag.String("o", "", "Path for the output image (default: derived from the root node text)")
+	outDir := flag.String("out-dir", "", "Directory for the output image; relative -o paths are placed inside it")
+	watch := flag.Bool("watch", false, "Re-render the -i file to the output whenever it changes, until interrupted")
+	dir := flag.String("dir", "", "Render every .md, .txt, .opml and .json outline in this directory to an image with the same base name (in -out-dir, or next to the outline)")
 	b64 := flag.Bool("b", false, "Print the output to stdout as base64 encoded string")
 	rawStr := flag.String("raw", "", "Parse raw content to mind map")
 	themeName := flag.String("theme", "default", "Theme to use for the mind map (e.g., default, dark, business)")
-	layout := flag.String("layout", "right", "Layout direction: right, left, both")
+	layout := flag.String("layout", "right", "Layout: "+strings.Join(drawer.Layouts(), ", "))
+	align := flag.String("align", "center", "Sibling alignment: center, top, justify")
+	format := flag.String("format", "", "Output format: png, jpeg, pdf, svg, gif (level-by-level reveal animation), bundle (.mmz archive with source, theme and renders) (default: from the -o extension, else png)")
+	quality := flag.String("quality", "", "Quality preset (draft, normal, high), or the JPEG quality (1-100) with -format jpeg")
+	inputFormat := flag.String("input-format", "", "Input format: text (indented text or Mermaid), opml, json, bundle (default: detected from the -i extension)")
+	scale := flag.Float64("scale", 0, "Output scale override (default: theme scale; clamped to the theme's minScale/maxScale)")
+	rootSpacing := flag.Float64("root-spacing", 0, "Gap between the root and first-level nodes (0 = theme default, same as deeper levels)")
+	connector := flag.String("connector", "", "Connector style: bezier, straight, elbow (default: theme setting, bezier)")
+	arrows := flag.Bool("arrows", false, "Draw arrowheads at the child end of connectors")
+	textAlign := flag.String("text-align", "", "Alignment of multi-line node text: left, center, right (default: theme setting, center)")
+	metaKeys := flag.String("meta", "", "Comma-separated metadata keys to show as a footer line in each node, e.g. owner,due")
+	maxDepth := flag.Int("max-depth", -1, "Render only this many levels below the root (0 = root only, -1 = all); hidden nodes are shown as a +k badge")
+	fontFile := flag.String("font", "", "Font file for png, jpeg and gif output (.ttf, .otf, or .ttc/.otc collection)")
+	fontIndex := flag.Int("font-index", 0, "Face index within a .ttc/.otc font collection")
+	transparent := flag.Bool("transparent", false, "Leave the background transparent (png, svg and pdf only)")
+	bareURLs := flag.Bool("bare-urls", false, "Use a plain http(s) URL in node text as the node's link (svg output makes linked nodes clickable)")
+	comments := flag.Bool("comments", false, "Skip lines starting with // or # and strip trailing // notes (escape with \\# or \\//)")
+	indentWidth := flag.Int("indent", 0, "Columns of indentation per level (0 = detect from the input)")
+	tabWidth := flag.Int("tab-width", 0, "Columns a tab in the indentation expands to (0 = one level of the input's space indentation)")
+	title := flag.String("title", "", "Title drawn centered above the map")
+	caption := flag.String("caption", "", "Caption drawn centered below the map, e.g. a date or source note")
+	iconLegend := flag.Bool("icon-legend", false, "Draw a legend in the bottom right corner listing each node icon and the nodes that use it")
+	rtl := flag.Bool("rtl", false, "Lay the map out right to left for Hebrew or Arabic text (mirrored layout, bidi-ordered text; needs a -font with the glyphs)")
+	skeleton := flag.Bool("skeleton", false, "Render a fast low-fidelity preview with placeholder bars instead of text")
+	themesDir := flag.String("themes-dir", "", "Directory of additional *.yaml themes; themes with the same name override the built-in ones")
 
 	// Customize usage message
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Generates a mind map PNG from a text file with customizable themes.\n\n")
+		fmt.Fprintf(os.Stderr, "Generates a mind map PNG, JPEG, PDF, SVG or animated GIF from a text file with customizable themes.\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o output.png\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o output.png -theme dark\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -raw \"mindmap\\n  root((Main Topic))\\n    Subtopic\" -theme business\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i outline.opml -o output.png\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  cat outline.md | %s -o output.png\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o output.jpeg -quality 80\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o handout.pdf\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o map.svg -layout both -transparent\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o reveal.gif\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o map.mmz\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -format svg -layout down -scale 2 -b\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i map.mmz -o map.png\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -out-dir renders\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.md -o preview.png -watch\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -dir outlines -out-dir renders -format svg\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -i input.txt -o preview.png -skeleton\n", os.Args[0])
 	}
 
 	// Parse the flags
 	flag.Parse()
 
-	var content []byte
-	// Read input file using os.ReadFile
-	if *inputFile != "" {
-		c, err := os.ReadFile(*inputFile)
+	if *themesDir != "" {
+		if err := theme.GetManager().LoadThemesFromDir(*themesDir); err != nil {
+			log.Fatalf("Failed to load themes: %v", err)
+		}
+	}
+
+	// -format used to select the input format; keep accepting those values.
+	outputFormat := *format
+	switch outputFormat {
+	case "text", "opml", "json":
+		log.Printf("Warning: -format %s is deprecated for input formats; use -input-format %s", outputFormat, outputFormat)
+		if *inputFormat == "" {
+			*inputFormat = outputFormat
+		}
+		outputFormat = "png"
+	}
+	outputFormat, err := resolveOutputFormat(outputFormat, *outputFile)
+	if err == nil {
+		err = checkOutputOptions(outputFormat, *layout, *scale, *transparent)
+	}
+	if err == nil && *dir != "" && (*inputFile != "" || *rawStr != "" || *outputFile != "" || *b64) {
+		err = fmt.Errorf("-dir cannot be combined with -i, -raw, -o or -b")
+	}
+	if err == nil && *watch && (*inputFile == "" || *inputFile == "-" || *rawStr != "" || *b64 || *dir != "") {
+		err = fmt.Errorf("-watch needs an input file (-i) and cannot be combined with -raw, -b or -dir")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	jpegQuality := drawer.DefaultJPEGQuality
+	if *quality != "" && !drawer.IsQualityPreset(*quality) {
+		if jpegQuality, err = strconv.Atoi(*quality); err != nil {
+			log.Fatalf("Invalid -quality %q: must be draft, normal, high or an integer between 1 and 100", *quality)
+		}
+	}
+
+	// prepare parses content and collects the draw function and options for
+	// the output format. A .mmz bundle brings its own theme and render settings.
+	prepare := func(content []byte, inputFormat string) (*rendering, error) {
+		r := &rendering{draw: drawer.Draw, theme: *themeName}
+		var src *bundle.Bundle
+		var err error
+		if inputFormat == "bundle" {
+			src, err = bundle.Read(bytes.NewReader(content), int64(len(content)))
+			if err != nil {
+				return nil, fmt.Errorf("read bundle: %w", err)
+			}
+			r.root, err = src.Root()
+			r.opts = src.Options()
+			r.theme = src.Manifest.Theme
+		} else {
+			var parseOpts []parser.Option
+			if *bareURLs {
+				parseOpts = append(parseOpts, parser.ParseBareURLs())
+			}
+			if *comments {
+				parseOpts = append(parseOpts, parser.ParseComments())
+			}
+			if *indentWidth > 0 {
+				parseOpts = append(parseOpts, parser.ParseIndentWidth(*indentWidth))
+			}
+			if *tabWidth > 0 {
+				parseOpts = append(parseOpts, parser.ParseTabWidth(*tabWidth))
+			}
+			r.root, err = parser.ParseFormat(content, inputFormat, parseOpts...)
+			r.opts = []drawer.Option{drawer.WithTheme(*themeName), drawer.WithLayout(*layout), drawer.WithAlignment(*align)}
+			if *scale > 0 {
+				r.opts = append(r.opts, drawer.WithScale(*scale))
+			}
+		}
 		if err != nil {
-			log.Fatalf("Failed to read input file '%s': %v", *inputFile, err)
+			return nil, fmt.Errorf("parse input: %w", err)
+		}
+		if *skeleton {
+			r.opts = append(r.opts, drawer.WithSkeleton())
+		}
+		if *transparent {
+			r.opts = append(r.opts, drawer.WithTransparentBackground())
+		}
+		if *fontFile != "" {
+			r.opts = append(r.opts, drawer.WithFontFile(*fontFile, *fontIndex))
+		}
+		if *maxDepth >= 0 {
+			r.opts = append(r.opts, drawer.WithMaxDepth(*maxDepth))
+		}
+		if *rootSpacing > 0 {
+			r.opts = append(r.opts, drawer.WithRootLevelSpacing(*rootSpacing))
+		}
+		if drawer.IsQualityPreset(*quality) {
+			r.opts = append(r.opts, drawer.WithQuality(*quality))
+		}
+		if *connector != "" {
+			r.opts = append(r.opts, drawer.WithConnectorStyle(*connector))
+		}
+		if *textAlign != "" {
+			r.opts = append(r.opts, drawer.WithTextAlign(*textAlign))
+		}
+		if *title != "" {
+			r.opts = append(r.opts, drawer.WithTitle(*title))
+		}
+		if *caption != "" {
+			r.opts = append(r.opts, drawer.WithCaption(*caption))
+		}
+		if *arrows {
+			r.opts = append(r.opts, drawer.WithArrows(true))
 		}
-		content = c
+		if *iconLegend {
+			r.opts = append(r.opts, drawer.WithIconLegend(true))
+		}
+		if *rtl {
+			r.opts = append(r.opts, drawer.WithRTL(true))
+		} else if drawer.IsMostlyRTL(r.root) {
+			log.Printf("Input is mostly right-to-left text; pass -rtl to mirror the layout")
+		}
+		if *metaKeys != "" {
+			r.opts = append(r.opts, drawer.WithMetaKeys(strings.Split(*metaKeys, ",")...))
+		}
+
+		switch outputFormat {
+		case "pdf":
+			r.draw = drawer.DrawPDF
+		case "svg":
+			r.draw = drawer.DrawSVG
+		case "gif":
+			r.draw = drawer.DrawReveal
+		case "jpeg":
+			r.opts = append(r.opts, drawer.WithFormat("jpeg"), drawer.WithJPEGQuality(jpegQuality))
+		case "bundle":
+			if src == nil {
+				settings := bundle.Settings{
+					Theme: *themeName, Layout: *layout, Align: *align, Scale: *scale,
+					BareURLs: *bareURLs, Comments: *comments, IndentWidth: *indentWidth, TabWidth: *tabWidth,
+					Transparent: *transparent, RTL: *rtl, Title: *title, Caption: *caption,
+					Connector: *connector, TextAlign: *textAlign, Arrows: *arrows, RootSpacing: *rootSpacing,
+					IconLegend: *iconLegend,
+				}
+				if *maxDepth >= 0 {
+					settings.MaxDepth = maxDepth
+				}
+				if drawer.IsQualityPreset(*quality) {
+					settings.Quality = *quality
+				}
+				if *metaKeys != "" {
+					settings.Meta = strings.Split(*metaKeys, ",")
+				}
+				if src, err = bundle.New(content, inputFormat, settings); err != nil {
+					return nil, fmt.Errorf("create bundle: %w", err)
+				}
+			}
+			r.draw = func(_ *types.Node, w io.Writer, _ ...drawer.Option) error {
+				return src.Write(w)
+			}
+		}
+		return r, nil
+	}
+
+	ext := outputFormat
+	if outputFormat == "bundle" {
+		ext = "mmz"
+	}
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			log.Fatalf("Failed to create output directory '%s': %v", *outDir, err)
+		}
+	}
+
+	if *dir != "" {
+		failed, total, err := renderDir(*dir, *outDir, ext, func(path string, w io.Writer) error {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			format := *inputFormat
+			if format == "" {
+				format = detectInputFormat(path)
+			}
+			r, err := prepare(content, format)
+			if err != nil {
+				return err
+			}
+			return r.write(w)
+		})
+		if err != nil {
+			log.Fatalf("Failed to read directory '%s': %v", *dir, err)
+		}
+		log.Printf("Rendered %d of %d outlines in %s", total-failed, total, *dir)
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *watch {
+		if *inputFormat == "" {
+			*inputFormat = detectInputFormat(*inputFile)
+		}
+		// A failed render is logged and the previous output kept until the next save.
+		rebuild := func() {
+			content, err := os.ReadFile(*inputFile)
+			if err != nil {
+				log.Printf("Failed to read input: %v", err)
+				return
+			}
+			r, err := prepare(content, *inputFormat)
+			if err != nil {
+				log.Printf("Failed to %v", err)
+				return
+			}
+			outputPath := resolveOutputPath(*outputFile, *outDir, r.root, ext)
+			if err := renderFile(*inputFile, outputPath, func(_ string, w io.Writer) error { return r.write(w) }); err != nil {
+				log.Printf("Failed to draw mind map: %v", err)
+				return
+			}
+			log.Printf("Generated mind map at %s", outputPath)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		rebuild()
+		log.Printf("Watching %s for changes; press Ctrl-C to stop", *inputFile)
+		if err := watchFile(ctx, *inputFile, watchInterval, watchQuiet, rebuild); err != nil {
+			log.Fatalf("Failed to watch input: %v", err)
+		}
+		log.Printf("Stopped watching %s", *inputFile)
+		return
 	}
 
-	if *rawStr != "" {
-		content = []byte(*rawStr)
+	content, err := readInput(*inputFile, *rawStr, os.Stdin, stdinPiped())
+	if err != nil {
+		log.Fatalf("Failed to read input: %v", err)
 	}
 
 	if len(content) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: No input provided. Use -i for file input or -raw for direct text input.\n\n")
+		fmt.Fprintf(os.Stderr, "Error: No input provided. Use -i for file input, -raw for direct text input, -dir for a directory, or pipe the outline to standard input.\n\n")
 		flag.Usage()
 		os.Exit(1)
 	}
+	if *inputFormat == "" {
+		*inputFormat = detectInputFormat(*inputFile)
+	}
 
-	// Parse the content
-	root, err := parser.Parse(string(content))
+	r, err := prepare(content, *inputFormat)
 	if err != nil {
-		log.Fatalf("Failed to parse input: %v", err)
+		log.Fatalf("Failed to %v", err)
 	}
 
 	if *b64 {
 		w := base64.NewEncoder(base64.StdEncoding, os.Stdout)
 		defer w.Close()
-		err := drawer.Draw(root, w, drawer.WithTheme(*themeName), drawer.WithLayout(*layout))
-		if err != nil {
+		if err := r.write(w); err != nil {
 			log.Fatalf("Failed to draw mind map: %v", err)
 		}
 		return
 	}
 
-	f, err := os.Create(*outputFile)
+	outputPath := resolveOutputPath(*outputFile, *outDir, r.root, ext)
+	f, err := os.Create(outputPath)
 	if err != nil {
-		log.Fatalf("Failed to create output file '%s': %v", *outputFile, err)
+		log.Fatalf("Failed to create output file '%s': %v", outputPath, err)
 	}
 	defer f.Close()
 
 	// Draw the mind map with specified theme
-	err = drawer.Draw(root, f, drawer.WithTheme(*themeName), drawer.WithLayout(*layout))
-	if err != nil {
+	if err := r.write(f); err != nil {
 		log.Fatalf("Failed to draw mind map: %v", err)
 	}
 
-	log.Printf("Successfully generated mind map at %s using theme '%s'", *outputFile, *themeName)
+	log.Printf("Successfully generated mind map at %s using theme '%s'", outputPath, r.theme)
+}
+
+// rendering is a parsed outline ready to be drawn in the chosen output format.
+type rendering struct {
+	root  *types.Node
+	draw  func(*types.Node, io.Writer, ...drawer.Option) error
+	opts  []drawer.Option
+	theme string // theme name reported after a successful render
+}
+
+// write draws the outline to w.
+func (r *rendering) write(w io.Writer) error {
+	return r.draw(r.root, w, r.opts...)
+}
+
+// outlineExtensions lists the input files picked up by -dir.
+var outlineExtensions = map[string]bool{".md": true, ".txt": true, ".opml": true, ".json": true}
+
+// renderDir renders every outline file directly inside dir to a file with the
+// same base name and extension ext, in outDir or next to the outline when
+// outDir is empty. Each result is logged; a failing file is removed and
+// counted, and the remaining files are still rendered. It returns the number
+// of failed and matching files, or an error if dir cannot be listed.
+func renderDir(dir, outDir, ext string, render func(path string, w io.Writer) error) (failed, total int, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !outlineExtensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		total++
+		path := filepath.Join(dir, name)
+		target := outDir
+		if target == "" {
+			target = dir
+		}
+		outputPath := filepath.Join(target, strings.TrimSuffix(name, filepath.Ext(name))+"."+ext)
+		if err := renderFile(path, outputPath, render); err != nil {
+			failed++
+			log.Printf("FAILED %s: %v", path, err)
+			continue
+		}
+		log.Printf("ok     %s -> %s", path, outputPath)
+	}
+	return failed, total, nil
+}
+
+// renderFile renders the outline at path into outputPath, removing the
+// partial output if rendering fails.
+func renderFile(path, outputPath string, render func(path string, w io.Writer) error) error {
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	err = render(path, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outputPath)
+	}
+	return err
+}
+
+// resolveOutputPath returns the output path, deriving the file name from the
+// root node text when -o is not given and placing relative paths in outDir.
+func resolveOutputPath(output, outDir string, root *types.Node, format string) string {
+	if output == "" {
+		output = sanitizeFilename(root.Text) + "." + format
+	}
+	if outDir != "" && !filepath.IsAbs(output) {
+		output = filepath.Join(outDir, output)
+	}
+	return output
+}
+
+const maxFilenameRunes = 64
+
+// windowsReservedNames are device names that cannot be used as file names on Windows.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// sanitizeFilename turns arbitrary node text into a safe file name stem.
+// Letters and digits (including CJK) are kept; separators, reserved and
+// control characters collapse into single underscores.
+func sanitizeFilename(text string) string {
+	var b strings.Builder
+	lastUnderscore := false
+	count := 0
+	for _, r := range text {
+		if count >= maxFilenameRunes {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '.' {
+			b.WriteRune(r)
+			lastUnderscore = false
+		} else if !lastUnderscore {
+			b.WriteRune('_')
+			lastUnderscore = true
+		} else {
+			continue
+		}
+		count++
+	}
+
+	name := strings.Trim(b.String(), "._-")
+	if name == "" {
+		return "mindmap"
+	}
+	if windowsReservedNames[strings.ToUpper(name)] {
+		name = "_" + name
+	}
+	return name
+}
+
+// readInput returns the outline to render: raw when set, otherwise the file
+// at path, standard input when path is "-", or piped standard input when no
+// path is given. It returns no content when there is no input at all.
+func readInput(path, raw string, stdin io.Reader, piped bool) ([]byte, error) {
+	switch {
+	case raw != "":
+		return []byte(raw), nil
+	case path == "-" || (path == "" && piped):
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("standard input: %w", err)
+		}
+		return content, nil
+	case path != "":
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("input file '%s': %w", path, err)
+		}
+		return content, nil
+	}
+	return nil, nil
+}
+
+// stdinPiped reports whether standard input is a pipe or file rather than a
+// terminal.
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// outputFormats maps output file extensions to output formats.
+var outputFormats = map[string]string{
+	".png":  "png",
+	".jpg":  "jpeg",
+	".jpeg": "jpeg",
+	".pdf":  "pdf",
+	".svg":  "svg",
+	".gif":  "gif",
+	".mmz":  "bundle",
+}
+
+// resolveOutputFormat validates -format, inferring it from the extension of
+// the -o path when unset and falling back to png.
+func resolveOutputFormat(format, output string) (string, error) {
+	switch strings.ToLower(format) {
+	case "":
+		if inferred, ok := outputFormats[strings.ToLower(filepath.Ext(output))]; ok {
+			return inferred, nil
+		}
+		return "png", nil
+	case "jpg", "jpeg":
+		return "jpeg", nil
+	case "png", "pdf", "svg", "gif", "bundle":
+		return strings.ToLower(format), nil
+	}
+	return "", fmt.Errorf("unknown output format %q (expected png, jpeg, pdf, svg, gif or bundle)", format)
+}
+
+// checkOutputOptions reports flag combinations that cannot be rendered.
+func checkOutputOptions(format, layout string, scale float64, transparent bool) error {
+	if !drawer.IsLayout(layout) {
+		return fmt.Errorf("unknown layout %q (expected %s)", layout, strings.Join(drawer.Layouts(), ", "))
+	}
+	if scale < 0 {
+		return fmt.Errorf("-scale must be positive, got %g", scale)
+	}
+	if transparent && (format == "jpeg" || format == "gif") {
+		return fmt.Errorf("-transparent is not supported with %s output; use png, svg or pdf", format)
+	}
+	return nil
+}
+
+// detectInputFormat guesses the input format from the input file extension.
+func detectInputFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".opml":
+		return "opml"
+	case ".json":
+		return "json"
+	case ".mmz":
+		return "bundle"
+	default:
+		return "text"
+	}
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Project Plan", "Project_Plan"},
+		{"../etc/passwd", "etc_passwd"},
+		{`a<b>:c"d|e?f*g`, "a_b_c_d_e_f_g"},
+		{"时间管理 核心", "时间管理_核心"},
+		{"   ", "mindmap"},
+		{"con", "_con"},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeFilename(tt.in); got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolveOutputPathDerivesFromRoot(t *testing.T) {
+	root := types.NewNode("Quarterly Goals")
+
+	if got := resolveOutputPath("", "", root, "png"); got != "Quarterly_Goals.png" {
+		t.Errorf("expected name derived from root label, got %q", got)
+	}
+	if got := resolveOutputPath("", "out", root, "png"); got != filepath.Join("out", "Quarterly_Goals.png") {
+		t.Errorf("expected name inside out dir, got %q", got)
+	}
+	if got := resolveOutputPath("custom.png", "out", root, "png"); got != filepath.Join("out", "custom.png") {
+		t.Errorf("expected relative -o inside out dir, got %q", got)
+	}
+	if got := resolveOutputPath("", "", root, "pdf"); got != "Quarterly_Goals.pdf" {
+		t.Errorf("expected extension to follow the output format, got %q", got)
+	}
+}
+
+func TestDetectInputFormat(t *testing.T) {
+	tests := map[string]string{
+		"outline.OPML": "opml",
+		"map.json":     "json",
+		"map.txt":      "text",
+		"archive.mmz":  "bundle",
+		"":             "text",
+	}
+	for path, want := range tests {
+		if got := detectInputFormat(path); got != want {
+			t.Errorf("detectInputFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "outline.md")
+	if err := os.WriteFile(file, []byte("from file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, path, raw string
+		piped           bool
+		want            string
+	}{
+		{"raw wins", file, "from raw", true, "from raw"},
+		{"file", file, "", true, "from file"},
+		{"dash reads stdin", "-", "", false, "from stdin"},
+		{"piped stdin", "", "", true, "from stdin"},
+		{"terminal without input", "", "", false, ""},
+	}
+	for _, tt := range tests {
+		got, err := readInput(tt.path, tt.raw, strings.NewReader("from stdin"), tt.piped)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := readInput(filepath.Join(t.TempDir(), "missing.md"), "", strings.NewReader(""), false); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestResolveOutputFormat(t *testing.T) {
+	tests := []struct {
+		format, output, want string
+	}{
+		{"", "map.SVG", "svg"},
+		{"", "photo.jpg", "jpeg"},
+		{"", "map.mmz", "bundle"},
+		{"", "map.txt", "png"},
+		{"", "", "png"},
+		{"jpg", "out.png", "jpeg"},
+		{"pdf", "", "pdf"},
+	}
+	for _, tt := range tests {
+		if got, err := resolveOutputFormat(tt.format, tt.output); err != nil || got != tt.want {
+			t.Errorf("resolveOutputFormat(%q, %q) = %q, %v; want %q", tt.format, tt.output, got, err, tt.want)
+		}
+	}
+	if _, err := resolveOutputFormat("webp", ""); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
+
+func TestCheckOutputOptions(t *testing.T) {
+	if err := checkOutputOptions("png", "both", 2, true); err != nil {
+		t.Errorf("expected a valid combination, got %v", err)
+	}
+	for _, tt := range []struct {
+		format, layout string
+		scale          float64
+		transparent    bool
+	}{
+		{"jpeg", "right", 0, true},
+		{"gif", "right", 0, true},
+		{"png", "sideways", 0, false},
+		{"png", "right", -1, false},
+	} {
+		if err := checkOutputOptions(tt.format, tt.layout, tt.scale, tt.transparent); err == nil {
+			t.Errorf("expected %+v to be rejected", tt)
+		}
+	}
+}
+
+func TestRenderDir(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	if err := os.Mkdir(out, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"good.md":    "# Good",
+		"bad.txt":    "broken",
+		"notes.JSON": "{}",
+		"image.png":  "skip",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	render := func(path string, w io.Writer) error {
+		if filepath.Base(path) == "bad.txt" {
+			return errors.New("parse failed")
+		}
+		_, err := io.WriteString(w, "image")
+		return err
+	}
+	failed, total, err := renderDir(dir, out, "svg", render)
+	if err != nil {
+		t.Fatalf("renderDir: %v", err)
+	}
+	if failed != 1 || total != 3 {
+		t.Errorf("expected 1 of 3 outlines to fail, got %d of %d", failed, total)
+	}
+	for _, name := range []string{"good.svg", "notes.svg"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("expected %s to be rendered: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "bad.svg")); !os.IsNotExist(err) {
+		t.Errorf("expected the failed output to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "image.svg")); !os.IsNotExist(err) {
+		t.Errorf("expected non-outline files to be skipped, got %v", err)
+	}
+
+	if _, _, err := renderDir(filepath.Join(dir, "missing"), "", "png", render); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const (
+	// watchInterval is how often -watch checks the input file.
+	watchInterval = 200 * time.Millisecond
+	// watchQuiet is how long the input must stay unchanged before -watch
+	// re-renders, so that editors that save in several writes trigger one render.
+	watchQuiet = 300 * time.Millisecond
+)
+
+// watchFile polls path every interval and calls onChange once the file's
+// size or modification time has changed and then stayed the same for quiet.
+// A file that is briefly missing, as when an editor saves by renaming a new
+// copy over it, counts as unchanged until it reappears. watchFile returns nil
+// when ctx is done, or an error if path cannot be read when watching starts.
+func watchFile(ctx context.Context, path string, interval, quiet time.Duration, onChange func()) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	last := info
+	var changedAt time.Time
+	pending := false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if info, err := os.Stat(path); err == nil && (info.ModTime() != last.ModTime() || info.Size() != last.Size()) {
+				last, changedAt, pending = info, now, true
+			}
+			if pending && now.Sub(changedAt) >= quiet {
+				pending = false
+				onChange()
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchFileDebounces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map.md")
+	if err := os.WriteFile(path, []byte("# Map"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	done := make(chan error, 1)
+	go func() {
+		done <- watchFile(ctx, path, 5*time.Millisecond, 100*time.Millisecond, func() { calls.Add(1) })
+	}()
+
+	// Several quick writes, as an editor's save may produce, render once.
+	time.Sleep(20 * time.Millisecond)
+	for i, text := range []string{"# Map\n", "# Map\n- a", "# Map\n- a\n- b"} {
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if i < 2 {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected one change callback, got %d", got)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected nil after cancel, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watchFile did not return after cancel")
+	}
+
+	if err := watchFile(context.Background(), filepath.Join(t.TempDir(), "missing.md"), time.Millisecond, time.Millisecond, func() {}); err == nil {
+		t.Error("expected an error for a missing input file")
+	}
+}
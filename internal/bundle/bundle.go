@@ -0,0 +1,321 @@
+// Package bundle reads and writes .mmz bundles: zip archives that hold a
+// mind map's source outline, the resolved theme, the rendered images and a
+// manifest, so a map can be archived and re-rendered exactly later.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hellodeveye/mindmapgen/internal/drawer"
+	"github.com/hellodeveye/mindmapgen/internal/parser"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Version 当前写入的清单版本，读取时拒绝更高的版本。
+// 版本 2 记录解析、筛选与绘制的全部设置，旧版本的读取方无法忠实重现，因此提升版本
+const Version = 2
+
+// ContentType is the media type used when serving a bundle.
+const ContentType = "application/zip"
+
+// 包内固定的文件名
+const (
+	manifestFile = "manifest.json"
+	themeFile    = "theme.yaml"
+	pngFile      = "map.png"
+	svgFile      = "map.svg"
+)
+
+// Settings are the parse and render options recorded in a bundle. Together
+// with the source and the bundled theme they reproduce the original render:
+// Root applies the parse options and Filter, Options returns the rest.
+type Settings struct {
+	Theme  string  `json:"theme"`
+	Layout string  `json:"layout,omitempty"`
+	Align  string  `json:"align,omitempty"`
+	Scale  float64 `json:"scale,omitempty"`
+
+	// 解析选项，只作用于文本输入
+	BareURLs    bool `json:"bareUrls,omitempty"`
+	Comments    bool `json:"comments,omitempty"`
+	IndentWidth int  `json:"indentWidth,omitempty"`
+	TabWidth    int  `json:"tabWidth,omitempty"`
+
+	// 绘制前对节点树的选择
+	Filter   string `json:"filter,omitempty"` // 只保留文本或备注包含该词的节点及其祖先
+	Focus    string `json:"focus,omitempty"`
+	MaxDepth *int   `json:"maxDepth,omitempty"` // nil 表示不限制深度
+
+	// 绘制选项
+	Highlight   string   `json:"highlight,omitempty"`
+	Dim         bool     `json:"dim,omitempty"`
+	Transparent bool     `json:"transparent,omitempty"`
+	RTL         bool     `json:"rtl,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Caption     string   `json:"caption,omitempty"`
+	Meta        []string `json:"meta,omitempty"`
+	Quality     string   `json:"quality,omitempty"` // 质量预设：draft、normal 或 high
+	Connector   string   `json:"connector,omitempty"`
+	TextAlign   string   `json:"textAlign,omitempty"`
+	Arrows      bool     `json:"arrows,omitempty"`
+	RootSpacing float64  `json:"rootSpacing,omitempty"`
+	IconLegend  bool     `json:"iconLegend,omitempty"`
+}
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	Version     int      `json:"version"`
+	InputFormat string   `json:"inputFormat"` // text、opml 或 json
+	Source      string   `json:"source"`      // 源文件在包内的文件名
+	ThemeFile   string   `json:"themeFile"`
+	Images      []string `json:"images"`
+	Settings
+}
+
+// Bundle is a source outline together with everything needed to render it.
+type Bundle struct {
+	Manifest Manifest
+	Source   []byte
+	Theme    *theme.ThemeConfig // 已解析的主题，不依赖运行环境中同名主题
+}
+
+// New creates a bundle for source, resolving the theme named in settings
+// so later renders do not depend on the themes installed at that time.
+func New(source []byte, inputFormat string, settings Settings) (*Bundle, error) {
+	if inputFormat == "" {
+		inputFormat = "text"
+	}
+	if settings.Theme == "" {
+		settings.Theme = "default"
+	}
+	themeConfig, err := theme.GetManager().GetTheme(settings.Theme)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		Manifest: Manifest{
+			Version:     Version,
+			InputFormat: inputFormat,
+			Source:      sourceFileName(inputFormat),
+			ThemeFile:   themeFile,
+			Images:      []string{pngFile, svgFile},
+			Settings:    settings,
+		},
+		Source: source,
+		Theme:  themeConfig,
+	}
+	if _, err := b.Root(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// sourceFileName 按输入格式返回源文件名
+func sourceFileName(inputFormat string) string {
+	switch inputFormat {
+	case "opml":
+		return "source.opml"
+	case "json":
+		return "source.json"
+	default:
+		return "source.txt"
+	}
+}
+
+// ErrNoFilterMatch is returned by Root when Settings.Filter matches no node.
+var ErrNoFilterMatch = errors.New("no nodes match filter")
+
+// Root parses the bundled source into a new node tree with the recorded
+// parse options and applies Settings.Filter.
+func (b *Bundle) Root() (*types.Node, error) {
+	settings := b.Manifest.Settings
+	var parseOpts []parser.Option
+	if settings.BareURLs {
+		parseOpts = append(parseOpts, parser.ParseBareURLs())
+	}
+	if settings.Comments {
+		parseOpts = append(parseOpts, parser.ParseComments())
+	}
+	if settings.IndentWidth > 0 {
+		parseOpts = append(parseOpts, parser.ParseIndentWidth(settings.IndentWidth))
+	}
+	if settings.TabWidth > 0 {
+		parseOpts = append(parseOpts, parser.ParseTabWidth(settings.TabWidth))
+	}
+	root, err := parser.ParseFormat(b.Source, b.Manifest.InputFormat, parseOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if root = root.FilterText(settings.Filter); root == nil {
+		return nil, fmt.Errorf("%w %q", ErrNoFilterMatch, settings.Filter)
+	}
+	return root, nil
+}
+
+// Options returns the draw options that reproduce the bundled render.
+func (b *Bundle) Options() []drawer.Option {
+	settings := b.Manifest.Settings
+	opts := []drawer.Option{
+		drawer.WithTheme(settings.Theme),
+		drawer.WithThemeConfig(b.Theme),
+		drawer.WithLayout(settings.Layout),
+		drawer.WithAlignment(settings.Align),
+	}
+	if settings.Scale > 0 {
+		opts = append(opts, drawer.WithScale(settings.Scale))
+	}
+	if settings.Focus != "" {
+		opts = append(opts, drawer.WithFocus(settings.Focus))
+	}
+	if settings.MaxDepth != nil {
+		opts = append(opts, drawer.WithMaxDepth(*settings.MaxDepth))
+	}
+	if settings.Highlight != "" {
+		opts = append(opts, drawer.WithHighlight(settings.Highlight), drawer.WithDimUnmatched(settings.Dim))
+	}
+	if settings.Transparent {
+		opts = append(opts, drawer.WithTransparentBackground())
+	}
+	if settings.RTL {
+		opts = append(opts, drawer.WithRTL(true))
+	}
+	if settings.IconLegend {
+		opts = append(opts, drawer.WithIconLegend(true))
+	}
+	if settings.Title != "" {
+		opts = append(opts, drawer.WithTitle(settings.Title))
+	}
+	if settings.Caption != "" {
+		opts = append(opts, drawer.WithCaption(settings.Caption))
+	}
+	if len(settings.Meta) > 0 {
+		opts = append(opts, drawer.WithMetaKeys(settings.Meta...))
+	}
+	if settings.Quality != "" {
+		opts = append(opts, drawer.WithQuality(settings.Quality))
+	}
+	if settings.Connector != "" {
+		opts = append(opts, drawer.WithConnectorStyle(settings.Connector))
+	}
+	if settings.TextAlign != "" {
+		opts = append(opts, drawer.WithTextAlign(settings.TextAlign))
+	}
+	if settings.Arrows {
+		opts = append(opts, drawer.WithArrows(true))
+	}
+	if settings.RootSpacing > 0 {
+		opts = append(opts, drawer.WithRootLevelSpacing(settings.RootSpacing))
+	}
+	return opts
+}
+
+// Render draws the bundled map as a PNG image to w.
+func (b *Bundle) Render(w io.Writer) error {
+	root, err := b.Root()
+	if err != nil {
+		return err
+	}
+	return drawer.Draw(root, w, b.Options()...)
+}
+
+// Write renders the map and writes the bundle as a zip archive to w.
+func (b *Bundle) Write(w io.Writer) error {
+	// 每种输出各自解析一次，避免绘制时对节点树的修改相互影响
+	var png, svg bytes.Buffer
+	if err := b.Render(&png); err != nil {
+		return err
+	}
+	root, err := b.Root()
+	if err != nil {
+		return err
+	}
+	if err := drawer.DrawSVG(root, &svg, b.Options()...); err != nil {
+		return err
+	}
+
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	themeData, err := yaml.Marshal(b.Theme)
+	if err != nil {
+		return fmt.Errorf("failed to encode theme: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{manifestFile, manifest},
+		{b.Manifest.Source, b.Source},
+		{b.Manifest.ThemeFile, themeData},
+		{pngFile, png.Bytes()},
+		{svgFile, svg.Bytes()},
+	}
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(file.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Read loads a bundle written by Write. The rendered images are not loaded;
+// use Render to reproduce them.
+func Read(r io.ReaderAt, size int64) (*Bundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a bundle: %w", err)
+	}
+
+	var b Bundle
+	data, err := readFile(zr, manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if b.Manifest.Version < 1 || b.Manifest.Version > Version {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Manifest.Version)
+	}
+
+	if b.Source, err = readFile(zr, b.Manifest.Source); err != nil {
+		return nil, err
+	}
+	data, err = readFile(zr, b.Manifest.ThemeFile)
+	if err != nil {
+		return nil, err
+	}
+	b.Theme = &theme.ThemeConfig{}
+	if err := yaml.Unmarshal(data, b.Theme); err != nil {
+		return nil, fmt.Errorf("invalid bundled theme: %w", err)
+	}
+	return &b, nil
+}
+
+// readFile 读取包内指定文件的全部内容
+func readFile(zr *zip.Reader, name string) ([]byte, error) {
+	if name == "" {
+		return nil, errors.New("bundle manifest is missing a file name")
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("bundle is missing %s: %w", name, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,159 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/internal/drawer"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	custom := &theme.ThemeConfig{Name: "Bundle Test", Colors: theme.ColorConfig{Background: "#102030"}}
+	custom.Layout = mustTheme(t, "default").Layout
+	custom.NodeStyles = mustTheme(t, "dark").NodeStyles
+	if err := theme.Register("bundle-test", custom); err != nil {
+		t.Fatal(err)
+	}
+
+	source := []byte("mindmap\n  root((Plan))\n    Research\n      Papers\n    Build")
+	b, err := New(source, "", Settings{Theme: "bundle-test", Layout: "both", Scale: 1})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var archive bytes.Buffer
+	if err := b.Write(&archive); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// 替换同名主题，验证重新渲染只依赖包内保存的主题
+	if err := theme.Register("bundle-test", &theme.ThemeConfig{Name: "Replaced"}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Read(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if loaded.Manifest.Layout != "both" || loaded.Manifest.InputFormat != "text" || string(loaded.Source) != string(source) {
+		t.Fatalf("unexpected bundle contents: %+v", loaded.Manifest)
+	}
+	if loaded.Theme.Colors.Background != "#102030" {
+		t.Fatalf("expected bundled theme, got %+v", loaded.Theme.Colors)
+	}
+
+	var rerendered bytes.Buffer
+	if err := loaded.Render(&rerendered); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want := zipFile(t, archive.Bytes(), pngFile); !bytes.Equal(rerendered.Bytes(), want) {
+		t.Fatal("expected re-rendering the bundle to reproduce the bundled PNG")
+	}
+	if svg := zipFile(t, archive.Bytes(), svgFile); !bytes.Contains(svg, []byte("<svg ")) {
+		t.Fatal("expected the bundle to contain an SVG rendering")
+	}
+}
+
+func TestBundleRoundTripSelection(t *testing.T) {
+	source := []byte("Plan\n  Design\n    Mockups\n      Icons\n  Build\n    API")
+	depth := 1
+	settings := Settings{Theme: "default", Filter: "MOCK", MaxDepth: &depth, Scale: 1}
+	b, err := New(source, "text", settings)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var archive bytes.Buffer
+	if err := b.Write(&archive); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	loaded, err := Read(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if loaded.Manifest.Filter != "MOCK" || loaded.Manifest.MaxDepth == nil || *loaded.Manifest.MaxDepth != 1 {
+		t.Fatalf("expected filter and maxDepth in the manifest, got %+v", loaded.Manifest.Settings)
+	}
+
+	// 过滤在重新加载后同样生效：只剩 Plan > Design > Mockups > Icons
+	root, err := loaded.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root.Children) != 1 || root.Children[0].Text != "Design" {
+		t.Fatalf("expected the filter to drop Build, got %d children", len(root.Children))
+	}
+
+	// 重新渲染与包内 PNG 一致，且与以相同选项直接绘制的结果一致
+	var rerendered, direct bytes.Buffer
+	if err := loaded.Render(&rerendered); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	bundled := zipFile(t, archive.Bytes(), pngFile)
+	if !bytes.Equal(rerendered.Bytes(), bundled) {
+		t.Fatal("expected re-rendering the bundle to reproduce the bundled PNG")
+	}
+	if err := drawer.Draw(root, &direct, drawer.WithTheme("default"), drawer.WithMaxDepth(1), drawer.WithScale(1)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(direct.Bytes(), bundled) {
+		t.Fatal("expected the bundled PNG to apply maxDepth to the filtered tree")
+	}
+
+	var full bytes.Buffer
+	unfiltered, _ := New(source, "text", Settings{Theme: "default", Scale: 1})
+	if err := unfiltered.Render(&full); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(full.Bytes(), bundled) {
+		t.Fatal("expected filter and maxDepth to change the render")
+	}
+
+	if _, err := New(source, "text", Settings{Filter: "missing"}); !errors.Is(err, ErrNoFilterMatch) {
+		t.Fatalf("expected ErrNoFilterMatch, got %v", err)
+	}
+}
+
+func TestReadRejectsInvalidBundles(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("not a zip")), 9); err == nil {
+		t.Error("expected an error for non-zip input")
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	fw, _ := zw.Create(manifestFile)
+	fw.Write([]byte(`{"version": 99, "source": "source.txt", "themeFile": "theme.yaml"}`))
+	zw.Close()
+	if _, err := Read(bytes.NewReader(archive.Bytes()), int64(archive.Len())); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
+
+func mustTheme(t *testing.T, name string) *theme.ThemeConfig {
+	t.Helper()
+	cfg, err := theme.GetManager().GetThemeStrict(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func zipFile(t *testing.T, archive []byte, name string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
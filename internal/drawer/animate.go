@@ -0,0 +1,83 @@
+package drawer
+
+import (
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// DefaultFrameDelay 逐层展开动画中每一帧的默认停留时间
+const DefaultFrameDelay = 800 * time.Millisecond
+
+// DrawReveal renders the mind map as an animated GIF that reveals it level by
+// level: the first frame shows only the root, each following frame adds one
+// more depth level, and the last frame shows the whole map. All frames share
+// the layout of the full map, so nodes never move between frames.
+func DrawReveal(rootNode *types.Node, w io.Writer, options ...Option) error {
+	r := NewRenderer(options...)
+	if r.opts.transparent {
+		return ErrTransparentBackground
+	}
+	if r.opts.err != nil {
+		return r.opts.err
+	}
+	if r.fontErr != nil {
+		return r.fontErr
+	}
+	rootNode, err := r.focusRoot(rootNode)
+	if err != nil {
+		return err
+	}
+	l := r.layout(rootNode)
+	pixelWidth, pixelHeight, err := r.rasterSize(l)
+	if err != nil {
+		return err
+	}
+
+	depth := l.root.Depth()
+	delay := int(r.opts.frameDelay / (10 * time.Millisecond)) // GIF 延迟以 1/100 秒计
+	anim := &gif.GIF{}
+
+	for level := 0; level <= depth; level++ {
+		frameLayout := l.toDepth(level)
+		// 每帧重置手绘随机源，使已出现的连接线与节点尽量保持稳定
+		if frameLayout.config.isSketch() {
+			frameLayout.config.rng = rand.New(rand.NewSource(frameLayout.config.Theme.SketchConfig.Seed))
+		}
+
+		dc := r.newRasterContext(frameLayout.config, pixelWidth, pixelHeight)
+		paintMindmap(dc, frameLayout)
+
+		frame := image.NewPaletted(image.Rect(0, 0, pixelWidth, pixelHeight), palette.Plan9)
+		// 不做抖动，映射到最近的调色板颜色，避免深色节点上的文字被噪点淹没
+		draw.Draw(frame, frame.Bounds(), dc.Image(), image.Point{}, draw.Src)
+
+		frameDelay := delay
+		if level == depth {
+			frameDelay = delay * 3 // 完整导图多停留一会再循环
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, frameDelay)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// toDepth 返回只包含深度不超过 depth 的节点的布局副本，节点位置保持不变
+func (l *mindmapLayout) toDepth(depth int) *mindmapLayout {
+	config := *l.config
+	visible := make(map[*types.Node]*NodeSize)
+	l.root.Walk(func(node *types.Node, level int) bool {
+		if size, ok := l.nodeSizes[node]; ok && level <= depth {
+			visible[node] = size
+		}
+		return true
+	})
+	return &mindmapLayout{root: l.root, config: &config, nodeSizes: visible, bounds: l.bounds, hidden: l.hidden, collapsed: l.collapsed, breadcrumb: l.breadcrumb, title: l.title, caption: l.caption, legend: l.legend}
+}
@@ -0,0 +1,39 @@
+package drawer
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestDrawReveal(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{
+		{Text: "A", Children: []*types.Node{{Text: "A1", Children: []*types.Node{{Text: "A1a"}}}}},
+		{Text: "B"},
+	}}
+
+	var buf bytes.Buffer
+	if err := DrawReveal(root, &buf, WithScale(1)); err != nil {
+		t.Fatalf("DrawReveal failed: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("output is not a GIF: %v", err)
+	}
+
+	// 深度 0-3 各一帧
+	if len(anim.Image) != 4 {
+		t.Fatalf("expected 4 frames, got %d", len(anim.Image))
+	}
+	size := anim.Image[0].Bounds()
+	for i, frame := range anim.Image {
+		if frame.Bounds() != size {
+			t.Errorf("frame %d has bounds %v, want %v", i, frame.Bounds(), size)
+		}
+	}
+	if bytes.Equal(anim.Image[0].Pix, anim.Image[len(anim.Image)-1].Pix) {
+		t.Error("expected the first and last frames to differ")
+	}
+}
@@ -0,0 +1,40 @@
+package drawer
+
+import "math"
+
+// 箭头尺寸（未缩放）：沿连接线方向的长度与底边宽度
+const (
+	arrowLength = 8.0
+	arrowWidth  = 6.0
+)
+
+// arrowDirection 返回连接线末端切线方向的单位向量。曲线的末端切线由第二个控制点指向终点，
+// 直线与折线为最后一段的方向；末段长度为零时改用起点到终点的方向，起止点也重合时返回 false
+func arrowDirection(style string, startX, startY, endX, endY, bend float64, vertical bool) (dx, dy float64, ok bool) {
+	_, _, c2x, c2y := connectorControls(startX, startY, endX, endY, bend, vertical)
+	if points := connectorPolyline(style, startX, startY, endX, endY, bend, vertical); points != nil {
+		c2x, c2y = points[len(points)-2].X, points[len(points)-2].Y
+	}
+	dx, dy = endX-c2x, endY-c2y
+	if math.Hypot(dx, dy) < 1e-9 {
+		dx, dy = endX-startX, endY-startY
+	}
+	length := math.Hypot(dx, dy)
+	if length < 1e-9 {
+		return 0, 0, false
+	}
+	return dx / length, dy / length, true
+}
+
+// drawArrowhead 以当前颜色绘制尖端位于 (tipX, tipY)、指向 (dx, dy) 的实心三角形
+func drawArrowhead(dc canvas, tipX, tipY, dx, dy, scale float64) {
+	length, half := arrowLength*scale, arrowWidth*scale/2
+	baseX, baseY := tipX-dx*length, tipY-dy*length
+	// 法向量 (-dy, dx) 给出底边两端
+	dc.NewSubPath()
+	dc.MoveTo(tipX, tipY)
+	dc.LineTo(baseX-dy*half, baseY+dx*half)
+	dc.LineTo(baseX+dy*half, baseY-dx*half)
+	dc.ClosePath()
+	dc.Fill()
+}
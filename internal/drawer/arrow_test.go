@@ -0,0 +1,89 @@
+package drawer
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestArrowDirection(t *testing.T) {
+	tests := []struct {
+		name                       string
+		startX, startY, endX, endY float64
+		vertical                   bool
+		wantDX, wantDY             float64
+	}{
+		{name: "right", startX: 0, startY: 0, endX: 100, endY: 40, wantDX: 1},
+		{name: "left", startX: 0, startY: 0, endX: -100, endY: -40, wantDX: -1},
+		{name: "down", startX: 0, startY: 0, endX: 40, endY: 100, vertical: true, wantDY: 1},
+		{name: "straight up", startX: 0, startY: 0, endX: 0, endY: -100, vertical: true, wantDY: -1},
+	}
+	for _, tt := range tests {
+		dx, dy, ok := arrowDirection("", tt.startX, tt.startY, tt.endX, tt.endY, defaultBend, tt.vertical)
+		if !ok || math.Abs(dx-tt.wantDX) > 1e-9 || math.Abs(dy-tt.wantDY) > 1e-9 {
+			t.Errorf("%s: arrowDirection() = (%v, %v, %v), want (%v, %v)", tt.name, dx, dy, ok, tt.wantDX, tt.wantDY)
+		}
+	}
+	if _, _, ok := arrowDirection("", 5, 5, 5, 5, defaultBend, false); ok {
+		t.Error("expected no direction for a zero-length connector")
+	}
+}
+
+func TestDrawArrows(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{
+		{Text: "Branch", Children: []*types.Node{{Text: "Leaf"}}},
+		{Text: "Other"},
+	}}
+	countTriangles := func(options ...Option) int {
+		var buf bytes.Buffer
+		if err := DrawSVG(root, &buf, options...); err != nil {
+			t.Fatalf("DrawSVG failed: %v", err)
+		}
+		// 箭头是仅含两条直线的闭合路径
+		n := 0
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.Contains(line, "<path") && strings.Count(line, "L") == 2 && strings.Contains(line, "Z") && !strings.Contains(line, "C") {
+				n++
+			}
+		}
+		return n
+	}
+
+	if got := countTriangles(); got != 0 {
+		t.Fatalf("expected no arrows by default, got %d", got)
+	}
+	if got := countTriangles(WithArrows(true)); got != 3 {
+		t.Fatalf("expected one arrow per connector, got %d", got)
+	}
+
+	cfg, err := theme.GetManager().GetThemeStrict("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withArrows := *cfg
+	withArrows.Layout.Arrows = true
+	if got := countTriangles(WithThemeConfig(&withArrows)); got != 3 {
+		t.Fatalf("expected the theme to enable arrows, got %d", got)
+	}
+	if got := countTriangles(WithThemeConfig(&withArrows), WithArrows(false)); got != 0 {
+		t.Fatalf("expected WithArrows(false) to override the theme, got %d", got)
+	}
+}
+
+func TestArrowsEndLeafConnectorsAtBox(t *testing.T) {
+	parent, leaf := &types.Node{X: 0, Y: 0}, &types.Node{X: 200, Y: 50}
+	parentSize := &NodeSize{Width: 100, Height: 40}
+	leafSize := &NodeSize{Width: 120, Height: 40, ActualTextWidth: 60}
+	config := &DrawConfig{Scale: 1}
+
+	_, _, trimmedX, _ := horizontalConnectionPoints(parent, leaf, parentSize, leafSize, config)
+	config.Arrows = true
+	_, _, endX, _ := horizontalConnectionPoints(parent, leaf, parentSize, leafSize, config)
+	if want := leaf.X - leafSize.Width/2; endX != want || trimmedX <= want {
+		t.Fatalf("expected arrows to end at the box edge %v (trimmed end %v), got %v", want, trimmedX, endX)
+	}
+}
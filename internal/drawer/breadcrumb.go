@@ -0,0 +1,97 @@
+package drawer
+
+import (
+	"math"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// breadcrumbAlpha 面包屑文本与连接线的不透明度，使其弱于导图本身
+const breadcrumbAlpha = 0.4
+
+// breadcrumb 子树渲染时在根节点外侧绘制的父节点标签与连接线残段（布局单位）
+type breadcrumb struct {
+	label         string
+	x, y          float64 // 标签中心
+	width, height float64 // 标签占用的区域
+	textWidth     float64
+	stubX, stubY  float64 // 连接线残段在根节点边缘的终点
+}
+
+// placeBreadcrumb 按布局方向把父节点标签放在根节点的来向一侧：
+// 向右生长时在左侧，向左生长时在右侧，向下生长（以及两侧布局）时在上方，向上生长时在下方。
+func placeBreadcrumb(label string, textWidth float64, root *types.Node, rootSize *NodeSize, config *DrawConfig) *breadcrumb {
+	b := &breadcrumb{
+		label:     label,
+		width:     textWidth,
+		height:    config.LineHeight,
+		textWidth: textWidth,
+	}
+	gap := config.LevelSpacing / 2
+	// 从右到左时布局已镜像，左右两侧互换
+	side := config.Layout
+	if config.RTL && side == "left" {
+		side = "right"
+	} else if config.RTL && (side == "right" || side == "") {
+		side = "left"
+	}
+	switch side {
+	case "left":
+		b.stubX, b.stubY = root.X+rootSize.Width/2, root.Y
+		b.x, b.y = b.stubX+gap+b.width/2, root.Y
+	case "up":
+		b.stubX, b.stubY = root.X, root.Y+rootSize.Height/2
+		b.x, b.y = root.X, b.stubY+gap+b.height/2
+	case "down", "both":
+		b.stubX, b.stubY = root.X, root.Y-rootSize.Height/2
+		b.x, b.y = root.X, b.stubY-gap-b.height/2
+	default:
+		b.stubX, b.stubY = root.X-rootSize.Width/2, root.Y
+		b.x, b.y = b.stubX-gap-b.width/2, root.Y
+	}
+	return b
+}
+
+// extend 扩展边界以包含面包屑标签
+func (b *breadcrumb) extend(bounds *Bounds) {
+	bounds.MinX = math.Min(bounds.MinX, b.x-b.width/2)
+	bounds.MaxX = math.Max(bounds.MaxX, b.x+b.width/2)
+	bounds.MinY = math.Min(bounds.MinY, b.y-b.height/2)
+	bounds.MaxY = math.Max(bounds.MaxY, b.y+b.height/2)
+}
+
+// drawBreadcrumb 以淡色绘制父节点标签，以及从标签指向根节点的连接线残段
+func drawBreadcrumb(dc canvas, b *breadcrumb, config *DrawConfig) {
+	dc.Push()
+	defer dc.Pop()
+
+	scale := config.Scale
+	line := config.ConnectionLineColor
+	dc.SetRGBA(line[0], line[1], line[2], breadcrumbAlpha)
+
+	// 残段从标签边缘留出少许间距后开始
+	pad := config.LineHeight / 4
+	fromX, fromY := b.x, b.y
+	switch {
+	case b.stubX > b.x:
+		fromX = b.x + b.width/2 + pad
+	case b.stubX < b.x:
+		fromX = b.x - b.width/2 - pad
+	case b.stubY > b.y:
+		fromY = b.y + b.height/2 + pad
+	default:
+		fromY = b.y - b.height/2 - pad
+	}
+	dc.SetLineWidth(1.0 * scale)
+	dc.MoveTo(fromX*scale, fromY*scale)
+	dc.LineTo(b.stubX*scale, b.stubY*scale)
+	dc.Stroke()
+
+	if config.skeleton {
+		h := config.FontSize * 0.5 * scale
+		drawRoundedRect(dc, (b.x-b.textWidth/2)*scale, b.y*scale-h/2, b.textWidth*scale, h, h/2)
+		dc.Fill()
+		return
+	}
+	drawText(dc, config, b.label, b.x*scale, b.y*scale, 0.5, 0.5)
+}
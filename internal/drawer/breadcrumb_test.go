@@ -0,0 +1,62 @@
+package drawer
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestBreadcrumb(t *testing.T) {
+	subtree := &types.Node{Text: "Research", Children: []*types.Node{{Text: "Papers"}, {Text: "Talks"}}}
+
+	r := NewRenderer(WithScale(1), WithBreadcrumb("Project Plan"))
+	l := r.layout(subtree)
+	crumb := l.breadcrumb
+	if crumb == nil {
+		t.Fatal("expected a breadcrumb in the layout")
+	}
+	rootSize := l.nodeSizes[l.root]
+	if crumb.x+crumb.width/2 >= l.root.X-rootSize.Width/2 || crumb.y != l.root.Y {
+		t.Fatalf("expected the parent label left of the root, got %+v", crumb)
+	}
+	if l.bounds.MinX > crumb.x-crumb.width/2 {
+		t.Fatalf("expected bounds %+v to include the parent label", l.bounds)
+	}
+
+	var withCrumb, without bytes.Buffer
+	if err := r.Render(subtree, &withCrumb); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if err := Draw(subtree, &without, WithScale(1)); err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(withCrumb.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := png.DecodeConfig(&without)
+	if img.Bounds().Dx() <= plain.Width {
+		t.Fatalf("expected the parent label to widen the image, got %d <= %d", img.Bounds().Dx(), plain.Width)
+	}
+
+	// 残段中点应被淡色连接线覆盖，而非背景色
+	midX := (crumb.x+crumb.width/2+crumb.stubX)/2 - l.bounds.MinX
+	midY := crumb.stubY - l.bounds.MinY
+	if r, g, b, _ := img.At(int(midX), int(midY)).RGBA(); r == 0xffff && g == 0xffff && b == 0xffff {
+		t.Fatal("expected a connector stub between the parent label and the root")
+	}
+
+	if NewRenderer(WithBreadcrumb("  ")).layout(subtree).breadcrumb != nil {
+		t.Fatal("expected a blank label to draw no breadcrumb")
+	}
+}
+
+func TestBreadcrumbVertical(t *testing.T) {
+	subtree := &types.Node{Text: "Research", Children: []*types.Node{{Text: "Papers"}}}
+	l := NewRenderer(WithLayout("down"), WithBreadcrumb("Plan")).layout(subtree)
+	if l.breadcrumb.y >= l.root.Y || l.breadcrumb.x != l.root.X {
+		t.Fatalf("expected the parent label above the root in a downward layout, got %+v", l.breadcrumb)
+	}
+}
@@ -0,0 +1,35 @@
+package drawer
+
+// canvas 绘图表面。*gg.Context 直接满足该接口，PDF 输出使用 pdfCanvas，
+// 使同一套布局与绘制流程可以输出位图或矢量文档。
+type canvas interface {
+	SetRGB(r, g, b float64)
+	SetRGBA(r, g, b, a float64)
+	SetLineWidth(lineWidth float64)
+
+	NewSubPath()
+	MoveTo(x, y float64)
+	LineTo(x, y float64)
+	CubicTo(x1, y1, x2, y2, x3, y3 float64)
+	ClosePath()
+	DrawArc(x, y, r, angle1, angle2 float64)
+	DrawCircle(x, y, r float64)
+	DrawEllipse(x, y, rx, ry float64)
+	DrawRectangle(x, y, w, h float64)
+	Fill()
+	Stroke()
+
+	Push()
+	Pop()
+	Translate(x, y float64)
+
+	MeasureString(s string) (w, h float64)
+	DrawStringAnchored(s string, x, y, ax, ay float64)
+}
+
+// linkCanvas 支持超链接的绘制面：beginLink 成功后、endLink 之前绘制的内容可点击打开 url。
+// 目前仅 SVG 实现，位图与 PDF 输出忽略节点链接。
+type linkCanvas interface {
+	beginLink(url string) bool
+	endLink()
+}
@@ -0,0 +1,72 @@
+package drawer
+
+import (
+	"strings"
+
+	"github.com/fogleman/gg"
+)
+
+// 连接线样式：贝塞尔 S 形曲线（默认）、直线与直角折线
+const (
+	connectorBezier   = "bezier"
+	connectorStraight = "straight"
+	connectorElbow    = "elbow"
+)
+
+// isConnectorStyle 判断是否为支持的连接线样式
+func isConnectorStyle(style string) bool {
+	switch style {
+	case connectorBezier, connectorStraight, connectorElbow:
+		return true
+	}
+	return false
+}
+
+// WithConnectorStyle selects how connectors are drawn, overriding the theme's
+// layout.connectorStyle: "bezier" (the default S-curve), "straight" lines, or
+// "elbow" connectors that run along the layout direction, turn at a right
+// angle to the child's row or column, and turn again into the child, as in
+// org charts. Unknown styles are ignored.
+func WithConnectorStyle(style string) Option {
+	return func(opts *drawOptions) {
+		if style = strings.ToLower(strings.TrimSpace(style)); isConnectorStyle(style) {
+			opts.connectorStyle = style
+		}
+	}
+}
+
+// connectorPolyline 返回直线与折线连接线的顶点；折线在 bend 对应的位置沿垂直于布局方向转折。
+// 贝塞尔样式返回 nil
+func connectorPolyline(style string, startX, startY, endX, endY, bend float64, vertical bool) []gg.Point {
+	switch style {
+	case connectorStraight:
+		return []gg.Point{{X: startX, Y: startY}, {X: endX, Y: endY}}
+	case connectorElbow:
+		if vertical {
+			midY := startY + (endY-startY)*bend
+			return []gg.Point{{X: startX, Y: startY}, {X: startX, Y: midY}, {X: endX, Y: midY}, {X: endX, Y: endY}}
+		}
+		midX := startX + (endX-startX)*bend
+		return []gg.Point{{X: startX, Y: startY}, {X: midX, Y: startY}, {X: midX, Y: endY}, {X: endX, Y: endY}}
+	}
+	return nil
+}
+
+// connectorSamples 返回沿连接线分布的采样点，用于检测连接线是否穿过节点框
+func connectorSamples(style string, startX, startY, endX, endY, bend float64, vertical bool) []gg.Point {
+	points := connectorPolyline(style, startX, startY, endX, endY, bend, vertical)
+	if points == nil {
+		c1x, c1y, c2x, c2y := connectorControls(startX, startY, endX, endY, bend, vertical)
+		return gg.CubicBezier(startX, startY, c1x, c1y, c2x, c2y, endX, endY)
+	}
+	const steps = 16 // 每段的采样数
+	samples := []gg.Point{points[0]}
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / steps
+			samples = append(samples, gg.Point{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t})
+		}
+	}
+	return samples
+}
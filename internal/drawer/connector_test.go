@@ -0,0 +1,71 @@
+package drawer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"image/png"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestConnectorPolyline(t *testing.T) {
+	if got := connectorPolyline(connectorBezier, 0, 0, 100, 40, defaultBend, false); got != nil {
+		t.Fatalf("expected no polyline for bezier connectors, got %v", got)
+	}
+	want := []gg.Point{{X: 0, Y: 0}, {X: 50, Y: 0}, {X: 50, Y: 40}, {X: 100, Y: 40}}
+	got := connectorPolyline(connectorElbow, 0, 0, 100, 40, defaultBend, false)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	// 纵向布局先垂直、再水平、再垂直
+	got = connectorPolyline(connectorElbow, 0, 0, 40, 100, defaultBend, true)
+	if got[1] != (gg.Point{X: 0, Y: 50}) || got[2] != (gg.Point{X: 40, Y: 50}) {
+		t.Fatalf("expected a vertical elbow, got %v", got)
+	}
+}
+
+func TestConnectorStyles(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Root", Children: []*types.Node{
+			{Text: "A", Children: []*types.Node{{Text: "A1"}, {Text: "A2"}}},
+			{Text: "B"},
+			{Text: "C"},
+		}}
+	}
+	seen := make(map[[32]byte]string)
+	for _, style := range []string{"bezier", "straight", "elbow"} {
+		for _, layout := range []string{"right", "down"} {
+			var buf bytes.Buffer
+			if err := Draw(newTree(), &buf, WithConnectorStyle(style), WithLayout(layout), WithScale(1)); err != nil {
+				t.Fatalf("%s/%s: Draw failed: %v", style, layout, err)
+			}
+			if _, err := png.Decode(bytes.NewReader(buf.Bytes())); err != nil {
+				t.Fatalf("%s/%s: invalid PNG: %v", style, layout, err)
+			}
+			sum := sha256.Sum256(buf.Bytes())
+			if prev, ok := seen[sum]; ok {
+				t.Fatalf("%s/%s: output identical to %s", style, layout, prev)
+			}
+			seen[sum] = style + "/" + layout
+		}
+	}
+
+	// 未知样式被忽略，与默认的贝塞尔曲线一致
+	var plain, unknown bytes.Buffer
+	if err := Draw(newTree(), &plain, WithScale(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Draw(newTree(), &unknown, WithConnectorStyle("zigzag"), WithScale(1)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plain.Bytes(), unknown.Bytes()) {
+		t.Fatal("expected an unknown connector style to fall back to bezier")
+	}
+}
@@ -0,0 +1,75 @@
+package drawer
+
+import (
+	"context"
+	"image"
+	"io"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// DrawContext is like Draw but stops when ctx is done, so a huge outline
+// cannot hold a caller past its deadline. The context is checked between
+// the measuring, layout and drawing passes and while walking the tree; on
+// cancellation nothing is written and ctx.Err() is returned.
+func DrawContext(ctx context.Context, rootNode *types.Node, w io.Writer, options ...Option) error {
+	return NewRenderer(options...).RenderContext(ctx, rootNode, w)
+}
+
+// RenderContext is like Render but stops when ctx is done; see DrawContext.
+func (r *Renderer) RenderContext(ctx context.Context, rootNode *types.Node, w io.Writer) error {
+	if r.opts.transparent && r.opts.format == "jpeg" {
+		return ErrTransparentBackground
+	}
+	img, err := r.ImageContext(ctx, rootNode)
+	if err != nil {
+		return err
+	}
+	return r.encode(w, img)
+}
+
+// ImageContext is like Image but stops when ctx is done; see DrawContext.
+func (r *Renderer) ImageContext(ctx context.Context, rootNode *types.Node) (image.Image, error) {
+	if r.opts.err != nil {
+		return nil, r.opts.err
+	}
+	if r.fontErr != nil {
+		return nil, r.fontErr
+	}
+	rootNode, err := r.focusRoot(rootNode)
+	if err != nil {
+		return nil, err
+	}
+	l, err := r.layoutContext(ctx, rootNode)
+	if err != nil {
+		return nil, err
+	}
+	pixelWidth, pixelHeight, err := r.rasterSize(l)
+	if err != nil {
+		return nil, err
+	}
+
+	// 超采样：在放大 k 倍的画布上绘制后缩小，使文字与曲线边缘更平滑
+	k := l.config.Supersample
+	if k <= 1 || pixelWidth*k*pixelHeight*k > maxSupersamplePixels {
+		k = 1
+	}
+	l.config.Scale *= float64(k)
+	dc := r.newRasterContext(l.config, pixelWidth*k, pixelHeight*k)
+	paintMindmap(dc, l)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	img := dc.Image()
+	if k > 1 {
+		img = downsample(img.(*image.RGBA), k)
+	}
+	return img, nil
+}
+
+// canceled 判断本次渲染的 context 是否已结束；递归遍历节点时据此提前返回，
+// 由各阶段之间的 ctx.Err() 检查报告错误
+func (c *DrawConfig) canceled() bool {
+	return c.ctx != nil && c.ctx.Err() != nil
+}
@@ -0,0 +1,55 @@
+package drawer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestDrawContext(t *testing.T) {
+	var buf bytes.Buffer
+	if err := DrawContext(context.Background(), SampleTree(), &buf, WithScale(1)); err != nil {
+		t.Fatalf("DrawContext failed: %v", err)
+	}
+	var plain bytes.Buffer
+	if err := Draw(SampleTree(), &plain, WithScale(1)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), plain.Bytes()) {
+		t.Error("expected DrawContext to match Draw when the context is not done")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf.Reset()
+	if err := DrawContext(ctx, SampleTree(), &buf); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written after cancellation, got %d bytes", buf.Len())
+	}
+}
+
+func TestDrawContextCanceledDuringLayout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// 布局引擎在定位时取消 context，模拟耗时的布局被超时打断
+	RegisterLayout("test-cancel", LayoutFunc(func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+		cancel()
+		if !config.canceled() {
+			t.Error("expected the config to observe the cancellation")
+		}
+	}))
+
+	root := &types.Node{Text: "Plan"}
+	for i := 0; i < 50; i++ {
+		root.Children = append(root.Children, &types.Node{Text: fmt.Sprintf("Item %d", i)})
+	}
+	if _, err := NewRenderer(WithLayout("test-cancel")).ImageContext(ctx, root); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
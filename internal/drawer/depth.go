@@ -0,0 +1,70 @@
+package drawer
+
+import (
+	"fmt"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// pruneToDepth 返回只保留深度不超过 maxDepth 的节点的浅拷贝树，原树不受影响。
+// 第二个返回值记录每个被裁剪节点（拷贝）隐藏的后代数量，第三个记录其对应的原节点。
+func pruneToDepth(node *types.Node, maxDepth int) (*types.Node, map[*types.Node]int, map[*types.Node]*types.Node) {
+	hidden := make(map[*types.Node]int)
+	collapsed := make(map[*types.Node]*types.Node)
+	var prune func(node *types.Node, depth int) *types.Node
+	prune = func(node *types.Node, depth int) *types.Node {
+		clone := *node
+		if depth == maxDepth {
+			clone.Children = nil
+			if n := node.Count() - 1; n > 0 {
+				hidden[&clone] = n
+				collapsed[&clone] = node
+			}
+			return &clone
+		}
+		clone.Children = make([]*types.Node, 0, len(node.Children))
+		for _, child := range node.Children {
+			clone.Children = append(clone.Children, prune(child, depth+1))
+		}
+		return &clone
+	}
+	return prune(node, 0), hidden, collapsed
+}
+
+// drawHiddenBadges 在隐藏了后代的节点右上角绘制 "+k" 标记，按树的顺序绘制以保证输出稳定
+func drawHiddenBadges(dc canvas, l *mindmapLayout) {
+	if len(l.hidden) == 0 {
+		return
+	}
+	l.root.Walk(func(node *types.Node, _ int) bool {
+		if count, ok := l.hidden[node]; ok {
+			if size, ok := l.nodeSizes[node]; ok {
+				drawHiddenBadge(dc, node, size, count, l.config)
+			}
+		}
+		return true
+	})
+}
+
+// drawHiddenBadge 绘制单个 "+k" 标记
+func drawHiddenBadge(dc canvas, node *types.Node, size *NodeSize, count int, config *DrawConfig) {
+	scale := config.Scale
+	label := fmt.Sprintf("+%d", count)
+	h := config.LineHeight * scale
+	w := h
+	if !config.skeleton {
+		w = max(h, measureText(dc, config, label)+h*0.6)
+	}
+	x := (node.X+size.Width/2)*scale - w/2
+	y := (node.Y-size.Height/2)*scale - h/2
+
+	// 以连接线颜色填充，背景色书写数字，与各主题保持协调
+	line, bg := config.ConnectionLineColor, config.BackgroundColor
+	dc.SetRGB(line[0], line[1], line[2])
+	drawRoundedRect(dc, x, y, w, h, h/2)
+	dc.Fill()
+	if !config.skeleton {
+		dc.SetRGB(bg[0], bg[1], bg[2])
+		drawText(dc, config, label, x+w/2, y+h/2, 0.5, 0.5)
+	}
+}
@@ -1,20 +1,24 @@
 package drawer
 
 import (
+	"context"
 	_ "embed" // Ensure embed is imported for //go:embed
+	"errors"
 	"fmt"
+	"image"
 	"io"
 	"log"
 	"math"
 	"math/rand"
-	"os"
-	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
 	"github.com/hellodeveye/mindmapgen/internal/theme"
 	"github.com/hellodeveye/mindmapgen/pkg/types"
 )
@@ -31,11 +35,6 @@ var embeddedFonts = []embeddedFont{
 	{"simhei.ttf", simhei},
 }
 
-var (
-	fontTempOnce sync.Once
-	fontTempPath string
-)
-
 // 默认常量 - 现在从主题配置中获取
 const (
 	DefaultMinNodeWidth  = 100.0
@@ -60,9 +59,22 @@ type NodeSize struct {
 	Height          float64
 	Lines           []string // 存储换行后的文本
 	ActualTextWidth float64
+	LineHeight      float64  // 节点文本的行高，随节点层级而定
+	Note            []string // 文本下方换行后的备注，为空时不绘制
+	Footer          string   // 文本下方的元数据脚注，为空时不绘制
+	Icon            string   // 文本左侧绘制的图标，为空时不绘制
+	IconWidth       float64  // 图标及其与文本的间距占用的宽度，包含在 ActualTextWidth 中
 }
 
-type textMeasureCache map[string]float64
+// textMeasureCache 缓存文本宽度，测量委托给当前的 TextShaper
+type textMeasureCache struct {
+	widths map[string]float64
+	shaper TextShaper
+}
+
+func newTextMeasureCache(shaper TextShaper) *textMeasureCache {
+	return &textMeasureCache{widths: make(map[string]float64), shaper: shaper}
+}
 
 // DrawConfig 绘制配置
 type DrawConfig struct {
@@ -71,6 +83,7 @@ type DrawConfig struct {
 	MaxNodeWidth        float64
 	MinNodeHeight       float64
 	LevelSpacing        float64
+	RootLevelSpacing    float64 // 根节点与一级节点的间距，0 表示使用 LevelSpacing
 	NodeSpacing         float64
 	CornerRadius        float64
 	FontSize            float64
@@ -79,11 +92,169 @@ type DrawConfig struct {
 	TextPadding         float64
 	BackgroundColor     [3]float64
 	ConnectionLineColor [3]float64
+	MarkerColor         [3]float64   // ==高亮== 文本的背景色
+	BranchColors        [][3]float64 // 分支配色，为空时使用主题的层级样式
+	Layout              string       // 布局名: right, left, both, down, up, radial 或自定义布局
+	Alignment           string       // 兄弟节点对齐方式: center, top, justify
+	MinAspectRatio      float64      // 节点最小宽高比，0 表示不限制
+	RouteConnectors     bool         // 调整连接线的弯曲位置，避免穿过其他节点
+	Arrows              bool         // 在连接线的子节点一端绘制箭头
+	ConnectorStyle      string       // 连接线样式: bezier, straight, elbow，为空时使用 bezier
+	Shadow              *NodeShadow  // 节点阴影，为空时不绘制
+	MetaKeys            []string     // 以脚注显示的节点元数据键，按顺序排列
+	Supersample         int          // 位图超采样倍数，0 或 1 表示不超采样
+	RTL                 bool         // 从右到左：镜像布局，文本按双向算法排列
+	TextAlign           string       // 节点内文本的对齐方式: left, center, right，为空时居中
+	Highlight           string       // 高亮搜索词（已转为小写），为空时不高亮
+	DimUnmatched        bool         // 高亮时淡化未匹配的节点
+
+	rng       *rand.Rand      // 手绘风格的随机源，按主题种子初始化
+	shaper    TextShaper      // 文本整形器，为空时使用 gg 的默认实现
+	hasGlyph  func(rune) bool // 字体是否包含某字符的字形，用于跳过无法绘制的图标
+	skeleton  bool            // 骨架预览模式：估算尺寸并以占位条代替文本
+	obstacles []nodeBox       // 连接线避让时检测的节点框，仅在 RouteConnectors 时收集
+	ctx       context.Context // 本次渲染的 context，结束后递归遍历提前返回；为空时不检查
+}
+
+// applyScale 应用用户指定的缩放，超出主题范围时截断并记录警告
+func (c *DrawConfig) applyScale(scale float64) {
+	if c.Theme != nil {
+		clamped, ok := c.Theme.Layout.ClampScale(scale)
+		if ok {
+			log.Printf("scale %g is outside the range of theme %q, using %g", scale, c.Theme.Name, clamped)
+		}
+		scale = clamped
+	}
+	c.Scale = scale
+}
+
+// widenToAspectRatio 按最小宽高比加宽节点，结果不超过最大节点宽度
+func (c *DrawConfig) widenToAspectRatio(width, height float64) float64 {
+	if c.MinAspectRatio <= 0 || width >= height*c.MinAspectRatio {
+		return width
+	}
+	return math.Max(width, math.Min(height*c.MinAspectRatio, c.MaxNodeWidth))
+}
+
+// lineHeightFor 返回节点所在层级的行高，主题未为该层级设置时使用全局行高
+func (c *DrawConfig) lineHeightFor(node *types.Node, depth int) float64 {
+	if c.Theme != nil {
+		if lh := c.Theme.LineHeightFor(depth, len(node.Children) == 0); lh > 0 {
+			return lh
+		}
+	}
+	return c.LineHeight
+}
+
+// levelSpacing 返回 depth 层节点与其子节点之间的间距，根节点可单独设置
+func (c *DrawConfig) levelSpacing(depth int) float64 {
+	if depth == 0 && c.RootLevelSpacing > 0 {
+		return c.RootLevelSpacing
+	}
+	return c.LevelSpacing
+}
+
+// isSketch 判断是否使用手绘风格绘制，骨架预览始终使用标准风格
+func (c *DrawConfig) isSketch() bool {
+	return !c.skeleton && c.Theme != nil && c.Theme.IsSketchStyle()
+}
+
+// textShaper 返回当前生效的文本整形器
+func (c *DrawConfig) textShaper() TextShaper {
+	if c.shaper != nil {
+		return c.shaper
+	}
+	return defaultShaper{}
 }
 
 type drawOptions struct {
-	theme  string
-	layout string
+	theme       string
+	themeConfig *theme.ThemeConfig // 直接指定的主题配置，优先于 theme
+	layout      string
+	align       string
+	shaper      TextShaper
+	skeleton    bool
+	scale       float64
+	maxW        int
+	maxH        int
+
+	format  string         // 位图编码格式：png 或 jpeg
+	quality int            // JPEG 质量 1-100
+	preset  *qualityPreset // 质量预设，为空时按普通质量渲染
+
+	minAspectRatio   float64
+	minNodeWidth     float64 // 覆盖主题的节点宽度范围，0 表示使用主题设置
+	maxNodeWidth     float64
+	nodeSpacing      float64 // 覆盖主题的兄弟节点间距，0 表示使用主题设置
+	levelSpacing     float64 // 覆盖主题的层级间距，0 表示使用主题设置
+	rootLevelSpacing float64
+	routeConnectors  bool
+	arrows           *bool  // 是否绘制箭头，为空时使用主题设置
+	connectorStyle   string // 连接线样式，为空时使用主题设置
+	metaKeys         []string
+	transparent      bool   // 不绘制背景，输出透明画布
+	rtl              bool   // 从右到左排列导图与文本
+	textAlign        string // 节点内文本的对齐方式，为空时使用主题设置
+	highlight        string // 高亮搜索词，为空时不高亮
+	dimUnmatched     bool   // 高亮时淡化未匹配的节点
+	maxDepth         int    // 绘制的最大深度，< 0 表示不限制
+	focus            string // 只绘制该选择器对应的子树，为空时绘制整棵树
+
+	fontFile  string // 外部字体文件路径，为空时使用内嵌字体
+	fontIndex int    // 字体集合中的字体序号
+
+	breadcrumb string // 子树渲染时显示的父节点标签
+	title      string // 画布顶部的标题
+	caption    string // 画布底部的说明
+	iconLegend bool   // 在画布右下角绘制图标图例
+
+	subtreeUpload SubtreeUploader // 布局数据中为被裁剪的子树上传完整图片，为空时不上传
+
+	inlineSVGStyles bool
+	frameDelay      time.Duration
+
+	err error // 第一个无效选项的错误，渲染时返回
+}
+
+// 默认允许的最大画布像素尺寸
+const (
+	DefaultMaxWidth  = 8000
+	DefaultMaxHeight = 8000
+)
+
+// ErrCanvasTooLarge is returned when a mind map cannot fit within the maximum
+// canvas dimensions even after reducing the scale.
+var ErrCanvasTooLarge = errors.New("mind map is too large to render")
+
+// ErrTransparentBackground is returned when WithTransparentBackground is
+// combined with an output format that has no alpha channel (JPEG) or no
+// usable transparency (the GIF reveal animation).
+var ErrTransparentBackground = errors.New("transparent background is only supported for PNG, SVG and PDF output")
+
+// minAutoScale 超出最大尺寸时自动缩小缩放的下限，低于该值文字难以辨认
+const minAutoScale = 1.0
+
+// newDrawOptions 返回应用了默认值与调用方选项的绘制选项
+func newDrawOptions(options []Option) drawOptions {
+	opts := drawOptions{
+		theme:  "default",
+		layout: "right",
+		align:  "center",
+		maxW:   DefaultMaxWidth,
+		maxH:   DefaultMaxHeight,
+
+		format:   "png",
+		quality:  DefaultJPEGQuality,
+		maxDepth: -1,
+
+		frameDelay: DefaultFrameDelay,
+	}
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+	return opts
 }
 
 // Option configures draw behavior.
@@ -98,13 +269,245 @@ func WithTheme(theme string) Option {
 	}
 }
 
-// WithLayout sets the layout direction: right, left, both.
+// WithThemeConfig renders with the given theme configuration instead of
+// looking a theme up by name, e.g. a theme restored from a bundle.
+func WithThemeConfig(cfg *theme.ThemeConfig) Option {
+	return func(opts *drawOptions) {
+		if cfg != nil {
+			opts.themeConfig = cfg
+		}
+	}
+}
+
+// WithLayout selects a registered layout engine by name: the built-in right,
+// left, both, down and up directions, "radial" (root at the center, branches
+// radiating outward with depth mapped to radius), or any layout added with
+// RegisterLayout. An empty name keeps the default right layout; an unknown
+// name makes rendering return an error wrapping ErrInvalidOption.
 func WithLayout(layout string) Option {
 	return func(opts *drawOptions) {
 		normalized := strings.ToLower(strings.TrimSpace(layout))
-		switch normalized {
-		case "right", "left", "both":
+		switch {
+		case normalized == "":
+		case IsLayout(normalized):
 			opts.layout = normalized
+		default:
+			opts.invalidOption("%s", unknownLayoutMessage(layout))
+		}
+	}
+}
+
+// WithAlignment sets how siblings are placed along the parent's span:
+// "center" (default) centers each child within its subtree band, "top"
+// aligns children with the top (or left, in vertical layouts) of the parent,
+// and "justify" gives every sibling an equal band so they are evenly spaced.
+// An empty value keeps the default; any other value makes rendering return
+// an error wrapping ErrInvalidOption.
+func WithAlignment(alignment string) Option {
+	return func(opts *drawOptions) {
+		normalized := strings.ToLower(strings.TrimSpace(alignment))
+		switch {
+		case normalized == "":
+		case IsAlignment(normalized):
+			opts.align = normalized
+		default:
+			opts.invalidOption("unknown alignment %q; available alignments: %s", alignment, strings.Join(Alignments(), ", "))
+		}
+	}
+}
+
+// Alignments lists the sibling alignments accepted by WithAlignment.
+func Alignments() []string {
+	return []string{"center", "top", "justify"}
+}
+
+// IsAlignment reports whether name is an alignment accepted by
+// WithAlignment. Names are case-insensitive.
+func IsAlignment(name string) bool {
+	return slices.Contains(Alignments(), strings.ToLower(strings.TrimSpace(name)))
+}
+
+// WithMinAspectRatio widens nodes whose width:height ratio is below ratio,
+// so short labels do not end up as small, nearly square boxes. Nodes never
+// grow beyond the theme's maximum node width.
+func WithMinAspectRatio(ratio float64) Option {
+	return func(opts *drawOptions) {
+		if ratio > 0 {
+			opts.minAspectRatio = ratio
+		}
+	}
+}
+
+// WithRootLevelSpacing sets the gap between the root and its immediate
+// children, independently of the theme's spacing between deeper levels. A
+// larger gap sets the root apart for emphasis.
+func WithRootLevelSpacing(gap float64) Option {
+	return func(opts *drawOptions) {
+		if gap > 0 {
+			opts.rootLevelSpacing = gap
+		}
+	}
+}
+
+// WithConnectorRouting makes connectors avoid passing through unrelated node
+// boxes. When a connector's default S-curve would cross a node, its bend is
+// moved closer to the parent or the child until the curve clears every box;
+// if no bend clears, the default curve is kept.
+func WithConnectorRouting() Option {
+	return func(opts *drawOptions) {
+		opts.routeConnectors = true
+	}
+}
+
+// WithArrows turns arrowheads at the child end of every connector on or off,
+// overriding the theme's layout.arrows setting. Arrows are off by default and
+// point along the connector's end tangent. The tip touches the child's box;
+// leaf connectors, which otherwise stop just before the text inside the box,
+// end at the box edge so the node background does not hide the arrow.
+func WithArrows(enabled bool) Option {
+	return func(opts *drawOptions) {
+		opts.arrows = &enabled
+	}
+}
+
+// WithMetaKeys shows the listed metadata keys of each node (parsed from a
+// trailing {key=value; ...} block) as a small footer line below its text, in
+// the given order. Nodes without any of the keys are drawn as usual.
+func WithMetaKeys(keys ...string) Option {
+	return func(opts *drawOptions) {
+		opts.metaKeys = nil
+		for _, key := range keys {
+			if key = strings.TrimSpace(key); key != "" {
+				opts.metaKeys = append(opts.metaKeys, key)
+			}
+		}
+	}
+}
+
+// WithMaxDepth renders only the first n levels below the root; 0 draws the
+// root alone. Nodes whose descendants were cut off get a small "+k" badge
+// with the number of hidden nodes. The caller's tree is not modified.
+func WithMaxDepth(n int) Option {
+	return func(opts *drawOptions) {
+		if n >= 0 {
+			opts.maxDepth = n
+		}
+	}
+}
+
+// WithFontFile renders PNG, JPEG and GIF output with the font at path
+// instead of the embedded SimHei. TrueType (.ttf), CFF-based OpenType (.otf)
+// and font collections (.ttc/.otc) are supported; index selects the face in
+// a collection and must be 0 for single fonts. If the file cannot be loaded,
+// rendering returns the error. PDF and SVG output keep the embedded font.
+func WithFontFile(path string, index int) Option {
+	return func(opts *drawOptions) {
+		opts.fontFile, opts.fontIndex = path, index
+	}
+}
+
+// WithBreadcrumb is meant for rendering a subtree on its own: it draws
+// parentLabel in a muted style on the side the root would be connected from,
+// with a faint connector stub leading into the root, so the subtree keeps
+// its context. An empty label draws nothing.
+func WithBreadcrumb(parentLabel string) Option {
+	return func(opts *drawOptions) {
+		opts.breadcrumb = strings.TrimSpace(parentLabel)
+	}
+}
+
+// WithTextShaper sets a custom text shaper used for measuring and drawing node
+// text. When unset, text is measured and drawn rune by rune by gg.
+func WithTextShaper(shaper TextShaper) Option {
+	return func(opts *drawOptions) {
+		opts.shaper = shaper
+	}
+}
+
+// WithScale overrides the theme's output scale. Values outside the theme's
+// minScale/maxScale range are clamped with a warning.
+func WithScale(scale float64) Option {
+	return func(opts *drawOptions) {
+		if scale > 0 {
+			opts.scale = scale
+		}
+	}
+}
+
+// WithMaxDimensions limits the rendered canvas to w x h pixels. Larger maps
+// are drawn at a reduced scale; if they still do not fit at scale 1, Draw
+// returns ErrCanvasTooLarge. The default is 8000 x 8000.
+func WithMaxDimensions(w, h int) Option {
+	return func(opts *drawOptions) {
+		if w > 0 && h > 0 {
+			opts.maxW, opts.maxH = w, h
+		}
+	}
+}
+
+// WithSkeleton renders a low-fidelity preview: node sizes are estimated from
+// rune counts instead of measured, text is replaced by placeholder bars and
+// the image is drawn at scale 1. Useful for instant feedback on huge maps.
+func WithSkeleton() Option {
+	return func(opts *drawOptions) {
+		opts.skeleton = true
+	}
+}
+
+// DefaultJPEGQuality is the JPEG quality used when WithJPEGQuality is not set.
+// It is higher than image/jpeg's default to keep node text free of artifacts.
+const DefaultJPEGQuality = 90
+
+// WithFormat selects how Draw and Renderer.Render encode the image: "png"
+// (default) or "jpeg" ("jpg" is accepted as an alias). JPEG output is much
+// smaller for sketch themes; the theme background is always opaque, so no
+// transparency is lost.
+func WithFormat(format string) Option {
+	return func(opts *drawOptions) {
+		normalized := strings.ToLower(strings.TrimSpace(format))
+		switch normalized {
+		case "png", "jpeg":
+			opts.format = normalized
+		case "jpg":
+			opts.format = "jpeg"
+		}
+	}
+}
+
+// WithJPEGQuality sets the JPEG quality from 1 to 100. Only used together
+// with WithFormat("jpeg").
+func WithJPEGQuality(quality int) Option {
+	return func(opts *drawOptions) {
+		if quality >= 1 && quality <= 100 {
+			opts.quality = quality
+		}
+	}
+}
+
+// WithTransparentBackground leaves the canvas behind the map transparent
+// instead of filling it with the theme background, e.g. to overlay a map on
+// slides. Nodes and connectors are drawn unchanged. JPEG and GIF output
+// return ErrTransparentBackground.
+func WithTransparentBackground() Option {
+	return func(opts *drawOptions) {
+		opts.transparent = true
+	}
+}
+
+// WithInlineSVGStyles makes DrawSVG write presentation attributes on every
+// element instead of sharing CSS classes from a <style> block. Only needed
+// for consumers that ignore embedded stylesheets.
+func WithInlineSVGStyles() Option {
+	return func(opts *drawOptions) {
+		opts.inlineSVGStyles = true
+	}
+}
+
+// WithFrameDelay sets how long each level is shown in DrawReveal.
+func WithFrameDelay(d time.Duration) Option {
+	return func(opts *drawOptions) {
+		if d > 0 {
+			opts.frameDelay = d
 		}
 	}
 }
@@ -116,7 +519,11 @@ func NewDrawConfig(themeName string) (*DrawConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newDrawConfigFromTheme(themeConfig), nil
+}
 
+// newDrawConfigFromTheme 根据主题配置创建绘制配置
+func newDrawConfigFromTheme(themeConfig *theme.ThemeConfig) *DrawConfig {
 	// 解析背景颜色
 	bgColor, ok := parseHexColor(themeConfig.Colors.Background, [3]float64{1.0, 1.0, 1.0})
 	if !ok {
@@ -126,6 +533,20 @@ func NewDrawConfig(themeName string) (*DrawConfig, error) {
 	if !ok {
 		log.Printf("theme %q has invalid connection line color %q", themeConfig.Name, themeConfig.Colors.ConnectionLine)
 	}
+	markerColor, ok := parseHexColor(themeConfig.Colors.Marker, defaultMarkerColor)
+	if !ok && themeConfig.Colors.Marker != "" {
+		log.Printf("theme %q has invalid marker color %q", themeConfig.Name, themeConfig.Colors.Marker)
+	}
+
+	var branchColors [][3]float64
+	for _, hex := range themeConfig.BranchPalette {
+		c, ok := parseHexColor(hex, [3]float64{})
+		if !ok {
+			log.Printf("theme %q has invalid branch palette color %q", themeConfig.Name, hex)
+			continue
+		}
+		branchColors = append(branchColors, c)
+	}
 
 	return &DrawConfig{
 		Theme:               themeConfig,
@@ -133,6 +554,10 @@ func NewDrawConfig(themeName string) (*DrawConfig, error) {
 		MaxNodeWidth:        themeConfig.Layout.MaxNodeWidth,
 		MinNodeHeight:       themeConfig.Layout.MinNodeHeight,
 		LevelSpacing:        themeConfig.Layout.LevelSpacing,
+		RootLevelSpacing:    themeConfig.Layout.RootLevelSpacing,
+		Arrows:              themeConfig.Layout.Arrows,
+		ConnectorStyle:      themeConfig.Layout.ConnectorStyle,
+		TextAlign:           themeConfig.Layout.TextAlign,
 		NodeSpacing:         themeConfig.Layout.NodeSpacing,
 		CornerRadius:        themeConfig.Layout.CornerRadius,
 		FontSize:            themeConfig.Layout.FontSize,
@@ -141,11 +566,17 @@ func NewDrawConfig(themeName string) (*DrawConfig, error) {
 		TextPadding:         themeConfig.Layout.TextPadding,
 		BackgroundColor:     bgColor,
 		ConnectionLineColor: lineColor,
-	}, nil
+		MarkerColor:         markerColor,
+		BranchColors:        branchColors,
+		Shadow:              newNodeShadow(themeConfig),
+	}
 }
 
-// parseHexColor 解析十六进制颜色为RGB数组
+// parseHexColor 解析十六进制颜色为RGB数组，支持 #rrggbb 与简写 #rgb
 func parseHexColor(hex string, defaultColor [3]float64) ([3]float64, bool) {
+	if len(hex) == 4 && hex[0] == '#' {
+		hex = string([]byte{'#', hex[1], hex[1], hex[2], hex[2], hex[3], hex[3]})
+	}
 	if hex == "" || hex[0] != '#' || len(hex) != 7 {
 		return defaultColor, false
 	}
@@ -161,207 +592,158 @@ func parseHexColor(hex string, defaultColor [3]float64) ([3]float64, bool) {
 	return [3]float64{float64(r) / 255.0, float64(g) / 255.0, float64(b) / 255.0}, true
 }
 
-func loadFont(dc *gg.Context, size float64) error {
-	fontLoaded := false
-
-	for _, font := range embeddedFonts {
-		fontBytes := font.Data
-		if len(fontBytes) == 0 {
-			continue
-		}
+var (
+	embeddedFontOnce sync.Once
+	embeddedFontVal  *truetype.Font
+	embeddedFontErr  error
+)
 
-		tmpFileName, err := ensureFontTempFile(font)
-		if err != nil {
-			fmt.Printf("Warning: failed to prepare font file for %s: %v\n", font.Name, err)
-			continue
+// parseEmbeddedFont 解析第一个可用的内嵌字体，结果在进程内复用。
+// 解析后的字体只读，可在并发渲染间共享；face 带字形缓存，仍需每次渲染单独创建。
+func parseEmbeddedFont() (*truetype.Font, error) {
+	embeddedFontOnce.Do(func() {
+		for _, font := range embeddedFonts {
+			if len(font.Data) == 0 {
+				continue
+			}
+			f, err := truetype.Parse(font.Data)
+			if err != nil {
+				fmt.Printf("Warning: failed to parse font %s: %v\n", font.Name, err)
+				continue
+			}
+			embeddedFontVal = f
+			return
 		}
+		embeddedFontErr = fmt.Errorf("failed to load preferred fonts from embed, using default font")
+	})
+	return embeddedFontVal, embeddedFontErr
+}
 
-		if err := dc.LoadFontFace(tmpFileName, size); err == nil {
-			fontLoaded = true
-			break
-		} else {
-			fmt.Printf("Warning: failed to load font from temp file %s: %v\n", tmpFileName, err)
-		}
+// Warmup parses the embedded fonts and loads the built-in themes ahead of
+// time, so the first render after startup does not pay for that work.
+// Servers call it once before accepting requests; later calls are cheap.
+func Warmup() error {
+	if _, err := parseEmbeddedFont(); err != nil {
+		return err
 	}
-
-	if !fontLoaded {
-		dc.LoadFontFace("", size)
-		return fmt.Errorf("failed to load preferred fonts from embed, using default font")
+	if _, err := loadVectorFont(); err != nil {
+		return err
 	}
+	_, err := NewDrawConfig("default")
+	return err
+}
 
-	return nil
+// Draw 使用默认主题绘制思维导图
+func Draw(rootNode *types.Node, w io.Writer, options ...Option) error {
+	return NewRenderer(options...).Render(rootNode, w)
 }
 
-func ensureFontTempFile(font embeddedFont) (string, error) {
-	var err error
-	fontTempOnce.Do(func() {
-		suffix := filepath.Ext(font.Name)
-		if suffix == "" {
-			suffix = ".font"
-		}
+// Render draws the mind map and returns the bitmap as an *image.RGBA instead
+// of encoding it, so callers can add overlays, tile several maps or choose
+// their own encoder. Draw is Render followed by PNG or JPEG encoding.
+func Render(rootNode *types.Node, options ...Option) (image.Image, error) {
+	return NewRenderer(options...).Image(rootNode)
+}
 
-		tmpfile, createErr := os.CreateTemp("", fmt.Sprintf("font*%s", suffix))
-		if createErr != nil {
-			err = createErr
-			return
-		}
-		tmpFileName := tmpfile.Name()
+// DrawWithTheme 使用指定主题绘制思维导图
+func DrawWithTheme(rootNode *types.Node, w io.Writer, themeName string) error {
+	return Draw(rootNode, w, WithTheme(themeName))
+}
 
-		if _, writeErr := tmpfile.Write(font.Data); writeErr != nil {
-			err = writeErr
-			_ = tmpfile.Close()
-			_ = os.Remove(tmpFileName)
-			return
-		}
+// DrawWithThemeAndLayout 使用指定主题和布局绘制思维导图
+func DrawWithThemeAndLayout(rootNode *types.Node, w io.Writer, themeName string, layout string) error {
+	return Draw(rootNode, w, WithTheme(themeName), WithLayout(layout))
+}
 
-		if closeErr := tmpfile.Close(); closeErr != nil {
-			err = closeErr
-			_ = os.Remove(tmpFileName)
-			return
-		}
+// mindmapLayout 一次布局计算的结果，供各输出格式共享
+type mindmapLayout struct {
+	root      *types.Node // 参与布局的根节点，限制深度时为裁剪后的副本
+	config    *DrawConfig
+	nodeSizes map[*types.Node]*NodeSize
+	bounds    *Bounds                     // 已包含边距的内容边界（未缩放）
+	hidden    map[*types.Node]int         // 因限制深度而隐藏的后代数量
+	collapsed map[*types.Node]*types.Node // 隐藏了后代的节点（副本）对应的原节点
+
+	breadcrumb *breadcrumb // 子树渲染时的父节点标签，未设置时为空
+	title      *titleBand  // 画布顶部的标题，未设置时为空
+	caption    *titleBand  // 画布底部的说明，未设置时为空
+	legend     *iconLegend // 画布右下角的图标图例，未启用或没有图标时为空
+}
 
-		fontTempPath = tmpFileName
-	})
+// size 返回未缩放的画布尺寸
+func (l *mindmapLayout) size() (width, height float64) {
+	return l.bounds.MaxX - l.bounds.MinX, l.bounds.MaxY - l.bounds.MinY
+}
 
-	if err != nil {
-		return "", err
+// fitScale 在画布超出 maxW x maxH 像素时降低缩放，无法适应时返回 ErrCanvasTooLarge
+func fitScale(config *DrawConfig, width, height float64, maxW, maxH int) error {
+	if width*config.Scale <= float64(maxW) && height*config.Scale <= float64(maxH) {
+		return nil
 	}
-	if fontTempPath == "" {
-		return "", fmt.Errorf("font temp file not available")
+	fit := math.Min(float64(maxW)/width, float64(maxH)/height)
+	if fit < math.Min(minAutoScale, config.Scale) {
+		return fmt.Errorf("%w: %.0fx%.0f exceeds the %dx%d pixel limit", ErrCanvasTooLarge, width, height, maxW, maxH)
 	}
-	return fontTempPath, nil
+	log.Printf("canvas %.0fx%.0f exceeds %dx%d pixels at scale %g, reducing scale to %.2f", width, height, maxW, maxH, config.Scale, fit)
+	config.Scale = fit
+	return nil
 }
 
-// 保存对根节点的引用，用于识别根节点
-var root *types.Node
+// maxCanvasPixels 单个画布允许的最大像素数，防止尺寸换算溢出或分配失败
+const maxCanvasPixels = 1 << 30
 
-// Draw 使用默认主题绘制思维导图
-func Draw(rootNode *types.Node, w io.Writer, options ...Option) error {
-	opts := drawOptions{
-		theme:  "default",
-		layout: "right",
+// canvasPixels 将画布尺寸按缩放换算为像素，检查整数溢出
+func canvasPixels(width, height, scale float64) (int, int, error) {
+	w, h := math.Floor(width*scale), math.Floor(height*scale)
+	if w < 1 || h < 1 || w > math.MaxInt32 || h > math.MaxInt32 || w*h > maxCanvasPixels {
+		return 0, 0, fmt.Errorf("canvas size %.0fx%.0f pixels at scale %g is out of range", w, h, scale)
 	}
-	for _, opt := range options {
-		if opt != nil {
-			opt(&opts)
-		}
-	}
-	return DrawWithThemeAndLayout(rootNode, w, opts.theme, opts.layout)
+	return int(w), int(h), nil
 }
 
-// DrawWithTheme 使用指定主题绘制思维导图
-func DrawWithTheme(rootNode *types.Node, w io.Writer, themeName string) error {
-	return Draw(rootNode, w, WithTheme(themeName))
-}
-
-// DrawWithThemeAndLayout 使用指定主题和布局绘制思维导图
-func DrawWithThemeAndLayout(rootNode *types.Node, w io.Writer, themeName string, layout string) error {
-	config, err := NewDrawConfig(themeName)
-	if err != nil {
-		// 如果主题加载失败，使用默认配置
-		config = &DrawConfig{
-			MinNodeWidth:        DefaultMinNodeWidth,
-			MaxNodeWidth:        DefaultMaxNodeWidth,
-			MinNodeHeight:       DefaultMinNodeHeight,
-			LevelSpacing:        DefaultLevelSpacing,
-			NodeSpacing:         DefaultNodeSpacing,
-			CornerRadius:        DefaultCornerRadius,
-			FontSize:            DefaultFontSize,
-			Scale:               DefaultScale,
-			LineHeight:          DefaultLineHeight,
-			TextPadding:         DefaultTextPadding,
-			BackgroundColor:     [3]float64{1.0, 1.0, 1.0},
-			ConnectionLineColor: [3]float64{0.051, 0.043, 0.133},
-		}
-	}
-
-	// 如果是手绘风格，初始化随机种子
-	if config.Theme != nil && config.Theme.IsSketchStyle() {
-		rand.Seed(config.Theme.SketchConfig.Seed)
-	}
-
-	// 创建临时上下文用于文本测量
-	tempDC := gg.NewContext(1, 1)
-	if err := loadFont(tempDC, config.FontSize); err != nil {
-		fmt.Printf("Warning: %v\n", err)
-	}
-
-	// 计算节点尺寸
-	nodeSizes := make(map[*types.Node]*NodeSize)
-	measureCache := make(textMeasureCache)
-	calculateNodeSizes(tempDC, rootNode, nodeSizes, config, measureCache)
-
-	// 获取树的深度和每层节点数
-	maxDepth := 0
-	levelCounts := make(map[int]int)
-	calculateTreeMetrics(rootNode, 0, &maxDepth, levelCounts)
-
-	// 保存根节点引用
-	root = rootNode
-
-	// 计算水平思维导图布局
-	subtreeHeights := make(map[*types.Node]float64)
-	calculateSubtreeHeights(rootNode, nodeSizes, subtreeHeights, config)
-	switch layout {
-	case "both":
-		horizontalMindmapLayoutBothSides(rootNode, 0, 0, nodeSizes, subtreeHeights, config)
-	case "left":
-		horizontalMindmapLayoutDirectional(rootNode, 0, 0, -1, nodeSizes, subtreeHeights, config)
-	default:
-		horizontalMindmapLayoutDirectional(rootNode, 0, 0, 1, nodeSizes, subtreeHeights, config)
-	}
-
-	// 计算边界
-	bounds := &Bounds{
-		MinX: math.MaxFloat64,
-		MinY: math.MaxFloat64,
-		MaxX: -math.MaxFloat64,
-		MaxY: -math.MaxFloat64,
-	}
-	calculateBoundsWithSizes(rootNode, nodeSizes, bounds)
-
-	// 扩展边界，确保有足够的边距
-	extraMargin := 50.0
-	bounds.MinX -= extraMargin
-	bounds.MinY -= extraMargin
-	bounds.MaxX += extraMargin
-	bounds.MaxY += extraMargin
-
-	// 计算画布尺寸
-	contentWidth := bounds.MaxX - bounds.MinX
-	contentHeight := bounds.MaxY - bounds.MinY
-	canvasWidth := contentWidth
-	canvasHeight := contentHeight
-
-	// 创建最终上下文
-	dc := gg.NewContext(int(canvasWidth*config.Scale), int(canvasHeight*config.Scale))
-	dc.SetLineWidth(1.0 * config.Scale)
-	dc.SetLineJoin(gg.LineJoinRound)
-	dc.SetLineCap(gg.LineCapButt)
-
-	if err := loadFont(dc, config.FontSize*config.Scale); err != nil {
-		fmt.Printf("Warning: %v\n", err)
-	}
-
-	// 设置背景
-	dc.SetRGB(config.BackgroundColor[0], config.BackgroundColor[1], config.BackgroundColor[2])
-	dc.Clear()
+// paintMindmap 将布局结果绘制到绘制面上：先连接线，后节点
+func paintMindmap(dc canvas, l *mindmapLayout) {
+	config := l.config
+	rootNode := l.root
 
 	// 应用变换
-	dc.Translate(-bounds.MinX*config.Scale, -bounds.MinY*config.Scale)
+	dc.Translate(-l.bounds.MinX*config.Scale, -l.bounds.MinY*config.Scale)
+
+	// 子树的父节点标签与连接线残段位于最底层
+	if l.breadcrumb != nil {
+		drawBreadcrumb(dc, l.breadcrumb, config)
+	}
 
 	// 先绘制所有连接线
-	drawConnectionsHorizontal(dc, rootNode, nodeSizes, config)
+	if config.RouteConnectors {
+		config.obstacles = collectNodeBoxes(rootNode, l.nodeSizes, nil)
+	}
+	drawConnectionsHorizontal(dc, rootNode, l.nodeSizes, config)
 
 	// 然后绘制所有节点
-	drawAllNodes(dc, rootNode, nodeSizes, config)
+	drawAllNodes(dc, rootNode, l.nodeSizes, config, -1, 0)
+
+	// 最后为隐藏了后代的节点绘制 "+k" 标记
+	drawHiddenBadges(dc, l)
 
-	return dc.EncodePNG(w)
+	if l.legend != nil {
+		drawIconLegend(dc, l.legend, config)
+	}
+	for _, band := range []*titleBand{l.title, l.caption} {
+		if band != nil {
+			drawTitleBand(dc, band, config)
+		}
+	}
 }
 
-// 计算每个节点及其子树所需的总垂直高度
+// isVertical 判断是否为纵向（上下生长）布局
+func (c *DrawConfig) isVertical() bool {
+	return c.Layout == "down" || c.Layout == "up"
+}
+
+// 计算每个节点及其子树所需的总垂直高度（纵向布局时为总水平宽度）
 func calculateSubtreeHeights(node *types.Node, nodeSizes map[*types.Node]*NodeSize, subtreeHeights map[*types.Node]float64, config *DrawConfig) {
-	if node == nil {
+	if node == nil || config.canceled() {
 		return
 	}
 
@@ -370,26 +752,27 @@ func calculateSubtreeHeights(node *types.Node, nodeSizes map[*types.Node]*NodeSi
 		return
 	}
 
+	span := nodeSize.Height
+	if config.isVertical() {
+		span = nodeSize.Width
+	}
+
 	if len(node.Children) == 0 {
-		subtreeHeights[node] = nodeSize.Height
+		subtreeHeights[node] = span
 		return
 	}
 
-	totalChildrenHeight := 0.0
 	for _, child := range node.Children {
 		calculateSubtreeHeights(child, nodeSizes, subtreeHeights, config)
-		totalChildrenHeight += subtreeHeights[child]
 	}
-
-	// 加上节点间的垂直间距
-	totalChildrenHeight += config.NodeSpacing * float64(len(node.Children)-1)
+	_, totalChildrenHeight := siblingSlots(node.Children, nodeSizes, subtreeHeights, config)
 
 	// 子树高度是自身高度和子节点总高度中的较大值
-	subtreeHeights[node] = math.Max(nodeSize.Height, totalChildrenHeight)
+	subtreeHeights[node] = math.Max(span, totalChildrenHeight)
 }
 
-// 水平思维导图布局算法（单方向）
-func horizontalMindmapLayoutDirectional(node *types.Node, x, y float64, direction int, nodeSizes map[*types.Node]*NodeSize, subtreeHeights map[*types.Node]float64, config *DrawConfig) {
+// 纵向思维导图布局算法（direction 为 1 时向下生长，-1 时向上生长）
+func verticalMindmapLayout(node *types.Node, x, y float64, direction, depth int, nodeSizes map[*types.Node]*NodeSize, subtreeWidths map[*types.Node]float64, config *DrawConfig) {
 	if node == nil {
 		return
 	}
@@ -403,39 +786,51 @@ func horizontalMindmapLayoutDirectional(node *types.Node, x, y float64, directio
 	node.X = x
 	node.Y = y
 
-	// 如果没有子节点，结束递归
 	if len(node.Children) == 0 {
 		return
 	}
 
-	// 计算子节点起始垂直位置
-	childrenTotalHeight := 0.0
-	for _, child := range node.Children {
-		childrenTotalHeight += subtreeHeights[child]
+	// 按对齐方式计算各子节点的水平位置
+	children, positions := placeChildren(node.Children, x, nodeSize.Width, nodeSizes, subtreeWidths, config)
+	for i, child := range children {
+		childSize := nodeSizes[child]
+		childY := y + float64(direction)*(nodeSize.Height/2+config.levelSpacing(depth)+childSize.Height/2)
+
+		verticalMindmapLayout(child, positions[i], childY, direction, depth+1, nodeSizes, subtreeWidths, config)
 	}
-	childrenTotalHeight += config.NodeSpacing * float64(len(node.Children)-1)
+}
 
-	currentY := y - childrenTotalHeight/2
+// 水平思维导图布局算法（单方向）
+func horizontalMindmapLayoutDirectional(node *types.Node, x, y float64, direction, depth int, nodeSizes map[*types.Node]*NodeSize, subtreeHeights map[*types.Node]float64, config *DrawConfig) {
+	if node == nil {
+		return
+	}
 
-	// 递归放置子节点
-	for _, child := range node.Children {
-		childSize := nodeSizes[child]
-		if childSize == nil {
-			continue
-		}
-		childSubtreeHeight := subtreeHeights[child]
-		// 将子节点垂直居中在其子树所占空间内
-		childY := currentY + childSubtreeHeight/2
-		childX := x + float64(direction)*(nodeSize.Width/2+config.LevelSpacing+childSize.Width/2)
+	nodeSize := nodeSizes[node]
+	if nodeSize == nil {
+		return
+	}
 
-		horizontalMindmapLayoutDirectional(child, childX, childY, direction, nodeSizes, subtreeHeights, config)
+	// 设置当前节点位置 (中心点)
+	node.X = x
+	node.Y = y
 
-		// 更新下一个子节点的起始Y坐标
-		currentY += childSubtreeHeight + config.NodeSpacing
+	// 如果没有子节点，结束递归
+	if len(node.Children) == 0 {
+		return
+	}
+
+	// 按对齐方式计算各子节点的垂直位置，并递归放置子节点
+	children, positions := placeChildren(node.Children, y, nodeSize.Height, nodeSizes, subtreeHeights, config)
+	for i, child := range children {
+		childSize := nodeSizes[child]
+		childX := x + float64(direction)*(nodeSize.Width/2+config.levelSpacing(depth)+childSize.Width/2)
+
+		horizontalMindmapLayoutDirectional(child, childX, positions[i], direction, depth+1, nodeSizes, subtreeHeights, config)
 	}
 }
 
-// 水平思维导图布局算法（左右分流）
+// 水平思维导图布局算法（左右分流），仅用于根节点
 func horizontalMindmapLayoutBothSides(node *types.Node, x, y float64, nodeSizes map[*types.Node]*NodeSize, subtreeHeights map[*types.Node]float64, config *DrawConfig) {
 	if node == nil {
 		return
@@ -454,63 +849,127 @@ func horizontalMindmapLayoutBothSides(node *types.Node, x, y float64, nodeSizes
 		return
 	}
 
-	leftGroup, rightGroup := splitChildrenBalanced(node.Children, subtreeHeights)
+	leftGroup, rightGroup := splitChildrenBalanced(node.Children, subtreeHeights, config.NodeSpacing)
 
 	layoutSide := func(children []*types.Node, direction int) {
 		if len(children) == 0 {
 			return
 		}
 
-		childrenTotalHeight := 0.0
-		for _, child := range children {
-			childrenTotalHeight += subtreeHeights[child]
+		placed, positions := placeChildren(children, y, nodeSize.Height, nodeSizes, subtreeHeights, config)
+		for i, child := range placed {
+			childSize := nodeSizes[child]
+			childX := x + float64(direction)*(nodeSize.Width/2+config.levelSpacing(0)+childSize.Width/2)
+
+			horizontalMindmapLayoutDirectional(child, childX, positions[i], direction, 1, nodeSizes, subtreeHeights, config)
 		}
-		childrenTotalHeight += config.NodeSpacing * float64(len(children)-1)
+	}
 
-		currentY := y - childrenTotalHeight/2
+	layoutSide(rightGroup, 1)
+	layoutSide(leftGroup, -1)
+}
 
-		for _, child := range children {
-			childSize := nodeSizes[child]
-			if childSize == nil {
-				continue
-			}
-			childSubtreeHeight := subtreeHeights[child]
-			childY := currentY + childSubtreeHeight/2
-			childX := x + float64(direction)*(nodeSize.Width/2+config.LevelSpacing+childSize.Width/2)
+// nodeSpan 返回节点在兄弟排列方向上的尺寸（横向布局为高度，纵向布局为宽度）
+func (c *DrawConfig) nodeSpan(size *NodeSize) float64 {
+	if c.isVertical() {
+		return size.Width
+	}
+	return size.Height
+}
 
-			horizontalMindmapLayoutDirectional(child, childX, childY, direction, nodeSizes, subtreeHeights, config)
+// siblingSlots 返回每个子节点在兄弟方向上占用的槽位长度及含间距的总长度。
+// 两端对齐时所有槽位取最大子树尺寸，使兄弟节点等距排列。
+func siblingSlots(children []*types.Node, nodeSizes map[*types.Node]*NodeSize, subtreeSpans map[*types.Node]float64, config *DrawConfig) ([]float64, float64) {
+	slots := make([]float64, 0, len(children))
+	maxSlot := 0.0
+	for _, child := range children {
+		if nodeSizes[child] == nil {
+			continue
+		}
+		slots = append(slots, subtreeSpans[child])
+		maxSlot = math.Max(maxSlot, subtreeSpans[child])
+	}
+	if len(slots) == 0 {
+		return slots, 0
+	}
 
-			currentY += childSubtreeHeight + config.NodeSpacing
+	total := config.NodeSpacing * float64(len(slots)-1)
+	for i := range slots {
+		if config.Alignment == "justify" {
+			slots[i] = maxSlot
 		}
+		total += slots[i]
 	}
+	return slots, total
+}
 
-	layoutSide(rightGroup, 1)
-	layoutSide(leftGroup, -1)
+// placeChildren 按对齐方式沿兄弟方向排列子节点，返回可放置的子节点及其中心坐标。
+// center 为父节点中心坐标，parentSpan 为父节点在该方向上的尺寸。
+func placeChildren(children []*types.Node, center, parentSpan float64, nodeSizes map[*types.Node]*NodeSize, subtreeSpans map[*types.Node]float64, config *DrawConfig) ([]*types.Node, []float64) {
+	slots, total := siblingSlots(children, nodeSizes, subtreeSpans, config)
+
+	// 顶部对齐时子节点从父节点上沿开始排列，否则整体居中于父节点
+	current := center - total/2
+	if config.Alignment == "top" {
+		current = center - parentSpan/2
+	}
+
+	placed := make([]*types.Node, 0, len(slots))
+	positions := make([]float64, 0, len(slots))
+	for _, child := range children {
+		childSize := nodeSizes[child]
+		if childSize == nil {
+			continue
+		}
+		slot := slots[len(placed)]
+		pos := current + slot/2
+		if config.Alignment == "top" {
+			pos = current + config.nodeSpan(childSize)/2
+		}
+		placed = append(placed, child)
+		positions = append(positions, pos)
+		current += slot + config.NodeSpacing
+	}
+	return placed, positions
 }
 
-func splitChildrenBalanced(children []*types.Node, subtreeHeights map[*types.Node]float64) ([]*types.Node, []*types.Node) {
-	var left []*types.Node
-	var right []*types.Node
-	leftHeight := 0.0
-	rightHeight := 0.0
+// splitChildrenBalanced 将子节点按原顺序切分为左右两组：前一段放在右侧、
+// 其余放在左侧，选择使两侧子树总高度（含节点间距）差值最小的切分点
+func splitChildrenBalanced(children []*types.Node, subtreeHeights map[*types.Node]float64, spacing float64) ([]*types.Node, []*types.Node) {
+	if len(children) == 0 {
+		return nil, nil
+	}
 
+	total := 0.0
 	for _, child := range children {
-		height := subtreeHeights[child]
-		if leftHeight <= rightHeight {
-			left = append(left, child)
-			leftHeight += height
-		} else {
-			right = append(right, child)
-			rightHeight += height
+		total += subtreeHeights[child]
+	}
+
+	groupHeight := func(sum float64, count int) float64 {
+		if count == 0 {
+			return 0
+		}
+		return sum + spacing*float64(count-1)
+	}
+
+	bestSplit := len(children)
+	bestDiff := math.MaxFloat64
+	prefix := 0.0
+	for k := 1; k <= len(children); k++ {
+		prefix += subtreeHeights[children[k-1]]
+		diff := math.Abs(groupHeight(prefix, k) - groupHeight(total-prefix, len(children)-k))
+		if diff < bestDiff {
+			bestDiff = diff
+			bestSplit = k
 		}
 	}
 
-	return left, right
+	return children[bestSplit:], children[:bestSplit]
 }
 
-// 绘制水平布局的连接线
-func drawConnectionsHorizontal(dc *gg.Context, node *types.Node, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig) {
-	if node == nil || len(node.Children) == 0 {
+// 绘制连接线（支持横向、纵向与径向布局）
+func drawConnectionsHorizontal(dc canvas, node *types.Node, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig) {
+	if node == nil || config.canceled() || len(node.Children) == 0 {
 		return
 	}
 
@@ -519,7 +978,7 @@ func drawConnectionsHorizontal(dc *gg.Context, node *types.Node, nodeSizes map[*
 		return
 	}
 
-	startY := node.Y * config.Scale
+	vertical := config.isVertical()
 
 	for _, child := range node.Children {
 		childSize := nodeSizes[child]
@@ -527,37 +986,49 @@ func drawConnectionsHorizontal(dc *gg.Context, node *types.Node, nodeSizes map[*
 			continue
 		}
 
-		endY := child.Y * config.Scale
-		isRight := child.X >= node.X
-		startX := (node.X + parentSize.Width/2) * config.Scale
-		endX := (child.X - childSize.Width/2) * config.Scale
-		if !isRight {
-			startX = (node.X - parentSize.Width/2) * config.Scale
-			endX = (child.X + childSize.Width/2) * config.Scale
-		}
-
-		if len(child.Children) == 0 { // 是叶子节点
-			// 对于叶子节点，连接线应在文本开始前停止
-			// 文本在 child.X 处水平居中
-			textGap := 5.0 // 线条与文本的间隙
-			if isRight {
-				textLeftEdgeX := child.X - childSize.ActualTextWidth/2
-				endX = (textLeftEdgeX - textGap) * config.Scale
-			} else {
-				textRightEdgeX := child.X + childSize.ActualTextWidth/2
-				endX = (textRightEdgeX + textGap) * config.Scale
-			}
-		}
-
 		// 设置连接线样式
 		dc.SetRGB(config.ConnectionLineColor[0], config.ConnectionLineColor[1], config.ConnectionLineColor[2])
 		dc.SetLineWidth(1.0 * config.Scale)
 
+		// 径向布局的连接线沿半径方向弯曲
+		if config.isRadial() {
+			drawRadialConnection(dc, node, child, parentSize, childSize, config)
+			drawConnectionsHorizontal(dc, child, nodeSizes, config)
+			continue
+		}
+
+		var startX, startY, endX, endY float64
+		if vertical {
+			startX, startY, endX, endY = verticalConnectionPoints(node, child, parentSize, childSize, config)
+		} else {
+			startX, startY, endX, endY = horizontalConnectionPoints(node, child, parentSize, childSize, config)
+		}
+
+		bend := defaultBend
+		if config.RouteConnectors {
+			bend = routeConnector(startX, startY, endX, endY, vertical, config.ConnectorStyle, node, child, config.obstacles, config.Scale)
+		}
+
+		// 绘制箭头时连接线在箭头底边结束，箭头尖端落在节点边框上
+		lineEndX, lineEndY := endX, endY
+		var arrowDX, arrowDY float64
+		arrow := config.Arrows
+		if arrow {
+			arrowDX, arrowDY, arrow = arrowDirection(config.ConnectorStyle, startX, startY, endX, endY, bend, vertical)
+		}
+		if arrow {
+			lineEndX -= arrowDX * arrowLength * config.Scale
+			lineEndY -= arrowDY * arrowLength * config.Scale
+		}
+
 		// 根据主题风格选择连接线绘制方法
-		if config.Theme != nil && config.Theme.IsSketchStyle() {
-			drawSketchConnection(dc, startX, startY, endX, endY, config)
+		if config.isSketch() {
+			drawSketchConnection(dc, startX, startY, lineEndX, lineEndY, bend, vertical, config)
 		} else {
-			drawStandardConnection(dc, startX, startY, endX, endY)
+			drawStandardConnection(dc, config.ConnectorStyle, startX, startY, lineEndX, lineEndY, bend, vertical)
+		}
+		if arrow {
+			drawArrowhead(dc, endX, endY, arrowDX, arrowDY, config.Scale)
 		}
 
 		// 递归绘制子节点的连接线
@@ -565,40 +1036,120 @@ func drawConnectionsHorizontal(dc *gg.Context, node *types.Node, nodeSizes map[*
 	}
 }
 
-// 绘制标准风格连接线
-func drawStandardConnection(dc *gg.Context, startX, startY, endX, endY float64) {
+// 计算横向布局中连接线的起止点（已乘以缩放）
+func horizontalConnectionPoints(node, child *types.Node, parentSize, childSize *NodeSize, config *DrawConfig) (startX, startY, endX, endY float64) {
+	startY = node.Y * config.Scale
+	endY = child.Y * config.Scale
+	isRight := child.X >= node.X
+	startX = (node.X + parentSize.Width/2) * config.Scale
+	endX = (child.X - childSize.Width/2) * config.Scale
+	if !isRight {
+		startX = (node.X - parentSize.Width/2) * config.Scale
+		endX = (child.X + childSize.Width/2) * config.Scale
+	}
+
+	// 绘制箭头时连接线终止于节点边框，否则箭头会被随后绘制的节点背景遮住
+	if len(child.Children) == 0 && child.Shape == types.ShapeDefault && !config.Arrows { // 是默认形状的叶子节点
+		// 对于叶子节点，连接线应在文本开始前停止
+		// 文本在 child.X 处水平居中
+		textGap := 5.0 // 线条与文本的间隙
+		// 文本块随对齐方式偏移时，连接线同样止于其实际边缘
+		textLeftEdgeX, textRightEdgeX := textSpan(child, childSize, config)
+		if isRight {
+			endX = (textLeftEdgeX - textGap) * config.Scale
+		} else {
+			endX = (textRightEdgeX + textGap) * config.Scale
+		}
+	}
+	return startX, startY, endX, endY
+}
+
+// 计算纵向布局中连接线的起止点（已乘以缩放）
+func verticalConnectionPoints(node, child *types.Node, parentSize, childSize *NodeSize, config *DrawConfig) (startX, startY, endX, endY float64) {
+	startX = node.X * config.Scale
+	endX = child.X * config.Scale
+	isDown := child.Y >= node.Y
+	startY = (node.Y + parentSize.Height/2) * config.Scale
+	endY = (child.Y - childSize.Height/2) * config.Scale
+	if !isDown {
+		startY = (node.Y - parentSize.Height/2) * config.Scale
+		endY = (child.Y + childSize.Height/2) * config.Scale
+	}
+
+	// 绘制箭头时连接线终止于节点边框，否则箭头会被随后绘制的节点背景遮住
+	if len(child.Children) == 0 && child.Shape == types.ShapeDefault && !config.Arrows { // 是默认形状的叶子节点
+		// 与横向布局一致，连接线在文本块的上（下）边缘前停止
+		textGap := 5.0
+		textHalfHeight := childSize.textBlockHeight() / 2
+		if isDown {
+			endY = (child.Y - textHalfHeight - textGap) * config.Scale
+		} else {
+			endY = (child.Y + textHalfHeight + textGap) * config.Scale
+		}
+	}
+	return startX, startY, endX, endY
+}
+
+// 绘制标准风格连接线，vertical 为 true 时沿垂直方向弯曲；bend 为转折位置，0.5 为对称的 S 形
+func drawStandardConnection(dc canvas, style string, startX, startY, endX, endY, bend float64, vertical bool) {
+	// 直线与折线按顶点依次连接
+	if points := connectorPolyline(style, startX, startY, endX, endY, bend, vertical); points != nil {
+		dc.MoveTo(points[0].X, points[0].Y)
+		for _, p := range points[1:] {
+			dc.LineTo(p.X, p.Y)
+		}
+		dc.Stroke()
+		return
+	}
+
 	// 绘制平滑的S形连接线 (Bézier curve)
 	dc.MoveTo(startX, startY)
-	controlX1 := startX + (endX-startX)/2
-	controlY1 := startY
-	controlX2 := startX + (endX-startX)/2
-	controlY2 := endY
+	controlX1, controlY1, controlX2, controlY2 := connectorControls(startX, startY, endX, endY, bend, vertical)
 	dc.CubicTo(controlX1, controlY1, controlX2, controlY2, endX, endY)
 	dc.Stroke()
 }
 
 // 绘制手绘风格连接线
-func drawSketchConnection(dc *gg.Context, startX, startY, endX, endY float64, config *DrawConfig) {
+func drawSketchConnection(dc canvas, startX, startY, endX, endY, bend float64, vertical bool, config *DrawConfig) {
 	sketchConfig := config.Theme.SketchConfig
+	rng := config.rng
 	roughness := sketchConfig.Roughness * config.Scale
 
+	points := connectorPolyline(config.ConnectorStyle, startX, startY, endX, endY, bend, vertical)
+
 	// 多次绘制连接线模拟手绘效果
 	for i := 0; i < sketchConfig.Iterations; i++ {
 		dc.Push()
 
 		// 每次绘制略有偏移
-		offsetX := (rand.Float64() - 0.5) * sketchConfig.LineVariation * config.Scale
-		offsetY := (rand.Float64() - 0.5) * sketchConfig.LineVariation * config.Scale
+		offsetX := (rng.Float64() - 0.5) * sketchConfig.LineVariation * config.Scale
+		offsetY := (rng.Float64() - 0.5) * sketchConfig.LineVariation * config.Scale
 		dc.Translate(offsetX, offsetY)
 
+		// 直线与折线的每一段以抖动的线段绘制
+		if points != nil {
+			for j := 1; j < len(points); j++ {
+				drawRoughLine(dc, points[j-1].X, points[j-1].Y, points[j].X, points[j].Y, roughness*0.5, rng)
+			}
+			dc.Stroke()
+			dc.Pop()
+			continue
+		}
+
 		// 创建不规则的贝塞尔曲线
 		dc.MoveTo(startX, startY)
 
 		// 控制点也添加随机扰动
-		controlX1 := startX + (endX-startX)/2 + (rand.Float64()-0.5)*roughness
-		controlY1 := startY + (rand.Float64()-0.5)*roughness*0.5
-		controlX2 := startX + (endX-startX)/2 + (rand.Float64()-0.5)*roughness
-		controlY2 := endY + (rand.Float64()-0.5)*roughness*0.5
+		controlX1 := startX + (endX-startX)*bend + (rng.Float64()-0.5)*roughness
+		controlY1 := startY + (rng.Float64()-0.5)*roughness*0.5
+		controlX2 := startX + (endX-startX)*bend + (rng.Float64()-0.5)*roughness
+		controlY2 := endY + (rng.Float64()-0.5)*roughness*0.5
+		if vertical {
+			controlX1 = startX + (rng.Float64()-0.5)*roughness*0.5
+			controlY1 = startY + (endY-startY)*bend + (rng.Float64()-0.5)*roughness
+			controlX2 = endX + (rng.Float64()-0.5)*roughness*0.5
+			controlY2 = startY + (endY-startY)*bend + (rng.Float64()-0.5)*roughness
+		}
 
 		dc.CubicTo(controlX1, controlY1, controlX2, controlY2, endX, endY)
 		dc.Stroke()
@@ -608,18 +1159,28 @@ func drawSketchConnection(dc *gg.Context, startX, startY, endX, endY float64, co
 }
 
 // 绘制单个节点
-func drawSingleNode(dc *gg.Context, node *types.Node, isRoot bool, nodeSizes map[*types.Node]*NodeSize, scale float64, config *DrawConfig) {
+func drawSingleNode(dc canvas, node *types.Node, isRoot bool, branch, depth int, nodeSizes map[*types.Node]*NodeSize, scale float64, config *DrawConfig) {
 	if node == nil {
 		return
 	}
 
-	style := getNodeStyle(node, isRoot, config)
+	style := config.highlightStyle(node, getNodeStyle(node, isRoot, branch, depth, config))
 	nodeSize := nodeSizes[node]
 
 	if nodeSize == nil {
 		return
 	}
 
+	// 支持超链接的绘制面将整个节点包裹在链接中
+	if lc, ok := dc.(linkCanvas); ok && node.Link != "" && lc.beginLink(node.Link) {
+		defer lc.endLink()
+	}
+	// 支持悬停提示的绘制面将备注作为节点的提示
+	if tc, ok := dc.(tooltipCanvas); ok && node.Note != "" {
+		tc.beginTooltip(node.Note)
+		defer tc.endTooltip()
+	}
+
 	// 计算节点位置
 	x := (node.X - nodeSize.Width/2) * scale
 	y := (node.Y - nodeSize.Height/2) * scale
@@ -627,50 +1188,88 @@ func drawSingleNode(dc *gg.Context, node *types.Node, isRoot bool, nodeSizes map
 	h := nodeSize.Height * scale
 	r := config.CornerRadius * scale
 
-	// 根据主题风格选择绘制方法
-	if config.Theme != nil && config.Theme.IsSketchStyle() {
-		drawSketchNode(dc, x, y, w, h, r, style, scale, config.Theme.SketchConfig)
+	// 阴影绘制在节点背景之下
+	if config.Shadow != nil {
+		drawNodeShadow(dc, config.Shadow, node.Shape, x, y, w, h, r, scale)
+	}
+
+	// 根据主题风格选择绘制方法；显式指定的形状始终使用标准描边
+	if node.Shape == types.ShapeDefault && config.isSketch() {
+		drawSketchNode(dc, x, y, w, h, r, style, scale, config.Theme.SketchConfig, config.rng)
 	} else {
-		drawStandardNode(dc, x, y, w, h, r, style, scale)
+		drawStandardNode(dc, node.Shape, x, y, w, h, r, style, scale)
+	}
+
+	// 骨架预览以占位条代替文本
+	if config.skeleton {
+		drawPlaceholderBar(dc, node, nodeSize, style, scale, config)
+		return
 	}
 
 	// 绘制文本
 	dc.SetRGB(style.TextColor[0], style.TextColor[1], style.TextColor[2])
-	scaledLineHeight := config.LineHeight * scale
-	startY := (node.Y * scale) - (float64(len(nodeSize.Lines))*scaledLineHeight)/2 + scaledLineHeight/2
-
+	scaledLineHeight := nodeSize.LineHeight * scale
+	textHeight := nodeSize.textBlockHeight() * scale
+	startY := (node.Y * scale) - textHeight/2 + scaledLineHeight/2
+
+	// 文本块按对齐方式放置；图标与首行文本对齐，位于文本块左端（从右到左时为右端），各行在剩余宽度内对齐
+	spanLeft, spanRight := textSpan(node, nodeSize, config)
+	left, right := spanLeft*scale, spanRight*scale
+	textLeft, textRight := left, right
+	if nodeSize.Icon != "" && config.RTL {
+		drawText(dc, config, nodeSize.Icon, right, startY, 1, 0.5)
+		textRight -= nodeSize.IconWidth * scale
+	} else if nodeSize.Icon != "" {
+		drawText(dc, config, nodeSize.Icon, left, startY, 0, 0.5)
+		textLeft += nodeSize.IconWidth * scale
+	}
+
+	var marks markState
 	for i, line := range nodeSize.Lines {
 		y := startY + float64(i)*scaledLineHeight
-		dc.DrawStringAnchored(line, node.X*scale, y, 0.5, 0.5)
+		if hasMarks(line) || marks.active() {
+			drawMarkedLine(dc, line, textLeft, textRight, y, scaledLineHeight, &marks, style, config)
+			continue
+		}
+		x, ax := alignedAnchor(config.TextAlign, textLeft, textRight)
+		drawText(dc, config, line, x, y, ax, 0.5)
+	}
+
+	// 备注与脚注依次排在文本下方
+	below := startY - scaledLineHeight/2 + float64(len(nodeSize.Lines))*scaledLineHeight
+	if len(nodeSize.Note) > 0 {
+		drawNote(dc, nodeSize.Note, left, right, below, scaledLineHeight, style, config)
+		below += nodeSize.noteHeight() * scale
+	}
+	if nodeSize.Footer != "" {
+		drawMetaFooter(dc, nodeSize.Footer, left, right, below+nodeSize.footerHeight()*scale/2, style, config)
 	}
 }
 
 // 绘制标准风格节点
-func drawStandardNode(dc *gg.Context, x, y, w, h, r float64, style *types.NodeStyle, scale float64) {
-	// 绘制节点背景
-	dc.SetRGB(style.FillColor[0], style.FillColor[1], style.FillColor[2])
-	drawRoundedRect(dc, x, y, w, h, r)
-	dc.Fill()
+func drawStandardNode(dc canvas, shape types.Shape, x, y, w, h, r float64, style *types.NodeStyle, scale float64) {
+	// 绘制节点背景，带渐变时渐变填充在节点轮廓内
+	drawShapePath(dc, shape, x, y, w, h, r)
+	fillStyle(dc, style, x, y, w, h)
 
 	// 绘制节点边框
 	dc.SetRGB(style.StrokeColor[0], style.StrokeColor[1], style.StrokeColor[2])
 	dc.SetLineWidth(0.8 * scale)
-	drawRoundedRect(dc, x, y, w, h, r)
+	drawShapePath(dc, shape, x, y, w, h, r)
 	dc.Stroke()
 }
 
 // 绘制手绘风格节点
-func drawSketchNode(dc *gg.Context, x, y, w, h, r float64, style *types.NodeStyle, scale float64, sketchConfig *theme.SketchConfig) {
+func drawSketchNode(dc canvas, x, y, w, h, r float64, style *types.NodeStyle, scale float64, sketchConfig *theme.SketchConfig, rng *rand.Rand) {
 	// 绘制背景填充
 	if sketchConfig.FillPattern == "crosshatch" {
-		drawCrosshatchFill(dc, x, y, w, h, style.FillColor, sketchConfig.Roughness*scale)
+		drawCrosshatchFill(dc, x, y, w, h, style.FillColor, sketchConfig.Roughness*scale, rng)
 	} else if sketchConfig.FillPattern == "dots" {
-		drawDottedFill(dc, x, y, w, h, style.FillColor, sketchConfig.Roughness*scale)
+		drawDottedFill(dc, x, y, w, h, style.FillColor, sketchConfig.Roughness*scale, rng)
 	} else {
-		// 标准填充但使用手绘边框
-		dc.SetRGB(style.FillColor[0], style.FillColor[1], style.FillColor[2])
-		drawRoughRect(dc, x, y, w, h, sketchConfig.Roughness*scale)
-		dc.Fill()
+		// 标准填充（或渐变）但使用手绘边框
+		drawRoughRect(dc, x, y, w, h, sketchConfig.Roughness*scale, rng)
+		fillStyle(dc, style, x, y, w, h)
 	}
 
 	// 绘制手绘边框
@@ -681,17 +1280,17 @@ func drawSketchNode(dc *gg.Context, x, y, w, h, r float64, style *types.NodeStyl
 	for i := 0; i < sketchConfig.Iterations; i++ {
 		dc.Push()
 		// 每次描边略有偏移
-		offsetX := (rand.Float64() - 0.5) * sketchConfig.LineVariation * scale
-		offsetY := (rand.Float64() - 0.5) * sketchConfig.LineVariation * scale
+		offsetX := (rng.Float64() - 0.5) * sketchConfig.LineVariation * scale
+		offsetY := (rng.Float64() - 0.5) * sketchConfig.LineVariation * scale
 		dc.Translate(offsetX, offsetY)
-		drawRoughRect(dc, x, y, w, h, sketchConfig.Roughness*scale)
+		drawRoughRect(dc, x, y, w, h, sketchConfig.Roughness*scale, rng)
 		dc.Stroke()
 		dc.Pop()
 	}
 }
 
 // 绘制手绘风格的不规则矩形
-func drawRoughRect(dc *gg.Context, x, y, w, h, roughness float64) {
+func drawRoughRect(dc canvas, x, y, w, h, roughness float64, rng *rand.Rand) {
 	// 创建不规则的矩形路径
 	segments := 8 // 每条边分成8段
 
@@ -704,7 +1303,7 @@ func drawRoughRect(dc *gg.Context, x, y, w, h, roughness float64) {
 		px := x + w*t
 		py := y
 		if i > 0 && i < segments {
-			py += (rand.Float64() - 0.5) * roughness
+			py += (rng.Float64() - 0.5) * roughness
 		}
 		if i == 0 {
 			dc.MoveTo(px, py)
@@ -719,7 +1318,7 @@ func drawRoughRect(dc *gg.Context, x, y, w, h, roughness float64) {
 		px := x + w
 		py := y + h*t
 		if i < segments {
-			px += (rand.Float64() - 0.5) * roughness
+			px += (rng.Float64() - 0.5) * roughness
 		}
 		dc.LineTo(px, py)
 	}
@@ -730,7 +1329,7 @@ func drawRoughRect(dc *gg.Context, x, y, w, h, roughness float64) {
 		px := x + w*t
 		py := y + h
 		if i > 0 && i < segments {
-			py += (rand.Float64() - 0.5) * roughness
+			py += (rng.Float64() - 0.5) * roughness
 		}
 		dc.LineTo(px, py)
 	}
@@ -741,7 +1340,7 @@ func drawRoughRect(dc *gg.Context, x, y, w, h, roughness float64) {
 		px := x
 		py := y + h*t
 		if i > 1 {
-			px += (rand.Float64() - 0.5) * roughness
+			px += (rng.Float64() - 0.5) * roughness
 		}
 		dc.LineTo(px, py)
 	}
@@ -750,13 +1349,13 @@ func drawRoughRect(dc *gg.Context, x, y, w, h, roughness float64) {
 }
 
 // 绘制交叉填充图案
-func drawCrosshatchFill(dc *gg.Context, x, y, w, h float64, color [3]float64, roughness float64) {
+func drawCrosshatchFill(dc canvas, x, y, w, h float64, color [3]float64, roughness float64, rng *rand.Rand) {
 	dc.SetRGB(color[0], color[1], color[2])
 
 	// 绘制背景色
 	dc.Push()
 	dc.SetRGBA(color[0], color[1], color[2], 0.3) // 浅色背景
-	drawRoughRect(dc, x, y, w, h, roughness)
+	drawRoughRect(dc, x, y, w, h, roughness, rng)
 	dc.Fill()
 	dc.Pop()
 
@@ -770,7 +1369,7 @@ func drawCrosshatchFill(dc *gg.Context, x, y, w, h float64, color [3]float64, ro
 		startY := y + h
 		endX := i + h
 		endY := y
-		drawRoughLine(dc, startX, startY, endX, endY, roughness*0.5)
+		drawRoughLine(dc, startX, startY, endX, endY, roughness*0.5, rng)
 		dc.Stroke()
 	}
 
@@ -780,19 +1379,19 @@ func drawCrosshatchFill(dc *gg.Context, x, y, w, h float64, color [3]float64, ro
 		startY := y
 		endX := i + h
 		endY := y + h
-		drawRoughLine(dc, startX, startY, endX, endY, roughness*0.5)
+		drawRoughLine(dc, startX, startY, endX, endY, roughness*0.5, rng)
 		dc.Stroke()
 	}
 }
 
 // 绘制点状填充图案
-func drawDottedFill(dc *gg.Context, x, y, w, h float64, color [3]float64, roughness float64) {
+func drawDottedFill(dc canvas, x, y, w, h float64, color [3]float64, roughness float64, rng *rand.Rand) {
 	dc.SetRGB(color[0], color[1], color[2])
 
 	// 绘制背景色
 	dc.Push()
 	dc.SetRGBA(color[0], color[1], color[2], 0.2) // 浅色背景
-	drawRoughRect(dc, x, y, w, h, roughness)
+	drawRoughRect(dc, x, y, w, h, roughness, rng)
 	dc.Fill()
 	dc.Pop()
 
@@ -803,8 +1402,8 @@ func drawDottedFill(dc *gg.Context, x, y, w, h float64, color [3]float64, roughn
 	for px := x + spacing/2; px < x+w; px += spacing {
 		for py := y + spacing/2; py < y+h; py += spacing {
 			// 添加随机偏移
-			dotX := px + (rand.Float64()-0.5)*roughness*0.5
-			dotY := py + (rand.Float64()-0.5)*roughness*0.5
+			dotX := px + (rng.Float64()-0.5)*roughness*0.5
+			dotY := py + (rng.Float64()-0.5)*roughness*0.5
 
 			dc.DrawCircle(dotX, dotY, 0.5)
 			dc.Fill()
@@ -813,7 +1412,7 @@ func drawDottedFill(dc *gg.Context, x, y, w, h float64, color [3]float64, roughn
 }
 
 // 绘制手绘风格的线条
-func drawRoughLine(dc *gg.Context, x1, y1, x2, y2, roughness float64) {
+func drawRoughLine(dc canvas, x1, y1, x2, y2, roughness float64, rng *rand.Rand) {
 	segments := int(math.Max(5, math.Sqrt((x2-x1)*(x2-x1)+(y2-y1)*(y2-y1))/10))
 
 	dc.MoveTo(x1, y1)
@@ -825,34 +1424,46 @@ func drawRoughLine(dc *gg.Context, x1, y1, x2, y2, roughness float64) {
 
 		// 添加随机扰动，但保持端点不变
 		if i < segments {
-			x += (rand.Float64() - 0.5) * roughness
-			y += (rand.Float64() - 0.5) * roughness
+			x += (rng.Float64() - 0.5) * roughness
+			y += (rng.Float64() - 0.5) * roughness
 		}
 
 		dc.LineTo(x, y)
 	}
 }
 
-func calculateNodeSizes(dc *gg.Context, node *types.Node, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig, cache textMeasureCache) {
-	if node == nil {
+func calculateNodeSizes(dc *gg.Context, node *types.Node, depth int, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig, cache *textMeasureCache) {
+	if node == nil || config.canceled() {
 		return
 	}
 
-	// 计算当前节点的尺寸，其宽度仅由其自身文本决定
-	size := calculateTextWrapping(dc, node.Text, config, cache)
+	// 计算当前节点的尺寸，其宽度仅由其自身文本决定，行高随层级而定
+	// 字体包含图标字形时在文本左侧为其预留宽度
+	var iconWidth float64
+	icon := config.nodeIcon(node.Icon)
+	if icon != "" {
+		iconWidth = measureStringCached(dc, icon, cache) + iconGap
+	}
+	size := calculateTextWrapping(dc, markedText(node), config.lineHeightFor(node, depth), iconWidth, config, cache)
+	if icon != "" {
+		size.Icon, size.IconWidth = icon, iconWidth
+	}
+	addNote(dc, size, node.Note, config, cache)
+	addMetaFooter(dc, size, metaFooter(node, config.MetaKeys), config, cache)
+	applyShapeSize(size, node.Shape, config)
 	nodeSizes[node] = size
 
 	// 递归为所有子节点计算尺寸
 	for _, child := range node.Children {
-		calculateNodeSizes(dc, child, nodeSizes, config, cache)
+		calculateNodeSizes(dc, child, depth+1, nodeSizes, config, cache)
 	}
 }
 
-// 修改计算文本换行和节点尺寸的函数，提高效率和美观度
-func calculateTextWrapping(dc *gg.Context, text string, config *DrawConfig, cache textMeasureCache) *NodeSize {
+// 修改计算文本换行和节点尺寸的函数，提高效率和美观度。reserve 为文本左侧图标占用的宽度
+func calculateTextWrapping(dc *gg.Context, text string, lineHeight, reserve float64, config *DrawConfig, cache *textMeasureCache) *NodeSize {
 	words := splitIntoWords(text)
 	if len(words) == 0 {
-		return &NodeSize{Width: config.MinNodeWidth, Height: config.MinNodeHeight, ActualTextWidth: 0}
+		return &NodeSize{Width: max(config.MinNodeWidth, reserve+2*config.TextPadding), Height: config.MinNodeHeight, ActualTextWidth: reserve, LineHeight: lineHeight}
 	}
 
 	// 计算单行文本宽度
@@ -863,8 +1474,8 @@ func calculateTextWrapping(dc *gg.Context, text string, config *DrawConfig, cach
 	spaceW := measureStringCached(dc, " ", cache)
 	textWidth += float64(len(words)-1) * spaceW
 
-	// 添加文本内边距
-	nodeWidth := textWidth + 2*config.TextPadding
+	// 添加文本内边距与图标宽度
+	nodeWidth := textWidth + reserve + 2*config.TextPadding
 
 	// 确保节点宽度在限制范围内
 	if nodeWidth < config.MinNodeWidth {
@@ -874,7 +1485,7 @@ func calculateTextWrapping(dc *gg.Context, text string, config *DrawConfig, cach
 	}
 
 	// 使用简化的换行策略
-	availableWidth := nodeWidth - 2*config.TextPadding
+	availableWidth := nodeWidth - 2*config.TextPadding - reserve
 	lines := breakTextIntoLines(dc, words, availableWidth, cache)
 
 	// 检查是否存在非常长的行，如果有，对这些行再次进行拆分
@@ -929,27 +1540,44 @@ func calculateTextWrapping(dc *gg.Context, text string, config *DrawConfig, cach
 	}
 
 	// 计算节点高度
-	nodeHeight := float64(len(finalLines))*config.LineHeight + 2*config.TextPadding
+	nodeHeight := float64(len(finalLines))*lineHeight + 2*config.TextPadding
 	if nodeHeight < config.MinNodeHeight {
 		nodeHeight = config.MinNodeHeight
 	}
 
+	// 短文本节点按最小宽高比加宽，使节点外观更统一
+	nodeWidth = config.widenToAspectRatio(nodeWidth, nodeHeight)
+
 	return &NodeSize{
 		Width:           nodeWidth,
 		Height:          nodeHeight,
 		Lines:           finalLines,
-		ActualTextWidth: maxLineWidth,
+		ActualTextWidth: maxLineWidth + reserve,
+		LineHeight:      lineHeight,
 	}
 }
 
 // 新增一个辅助函数用于文本换行
-func breakTextIntoLines(dc *gg.Context, words []string, availableWidth float64, cache textMeasureCache) []string {
+func breakTextIntoLines(dc *gg.Context, words []string, availableWidth float64, cache *textMeasureCache) []string {
 	var lines []string
 	currentLine := ""
 	currentWidth := 0.0
 
 	for i, word := range words {
 		wordWidth := measureStringCached(dc, word, cache)
+
+		// 单个词（如长网址）比整行还宽时按字符拆开，前面的片段各占一行，最后一段继续参与排版
+		if wordWidth > availableWidth {
+			if currentLine != "" {
+				lines = append(lines, currentLine)
+			}
+			pieces := breakLongWord(dc, word, availableWidth, cache)
+			lines = append(lines, pieces[:len(pieces)-1]...)
+			currentLine = pieces[len(pieces)-1]
+			currentWidth = measureStringCached(dc, currentLine, cache)
+			continue
+		}
+
 		spaceWidth := 0.0
 		if i > 0 && currentLine != "" {
 			spaceWidth = measureStringCached(dc, " ", cache)
@@ -979,12 +1607,76 @@ func breakTextIntoLines(dc *gg.Context, words []string, availableWidth float64,
 	return lines
 }
 
-func measureStringCached(dc *gg.Context, text string, cache textMeasureCache) float64 {
-	if width, ok := cache[text]; ok {
+// breakLongWord 将超出可用宽度的词按字符拆成多段，每段（含连字符）都不超过可用宽度。
+// 在两个字母之间断开的纯字母数字词在断点处补连字符；网址等含标点的词优先在 / . - 等标点之后断开。
+// 可用宽度连一个字符都放不下时每段至少保留一个字符。
+func breakLongWord(dc *gg.Context, word string, availableWidth float64, cache *textMeasureCache) []string {
+	runes := []rune(word)
+	hyphenate := true
+	for _, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !isMark(r) {
+			hyphenate = false
+			break
+		}
+	}
+
+	var pieces []string
+	for len(runes) > 0 {
+		if measureStringCached(dc, string(runes), cache) <= availableWidth {
+			pieces = append(pieces, string(runes))
+			break
+		}
+		// 找到能放下的最长前缀，断开处若需要连字符则一并计入宽度
+		n := 1
+		for n < len(runes) {
+			piece := string(runes[:n+1])
+			if hyphenate && breaksLetters(runes, n+1) {
+				piece += "-"
+			}
+			if measureStringCached(dc, piece, cache) > availableWidth {
+				break
+			}
+			n++
+		}
+		if !hyphenate {
+			// 在前缀后半段的标点之后断开，使网址按路径片段换行
+			for j := n; j >= n/2 && j > 0; j-- {
+				if strings.ContainsRune("/.-_?&=#:", runes[j-1]) {
+					n = j
+					break
+				}
+			}
+		}
+		piece := string(runes[:n])
+		if hyphenate && breaksLetters(runes, n) {
+			piece += "-"
+		}
+		pieces = append(pieces, piece)
+		runes = runes[n:]
+	}
+	return pieces
+}
+
+// breaksLetters 判断在 runes[n] 之前断开是否会把两个拉丁字母分开（需要补连字符）
+func breaksLetters(runes []rune, n int) bool {
+	if n <= 0 || n >= len(runes) {
+		return false
+	}
+	isLatin := func(r rune) bool { return unicode.Is(unicode.Latin, r) }
+	return isLatin(runes[n-1]) && isLatin(runes[n])
+}
+
+func measureStringCached(dc *gg.Context, text string, cache *textMeasureCache) float64 {
+	if width, ok := cache.widths[text]; ok {
 		return width
 	}
-	width, _ := dc.MeasureString(text)
-	cache[text] = width
+	var width float64
+	if hasMarks(text) {
+		width = measureMarked(text, func(s string) float64 { return cache.shaper.MeasureString(dc, s) })
+	} else {
+		width = cache.shaper.MeasureString(dc, text)
+	}
+	cache.widths[text] = width
 	return width
 }
 
@@ -998,7 +1690,10 @@ func splitIntoWords(text string) []string {
 		isHan := unicode.Is(unicode.Han, r)
 		isSpace := unicode.IsSpace(r)
 
-		if isSpace {
+		if isMark(r) {
+			// 行内标记不占宽度，也不影响中英文分词
+			currentWord = append(currentWord, r)
+		} else if isSpace {
 			// 遇到空格，结束当前单词（无论是中文还是英文）
 			if len(currentWord) > 0 {
 				words = append(words, string(currentWord))
@@ -1034,37 +1729,23 @@ func splitIntoWords(text string) []string {
 	return words
 }
 
-func calculateTreeMetrics(node *types.Node, level int, maxDepth *int, levelCounts map[int]int) {
-	if node == nil {
-		return
-	}
-
-	// 更新最大深度
-	if level > *maxDepth {
-		*maxDepth = level
-	}
-
-	// 更新当前层的节点数
-	levelCounts[level]++
-
-	// 递归处理子节点
-	for _, child := range node.Children {
-		calculateTreeMetrics(child, level+1, maxDepth, levelCounts)
-	}
-}
-
 // 绘制所有节点（与连接线分离，确保节点绘制在连接线上方）
-func drawAllNodes(dc *gg.Context, node *types.Node, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig) {
-	if node == nil {
+// branch 为节点所属的根节点子分支序号（根节点为 -1），depth 为节点深度
+func drawAllNodes(dc canvas, node *types.Node, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig, branch, depth int) {
+	if node == nil || config.canceled() {
 		return
 	}
 
 	// 绘制当前节点
-	drawSingleNode(dc, node, node == root, nodeSizes, config.Scale, config)
+	drawSingleNode(dc, node, depth == 0, branch, depth, nodeSizes, config.Scale, config)
 
-	// 递归处理所有子节点
-	for _, child := range node.Children {
-		drawAllNodes(dc, child, nodeSizes, config)
+	// 递归处理所有子节点，根节点的子节点各自开启一个新分支
+	for i, child := range node.Children {
+		childBranch := branch
+		if depth == 0 {
+			childBranch = i
+		}
+		drawAllNodes(dc, child, nodeSizes, config, childBranch, depth+1)
 	}
 }
 
@@ -1099,11 +1780,16 @@ func calculateBoundsWithSizes(node *types.Node, nodeSizes map[*types.Node]*NodeS
 	}
 }
 
-func getNodeStyle(node *types.Node, isRoot bool, config *DrawConfig) *types.NodeStyle {
+func getNodeStyle(node *types.Node, isRoot bool, branch, depth int, config *DrawConfig) *types.NodeStyle {
 	if node.Style != nil {
 		return node.Style
 	}
 
+	// 配置了分支配色时，非根节点按所属分支取色
+	if !isRoot && branch >= 0 && len(config.BranchColors) > 0 {
+		return branchNodeStyle(config.BranchColors[branch%len(config.BranchColors)], depth)
+	}
+
 	// 如果有主题配置，使用主题的样式
 	if config.Theme != nil {
 		nodeStyles := config.Theme.GetNodeStyles()
@@ -1117,19 +1803,9 @@ func getNodeStyle(node *types.Node, isRoot bool, config *DrawConfig) *types.Node
 			return nodeStyles["leaf"]
 		}
 
-		// 根据子节点类型判断层级
-		hasGrandchildren := false
-		for _, child := range node.Children {
-			if len(child.Children) > 0 {
-				hasGrandchildren = true
-				break
-			}
-		}
-
-		if hasGrandchildren {
-			return nodeStyles["level1"]
-		} else {
-			return nodeStyles["level2"]
+		// 按距根节点的深度取层级样式，超出已定义层级时沿用最后一层
+		if style := config.Theme.LevelStyle(depth); style != nil {
+			return style
 		}
 	}
 
@@ -1158,7 +1834,24 @@ func getNodeStyle(node *types.Node, isRoot bool, config *DrawConfig) *types.Node
 	}
 }
 
-func drawRoundedRect(dc *gg.Context, x, y, w, h, r float64) {
+// branchLightenStep 分支配色每加深一层向白色混合的比例
+const branchLightenStep = 0.2
+
+// branchNodeStyle 根据分支颜色与深度生成节点样式，越深的层级颜色越浅
+func branchNodeStyle(color [3]float64, depth int) *types.NodeStyle {
+	t := math.Min(float64(depth-1)*branchLightenStep, 0.8)
+	var fill [3]float64
+	for i := range fill {
+		fill[i] = color[i] + (1-color[i])*t
+	}
+	return &types.NodeStyle{
+		FillColor:   fill,
+		StrokeColor: color,
+		TextColor:   contrastTextColor(fill),
+	}
+}
+
+func drawRoundedRect(dc canvas, x, y, w, h, r float64) {
 	// Ensure radius is not too large
 	r = math.Min(r, math.Min(w/2, h/2))
 
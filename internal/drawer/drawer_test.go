@@ -2,10 +2,18 @@ package drawer
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"image/png"
 	"io"
+	"math"
 	"os"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
 	"github.com/hellodeveye/mindmapgen/pkg/types"
 )
 
@@ -102,3 +110,589 @@ func TestDrawLayoutDirectional(t *testing.T) {
 		})
 	}
 }
+
+func TestDrawSketchDeterministic(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{
+			Text: "Root",
+			Children: []*types.Node{
+				{Text: "Child1", Children: []*types.Node{{Text: "Leaf"}}},
+				{Text: "Child2"},
+			},
+		}
+	}
+
+	for _, themeName := range []string{"sketch", "sketch-dots"} {
+		t.Run(themeName, func(t *testing.T) {
+			var first, second bytes.Buffer
+			if err := Draw(newTree(), &first, WithTheme(themeName)); err != nil {
+				t.Fatalf("first draw failed: %v", err)
+			}
+			if err := Draw(newTree(), &second, WithTheme(themeName)); err != nil {
+				t.Fatalf("second draw failed: %v", err)
+			}
+			if !bytes.Equal(first.Bytes(), second.Bytes()) {
+				t.Fatalf("expected identical output for the same seed")
+			}
+		})
+	}
+}
+
+func TestDrawLayoutVertical(t *testing.T) {
+	tests := []struct {
+		name      string
+		layout    string
+		expectDir int
+	}{
+		{name: "down", layout: "down", expectDir: 1},
+		{name: "up", layout: "up", expectDir: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &types.Node{
+				Text: "Root",
+				Children: []*types.Node{
+					{Text: "Child1", Children: []*types.Node{{Text: "Leaf"}}},
+					{Text: "Child2"},
+				},
+			}
+
+			if err := Draw(root, io.Discard, WithLayout(tt.layout)); err != nil {
+				t.Fatalf("draw failed: %v", err)
+			}
+
+			for _, child := range root.Children {
+				if tt.expectDir > 0 && child.Y <= root.Y {
+					t.Fatalf("expected child below root, got child.Y=%v root.Y=%v", child.Y, root.Y)
+				}
+				if tt.expectDir < 0 && child.Y >= root.Y {
+					t.Fatalf("expected child above root, got child.Y=%v root.Y=%v", child.Y, root.Y)
+				}
+			}
+			if root.Children[0].X >= root.Children[1].X {
+				t.Fatalf("expected siblings to spread horizontally, got %v and %v", root.Children[0].X, root.Children[1].X)
+			}
+		})
+	}
+}
+
+func TestSplitChildrenBalanced(t *testing.T) {
+	big := &types.Node{Text: "Big"}
+	small := []*types.Node{{Text: "A"}, {Text: "B"}, {Text: "C"}, {Text: "D"}}
+	children := append([]*types.Node{big}, small...)
+
+	heights := map[*types.Node]float64{big: 160}
+	for _, n := range small {
+		heights[n] = 40
+	}
+
+	left, right := splitChildrenBalanced(children, heights, 0)
+	if len(right) != 1 || right[0] != big {
+		t.Fatalf("expected the big subtree alone on the right, got %d nodes", len(right))
+	}
+	if len(left) != 4 {
+		t.Fatalf("expected the four small subtrees on the left, got %d", len(left))
+	}
+	for i, n := range left {
+		if n != small[i] {
+			t.Fatalf("expected source order to be preserved on the left side")
+		}
+	}
+}
+
+func TestPlaceChildrenAlignment(t *testing.T) {
+	a, b := &types.Node{Text: "A"}, &types.Node{Text: "B"}
+	children := []*types.Node{a, b}
+	nodeSizes := map[*types.Node]*NodeSize{a: {Width: 100, Height: 40}, b: {Width: 100, Height: 40}}
+	spans := map[*types.Node]float64{a: 40, b: 120}
+
+	tests := []struct {
+		alignment string
+		want      []float64
+	}{
+		// 居中：两个槽位共 40+30+120，整体居中于父节点
+		{alignment: "center", want: []float64{-75, 35}},
+		// 顶部对齐：从父节点上沿（-100）开始，节点贴在槽位顶部
+		{alignment: "top", want: []float64{-80, -10}},
+		// 两端对齐：所有槽位等于最大子树高度，节点等距
+		{alignment: "justify", want: []float64{-75, 75}},
+	}
+	for _, tt := range tests {
+		config := &DrawConfig{NodeSpacing: 30, Alignment: tt.alignment}
+		_, got := placeChildren(children, 0, 200, nodeSizes, spans, config)
+		for i := range tt.want {
+			if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: child %d at %v, want %v", tt.alignment, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDrawAlignmentTop(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}, {Text: "B"}, {Text: "C"}}}
+	}
+
+	centered, top := newTree(), newTree()
+	if err := Draw(centered, io.Discard, WithScale(1)); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	if err := Draw(top, io.Discard, WithScale(1), WithAlignment("top")); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	// 节点高度相同时，顶部对齐的第一个子节点与父节点齐平，居中时则明显偏上
+	if math.Abs(top.Children[0].Y-top.Y) > 1 {
+		t.Errorf("expected first top-aligned child at the parent's top band, got child.Y=%v root.Y=%v", top.Children[0].Y, top.Y)
+	}
+	if centered.Children[0].Y >= centered.Y-1 {
+		t.Errorf("expected first centered child above the parent, got child.Y=%v root.Y=%v", centered.Children[0].Y, centered.Y)
+	}
+	for i := 1; i < len(top.Children); i++ {
+		if top.Children[i].Y <= top.Children[i-1].Y {
+			t.Fatalf("expected top-aligned children in order, got %v after %v", top.Children[i].Y, top.Children[i-1].Y)
+		}
+	}
+}
+
+func TestWithAlignmentUnknown(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}}}
+	if err := Draw(root, io.Discard, WithAlignment("topp")); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for an unknown alignment, got %v", err)
+	}
+	for _, align := range []string{"", "Top", "justify"} {
+		if err := Draw(root, io.Discard, WithScale(1), WithAlignment(align)); err != nil {
+			t.Errorf("alignment %q: %v", align, err)
+		}
+	}
+}
+
+func TestDrawOrderKeys(t *testing.T) {
+	order := func(n int) *int { return &n }
+	third := &types.Node{Text: "Third", Order: order(3)}
+	first := &types.Node{Text: "First", Order: order(1)}
+	unkeyed := &types.Node{Text: "Unkeyed"}
+	second := &types.Node{Text: "Second", Order: order(2)}
+	nested := &types.Node{Text: "Nested", Children: []*types.Node{
+		{Text: "Later", Order: order(2)}, {Text: "Sooner", Order: order(1)},
+	}}
+	root := &types.Node{Text: "Root", Children: []*types.Node{third, first, unkeyed, second, nested}}
+
+	if err := Draw(root, io.Discard, WithScale(1)); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	// 绘制不修改传入的树
+	input := []*types.Node{third, first, unkeyed, second, nested}
+	for i, n := range input {
+		if root.Children[i] != n {
+			t.Fatalf("child %d changed to %q, want %q", i, root.Children[i].Text, n.Text)
+		}
+	}
+	if nested.Children[0].Text != "Later" {
+		t.Fatalf("expected nested children to keep their order, got %q first", nested.Children[0].Text)
+	}
+
+	// 带排序键的节点按键排列，未设置排序键的节点保持原位置
+	l, err := ComputeLayout(root, WithScale(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	var ys []float64
+	for _, n := range l.Nodes {
+		if n.Parent == 0 {
+			texts = append(texts, n.Text)
+			ys = append(ys, n.Y)
+		}
+	}
+	if got, want := strings.Join(texts, ","), "First,Second,Unkeyed,Third,Nested"; got != want {
+		t.Fatalf("rendered children %s, want %s", got, want)
+	}
+	if !sort.Float64sAreSorted(ys) {
+		t.Fatalf("expected siblings rendered top to bottom in key order, got %v", ys)
+	}
+	for i, n := range l.Nodes {
+		if n.Text == "Nested" && (l.Nodes[i+1].Text != "Sooner" || l.Nodes[i+2].Text != "Later") {
+			t.Fatalf("expected nested children sorted, got %q, %q", l.Nodes[i+1].Text, l.Nodes[i+2].Text)
+		}
+	}
+
+	// 没有排序键时不复制树
+	plain := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}}}
+	if orderChildren(plain) != plain {
+		t.Fatal("expected a tree without order keys to be returned as is")
+	}
+}
+
+func TestMinAspectRatio(t *testing.T) {
+	word := &types.Node{Text: "Go"}
+	root := &types.Node{Text: "Root", Children: []*types.Node{word}}
+
+	l := NewRenderer(WithMinAspectRatio(4)).layout(root)
+	size := l.nodeSizes[word]
+	if size.Width < 4*size.Height {
+		t.Fatalf("expected width %v to be at least 4x height %v", size.Width, size.Height)
+	}
+
+	// 加宽不超过主题的最大节点宽度
+	l = NewRenderer(WithMinAspectRatio(20)).layout(root)
+	if got := l.nodeSizes[word].Width; got != l.config.MaxNodeWidth {
+		t.Fatalf("expected width capped at %v, got %v", l.config.MaxNodeWidth, got)
+	}
+}
+
+func TestRootLevelSpacing(t *testing.T) {
+	for _, layout := range []string{"right", "both", "down"} {
+		t.Run(layout, func(t *testing.T) {
+			grandchild := &types.Node{Text: "Grandchild"}
+			child := &types.Node{Text: "Child", Children: []*types.Node{grandchild}}
+			root := &types.Node{Text: "Root", Children: []*types.Node{child}}
+
+			l := NewRenderer(WithLayout(layout), WithRootLevelSpacing(400)).layout(root)
+			// 父子节点相对边缘之间的间距
+			gap := func(parent, child *types.Node) float64 {
+				p, c := l.nodeSizes[parent], l.nodeSizes[child]
+				if layout == "down" {
+					return math.Abs(child.Y-parent.Y) - (p.Height+c.Height)/2
+				}
+				return math.Abs(child.X-parent.X) - (p.Width+c.Width)/2
+			}
+
+			if got := gap(root, child); math.Abs(got-400) > 1e-9 {
+				t.Errorf("expected a root-level gap of 400, got %v", got)
+			}
+			if got := gap(child, grandchild); math.Abs(got-l.config.LevelSpacing) > 1e-9 {
+				t.Errorf("expected deeper levels to keep the theme spacing %v, got %v", l.config.LevelSpacing, got)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	deep := &types.Node{Text: "A1", Children: []*types.Node{{Text: "A1a"}}}
+	a := &types.Node{Text: "A", Children: []*types.Node{deep}}
+	root := &types.Node{Text: "Root", Children: []*types.Node{a, {Text: "B"}}}
+
+	l := NewRenderer(WithMaxDepth(1)).layout(root)
+	if len(l.root.Children) != 2 || len(l.root.Children[0].Children) != 0 {
+		t.Fatalf("expected only the first level to be laid out, got %+v", l.root.Children)
+	}
+	if got := l.hidden[l.root.Children[0]]; got != 2 {
+		t.Fatalf("expected A to hide 2 descendants, got %d", got)
+	}
+	if len(a.Children) != 1 || len(deep.Children) != 1 {
+		t.Fatal("expected the caller's tree to be left intact")
+	}
+
+	l = NewRenderer(WithMaxDepth(0)).layout(root)
+	if len(l.root.Children) != 0 || l.hidden[l.root] != 4 {
+		t.Fatalf("expected depth 0 to keep only the root with 4 hidden nodes, got %d", l.hidden[l.root])
+	}
+
+	var full, pruned bytes.Buffer
+	if err := Draw(root, &full, WithScale(1)); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	if err := Draw(root, &pruned, WithScale(1), WithMaxDepth(1)); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	fullCfg, _ := png.DecodeConfig(&full)
+	prunedCfg, _ := png.DecodeConfig(&pruned)
+	if prunedCfg.Width >= fullCfg.Width {
+		t.Fatalf("expected pruned map to be narrower, got %d >= %d", prunedCfg.Width, fullCfg.Width)
+	}
+}
+
+func TestLevelLineHeight(t *testing.T) {
+	base, err := theme.GetManager().GetThemeStrict("default")
+	if err != nil {
+		t.Fatalf("load theme: %v", err)
+	}
+	cfg := *base
+	cfg.NodeStyles.Leaf.LineHeight = base.Layout.LineHeight / 2
+
+	text := "A long label that certainly wraps onto several lines of text"
+	leaf := &types.Node{Text: text}
+	root := &types.Node{Text: text, Children: []*types.Node{leaf}}
+
+	l := NewRenderer(WithThemeConfig(&cfg), WithScale(1)).layout(root)
+	rootSize, leafSize := l.nodeSizes[root], l.nodeSizes[leaf]
+	if len(leafSize.Lines) < 2 || len(leafSize.Lines) != len(rootSize.Lines) {
+		t.Fatalf("expected both nodes to wrap the same way, got %d and %d lines", len(rootSize.Lines), len(leafSize.Lines))
+	}
+	if leafSize.LineHeight != cfg.NodeStyles.Leaf.LineHeight || rootSize.LineHeight != base.Layout.LineHeight {
+		t.Fatalf("unexpected line heights: root %v, leaf %v", rootSize.LineHeight, leafSize.LineHeight)
+	}
+	if leafSize.Height >= rootSize.Height {
+		t.Fatalf("expected the leaf box to be shorter, got %v >= %v", leafSize.Height, rootSize.Height)
+	}
+}
+
+func TestDrawLayoutBounds(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{
+			Text: "Root",
+			Children: []*types.Node{
+				{Text: "A", Children: []*types.Node{{Text: "A1"}, {Text: "A2"}, {Text: "A3"}}},
+				{Text: "B", Children: []*types.Node{{Text: "B1"}}},
+				{Text: "C"},
+				{Text: "D", Children: []*types.Node{{Text: "D1"}, {Text: "D2"}}},
+			},
+		}
+	}
+
+	var walk func(n *types.Node, fn func(*types.Node))
+	walk = func(n *types.Node, fn func(*types.Node)) {
+		for _, c := range n.Children {
+			fn(c)
+			walk(c, fn)
+		}
+	}
+
+	for _, layout := range []string{"right", "left"} {
+		t.Run(layout, func(t *testing.T) {
+			root := newTree()
+			if err := Draw(root, io.Discard, WithLayout(layout)); err != nil {
+				t.Fatalf("draw failed: %v", err)
+			}
+			walk(root, func(n *types.Node) {
+				if layout == "right" && n.X <= root.X {
+					t.Errorf("expected %q right of root, got X=%v", n.Text, n.X)
+				}
+				if layout == "left" && n.X >= root.X {
+					t.Errorf("expected %q left of root, got X=%v", n.Text, n.X)
+				}
+			})
+		})
+	}
+
+	t.Run("both", func(t *testing.T) {
+		root := newTree()
+		if err := Draw(root, io.Discard, WithLayout("both")); err != nil {
+			t.Fatalf("draw failed: %v", err)
+		}
+		for _, child := range root.Children {
+			side := child.X - root.X
+			walk(child, func(n *types.Node) {
+				if (n.X-root.X)*side <= 0 {
+					t.Errorf("expected %q to stay on the same side as %q", n.Text, child.Text)
+				}
+			})
+		}
+
+		// 两侧在纵向上都应大致以根节点为中心
+		var leftMin, leftMax, rightMin, rightMax = math.MaxFloat64, -math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64
+		walk(root, func(n *types.Node) {
+			if n.X < root.X {
+				leftMin, leftMax = math.Min(leftMin, n.Y), math.Max(leftMax, n.Y)
+			} else {
+				rightMin, rightMax = math.Min(rightMin, n.Y), math.Max(rightMax, n.Y)
+			}
+		})
+		if leftMin > root.Y || leftMax < root.Y || rightMin > root.Y || rightMax < root.Y {
+			t.Errorf("expected both sides to straddle the root: left=[%v,%v] right=[%v,%v] root=%v", leftMin, leftMax, rightMin, rightMax, root.Y)
+		}
+	})
+}
+
+func TestDrawShapes(t *testing.T) {
+	shapes := []types.Shape{
+		types.ShapeSquare, types.ShapeRounded, types.ShapeCircle,
+		types.ShapeCloud, types.ShapeHexagon, types.ShapeBang, types.Shape("unknown"),
+	}
+	root := &types.Node{Text: "Shapes"}
+	for _, s := range shapes {
+		root.Children = append(root.Children, &types.Node{Text: string(s), Shape: s})
+	}
+
+	var buf bytes.Buffer
+	if err := Draw(root, &buf); err != nil {
+		t.Fatalf("draw with shapes failed: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("expected PNG output")
+	}
+}
+
+func TestParseHexColorShorthand(t *testing.T) {
+	got, ok := parseHexColor("#e57", [3]float64{})
+	want, _ := parseHexColor("#ee5577", [3]float64{})
+	if !ok || got != want {
+		t.Fatalf("parseHexColor(#e57) = %v, %v; want %v", got, ok, want)
+	}
+}
+
+func TestGetNodeStyleBranchPalette(t *testing.T) {
+	config, err := NewDrawConfig("default")
+	if err != nil {
+		t.Fatalf("load theme: %v", err)
+	}
+	leaf := &types.Node{Text: "leaf"}
+	branch := &types.Node{Text: "branch", Children: []*types.Node{leaf}}
+
+	// 未配置分支配色时保持主题的层级样式
+	if got, want := getNodeStyle(branch, false, 0, 1, config), config.Theme.GetNodeStyles()["level1"]; *got != *want {
+		t.Fatalf("expected level1 style without palette, got %+v", got)
+	}
+
+	red := [3]float64{1, 0, 0}
+	green := [3]float64{0, 1, 0}
+	config.BranchColors = [][3]float64{red, green}
+
+	if got := getNodeStyle(branch, true, -1, 0, config); *got != *config.Theme.GetNodeStyles()["root"] {
+		t.Fatalf("root should keep theme style, got %+v", got)
+	}
+	if got := getNodeStyle(branch, false, 0, 1, config); got.FillColor != red || got.StrokeColor != red {
+		t.Fatalf("first branch should use first palette color, got %+v", got)
+	}
+	if got := getNodeStyle(branch, false, 2, 1, config); got.FillColor != red {
+		t.Fatalf("palette should rotate, got %+v", got)
+	}
+	deep := getNodeStyle(leaf, false, 1, 2, config)
+	if deep.StrokeColor != green || deep.FillColor == green || deep.FillColor[1] != 1 {
+		t.Fatalf("deeper nodes should inherit a lighter branch color, got %+v", deep)
+	}
+
+	custom := &types.NodeStyle{FillColor: [3]float64{0.5, 0.5, 0.5}}
+	if got := getNodeStyle(&types.Node{Text: "x", Style: custom}, false, 0, 1, config); got != custom {
+		t.Fatal("explicit node style should win over the palette")
+	}
+}
+
+func TestDrawRegisteredTheme(t *testing.T) {
+	base, err := theme.GetManager().GetThemeStrict("default")
+	if err != nil {
+		t.Fatalf("load theme: %v", err)
+	}
+	cfg := *base
+	cfg.Name = "Registered"
+	cfg.Colors.Background = "#336699"
+	if err := theme.Register("test-registered", &cfg); err != nil {
+		t.Fatalf("register theme: %v", err)
+	}
+
+	img := renderPNG(t, &types.Node{Text: "Root"}, WithTheme("test-registered"), WithScale(1))
+	r, g, b, _ := img.At(0, 0).RGBA()
+	if r>>8 != 0x33 || g>>8 != 0x66 || b>>8 != 0x99 {
+		t.Fatalf("expected registered background color, got %02x%02x%02x", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestApplyScaleClampsToTheme(t *testing.T) {
+	config, err := NewDrawConfig("default")
+	if err != nil {
+		t.Fatalf("load theme: %v", err)
+	}
+	layout := config.Theme.Layout
+	layout.MinScale, layout.MaxScale = 1, 4
+	config.Theme = &theme.ThemeConfig{Name: "bounded", Layout: layout}
+
+	tests := []struct {
+		scale, want float64
+	}{
+		{scale: 0.2, want: 1},
+		{scale: 2.5, want: 2.5},
+		{scale: 10, want: 4},
+	}
+	for _, tt := range tests {
+		config.applyScale(tt.scale)
+		if config.Scale != tt.want {
+			t.Errorf("applyScale(%g) = %g, want %g", tt.scale, config.Scale, tt.want)
+		}
+	}
+
+	// 未配置范围时不做限制
+	config.Theme.Layout.MinScale, config.Theme.Layout.MaxScale = 0, 0
+	config.applyScale(10)
+	if config.Scale != 10 {
+		t.Errorf("expected unbounded theme to keep scale 10, got %g", config.Scale)
+	}
+}
+
+func TestCanvasPixelsOverflow(t *testing.T) {
+	if w, h, err := canvasPixels(100.5, 50, 3); err != nil || w != 301 || h != 150 {
+		t.Fatalf("canvasPixels = %d, %d, %v", w, h, err)
+	}
+	if _, _, err := canvasPixels(1e12, 100, 8); err == nil {
+		t.Fatal("expected an error for a canvas that overflows int")
+	}
+}
+
+func TestFitScale(t *testing.T) {
+	config := &DrawConfig{Scale: 3}
+	if err := fitScale(config, 1000, 500, 8000, 8000); err != nil || config.Scale != 3 {
+		t.Fatalf("expected scale to stay 3, got %g, %v", config.Scale, err)
+	}
+	if err := fitScale(config, 4000, 500, 8000, 8000); err != nil || config.Scale != 2 {
+		t.Fatalf("expected scale to shrink to 2, got %g, %v", config.Scale, err)
+	}
+	if err := fitScale(config, 20000, 500, 8000, 8000); !errors.Is(err, ErrCanvasTooLarge) {
+		t.Fatalf("expected ErrCanvasTooLarge, got %v", err)
+	}
+}
+
+func TestDrawMaxDimensions(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}, {Text: "B"}}}
+	img := renderPNG(t, root, WithMaxDimensions(1000, 1000))
+	if b := img.Bounds(); b.Dx() > 1000 || b.Dy() > 1000 {
+		t.Fatalf("expected canvas within 1000x1000, got %v", b)
+	}
+	if err := Draw(root, io.Discard, WithMaxDimensions(50, 50)); !errors.Is(err, ErrCanvasTooLarge) {
+		t.Fatalf("expected ErrCanvasTooLarge, got %v", err)
+	}
+}
+
+func TestCalculateTextWrappingBreaksLongWords(t *testing.T) {
+	r := NewRenderer()
+	config := r.newConfig()
+	dc := gg.NewContext(1, 1)
+	r.setFontFace(dc, config.FontSize)
+	cache := newTextMeasureCache(config.textShaper())
+	available := config.MaxNodeWidth - 2*config.TextPadding
+
+	tests := []struct {
+		name, text, token string
+		hyphen            bool
+	}{
+		{name: "url among words", text: "see https://example.com/a/very/long/path/to/some/document.html for details", token: "https://example.com/a/very/long/path/to/some/document.html"},
+		{name: "long token", text: "a Pneumonoultramicroscopicsilicovolcanoconiosisandsomemore b", token: "Pneumonoultramicroscopicsilicovolcanoconiosisandsomemore", hyphen: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := calculateTextWrapping(dc, tt.text, config.LineHeight, 0, config, cache)
+			var widest float64
+			for _, line := range size.Lines {
+				w := measureStringCached(dc, line, cache)
+				if w > available+1e-9 {
+					t.Errorf("line %q is %.1f wide, exceeds %.1f", line, w, available)
+				}
+				widest = max(widest, w)
+			}
+			if size.ActualTextWidth != widest {
+				t.Errorf("expected ActualTextWidth %.1f to match the widest line %.1f", size.ActualTextWidth, widest)
+			}
+			if size.Width > config.MaxNodeWidth {
+				t.Errorf("expected node width within %v, got %v", config.MaxNodeWidth, size.Width)
+			}
+
+			joined := strings.Join(size.Lines, "\n")
+			if tt.hyphen != strings.Contains(joined, "-\n") {
+				t.Errorf("expected hyphenated breaks = %v, got %q", tt.hyphen, size.Lines)
+			}
+			rebuilt := strings.ReplaceAll(strings.ReplaceAll(joined, "-\n", ""), "\n", " ")
+			if tt.hyphen {
+				rebuilt = strings.ReplaceAll(rebuilt, " ", "")
+				if !strings.Contains(rebuilt, tt.token) {
+					t.Errorf("expected the token to survive the breaks, got %q", size.Lines)
+				}
+			} else if !strings.Contains(strings.ReplaceAll(joined, "\n", ""), tt.token) {
+				t.Errorf("expected the URL to survive the breaks, got %q", size.Lines)
+			}
+			if size.Lines[0] == tt.token || len(size.Lines) < 3 {
+				t.Errorf("expected the long token to be split across lines, got %q", size.Lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package drawer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// LayoutEngine positions a measured tree. Position sets X and Y, the center
+// of each node in unscaled layout units, for root and every descendant;
+// sizes holds the measured size of each node. The drawer computes bounds,
+// connectors and the canvas from the positions, so an engine may place nodes
+// anywhere, including negative coordinates.
+type LayoutEngine interface {
+	Position(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig)
+}
+
+// LayoutFunc adapts an ordinary function to the LayoutEngine interface.
+type LayoutFunc func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig)
+
+// Position calls f(root, sizes, config).
+func (f LayoutFunc) Position(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+	f(root, sizes, config)
+}
+
+// layoutRegistry 已注册的布局引擎，names 保持注册顺序
+var layoutRegistry = struct {
+	sync.RWMutex
+	engines map[string]LayoutEngine
+	names   []string
+}{engines: make(map[string]LayoutEngine)}
+
+func init() {
+	RegisterLayout("right", LayoutFunc(func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+		horizontalMindmapLayoutDirectional(root, 0, 0, 1, 0, sizes, subtreeExtents(root, sizes, config), config)
+	}))
+	RegisterLayout("left", LayoutFunc(func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+		horizontalMindmapLayoutDirectional(root, 0, 0, -1, 0, sizes, subtreeExtents(root, sizes, config), config)
+	}))
+	RegisterLayout("both", LayoutFunc(func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+		horizontalMindmapLayoutBothSides(root, 0, 0, sizes, subtreeExtents(root, sizes, config), config)
+	}))
+	RegisterLayout("down", LayoutFunc(func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+		verticalMindmapLayout(root, 0, 0, 1, 0, sizes, subtreeExtents(root, sizes, config), config)
+	}))
+	RegisterLayout("up", LayoutFunc(func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+		verticalMindmapLayout(root, 0, 0, -1, 0, sizes, subtreeExtents(root, sizes, config), config)
+	}))
+	RegisterLayout(layoutRadial, LayoutFunc(radialLayout))
+}
+
+// subtreeExtents 计算每棵子树在兄弟排列方向上占用的长度：横向布局为高度，纵向布局为宽度
+func subtreeExtents(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) map[*types.Node]float64 {
+	extents := make(map[*types.Node]float64)
+	calculateSubtreeHeights(root, sizes, extents, config)
+	return extents
+}
+
+// RegisterLayout makes engine available to WithLayout under name, which is
+// matched case-insensitively. Registering an existing name replaces its
+// engine, including the built-in right, left, both, down, up and radial layouts.
+// It panics if name is empty or engine is nil.
+func RegisterLayout(name string, engine LayoutEngine) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || engine == nil {
+		panic("drawer: RegisterLayout requires a name and an engine")
+	}
+	layoutRegistry.Lock()
+	defer layoutRegistry.Unlock()
+	if _, ok := layoutRegistry.engines[name]; !ok {
+		layoutRegistry.names = append(layoutRegistry.names, name)
+	}
+	layoutRegistry.engines[name] = engine
+}
+
+// Layouts returns the names of the registered layouts in registration order,
+// starting with the built-in ones.
+func Layouts() []string {
+	layoutRegistry.RLock()
+	defer layoutRegistry.RUnlock()
+	return slices.Clone(layoutRegistry.names)
+}
+
+// IsLayout reports whether name is a registered layout accepted by
+// WithLayout.
+func IsLayout(name string) bool {
+	_, ok := lookupLayout(name)
+	return ok
+}
+
+// lookupLayout 按名称查找布局引擎，名称不区分大小写
+func lookupLayout(name string) (LayoutEngine, bool) {
+	layoutRegistry.RLock()
+	defer layoutRegistry.RUnlock()
+	engine, ok := layoutRegistry.engines[strings.ToLower(strings.TrimSpace(name))]
+	return engine, ok
+}
+
+// unknownLayoutMessage 描述未注册的布局名并列出可用布局
+func unknownLayoutMessage(name string) string {
+	return fmt.Sprintf("unknown layout %q; available layouts: %s", name, strings.Join(Layouts(), ", "))
+}
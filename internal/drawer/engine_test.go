@@ -0,0 +1,52 @@
+package drawer
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestLayoutRegistry(t *testing.T) {
+	if got := Layouts(); !slices.Equal(got[:5], []string{"right", "left", "both", "down", "up"}) {
+		t.Fatalf("expected the built-in layouts first, got %v", got)
+	}
+	if !IsLayout("Right") || IsLayout("diagonal") {
+		t.Fatalf("unexpected IsLayout results")
+	}
+
+	// 自定义引擎：所有子节点排成一列，位于根节点正下方
+	RegisterLayout("test-column", LayoutFunc(func(root *types.Node, sizes map[*types.Node]*NodeSize, config *DrawConfig) {
+		y := 0.0
+		var walk func(n *types.Node)
+		walk = func(n *types.Node) {
+			n.X, n.Y = 0, y
+			y += sizes[n].Height + config.NodeSpacing
+			for _, c := range n.Children {
+				walk(c)
+			}
+		}
+		walk(root)
+	}))
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}, {Text: "B"}}}
+	l := NewRenderer(WithLayout("test-column")).layout(root)
+	if root.Children[0].X != 0 || root.Children[1].Y <= root.Children[0].Y || root.Children[0].Y <= root.Y {
+		t.Fatalf("expected the custom engine to position nodes, got %+v", root.Children)
+	}
+	if w, h := l.size(); h <= w {
+		t.Errorf("expected a tall canvas for a column layout, got %gx%g", w, h)
+	}
+	if !slices.Contains(Layouts(), "test-column") {
+		t.Errorf("expected the registered layout to be listed")
+	}
+
+	var buf bytes.Buffer
+	if err := Draw(root, &buf, WithLayout("diagonal")); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected an unknown layout to fail, got %v", err)
+	}
+	if err := Draw(root, &buf, WithLayout("")); err != nil {
+		t.Fatalf("expected an empty layout to keep the default, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package drawer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// ErrFocusNotFound is returned when no node matches the path given to
+// WithFocus.
+var ErrFocusNotFound = errors.New("focus node not found")
+
+// WithFocus renders only the subtree rooted at the node selected by path,
+// for drilling into one branch of a large map. path is first matched
+// against node texts, ignoring case and surrounding space, and the first
+// match in pre-order wins. Otherwise a path containing dots is followed
+// from the root one node text at a time, e.g. "Plan.Design.Mockups"; the
+// root's own text may be left out. Rendering fails with ErrFocusNotFound
+// when nothing matches. An empty path renders the whole tree.
+func WithFocus(path string) Option {
+	return func(opts *drawOptions) {
+		opts.focus = strings.TrimSpace(path)
+	}
+}
+
+// focusRoot 返回 WithFocus 选中的子树根节点，未设置时返回原根节点
+func (r *Renderer) focusRoot(root *types.Node) (*types.Node, error) {
+	if r.opts.focus == "" {
+		return root, nil
+	}
+	if node := findFocus(root, r.opts.focus); node != nil {
+		return node, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrFocusNotFound, r.opts.focus)
+}
+
+// findFocus 先按节点文本查找，找不到时把含点号的 path 当作从根节点开始的逐级路径
+func findFocus(root *types.Node, path string) *types.Node {
+	matches := root.Find(func(n *types.Node) bool { return sameText(n.Text, path) })
+	if len(matches) > 0 {
+		return matches[0]
+	}
+	if root == nil || !strings.Contains(path, ".") {
+		return nil
+	}
+	segments := strings.Split(path, ".")
+	if sameText(root.Text, segments[0]) {
+		segments = segments[1:]
+	}
+	node := root
+	for _, segment := range segments {
+		var next *types.Node
+		for _, child := range node.Children {
+			if child != nil && sameText(child.Text, segment) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// sameText 判断节点文本与选择器是否相同，忽略大小写与首尾空白
+func sameText(text, selector string) bool {
+	return strings.EqualFold(strings.TrimSpace(text), strings.TrimSpace(selector))
+}
@@ -0,0 +1,63 @@
+package drawer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestFindFocus(t *testing.T) {
+	root := &types.Node{Text: "Plan", Children: []*types.Node{
+		{Text: "Design", Children: []*types.Node{{Text: "Review"}, {Text: "v1.2"}}},
+		{Text: "Build", Children: []*types.Node{{Text: "Review"}}},
+	}}
+	design, build := root.Children[0], root.Children[1]
+
+	for _, tt := range []struct {
+		path string
+		want *types.Node
+	}{
+		{"design", design},
+		{" Review ", design.Children[0]},
+		{"v1.2", design.Children[1]},
+		{"Plan.Build.Review", build.Children[0]},
+		{"Build.review", build.Children[0]},
+		{"Build.Missing", nil},
+		{"Missing", nil},
+	} {
+		if got := findFocus(root, tt.path); got != tt.want {
+			t.Errorf("findFocus(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWithFocus(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Plan", Children: []*types.Node{
+			{Text: "Design", Children: []*types.Node{{Text: "Mockups"}}},
+			{Text: "Build"},
+		}}
+	}
+	l, err := ComputeLayout(newTree(), WithFocus("Design"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Nodes) != 2 || l.Nodes[0].Text != "Design" || l.Nodes[0].Parent != -1 {
+		t.Fatalf("expected the Design subtree, got %+v", l.Nodes)
+	}
+
+	var buf bytes.Buffer
+	for name, draw := range map[string]func() error{
+		"png":    func() error { return Draw(newTree(), &buf, WithFocus("Nowhere")) },
+		"svg":    func() error { return DrawSVG(newTree(), &buf, WithFocus("Nowhere")) },
+		"gif":    func() error { return DrawReveal(newTree(), &buf, WithFocus("Nowhere")) },
+		"toc":    func() error { return DrawTOC(newTree(), &buf, WithFocus("Nowhere")) },
+		"layout": func() error { _, err := ComputeLayout(newTree(), WithFocus("Nowhere")); return err },
+	} {
+		if err := draw(); !errors.Is(err, ErrFocusNotFound) {
+			t.Errorf("%s: expected ErrFocusNotFound, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package drawer
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+// loadFontFile 读取外部字体文件，支持 TrueType（.ttf）、CFF 轮廓的 OpenType（.otf）
+// 以及字体集合（.ttc/.otc），index 选择集合中的字体。格式按文件内容识别而非扩展名；
+// 路径直接交给 os.ReadFile，非 ASCII 路径（包括 Windows）无需额外处理。
+func loadFontFile(path string, index int) (*opentype.Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font file: %w", err)
+	}
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font file %s: %w", path, err)
+	}
+	if index < 0 || index >= collection.NumFonts() {
+		return nil, fmt.Errorf("font file %s has %d faces, index %d is out of range", path, collection.NumFonts(), index)
+	}
+	f, err := collection.Font(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse face %d of font file %s: %w", index, path, err)
+	}
+	return f, nil
+}
+
+// newFontFileFace 以指定字号创建外部字体的 face，度量方式与内嵌字体（72 DPI、不做 hinting）一致
+func newFontFileFace(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+}
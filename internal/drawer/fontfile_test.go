@@ -0,0 +1,68 @@
+package drawer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestWithFontFileOTF(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "CFFTest.otf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 非 ASCII 路径应能直接加载
+	dir := filepath.Join(t.TempDir(), "字体 fonts")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "测试.otf")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// CFFTest.otf 只包含 0、1、Q 与「中」四个字形
+	child := &types.Node{Text: "Q10"}
+	root := &types.Node{Text: "中", Children: []*types.Node{child}}
+
+	custom := NewRenderer(WithFontFile(path, 0), WithScale(1))
+	embedded := NewRenderer(WithScale(1))
+	if got, base := custom.layout(root).nodeSizes[child].ActualTextWidth, embedded.layout(root).nodeSizes[child].ActualTextWidth; got <= 0 || got == base {
+		t.Fatalf("expected text measured with the OTF font, got width %v (embedded font: %v)", got, base)
+	}
+
+	var withFont, withoutFont bytes.Buffer
+	if err := custom.Render(root, &withFont); err != nil {
+		t.Fatalf("Render with font file failed: %v", err)
+	}
+	if err := embedded.Render(root, &withoutFont); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if bytes.Equal(withFont.Bytes(), withoutFont.Bytes()) {
+		t.Fatal("expected the font file to change the rendered text")
+	}
+}
+
+func TestWithFontFileErrors(t *testing.T) {
+	root := &types.Node{Text: "Root"}
+	otf := filepath.Join("testdata", "CFFTest.otf")
+
+	var buf bytes.Buffer
+	if err := Draw(root, &buf, WithFontFile(filepath.Join(t.TempDir(), "missing.ttf"), 0)); err == nil {
+		t.Error("expected an error for a missing font file")
+	}
+	if err := Draw(root, &buf, WithFontFile(otf, 1)); err == nil {
+		t.Error("expected an error for a face index out of range")
+	}
+
+	notFont := filepath.Join(t.TempDir(), "notes.ttc")
+	if err := os.WriteFile(notFont, []byte("not a font"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Draw(root, &buf, WithFontFile(notFont, 0)); err == nil {
+		t.Error("expected an error for a file that is not a font")
+	}
+}
@@ -0,0 +1,101 @@
+package drawer
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata")
+
+// 金图比较的容差：字体光栅化在不同平台上可能略有差异，
+// 任一颜色通道相差超过 goldenChannelTolerance（0-255）的像素视为不同，
+// 不同的像素不超过总数的 goldenPixelTolerance 时认为一致
+const (
+	goldenChannelTolerance = 32
+	goldenPixelTolerance   = 0.005
+)
+
+// TestGoldenImages 将固定的示例导图（SampleTree）与 testdata 中的金图比较，防止布局与配色的回归。
+// 有意修改渲染结果后以 go test ./internal/drawer -run TestGoldenImages -update 重新生成
+func TestGoldenImages(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"default_right", []Option{WithTheme("default"), WithScale(1)}},
+		{"default_both", []Option{WithTheme("default"), WithLayout("both"), WithScale(1)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Draw(SampleTree(), &buf, tc.opts...); err != nil {
+				t.Fatalf("draw failed: %v", err)
+			}
+			path := filepath.Join("testdata", tc.name+".golden.png")
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			got, err := png.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatalf("missing golden image (run with -update to create it): %v", err)
+			}
+			defer f.Close()
+			want, err := png.Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff, ok := compareImages(got, want); !ok {
+				actual := filepath.Join(t.TempDir(), tc.name+".png")
+				_ = os.WriteFile(actual, buf.Bytes(), 0o644)
+				t.Errorf("output differs from %s (%s); actual image written to %s", path, diff, actual)
+			}
+		})
+	}
+}
+
+// compareImages 在容差范围内比较两张图片，不一致时返回差异描述
+func compareImages(got, want image.Image) (string, bool) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return fmt.Sprintf("size %v, want %v", gb.Size(), wb.Size()), false
+	}
+	differing := 0
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			r1, g1, b1, a1 := got.At(gb.Min.X+x, gb.Min.Y+y).RGBA()
+			r2, g2, b2, a2 := want.At(wb.Min.X+x, wb.Min.Y+y).RGBA()
+			for _, d := range [4][2]uint32{{r1, r2}, {g1, g2}, {b1, b2}, {a1, a2}} {
+				if channelDiff(d[0], d[1]) > goldenChannelTolerance {
+					differing++
+					break
+				}
+			}
+		}
+	}
+	total := gb.Dx() * gb.Dy()
+	if float64(differing) > float64(total)*goldenPixelTolerance {
+		return fmt.Sprintf("%d of %d pixels differ", differing, total), false
+	}
+	return "", true
+}
+
+// channelDiff 返回两个 16 位颜色通道之差，换算为 0-255
+func channelDiff(a, b uint32) uint32 {
+	if a > b {
+		return (a - b) >> 8
+	}
+	return (b - a) >> 8
+}
@@ -0,0 +1,58 @@
+package drawer
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// gradientCanvas 支持线性渐变填充的矢量绘制面：以渐变填充当前路径（即渐变裁剪到路径内），
+// 渐变轴从 (x0, y0) 到 (x1, y1)（绘制坐标，受当前变换影响），轴两端之外延续端点颜色
+type gradientCanvas interface {
+	fillLinearGradient(x0, y0, x1, y1 float64, from, to [3]float64)
+}
+
+// gradientAxis 返回覆盖矩形 (x, y, w, h) 的渐变轴端点：轴经过矩形中心，方向由 angle（度）决定，
+// 长度为矩形在该方向上的投影，使起止颜色恰好落在矩形的两个角或两条边上
+func gradientAxis(angle, x, y, w, h float64) (x0, y0, x1, y1 float64) {
+	rad := angle * math.Pi / 180
+	dx, dy := math.Cos(rad), math.Sin(rad)
+	half := (math.Abs(w*dx) + math.Abs(h*dy)) / 2
+	cx, cy := x+w/2, y+h/2
+	return cx - dx*half, cy - dy*half, cx + dx*half, cy + dy*half
+}
+
+// fillStyle 填充已构建好的节点路径 (x, y, w, h)：样式带渐变且绘制面支持时使用渐变，否则使用 FillColor
+func fillStyle(dc canvas, style *types.NodeStyle, x, y, w, h float64) {
+	g := style.FillGradient
+	if g == nil {
+		dc.SetRGB(style.FillColor[0], style.FillColor[1], style.FillColor[2])
+		dc.Fill()
+		return
+	}
+	x0, y0, x1, y1 := gradientAxis(g.Angle, x, y, w, h)
+	switch c := dc.(type) {
+	case *gg.Context:
+		// gg 的渐变按设备坐标取色，端点需经过当前变换
+		x0, y0 = c.TransformPoint(x0, y0)
+		x1, y1 = c.TransformPoint(x1, y1)
+		gradient := gg.NewLinearGradient(x0, y0, x1, y1)
+		gradient.AddColorStop(0, rgbColor(g.From))
+		gradient.AddColorStop(1, rgbColor(g.To))
+		c.SetFillStyle(gradient)
+		c.Fill()
+		c.SetRGB(style.FillColor[0], style.FillColor[1], style.FillColor[2])
+	case gradientCanvas:
+		c.fillLinearGradient(x0, y0, x1, y1, g.From, g.To)
+	default:
+		dc.SetRGB(style.FillColor[0], style.FillColor[1], style.FillColor[2])
+		dc.Fill()
+	}
+}
+
+// rgbColor 将 0-1 的颜色分量转换为不透明的 color.Color
+func rgbColor(c [3]float64) color.Color {
+	return color.NRGBA{R: uint8(colorByte(c[0])), G: uint8(colorByte(c[1])), B: uint8(colorByte(c[2])), A: 255}
+}
@@ -0,0 +1,106 @@
+package drawer
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestGradientAxis(t *testing.T) {
+	near := func(got, want [4]float64) bool {
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				return false
+			}
+		}
+		return true
+	}
+	for _, tc := range []struct {
+		angle float64
+		want  [4]float64
+	}{
+		{0, [4]float64{10, 40, 110, 40}},
+		{90, [4]float64{60, 20, 60, 60}},
+		{180, [4]float64{110, 40, 10, 40}},
+	} {
+		x0, y0, x1, y1 := gradientAxis(tc.angle, 10, 20, 100, 40)
+		if got := [4]float64{x0, y0, x1, y1}; !near(got, tc.want) {
+			t.Errorf("angle %g: got %v, want %v", tc.angle, got, tc.want)
+		}
+	}
+}
+
+// gradientTheme 返回根节点以从左（红）到右（蓝）的渐变填充的 default 主题
+func gradientTheme(t *testing.T) *theme.ThemeConfig {
+	t.Helper()
+	cfg, err := theme.GetManager().GetThemeStrict("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.NodeStyles.Root.FillGradient = &theme.GradientConfig{From: [3]float64{1, 0, 0}, To: [3]float64{0, 0, 1}}
+	return cfg
+}
+
+func TestDrawGradientFill(t *testing.T) {
+	cfg := gradientTheme(t)
+	img, err := Render(&types.Node{Text: "Plan"}, WithThemeConfig(cfg), WithScale(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 根节点两端内侧的颜色分别接近渐变的起止颜色
+	l, err := ComputeLayout(&types.Node{Text: "Plan"}, WithThemeConfig(cfg), WithScale(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := l.Nodes[0]
+	cy := int(node.Y * l.Scale)
+	left := int((node.X-node.Width/2)*l.Scale) + 8
+	right := int((node.X+node.Width/2)*l.Scale) - 8
+	redness := func(x int) float64 {
+		r, _, b, _ := img.At(x, cy).RGBA()
+		return float64(r) - float64(b)
+	}
+	if redness(left) <= 0 || redness(right) >= 0 {
+		t.Fatalf("expected a red-to-blue fill across the root, got %v at the left and %v at the right",
+			img.At(left, cy), img.At(right, cy))
+	}
+
+	var svg bytes.Buffer
+	if err := DrawSVG(&types.Node{Text: "Plan"}, &svg, WithThemeConfig(cfg)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(svg.String(), `<stop offset="0" stop-color="#ff0000"/><stop offset="1" stop-color="#0000ff"/>`) ||
+		!strings.Contains(svg.String(), "fill:url(#g0)") {
+		t.Fatalf("expected an SVG linear gradient, got %s", svg.String())
+	}
+
+	var pdf bytes.Buffer
+	if err := DrawPDF(&types.Node{Text: "Plan"}, &pdf, WithThemeConfig(cfg)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(pdf.Bytes(), []byte("/ShadingType 2")) {
+		t.Fatal("expected an axial shading in the PDF")
+	}
+
+	// 未配置渐变时仍为纯色填充
+	var plain bytes.Buffer
+	if err := DrawSVG(&types.Node{Text: "Plan"}, &plain, WithTheme("default")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain.String(), "linearGradient") {
+		t.Fatal("expected no gradient without fillGradient")
+	}
+}
+
+func TestDimmedGradient(t *testing.T) {
+	config := newDrawConfigFromTheme(gradientTheme(t))
+	config.Highlight, config.DimUnmatched = "nothing", true
+	style := config.highlightStyle(&types.Node{Text: "Plan"}, config.Theme.NodeStyles.Root.ToNodeStyle())
+	if style.FillGradient == nil || style.FillGradient.From == [3]float64{1, 0, 0} {
+		t.Fatalf("expected the gradient to be dimmed, got %+v", style.FillGradient)
+	}
+}
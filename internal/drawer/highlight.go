@@ -0,0 +1,77 @@
+package drawer
+
+import (
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// dimAmount 淡化未匹配节点时各颜色向背景色混合的比例
+const dimAmount = 0.65
+
+// WithHighlight draws the nodes whose text or note contains term, compared
+// case-insensitively, in the theme's nodeStyles.highlight style (by default
+// a fill in the theme's marker color). Other nodes keep their normal style
+// unless WithDimUnmatched is also given. An empty term highlights nothing.
+func WithHighlight(term string) Option {
+	return func(opts *drawOptions) {
+		opts.highlight = strings.ToLower(strings.TrimSpace(term))
+	}
+}
+
+// WithDimUnmatched fades the nodes that do not match the WithHighlight term
+// towards the background color, so the matches stand out. It has no effect
+// without a highlight term.
+func WithDimUnmatched(enabled bool) Option {
+	return func(opts *drawOptions) {
+		opts.dimUnmatched = enabled
+	}
+}
+
+// matchesHighlight 判断节点文本或备注是否包含高亮搜索词（term 已转为小写）
+func matchesHighlight(node *types.Node, term string) bool {
+	return strings.Contains(strings.ToLower(node.Text), term) || strings.Contains(strings.ToLower(node.Note), term)
+}
+
+// highlightStyle 按高亮搜索词调整节点样式：匹配的节点使用高亮样式，
+// 开启淡化时未匹配节点的各颜色向背景色混合，其余节点保持原样式
+func (c *DrawConfig) highlightStyle(node *types.Node, style *types.NodeStyle) *types.NodeStyle {
+	if c.Highlight == "" {
+		return style
+	}
+	if matchesHighlight(node, c.Highlight) {
+		if c.Theme != nil && c.Theme.NodeStyles.Highlight != nil {
+			return c.Theme.NodeStyles.Highlight.ToNodeStyle()
+		}
+		return &types.NodeStyle{
+			FillColor:   c.MarkerColor,
+			StrokeColor: mixColor(c.MarkerColor, [3]float64{0, 0, 0}, 0.4),
+			TextColor:   contrastTextColor(c.MarkerColor),
+		}
+	}
+	if !c.DimUnmatched {
+		return style
+	}
+	dimmed := &types.NodeStyle{
+		FillColor:   mixColor(style.FillColor, c.BackgroundColor, dimAmount),
+		StrokeColor: mixColor(style.StrokeColor, c.BackgroundColor, dimAmount),
+		TextColor:   mixColor(style.TextColor, c.BackgroundColor, dimAmount),
+	}
+	if g := style.FillGradient; g != nil {
+		dimmed.FillGradient = &types.Gradient{
+			From:  mixColor(g.From, c.BackgroundColor, dimAmount),
+			To:    mixColor(g.To, c.BackgroundColor, dimAmount),
+			Angle: g.Angle,
+		}
+	}
+	return dimmed
+}
+
+// mixColor 按比例 t 将颜色 a 向颜色 b 混合
+func mixColor(a, b [3]float64, t float64) [3]float64 {
+	var out [3]float64
+	for i := range out {
+		out[i] = a[i] + (b[i]-a[i])*t
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestHighlightStyle(t *testing.T) {
+	normal := &types.NodeStyle{FillColor: [3]float64{0, 0, 0}, StrokeColor: [3]float64{0, 0, 0}, TextColor: [3]float64{0, 0, 0}}
+	config := &DrawConfig{BackgroundColor: [3]float64{1, 1, 1}, MarkerColor: defaultMarkerColor}
+
+	if got := config.highlightStyle(types.NewNode("Anything"), normal); got != normal {
+		t.Errorf("expected the normal style without a highlight term, got %+v", got)
+	}
+
+	var opts drawOptions
+	WithHighlight("  设计 ")(&opts)
+	config.Highlight = opts.highlight
+	if config.Highlight != "设计" {
+		t.Fatalf("expected a trimmed term, got %q", config.Highlight)
+	}
+	if got := config.highlightStyle(types.NewNode("产品设计评审"), normal); got.FillColor != defaultMarkerColor {
+		t.Errorf("expected a CJK substring match to use the marker fill, got %+v", got)
+	}
+	note := &types.Node{Text: "Review", Note: "设计稿"}
+	if got := config.highlightStyle(note, normal); got.FillColor != defaultMarkerColor {
+		t.Errorf("expected a note match to be highlighted, got %+v", got)
+	}
+	if got := config.highlightStyle(types.NewNode("Build"), normal); got != normal {
+		t.Errorf("expected a non-match to keep its style, got %+v", got)
+	}
+
+	WithHighlight("API")(&opts)
+	config.Highlight = opts.highlight
+	if got := config.highlightStyle(types.NewNode("public api docs"), normal); got.FillColor != defaultMarkerColor {
+		t.Errorf("expected a case-insensitive match, got %+v", got)
+	}
+
+	config.DimUnmatched = true
+	got := config.highlightStyle(types.NewNode("Build"), normal)
+	if want := mixColor(normal.TextColor, config.BackgroundColor, dimAmount); got.TextColor != want {
+		t.Errorf("expected a dimmed text color %v, got %v", want, got.TextColor)
+	}
+
+	custom := &theme.NodeStyleConfig{FillColor: [3]float64{1, 0, 0}}
+	config.Theme = &theme.ThemeConfig{NodeStyles: theme.NodeStylesConfig{Highlight: custom}}
+	if got := config.highlightStyle(types.NewNode("API"), normal); got.FillColor != custom.FillColor {
+		t.Errorf("expected the theme's highlight style, got %+v", got)
+	}
+}
+
+func TestDrawSVGHighlight(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Match"}, {Text: "Other"}}}
+	var plain, highlighted bytes.Buffer
+	if err := DrawSVG(root, &plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := DrawSVG(root, &highlighted, WithHighlight("match")); err != nil {
+		t.Fatal(err)
+	}
+	marker := "fill:" + svgColor(defaultMarkerColor)
+	if strings.Contains(plain.String(), marker) {
+		t.Fatalf("expected no marker fill without a highlight")
+	}
+	if !strings.Contains(highlighted.String(), marker) {
+		t.Errorf("expected the matching node to use the marker fill %s", marker)
+	}
+}
@@ -0,0 +1,91 @@
+package drawer
+
+import (
+	"strings"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// iconGap 图标与文本之间的间距（未缩放）
+const iconGap = 6.0
+
+// iconGlyphs 常用 Font Awesome / Material Design 图标名对应的 Unicode 符号。
+// 没有内置图标集，图标类名借助这些符号绘制，字体缺少字形时同样跳过。
+var iconGlyphs = map[string]string{
+	"rocket":               "🚀",
+	"book":                 "📖",
+	"lightbulb":            "💡",
+	"fire":                 "🔥",
+	"thumbtack":            "📌",
+	"star":                 "★",
+	"star-o":               "☆",
+	"heart":                "♥",
+	"check":                "✔",
+	"times":                "✘",
+	"xmark":                "✘",
+	"warning":              "⚠",
+	"exclamation-triangle": "⚠",
+	"flag":                 "⚑",
+	"cog":                  "⚙",
+	"gear":                 "⚙",
+	"phone":                "☎",
+	"envelope":             "✉",
+	"circle":               "●",
+	"circle-o":             "○",
+	"square":               "■",
+	"square-o":             "□",
+	"arrow-right":          "→",
+	"arrow-left":           "←",
+	"arrow-up":             "↑",
+	"arrow-down":           "↓",
+}
+
+// iconGlyph 返回节点图标实际绘制的文本：图标类名（如 "fa fa-rocket"、"mdi mdi-book"）
+// 映射为对应符号，emoji 原样使用并去掉变体选择符；无法识别的类名返回空串
+func iconGlyph(icon string) string {
+	icon = strings.TrimSpace(icon)
+	if icon == "" {
+		return ""
+	}
+	if strings.IndexFunc(icon, isASCIILetter) >= 0 {
+		for _, class := range strings.Fields(icon) {
+			for _, prefix := range []string{"fa-", "mdi-"} {
+				if name, ok := strings.CutPrefix(class, prefix); ok {
+					if glyph, ok := iconGlyphs[name]; ok {
+						return glyph
+					}
+				}
+			}
+		}
+		return ""
+	}
+	return strings.NewReplacer("\uFE0E", "", "\uFE0F", "").Replace(icon)
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// nodeIcon 返回节点可绘制的图标，字体缺少其中任一字形时返回空串，节点按无图标处理
+func (c *DrawConfig) nodeIcon(icon string) string {
+	glyph := iconGlyph(icon)
+	if glyph == "" || c.hasGlyph == nil {
+		return ""
+	}
+	for _, r := range glyph {
+		if !c.hasGlyph(r) {
+			return ""
+		}
+	}
+	return glyph
+}
+
+// hasGlyph 判断测量与绘制使用的字体是否包含该字符的字形
+func (r *Renderer) hasGlyph(ch rune) bool {
+	if r.fontFile != nil {
+		var buf sfnt.Buffer
+		index, err := r.fontFile.GlyphIndex(&buf, ch)
+		return err == nil && index != 0
+	}
+	return r.font != nil && r.font.Index(ch) != 0
+}
@@ -0,0 +1,62 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestIconGlyph(t *testing.T) {
+	tests := map[string]string{
+		"🚀":                       "🚀",
+		"⭐️":                      "⭐",
+		"fa fa-star":              "★",
+		"fa-solid fa-arrow-right": "→",
+		"mdi mdi-book":            "📖",
+		"fa fa-unknown":           "",
+		"":                        "",
+	}
+	for icon, want := range tests {
+		if got := iconGlyph(icon); got != want {
+			t.Errorf("iconGlyph(%q) = %q, want %q", icon, got, want)
+		}
+	}
+}
+
+func TestDrawNodeIcon(t *testing.T) {
+	layout := func(icon string) *NodeSize {
+		child := &types.Node{Text: "Launch", Icon: icon}
+		root := &types.Node{Text: "Project", Children: []*types.Node{child}}
+		return NewRenderer(WithMinAspectRatio(0)).layout(root).nodeSizes[child]
+	}
+	plain := layout("")
+
+	// 内嵌字体包含 ★，为图标预留宽度
+	star := layout("fa fa-star")
+	if star.Icon != "★" || star.IconWidth <= iconGap {
+		t.Fatalf("expected a star icon with reserved width, got %q (%v)", star.Icon, star.IconWidth)
+	}
+	if star.ActualTextWidth != plain.ActualTextWidth+star.IconWidth {
+		t.Fatalf("expected the icon width to be added to the text width: %v vs %v", star.ActualTextWidth, plain.ActualTextWidth)
+	}
+
+	// 内嵌字体没有 emoji 字形，图标被跳过
+	rocket := layout("🚀")
+	if rocket.Icon != "" || rocket.Width != plain.Width {
+		t.Fatalf("expected the missing emoji to be skipped, got %q with width %v (plain %v)", rocket.Icon, rocket.Width, plain.Width)
+	}
+
+	root := &types.Node{Text: "Project", Icon: "★", Children: []*types.Node{{Text: "Launch", Icon: "🚀"}}}
+	var png, svg bytes.Buffer
+	if err := Draw(root, &png); err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	if err := DrawSVG(root, &svg); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	if !strings.Contains(svg.String(), "★") || strings.Contains(svg.String(), "🚀") {
+		t.Fatalf("expected only the covered icon in the SVG output")
+	}
+}
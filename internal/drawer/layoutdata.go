@@ -0,0 +1,96 @@
+package drawer
+
+import "github.com/hellodeveye/mindmapgen/pkg/types"
+
+// Layout is the geometry the drawer computes for a mind map, for clients
+// that draw the map themselves. Lengths are in unscaled layout units;
+// multiply by Scale for output pixels. Coordinates are relative to the top
+// left corner of the canvas, whose size includes the margins and any title
+// or caption band.
+type Layout struct {
+	Width  float64      `json:"width"`
+	Height float64      `json:"height"`
+	Scale  float64      `json:"scale"` // output scale the drawer would apply
+	Nodes  []LayoutNode `json:"nodes"` // in pre-order; the root comes first
+}
+
+// LayoutNode is the position and size of one node.
+type LayoutNode struct {
+	ID     int      `json:"id"`     // index in Layout.Nodes
+	Parent int      `json:"parent"` // ID of the parent node, -1 for the root
+	Depth  int      `json:"depth"`
+	Text   string   `json:"text"`
+	X      float64  `json:"x"` // center of the node
+	Y      float64  `json:"y"`
+	Width  float64  `json:"width"`
+	Height float64  `json:"height"`
+	Lines  []string `json:"lines"` // the text wrapped to the node width
+	// SubtreeImage is the URL of the full image of the subtree hidden below
+	// this node, set only with WithMaxDepth and WithSubtreeImages.
+	SubtreeImage string `json:"subtreeImage,omitempty"`
+}
+
+// ComputeLayout measures and positions the tree with the given options and
+// returns the geometry instead of drawing it.
+func ComputeLayout(rootNode *types.Node, options ...Option) (*Layout, error) {
+	return NewRenderer(options...).Layout(rootNode)
+}
+
+// Layout measures and positions the tree and returns the geometry instead
+// of drawing it.
+func (r *Renderer) Layout(rootNode *types.Node) (*Layout, error) {
+	if r.opts.err != nil {
+		return nil, r.opts.err
+	}
+	if r.fontErr != nil {
+		return nil, r.fontErr
+	}
+	rootNode, err := r.focusRoot(rootNode)
+	if err != nil {
+		return nil, err
+	}
+	l := r.layout(rootNode)
+	width, height := l.size()
+	out := &Layout{Width: width, Height: height, Scale: l.config.Scale, Nodes: []LayoutNode{}}
+
+	// 按先序记录节点，parents 保存当前路径上各深度节点的编号；未测量的节点连同其后代一起跳过
+	var parents []int
+	var uploadErr error
+	l.root.Walk(func(node *types.Node, depth int) bool {
+		size := l.nodeSizes[node]
+		if size == nil || depth > len(parents) {
+			parents = parents[:min(depth, len(parents))]
+			return true
+		}
+		parent := -1
+		if depth > 0 {
+			parent = parents[depth-1]
+		}
+		id := len(out.Nodes)
+		parents = append(parents[:depth], id)
+		out.Nodes = append(out.Nodes, LayoutNode{
+			ID:     id,
+			Parent: parent,
+			Depth:  depth,
+			Text:   node.Text,
+			X:      node.X - l.bounds.MinX,
+			Y:      node.Y - l.bounds.MinY,
+			Width:  size.Width,
+			Height: size.Height,
+			Lines:  size.Lines,
+		})
+		if original, ok := l.collapsed[node]; ok && r.opts.subtreeUpload != nil {
+			url, err := r.subtreeImage(original)
+			if err != nil {
+				uploadErr = err
+				return false
+			}
+			out.Nodes[id].SubtreeImage = url
+		}
+		return true
+	})
+	if uploadErr != nil {
+		return nil, uploadErr
+	}
+	return out, nil
+}
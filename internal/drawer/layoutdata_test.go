@@ -0,0 +1,117 @@
+package drawer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestComputeLayout(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{
+		{Text: "A", Children: []*types.Node{{Text: "A1"}}},
+		{Text: "B"},
+	}}
+	l, err := ComputeLayout(root, WithScale(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Scale != 2 || l.Width <= 0 || l.Height <= 0 {
+		t.Fatalf("unexpected canvas %gx%g at scale %g", l.Width, l.Height, l.Scale)
+	}
+
+	want := []struct {
+		text   string
+		parent int
+		depth  int
+	}{{"Root", -1, 0}, {"A", 0, 1}, {"A1", 1, 2}, {"B", 0, 1}}
+	if len(l.Nodes) != len(want) {
+		t.Fatalf("expected %d nodes, got %+v", len(want), l.Nodes)
+	}
+	for i, w := range want {
+		n := l.Nodes[i]
+		if n.ID != i || n.Text != w.text || n.Parent != w.parent || n.Depth != w.depth {
+			t.Errorf("node %d: got %+v, want %s with parent %d at depth %d", i, n, w.text, w.parent, w.depth)
+		}
+		if len(n.Lines) == 0 || n.Width <= 0 || n.Height <= 0 {
+			t.Errorf("node %d: expected measured lines and size, got %+v", i, n)
+		}
+		// 节点位于画布内
+		if n.X-n.Width/2 < 0 || n.X+n.Width/2 > l.Width || n.Y-n.Height/2 < 0 || n.Y+n.Height/2 > l.Height {
+			t.Errorf("node %d at (%g, %g) lies outside the %gx%g canvas", i, n.X, n.Y, l.Width, l.Height)
+		}
+	}
+	// 默认布局中子节点位于父节点右侧
+	if l.Nodes[1].X <= l.Nodes[0].X || l.Nodes[2].X <= l.Nodes[1].X {
+		t.Errorf("expected children to the right of their parents, got %+v", l.Nodes)
+	}
+
+	if _, err := ComputeLayout(root, WithLayout("diagonal")); err == nil {
+		t.Error("expected an error for an unknown layout")
+	}
+}
+
+func TestComputeLayoutSubtreeImages(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{
+		{Text: "A", Children: []*types.Node{{Text: "A1", Children: []*types.Node{{Text: "A1a"}}}}},
+		{Text: "B"},
+		{Text: "C", Children: []*types.Node{{Text: "C1"}}},
+	}}
+
+	// 假的上传函数按顺序返回地址，并检查上传的是完整子树的图片
+	var uploaded []image.Config
+	upload := func(data []byte, contentType string) (string, error) {
+		if contentType != "image/png" {
+			t.Errorf("expected a PNG subtree image, got %s", contentType)
+		}
+		cfg, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return "", err
+		}
+		uploaded = append(uploaded, cfg)
+		return fmt.Sprintf("https://cdn.example.com/subtree-%d.png", len(uploaded)), nil
+	}
+	l, err := ComputeLayout(root, WithMaxDepth(1), WithSubtreeImages(upload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Root": "",
+		"A":    "https://cdn.example.com/subtree-1.png",
+		"B":    "",
+		"C":    "https://cdn.example.com/subtree-2.png",
+	}
+	if len(l.Nodes) != len(want) {
+		t.Fatalf("expected %d nodes, got %+v", len(want), l.Nodes)
+	}
+	for _, n := range l.Nodes {
+		if n.SubtreeImage != want[n.Text] {
+			t.Errorf("node %s: subtree image %q, want %q", n.Text, n.SubtreeImage, want[n.Text])
+		}
+	}
+
+	// 子树图片不受深度限制：A 的三层子树比只有两层的 C 更宽
+	if len(uploaded) != 2 || uploaded[0].Width <= uploaded[1].Width {
+		t.Fatalf("expected full-depth subtree images, got %+v", uploaded)
+	}
+
+	// 上传失败时返回错误
+	failing := func([]byte, string) (string, error) { return "", errors.New("bucket unavailable") }
+	if _, err := ComputeLayout(root, WithMaxDepth(1), WithSubtreeImages(failing)); err == nil || !strings.Contains(err.Error(), "bucket unavailable") {
+		t.Fatalf("expected the upload error, got %v", err)
+	}
+
+	// 未限制深度时没有被裁剪的子树
+	l, err = ComputeLayout(root, WithSubtreeImages(func([]byte, string) (string, error) {
+		t.Error("unexpected upload without WithMaxDepth")
+		return "", nil
+	}))
+	if err != nil || len(l.Nodes) != 7 {
+		t.Fatalf("expected the full tree without uploads, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package drawer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// 图例中每个图标最多列出的节点标签数，以及单个标签的最大字符数
+const (
+	legendMaxLabels   = 3
+	legendMaxLabelLen = 16
+)
+
+// WithIconLegend adds a legend box in the bottom right corner that lists
+// each distinct node icon once, next to the labels of the nodes that use
+// it. Icons the font cannot draw are left out, and nothing is drawn when
+// no node has a drawable icon. The canvas grows to make room for the box.
+func WithIconLegend(enabled bool) Option {
+	return func(opts *drawOptions) {
+		opts.iconLegend = enabled
+	}
+}
+
+// legendEntry 图例中的一行：图标及使用该图标的节点标签
+type legendEntry struct {
+	icon  string
+	label string
+}
+
+// iconLegend 画布右下角的图标图例（布局单位）
+type iconLegend struct {
+	entries       []legendEntry
+	x, y          float64 // 左上角
+	width, height float64
+	iconWidth     float64 // 图标列宽度
+	labelWidths   []float64
+	pad           float64
+}
+
+// collectLegendEntries 按先序收集可绘制的不同图标，同一图标的节点标签合并为一行：
+// 多于 legendMaxLabels 个时只列出前几个并注明其余数量
+func collectLegendEntries(root *types.Node, config *DrawConfig) []legendEntry {
+	var icons []string
+	labels := map[string][]string{}
+	root.Walk(func(node *types.Node, _ int) bool {
+		icon := config.nodeIcon(node.Icon)
+		if icon == "" {
+			return true
+		}
+		if _, ok := labels[icon]; !ok {
+			icons = append(icons, icon)
+		}
+		labels[icon] = append(labels[icon], legendLabel(node.Text))
+		return true
+	})
+	entries := make([]legendEntry, len(icons))
+	for i, icon := range icons {
+		names := labels[icon]
+		label := strings.Join(names[:min(len(names), legendMaxLabels)], ", ")
+		if extra := len(names) - legendMaxLabels; extra > 0 {
+			label += fmt.Sprintf(" +%d", extra)
+		}
+		entries[i] = legendEntry{icon: icon, label: label}
+	}
+	return entries
+}
+
+// legendLabel 将节点文本压缩为单行，过长时截断并加省略号
+func legendLabel(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > legendMaxLabelLen {
+		text = string(runes[:legendMaxLabelLen-1]) + "…"
+	}
+	return text
+}
+
+// placeIconLegend 在已含边距的边界下方右侧放置图例，并扩展边界：图例比导图宽时向左加宽。
+// 没有可绘制的图标时不修改边界，返回 nil。
+func placeIconLegend(root *types.Node, measure func(string) float64, bounds *Bounds, config *DrawConfig) *iconLegend {
+	entries := collectLegendEntries(root, config)
+	if len(entries) == 0 {
+		return nil
+	}
+	pad := config.LineHeight / 2
+	l := &iconLegend{entries: entries, labelWidths: make([]float64, len(entries)), pad: pad}
+	labelWidth := 0.0
+	for i, e := range entries {
+		l.iconWidth = math.Max(l.iconWidth, measure(e.icon))
+		l.labelWidths[i] = measure(e.label)
+		labelWidth = math.Max(labelWidth, l.labelWidths[i])
+	}
+	l.width = 2*pad + l.iconWidth + iconGap + labelWidth
+	l.height = 2*pad + float64(len(entries))*config.LineHeight
+
+	// 图例右缘与导图右侧留出一行行高，下方同样留出一行行高
+	margin := config.LineHeight
+	l.x = bounds.MaxX - margin - l.width
+	l.y = bounds.MaxY
+	bounds.MaxY += l.height + margin
+	bounds.MinX = math.Min(bounds.MinX, l.x-margin)
+	return l
+}
+
+// drawIconLegend 以背景色填充、连接线颜色描边绘制图例框，逐行绘制图标与标签；
+// 骨架预览以占位条代替文本
+func drawIconLegend(dc canvas, l *iconLegend, config *DrawConfig) {
+	dc.Push()
+	defer dc.Pop()
+
+	scale := config.Scale
+	line, bg := config.ConnectionLineColor, config.BackgroundColor
+	x, y, w, h := l.x*scale, l.y*scale, l.width*scale, l.height*scale
+	radius := l.pad * scale
+	dc.SetRGB(bg[0], bg[1], bg[2])
+	drawRoundedRect(dc, x, y, w, h, radius)
+	dc.Fill()
+	dc.SetRGB(line[0], line[1], line[2])
+	dc.SetLineWidth(1.0 * scale)
+	drawRoundedRect(dc, x, y, w, h, radius)
+	dc.Stroke()
+
+	iconX := (l.x + l.pad) * scale
+	labelX := (l.x + l.pad + l.iconWidth + iconGap) * scale
+	for i, e := range l.entries {
+		rowY := (l.y + l.pad + (float64(i)+0.5)*config.LineHeight) * scale
+		if config.skeleton {
+			barH := config.FontSize * 0.5 * scale
+			drawRoundedRect(dc, labelX, rowY-barH/2, l.labelWidths[i]*scale, barH, barH/2)
+			dc.Fill()
+			continue
+		}
+		drawText(dc, config, e.icon, iconX, rowY, 0, 0.5)
+		drawText(dc, config, e.label, labelX, rowY, 0, 0.5)
+	}
+}
@@ -0,0 +1,88 @@
+package drawer
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestIconLegendListsEachIcon(t *testing.T) {
+	root := &types.Node{Text: "Project", Icon: "★", Children: []*types.Node{
+		{Text: "Launch", Icon: "fa fa-star"},
+		{Text: "Review", Icon: "fa fa-circle"},
+		{Text: "Ship", Icon: "fa fa-arrow-right", Children: []*types.Node{
+			{Text: "Done", Icon: "●"},
+			{Text: "Later", Icon: "🚀"}, // 内嵌字体没有该字形，不列入图例
+		}},
+	}}
+	l := NewRenderer(WithIconLegend(true)).layout(root)
+	if l.legend == nil {
+		t.Fatal("expected a legend for a tree with icons")
+	}
+
+	// 每个不同的图标各占一行，同一图标的节点标签合并
+	want := []legendEntry{
+		{icon: "★", label: "Project, Launch"},
+		{icon: "●", label: "Review, Done"},
+		{icon: "→", label: "Ship"},
+	}
+	if !slices.Equal(l.legend.entries, want) {
+		t.Fatalf("legend entries = %q, want %q", l.legend.entries, want)
+	}
+
+	dc := &textRecorder{Context: gg.NewContext(1, 1)}
+	paintMindmap(dc, l)
+	for _, e := range want {
+		if !slices.Contains(dc.texts, e.icon) || !slices.Contains(dc.texts, e.label) {
+			t.Errorf("expected the legend to draw %q next to %q, got %q", e.icon, e.label, dc.texts)
+		}
+	}
+
+	// 图例位于原有内容下方且完整位于画布内
+	base := NewRenderer().layout(root)
+	if l.legend.y < base.bounds.MaxY || l.legend.y+l.legend.height > l.bounds.MaxY {
+		t.Errorf("legend at y=%g does not fit between %g and %g", l.legend.y, base.bounds.MaxY, l.bounds.MaxY)
+	}
+	if l.legend.x < l.bounds.MinX || l.legend.x+l.legend.width > l.bounds.MaxX {
+		t.Errorf("legend at x=%g does not fit between %g and %g", l.legend.x, l.bounds.MinX, l.bounds.MaxX)
+	}
+
+	var svg bytes.Buffer
+	if err := DrawSVG(root, &svg, WithIconLegend(true)); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	if !strings.Contains(svg.String(), "Review, Done") {
+		t.Fatal("expected the legend in the SVG output")
+	}
+}
+
+func TestIconLegendWithoutIcons(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}, {Text: "B", Icon: "🚀"}}}
+	base := NewRenderer().layout(root)
+	l := NewRenderer(WithIconLegend(true)).layout(root)
+	if l.legend != nil || *l.bounds != *base.bounds {
+		t.Fatalf("expected no legend without drawable icons, got %+v", l.legend)
+	}
+}
+
+func TestLegendLabel(t *testing.T) {
+	if got := legendLabel("Multi\nline  text"); got != "Multi line text" {
+		t.Errorf("legendLabel collapsed whitespace to %q", got)
+	}
+	if got := legendLabel(strings.Repeat("a", 40)); len([]rune(got)) != legendMaxLabelLen || !strings.HasSuffix(got, "…") {
+		t.Errorf("expected a truncated label, got %q", got)
+	}
+
+	root := &types.Node{Text: "Root"}
+	for _, text := range []string{"A", "B", "C", "D", "E"} {
+		root.Children = append(root.Children, &types.Node{Text: text, Icon: "★"})
+	}
+	entries := collectLegendEntries(root, NewRenderer().newConfig())
+	if len(entries) != 1 || entries[0].label != "A, B, C +2" {
+		t.Fatalf("expected the extra labels to be counted, got %q", entries)
+	}
+}
@@ -0,0 +1,61 @@
+package drawer
+
+import (
+	"io"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+)
+
+// 消息卡片的版式（布局单位，按 messageScale 放大输出）
+const (
+	messageWidth    = 420.0
+	messagePadding  = 18.0
+	messageFontSize = 15.0
+	messageLineGap  = 6.0
+	messageScale    = 2.0
+)
+
+// DrawMessage renders a short plain-text message, such as an error, as a
+// small PNG card. It is meant for clients that embed the output in an <img>
+// tag and need an image even when the mind map itself cannot be drawn.
+func DrawMessage(w io.Writer, message string) error {
+	measure := gg.NewContext(1, 1)
+	font, err := parseEmbeddedFont()
+	if err == nil {
+		measure.SetFontFace(truetype.NewFace(font, &truetype.Options{Size: messageFontSize}))
+	}
+
+	// 按卡片宽度换行，中英文混排沿用节点文本的分词规则
+	cache := newTextMeasureCache(defaultShaper{})
+	lines := breakTextIntoLines(measure, splitIntoWords(message), messageWidth-2*messagePadding, cache)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	_, lineHeight := measure.MeasureString("M")
+	height := 2*messagePadding + float64(len(lines))*lineHeight + float64(len(lines)-1)*messageLineGap
+
+	// 字体按输出尺寸加载，避免缩放位图字形导致文字模糊
+	const k = messageScale
+	dc := gg.NewContext(int(messageWidth*k), int(height*k))
+	if font != nil {
+		dc.SetFontFace(truetype.NewFace(font, &truetype.Options{Size: messageFontSize * k}))
+	}
+
+	// 浅红底色与边框，提示这是一条错误信息而非导图
+	dc.SetRGB(1.0, 0.961, 0.961)
+	dc.Clear()
+	dc.SetRGB(0.898, 0.282, 0.302)
+	dc.SetLineWidth(2 * k)
+	dc.DrawRectangle(k, k, (messageWidth-2)*k, (height-2)*k)
+	dc.Stroke()
+
+	dc.SetRGB(0.392, 0.090, 0.137)
+	y := messagePadding
+	for _, line := range lines {
+		dc.DrawStringAnchored(line, messagePadding*k, y*k, 0, 1)
+		y += lineHeight + messageLineGap
+	}
+
+	return dc.EncodePNG(w)
+}
@@ -0,0 +1,52 @@
+package drawer
+
+import (
+	"strings"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// metaScale 元数据脚注相对正文的字号比例
+const metaScale = 0.75
+
+// metaFooter 按 keys 的顺序拼接节点中存在的元数据，如 "owner: Ann · due: 5/1"
+func metaFooter(node *types.Node, keys []string) string {
+	if len(node.Meta) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, key := range keys {
+		if value, ok := node.Meta[key]; ok && value != "" {
+			parts = append(parts, key+": "+value)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// addMetaFooter 为节点尺寸加入元数据脚注：高度增加一行缩小的文字，宽度至少容纳整条脚注
+func addMetaFooter(dc *gg.Context, size *NodeSize, footer string, config *DrawConfig, cache *textMeasureCache) {
+	if footer == "" {
+		return
+	}
+	width := measureStringCached(dc, footer, cache) * metaScale
+	size.Footer = footer
+	size.Height += size.LineHeight * metaScale
+	size.Width = max(size.Width, width+2*config.TextPadding)
+	size.ActualTextWidth = max(size.ActualTextWidth, width)
+}
+
+// footerHeight 返回节点脚注占用的高度（未缩放），没有脚注时为 0
+func (s *NodeSize) footerHeight() float64 {
+	if s.Footer == "" {
+		return 0
+	}
+	return s.LineHeight * metaScale
+}
+
+// drawMetaFooter 在文本块下方以较小字号、半透明的文本色绘制脚注，在 [left, right] 内按文本对齐方式放置
+func drawMetaFooter(dc canvas, footer string, left, right, cy float64, style *types.NodeStyle, config *DrawConfig) {
+	width := measureText(dc, config, footer) * metaScale
+	dc.SetRGBA(style.TextColor[0], style.TextColor[1], style.TextColor[2], 0.7)
+	drawScaledText(dc, config, footer, alignedX(config.TextAlign, left, right, width), cy, metaScale)
+}
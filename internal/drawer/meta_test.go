@@ -0,0 +1,50 @@
+package drawer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestMetaFooter(t *testing.T) {
+	node := &types.Node{Text: "Launch", Meta: map[string]string{"owner": "Ann", "due": "5/1", "status": "done"}}
+	if got, want := metaFooter(node, []string{"status", "owner", "missing"}), "status: done · owner: Ann"; got != want {
+		t.Fatalf("metaFooter() = %q, want %q", got, want)
+	}
+	if got := metaFooter(&types.Node{Text: "Plain"}, []string{"owner"}); got != "" {
+		t.Fatalf("expected no footer without metadata, got %q", got)
+	}
+}
+
+func TestDrawMetaKeys(t *testing.T) {
+	newTree := func() (*types.Node, *types.Node) {
+		child := &types.Node{Text: "Launch", Meta: map[string]string{"owner": "Ann"}}
+		return &types.Node{Text: "Project", Children: []*types.Node{child}}, child
+	}
+
+	root, child := newTree()
+	plain := NewRenderer().layout(root).nodeSizes[child]
+	root, child = newTree()
+	withMeta := NewRenderer(WithMetaKeys("owner")).layout(root).nodeSizes[child]
+
+	if plain.Footer != "" {
+		t.Fatalf("expected no footer without WithMetaKeys, got %q", plain.Footer)
+	}
+	if withMeta.Footer != "owner: Ann" {
+		t.Fatalf("expected the owner footer, got %q", withMeta.Footer)
+	}
+	if withMeta.Height <= plain.Height {
+		t.Fatalf("expected the footer to add height: %v <= %v", withMeta.Height, plain.Height)
+	}
+
+	for _, draw := range []func(*types.Node, *bytes.Buffer) error{
+		func(n *types.Node, buf *bytes.Buffer) error { return Draw(n, buf, WithMetaKeys("owner")) },
+		func(n *types.Node, buf *bytes.Buffer) error { return DrawSVG(n, buf, WithMetaKeys("owner")) },
+	} {
+		var buf bytes.Buffer
+		if err := draw(root, &buf); err != nil {
+			t.Fatalf("draw failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package drawer
+
+import (
+	"strings"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// noteScale 节点备注相对正文的字号比例
+const noteScale = 0.8
+
+// tooltipCanvas 支持悬停提示的绘制面：beginTooltip 之后、endTooltip 之前绘制的内容悬停时显示 text。
+// 目前仅 SVG 实现，以 <title> 元素输出节点备注。
+type tooltipCanvas interface {
+	beginTooltip(text string)
+	endTooltip()
+}
+
+// addNote 为节点尺寸加入备注：备注按最大节点宽度换行，以缩小的字号排在文本下方，
+// 节点随之加高，必要时加宽以容纳最长的一行
+func addNote(dc *gg.Context, size *NodeSize, note string, config *DrawConfig, cache *textMeasureCache) {
+	available := (config.MaxNodeWidth - 2*config.TextPadding) / noteScale
+	var lines []string
+	for _, paragraph := range strings.Split(note, "\n") {
+		if words := splitIntoWords(paragraph); len(words) > 0 {
+			lines = append(lines, breakTextIntoLines(dc, words, available, cache)...)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	var width float64
+	for _, line := range lines {
+		width = max(width, measureStringCached(dc, line, cache)*noteScale)
+	}
+	size.Note = lines
+	size.Height += size.noteHeight()
+	size.Width = max(size.Width, width+2*config.TextPadding)
+	size.ActualTextWidth = max(size.ActualTextWidth, width)
+}
+
+// noteHeight 返回节点备注占用的高度（未缩放），没有备注时为 0
+func (s *NodeSize) noteHeight() float64 {
+	return float64(len(s.Note)) * s.LineHeight * noteScale
+}
+
+// textBlockHeight 返回文本、备注与脚注合计的高度（未缩放）
+func (s *NodeSize) textBlockHeight() float64 {
+	return float64(len(s.Lines))*s.LineHeight + s.noteHeight() + s.footerHeight()
+}
+
+// drawNote 从 top 开始以较小字号、更淡的文本色逐行绘制备注，各行在 [left, right] 内按文本对齐方式放置，
+// lineHeight 为已缩放的正文行高
+func drawNote(dc canvas, lines []string, left, right, top, lineHeight float64, style *types.NodeStyle, config *DrawConfig) {
+	dc.SetRGBA(style.TextColor[0], style.TextColor[1], style.TextColor[2], 0.6)
+	step := lineHeight * noteScale
+	for i, line := range lines {
+		width := measureText(dc, config, line) * noteScale
+		drawScaledText(dc, config, line, alignedX(config.TextAlign, left, right, width), top+(float64(i)+0.5)*step, noteScale)
+	}
+}
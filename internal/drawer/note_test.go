@@ -0,0 +1,45 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestNoteExpandsNode(t *testing.T) {
+	plain := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Task"}}}
+	noted := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Task", Note: "a fairly long note that has to wrap onto more than one line below the label"}}}
+
+	r := NewRenderer()
+	before := r.layout(plain).nodeSizes[plain.Children[0]]
+	after := r.layout(noted).nodeSizes[noted.Children[0]]
+	if len(after.Note) < 2 {
+		t.Fatalf("expected the note to wrap, got %q", after.Note)
+	}
+	if after.Height <= before.Height || after.Width <= before.Width {
+		t.Fatalf("expected the note to enlarge the node: %gx%g -> %gx%g", before.Width, before.Height, after.Width, after.Height)
+	}
+	if want := float64(len(after.Lines))*after.LineHeight + after.noteHeight(); after.textBlockHeight() != want {
+		t.Errorf("expected the text block to include the note, got %g want %g", after.textBlockHeight(), want)
+	}
+	if len(before.Note) != 0 || before.textBlockHeight() != float64(len(before.Lines))*before.LineHeight {
+		t.Errorf("expected nodes without a note to be unchanged")
+	}
+}
+
+func TestDrawSVGNoteTooltip(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Task", Note: "due <Friday>"}}}
+	var buf bytes.Buffer
+	if err := DrawSVG(root, &buf); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	svg := buf.String()
+	if strings.Count(svg, "<title>") != 1 || !strings.Contains(svg, "<title>due &lt;Friday&gt;</title>") {
+		t.Fatalf("expected one escaped tooltip, got %s", svg)
+	}
+	if !strings.Contains(svg, "due &lt;Friday&gt;</text>") {
+		t.Errorf("expected the note to be drawn as text too")
+	}
+}
@@ -0,0 +1,62 @@
+package drawer
+
+import (
+	"sort"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// orderChildren 返回按排序键重排兄弟节点后的树，原树不受影响。带排序键的节点在它们原来占据的
+// 位置之间按键稳定排序，没有排序键的节点保持原位置不变。只有子节点顺序改变的节点及其祖先
+// 被浅拷贝，其余子树仍是原节点；没有需要调整的顺序时返回 node 本身。
+func orderChildren(node *types.Node) *types.Node {
+	if node == nil {
+		return nil
+	}
+
+	// children 为 nil 时表示沿用 node.Children，需要修改时才复制
+	var children []*types.Node
+	for i, child := range node.Children {
+		if ordered := orderChildren(child); ordered != child {
+			if children == nil {
+				children = append([]*types.Node(nil), node.Children...)
+			}
+			children[i] = ordered
+		}
+	}
+	current := node.Children
+	if children != nil {
+		current = children
+	}
+
+	var slots []int
+	var keyed []*types.Node
+	for i, child := range current {
+		if child != nil && child.Order != nil {
+			slots = append(slots, i)
+			keyed = append(keyed, child)
+		}
+	}
+	if len(keyed) > 1 {
+		sort.SliceStable(keyed, func(i, j int) bool {
+			return *keyed[i].Order < *keyed[j].Order
+		})
+		for i, slot := range slots {
+			if current[slot] == keyed[i] {
+				continue
+			}
+			if children == nil {
+				children = append([]*types.Node(nil), node.Children...)
+				current = children
+			}
+			children[slot] = keyed[i]
+		}
+	}
+
+	if children == nil {
+		return node
+	}
+	clone := *node
+	clone.Children = children
+	return &clone
+}
@@ -0,0 +1,311 @@
+package drawer
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+	"golang.org/x/image/math/fixed"
+)
+
+// DrawPDF renders the mind map as a single-page vector PDF. It reuses the
+// same layout as Draw; connectors are real Bézier curves, text is embedded
+// with a subset of the bundled CJK font, and the page size follows the
+// content bounds (one layout unit per PDF point).
+func DrawPDF(rootNode *types.Node, w io.Writer, options ...Option) error {
+	return drawVector(rootNode, w, options, func(_ drawOptions, height float64) vectorSurface {
+		return newPDFCanvas(height)
+	})
+}
+
+// pdfCanvas 将 canvas 调用记录为 PDF 内容流，页面级变换负责翻转 y 轴
+type pdfCanvas struct {
+	vectorCanvas
+
+	content  bytes.Buffer
+	alphas   map[float64]string // 透明度 -> ExtGState 名称
+	glyphs   map[uint16]rune    // 已使用的字形及其对应字符
+	shadings []string           // 渐变填充的轴向着色字典，名称为 Sh 加下标
+}
+
+func newPDFCanvas(pageHeight float64) *pdfCanvas {
+	pc := &pdfCanvas{
+		vectorCanvas: newVectorCanvas(),
+		alphas:       make(map[float64]string),
+		glyphs:       make(map[uint16]rune),
+	}
+	// 翻转 y 轴，使后续坐标与 gg 保持一致
+	fmt.Fprintf(&pc.content, "1 0 0 -1 0 %s cm\n", formatNum(pageHeight))
+	return pc
+}
+
+func (pc *pdfCanvas) Fill() {
+	pc.paint("rg", "f")
+}
+
+func (pc *pdfCanvas) Stroke() {
+	pc.paint("RG", "S")
+}
+
+// paint 以当前状态输出并清空待绘制的路径
+func (pc *pdfCanvas) paint(colorOp, paintOp string) {
+	path := pc.takePath()
+	if len(path) == 0 {
+		return
+	}
+	pc.content.WriteString("q\n")
+	pc.writeAlpha()
+	c := pc.state.color
+	fmt.Fprintf(&pc.content, "%s %s %s %s\n", formatNum(c[0]), formatNum(c[1]), formatNum(c[2]), colorOp)
+	if paintOp == "S" {
+		fmt.Fprintf(&pc.content, "%s w 0 J 1 j\n", formatNum(pc.state.lineWidth))
+	}
+	pc.writePath(path)
+	pc.content.WriteString(paintOp + "\nQ\n")
+}
+
+// fillLinearGradient 以当前路径为裁剪区域绘制轴向着色（ShadingType 2）
+func (pc *pdfCanvas) fillLinearGradient(x0, y0, x1, y1 float64, from, to [3]float64) {
+	path := pc.takePath()
+	if len(path) == 0 {
+		return
+	}
+	p0, p1 := pc.transform(x0, y0), pc.transform(x1, y1)
+	name := "Sh" + strconv.Itoa(len(pc.shadings))
+	pc.shadings = append(pc.shadings, fmt.Sprintf(
+		"<< /ShadingType 2 /ColorSpace /DeviceRGB /Coords [%s %s %s %s] /Function << /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >> /Extend [true true] >>",
+		formatNum(p0.X), formatNum(p0.Y), formatNum(p1.X), formatNum(p1.Y), pdfColor(from), pdfColor(to)))
+
+	pc.content.WriteString("q\n")
+	pc.writeAlpha()
+	pc.writePath(path)
+	fmt.Fprintf(&pc.content, "W n\n/%s sh\nQ\n", name)
+}
+
+// pdfColor 将 0-1 的颜色分量写为 PDF 数组中的三个数
+func pdfColor(c [3]float64) string {
+	return formatNum(c[0]) + " " + formatNum(c[1]) + " " + formatNum(c[2])
+}
+
+// writePath 将路径片段写入内容流
+func (pc *pdfCanvas) writePath(path []pathSegment) {
+	for _, seg := range path {
+		for _, p := range seg.pts {
+			fmt.Fprintf(&pc.content, "%s %s ", formatNum(p.X), formatNum(p.Y))
+		}
+		switch seg.op {
+		case 'M':
+			pc.content.WriteString("m\n")
+		case 'L':
+			pc.content.WriteString("l\n")
+		case 'C':
+			pc.content.WriteString("c\n")
+		case 'Z':
+			pc.content.WriteString("h\n")
+		}
+	}
+}
+
+func (pc *pdfCanvas) writeAlpha() {
+	if pc.state.alpha >= 1 {
+		return
+	}
+	name, ok := pc.alphas[pc.state.alpha]
+	if !ok {
+		name = "GS" + strconv.Itoa(len(pc.alphas))
+		pc.alphas[pc.state.alpha] = name
+	}
+	fmt.Fprintf(&pc.content, "/%s gs\n", name)
+}
+
+func (pc *pdfCanvas) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	if pc.font == nil || s == "" {
+		return
+	}
+	p := pc.textOrigin(s, x, y, ax, ay)
+
+	var hex strings.Builder
+	for _, r := range s {
+		gid := pc.font.glyph(r)
+		if _, ok := pc.glyphs[gid]; !ok {
+			pc.glyphs[gid] = r
+		}
+		fmt.Fprintf(&hex, "%04X", gid)
+	}
+
+	pc.content.WriteString("q\n")
+	pc.writeAlpha()
+	c := pc.state.color
+	fmt.Fprintf(&pc.content, "%s %s %s rg\n", formatNum(c[0]), formatNum(c[1]), formatNum(c[2]))
+	// 文本矩阵再次翻转 y 轴，使字形保持正向
+	fmt.Fprintf(&pc.content, "BT /F1 %s Tf 1 0 0 -1 %s %s Tm <%s> Tj ET\nQ\n",
+		formatNum(pc.fontSize), formatNum(p.X), formatNum(p.Y), hex.String())
+}
+
+// writeTo 写出包含单页的完整 PDF 文档
+func (pc *pdfCanvas) writeTo(w io.Writer, width, height float64) error {
+	doc := &pdfWriter{}
+	doc.add("<< /Type /Catalog /Pages 2 0 R >>")
+	doc.add("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	pageID := doc.reserve()
+	contentID := doc.addStream("", pc.content.Bytes())
+
+	var resources strings.Builder
+	resources.WriteString("<<")
+	if len(pc.glyphs) > 0 {
+		fontID, err := pc.writeFont(doc)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&resources, " /Font << /F1 %d 0 R >>", fontID)
+	}
+	if len(pc.alphas) > 0 {
+		alphas := make([]float64, 0, len(pc.alphas))
+		for a := range pc.alphas {
+			alphas = append(alphas, a)
+		}
+		sort.Float64s(alphas)
+		resources.WriteString(" /ExtGState <<")
+		for _, a := range alphas {
+			fmt.Fprintf(&resources, " /%s << /ca %s /CA %s >>", pc.alphas[a], formatNum(a), formatNum(a))
+		}
+		resources.WriteString(" >>")
+	}
+	if len(pc.shadings) > 0 {
+		resources.WriteString(" /Shading <<")
+		for i, shading := range pc.shadings {
+			fmt.Fprintf(&resources, " /Sh%d %s", i, shading)
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+
+	doc.set(pageID, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+		formatNum(width), formatNum(height), resources.String(), contentID))
+
+	return doc.writeTo(w)
+}
+
+// writeFont 写出 Type0 复合字体（Identity-H 编码，字符编码即字形编号）
+func (pc *pdfCanvas) writeFont(doc *pdfWriter) (int, error) {
+	font := pc.font
+	fontFile, err := font.subset(usedGlyphSet(pc.glyphs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to subset PDF font: %w", err)
+	}
+
+	gids := make([]int, 0, len(pc.glyphs))
+	for gid := range pc.glyphs {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var widths strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%d] ", gid, font.toPDFUnits(font.advance(uint16(gid))))
+	}
+
+	bounds := font.font.Bounds(fixed.Int26_6(font.unitsPerEm))
+	bbox := fmt.Sprintf("[%d %d %d %d]",
+		font.toPDFUnits(int(bounds.Min.X)), font.toPDFUnits(int(bounds.Min.Y)),
+		font.toPDFUnits(int(bounds.Max.X)), font.toPDFUnits(int(bounds.Max.Y)))
+	const baseFont = "/MMGSUB+SimHei"
+
+	fileID := doc.addStream(fmt.Sprintf("/Length1 %d", len(fontFile)), fontFile)
+	descriptorID := doc.add(fmt.Sprintf("<< /Type /FontDescriptor /FontName %s /Flags 4 /FontBBox %s /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		baseFont, bbox, font.toPDFUnits(int(bounds.Max.Y)), font.toPDFUnits(int(bounds.Min.Y)), font.toPDFUnits(int(bounds.Max.Y)), fileID))
+	cidFontID := doc.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont %s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		baseFont, descriptorID, strings.TrimSpace(widths.String())))
+	toUnicodeID := doc.addStream("", toUnicodeCMap(gids, pc.glyphs))
+	return doc.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont %s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		baseFont, cidFontID, toUnicodeID)), nil
+}
+
+func usedGlyphSet(glyphs map[uint16]rune) map[uint16]bool {
+	used := make(map[uint16]bool, len(glyphs))
+	for gid := range glyphs {
+		used[gid] = true
+	}
+	return used
+}
+
+// toUnicodeCMap 生成字形到 Unicode 的映射，使 PDF 中的文本可复制与检索
+func toUnicodeCMap(gids []int, glyphs map[uint16]rune) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(gids); start += 100 {
+		end := min(start+100, len(gids))
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			fmt.Fprintf(&b, "<%04X> <%s>\n", gid, utf16Hex(glyphs[uint16(gid)]))
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+func utf16Hex(r rune) string {
+	if r >= 0x10000 {
+		r -= 0x10000
+		return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+	}
+	return fmt.Sprintf("%04X", r)
+}
+
+// pdfWriter 收集间接对象并写出交叉引用表
+type pdfWriter struct {
+	objects []string
+}
+
+func (d *pdfWriter) add(obj string) int {
+	d.objects = append(d.objects, obj)
+	return len(d.objects)
+}
+
+func (d *pdfWriter) reserve() int {
+	return d.add("")
+}
+
+func (d *pdfWriter) set(id int, obj string) {
+	d.objects[id-1] = obj
+}
+
+// addStream 添加以 FlateDecode 压缩的流对象，extra 为附加的字典项
+func (d *pdfWriter) addStream(extra string, data []byte) int {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(data)
+	zw.Close()
+	dict := fmt.Sprintf("<< /Length %d /Filter /FlateDecode", compressed.Len())
+	if extra != "" {
+		dict += " " + extra
+	}
+	return d.add(dict + " >>\nstream\n" + compressed.String() + "\nendstream")
+}
+
+func (d *pdfWriter) writeTo(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	offsets := make([]int, len(d.objects))
+	for i, obj := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,95 @@
+package drawer
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+var pdfStreamPattern = regexp.MustCompile(`(?s)\d+ 0 obj\n<<([^\n]*?)>>\nstream\n(.*?)\nendstream`)
+
+// pdfStreams 解压 PDF 中的所有流对象，返回字典与内容
+func pdfStreams(t *testing.T, data []byte) (dicts []string, streams [][]byte) {
+	t.Helper()
+	for _, m := range pdfStreamPattern.FindAllSubmatch(data, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[2]))
+		if err != nil {
+			t.Fatalf("invalid stream: %v", err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("invalid stream: %v", err)
+		}
+		dicts = append(dicts, string(m[1]))
+		streams = append(streams, b)
+	}
+	return dicts, streams
+}
+
+func TestDrawPDF(t *testing.T) {
+	root := &types.Node{Text: "中文导图", Children: []*types.Node{
+		{Text: "Child", Children: []*types.Node{{Text: "叶子"}}},
+		{Text: "Cloud", Shape: types.ShapeCloud},
+	}}
+
+	var buf bytes.Buffer
+	if err := DrawPDF(root, &buf); err != nil {
+		t.Fatalf("DrawPDF failed: %v", err)
+	}
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("output is not a PDF document")
+	}
+
+	// 交叉引用表中的偏移量必须指向对应对象
+	xrefAt := bytes.LastIndex(data, []byte("startxref\n"))
+	xrefOffset, _ := strconv.Atoi(strings.Fields(string(data[xrefAt+len("startxref\n"):]))[0])
+	lines := strings.Split(string(data[xrefOffset:]), "\n")
+	for i, line := range lines[3:] {
+		if !strings.HasSuffix(line, " n ") {
+			break
+		}
+		off, _ := strconv.Atoi(line[:10])
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Fatalf("xref entry %d points at %q", i+1, data[off:off+10])
+		}
+	}
+
+	dicts, streams := pdfStreams(t, data)
+	var content, fontFile []byte
+	for i, d := range dicts {
+		if strings.Contains(d, "/Length1") {
+			fontFile = streams[i]
+		} else if content == nil {
+			content = streams[i]
+		}
+	}
+	if !bytes.Contains(content, []byte(" c\n")) {
+		t.Fatal("expected connectors to be drawn as Bézier curves")
+	}
+	if !bytes.Contains(content, []byte("Tj")) {
+		t.Fatal("expected text to be drawn as PDF text")
+	}
+
+	font, err := truetype.Parse(fontFile)
+	if err != nil {
+		t.Fatalf("embedded font is not valid TrueType: %v", err)
+	}
+	if len(fontFile) > 1<<20 {
+		t.Fatalf("expected a subset font, got %d bytes", len(fontFile))
+	}
+	var glyph truetype.GlyphBuf
+	if err := glyph.Load(font, 1000, font.Index('中'), 0); err != nil || len(glyph.Points) == 0 {
+		t.Fatalf("expected used glyph to keep its outline: %v", err)
+	}
+	if err := glyph.Load(font, 1000, font.Index('龍'), 0); err != nil || len(glyph.Points) != 0 {
+		t.Fatalf("expected unused glyph to be dropped from the subset: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package drawer
+
+import (
+	"image"
+	"image/png"
+	"strings"
+)
+
+// qualityPreset 质量预设对应的具体渲染参数
+type qualityPreset struct {
+	scale       float64              // 输出缩放，0 表示使用主题的缩放
+	supersample int                  // 超采样倍数，1 表示不超采样
+	compression png.CompressionLevel // PNG 压缩级别
+}
+
+// qualityPresets 可选的质量预设：draft 追求速度与体积，high 追求边缘平滑与压缩率
+var qualityPresets = map[string]qualityPreset{
+	"draft":  {scale: 1, supersample: 1, compression: png.BestSpeed},
+	"normal": {supersample: 1, compression: png.DefaultCompression},
+	"high":   {supersample: 2, compression: png.BestCompression},
+}
+
+// maxSupersamplePixels 超采样画布允许的最大像素数，超出时退回普通渲染
+const maxSupersamplePixels = DefaultMaxWidth * DefaultMaxHeight
+
+// IsQualityPreset reports whether name is one of the quality presets accepted
+// by WithQuality: draft, normal or high.
+func IsQualityPreset(name string) bool {
+	_, ok := qualityPresets[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// WithQuality selects a quality preset instead of tuning individual knobs:
+// "draft" renders at scale 1 with fast PNG compression for quick previews,
+// "normal" keeps the theme's scale, and "high" additionally supersamples the
+// raster 2x for smoother edges and compresses the PNG harder. An explicit
+// WithScale takes precedence over the preset's scale. Unknown names are
+// ignored.
+func WithQuality(preset string) Option {
+	return func(opts *drawOptions) {
+		if p, ok := qualityPresets[strings.ToLower(strings.TrimSpace(preset))]; ok {
+			opts.preset = &p
+		}
+	}
+}
+
+// downsample 按整数倍数缩小位图，每个输出像素取 k×k 源像素的平均值。
+// image.RGBA 为预乘 alpha，直接平均即可得到正确的透明边缘。
+func downsample(src *image.RGBA, k int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()/k, b.Dy()/k))
+	n := uint32(k * k)
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			var sum [4]uint32
+			for sy := 0; sy < k; sy++ {
+				i := src.PixOffset(b.Min.X+x*k, b.Min.Y+y*k+sy)
+				for sx := 0; sx < k; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += uint32(src.Pix[i+c])
+					}
+					i += 4
+				}
+			}
+			j := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[j+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
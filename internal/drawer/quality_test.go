@@ -0,0 +1,70 @@
+package drawer
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestQualityPresets(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Root", Children: []*types.Node{
+			{Text: "Alpha", Children: []*types.Node{{Text: "One"}, {Text: "Two"}}},
+			{Text: "Beta"},
+		}}
+	}
+	render := func(preset string) (image.Image, int) {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := Draw(newTree(), &buf, WithQuality(preset)); err != nil {
+			t.Fatalf("%s: draw failed: %v", preset, err)
+		}
+		size := buf.Len()
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: invalid PNG: %v", preset, err)
+		}
+		return img, size
+	}
+
+	// draft 以 1 倍缩放绘制且不超采样，绘制的像素远少于 high，因此更快、体积更小
+	draft, draftBytes := render("draft")
+	normal, _ := render("normal")
+	high, highBytes := render("high")
+
+	if draft.Bounds().Dx() >= high.Bounds().Dx() || draftBytes >= highBytes {
+		t.Fatalf("expected draft to be smaller than high: %v/%d bytes vs %v/%d bytes",
+			draft.Bounds(), draftBytes, high.Bounds(), highBytes)
+	}
+	// 超采样不改变输出尺寸
+	if high.Bounds() != normal.Bounds() {
+		t.Fatalf("expected high to keep the normal size %v, got %v", normal.Bounds(), high.Bounds())
+	}
+	if !IsQualityPreset("High") || IsQualityPreset("ultra") {
+		t.Fatalf("unexpected preset recognition")
+	}
+	// 显式缩放优先于预设
+	if got := NewRenderer(WithQuality("draft"), WithScale(2)).config.Scale; got != 2 {
+		t.Fatalf("expected WithScale to override the draft scale, got %v", got)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	src.Set(0, 0, color.RGBA{255, 255, 255, 255})
+	src.Set(1, 1, color.RGBA{255, 255, 255, 255})
+	dst := downsample(src, 2)
+	if dst.Bounds().Dx() != 2 || dst.Bounds().Dy() != 1 {
+		t.Fatalf("unexpected bounds %v", dst.Bounds())
+	}
+	if got := dst.RGBAAt(0, 0); got.A != 128 || got.R != 128 {
+		t.Fatalf("expected averaged pixel, got %v", got)
+	}
+	if got := dst.RGBAAt(1, 0); got.A != 0 {
+		t.Fatalf("expected a transparent pixel, got %v", got)
+	}
+}
@@ -0,0 +1,204 @@
+package drawer
+
+import (
+	"math"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// layoutRadial 径向布局：根节点位于原点，分支按角度向外辐射，深度对应半径
+const layoutRadial = "radial"
+
+// isRadial 判断是否为径向布局
+func (c *DrawConfig) isRadial() bool {
+	return c.Layout == layoutRadial
+}
+
+// radialPlacement 径向布局中一个节点的方向与所占扇区（弧度）
+type radialPlacement struct {
+	node, parent *types.Node
+	angle        float64 // 节点中心的方向
+	wedge        float64 // 扇区宽度，子孙节点都落在其中
+}
+
+// radialLayout 径向布局算法：根节点的子节点从正上方开始顺时针瓜分整圆，
+// 每个节点的扇区与其子树叶子数成正比，子孙节点在父节点的扇区内继续细分。
+// 同一深度的节点位于同一圆周上，半径取满足以下条件的最小值：
+// 与父节点之间保留层级间距，且扇区的弦长足以容纳节点与节点间距。
+func radialLayout(root *types.Node, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig) {
+	if root == nil || nodeSizes[root] == nil {
+		return
+	}
+	root.X, root.Y = 0, 0
+
+	leaves := make(map[*types.Node]float64)
+	countRadialLeaves(root, nodeSizes, leaves)
+
+	// 按深度分组记录每个节点的方向与扇区
+	var levels [][]radialPlacement
+	var assign func(node *types.Node, start, wedge float64, depth int)
+	assign = func(node *types.Node, start, wedge float64, depth int) {
+		current := start
+		for _, child := range node.Children {
+			if nodeSizes[child] == nil {
+				continue
+			}
+			share := wedge * leaves[child] / leaves[node]
+			if len(levels) <= depth {
+				levels = append(levels, nil)
+			}
+			levels[depth] = append(levels[depth], radialPlacement{node: child, parent: node, angle: current + share/2, wedge: share})
+			assign(child, current, share, depth+1)
+			current += share
+		}
+	}
+	assign(root, -math.Pi/2, 2*math.Pi, 0)
+
+	radius := map[*types.Node]float64{root: 0}
+	for depth, level := range levels {
+		ring := 0.0
+		for _, p := range level {
+			size := nodeSizes[p.node]
+			need := radius[p.parent] + radialExtent(nodeSizes[p.parent], p.angle)/2 + config.levelSpacing(depth) + radialExtent(size, p.angle)/2
+			// 扇区不足半圆时，弦长 2r·sin(w/2) 需容纳节点在切线方向上的尺寸
+			if p.wedge < math.Pi {
+				need = math.Max(need, (tangentExtent(size, p.angle)+config.NodeSpacing)/(2*math.Sin(p.wedge/2)))
+			}
+			ring = math.Max(ring, need)
+		}
+		for _, p := range level {
+			radius[p.node] = ring
+			p.node.X = ring * math.Cos(p.angle)
+			p.node.Y = ring * math.Sin(p.angle)
+		}
+	}
+}
+
+// countRadialLeaves 统计每棵子树的叶子数，没有可见子节点的节点计为 1
+func countRadialLeaves(node *types.Node, nodeSizes map[*types.Node]*NodeSize, leaves map[*types.Node]float64) float64 {
+	count := 0.0
+	for _, child := range node.Children {
+		if nodeSizes[child] != nil {
+			count += countRadialLeaves(child, nodeSizes, leaves)
+		}
+	}
+	leaves[node] = math.Max(count, 1)
+	return leaves[node]
+}
+
+// radialExtent 返回节点在 angle 方向（半径方向）上的投影长度
+func radialExtent(size *NodeSize, angle float64) float64 {
+	return math.Abs(size.Width*math.Cos(angle)) + math.Abs(size.Height*math.Sin(angle))
+}
+
+// tangentExtent 返回节点在垂直于 angle 的方向（切线方向）上的投影长度
+func tangentExtent(size *NodeSize, angle float64) float64 {
+	return math.Abs(size.Width*math.Sin(angle)) + math.Abs(size.Height*math.Cos(angle))
+}
+
+// radialEdge 返回从节点中心沿 (dx, dy) 方向射出的射线与节点边框的交点（未缩放）。
+// leafText 为 true 时改用文本块的边缘，与其他布局中默认形状叶子节点的连接线一致
+func radialEdge(node *types.Node, size *NodeSize, dx, dy float64, leafText bool) (float64, float64) {
+	halfW, halfH := size.Width/2, size.Height/2
+	if leafText {
+		textGap := 5.0
+		halfW, halfH = size.ActualTextWidth/2+textGap, size.textBlockHeight()/2+textGap
+	}
+	t := math.Inf(1)
+	if dx != 0 {
+		t = halfW / math.Abs(dx)
+	}
+	if dy != 0 {
+		t = math.Min(t, halfH/math.Abs(dy))
+	}
+	return node.X + dx*t, node.Y + dy*t
+}
+
+// radialConnectionPoints 计算径向连接线的起止点与贝塞尔控制点（已乘以缩放）。
+// 曲线从父节点大致沿其半径方向离开（父节点为根节点时朝向子节点），沿子节点的半径方向进入子节点；
+// 直线样式的控制点与端点重合。径向布局的根节点位于原点，节点坐标即其半径方向
+func radialConnectionPoints(node, child *types.Node, parentSize, childSize *NodeSize, config *DrawConfig) (start, c1, c2, end [2]float64, ok bool) {
+	dx, dy := child.X-node.X, child.Y-node.Y
+	distance := math.Hypot(dx, dy)
+	if distance < 1e-9 {
+		return start, c1, c2, end, false
+	}
+	dx, dy = dx/distance, dy/distance
+
+	// 文本块不居中时连接线止于节点边框
+	leafText := len(child.Children) == 0 && child.Shape == types.ShapeDefault && !config.Arrows && config.textCentered()
+	sx, sy := radialEdge(node, parentSize, dx, dy, false)
+	ex, ey := radialEdge(child, childSize, -dx, -dy, leafText)
+
+	scale := config.Scale
+	start = [2]float64{sx * scale, sy * scale}
+	end = [2]float64{ex * scale, ey * scale}
+	if config.ConnectorStyle == connectorStraight {
+		return start, start, end, end, true
+	}
+
+	// 起点切线取父节点半径方向与连线方向的平分方向，避免子节点偏离父节点方向较多时曲线绕行
+	outX, outY := dx, dy
+	if r := math.Hypot(node.X, node.Y); r > 1e-9 {
+		outX, outY = node.X/r+dx, node.Y/r+dy
+		if n := math.Hypot(outX, outY); n > 1e-9 {
+			outX, outY = outX/n, outY/n
+		}
+	}
+	inX, inY := dx, dy
+	if r := math.Hypot(child.X, child.Y); r > 1e-9 {
+		inX, inY = child.X/r, child.Y/r
+	}
+	reach := math.Hypot(ex-sx, ey-sy) * scale / 3
+	c1 = [2]float64{start[0] + outX*reach, start[1] + outY*reach}
+	c2 = [2]float64{end[0] - inX*reach, end[1] - inY*reach}
+	return start, c1, c2, end, true
+}
+
+// drawRadialConnection 以当前颜色绘制径向布局中父子节点之间的连接线。
+// 折线样式在径向布局中按曲线绘制，连接线避让不适用于径向布局
+func drawRadialConnection(dc canvas, node, child *types.Node, parentSize, childSize *NodeSize, config *DrawConfig) {
+	start, c1, c2, end, ok := radialConnectionPoints(node, child, parentSize, childSize, config)
+	if !ok {
+		return
+	}
+
+	// 绘制箭头时连接线在箭头底边结束，箭头方向为末端切线
+	lineEnd := end
+	var arrowDX, arrowDY float64
+	arrow := config.Arrows
+	if arrow {
+		arrowDX, arrowDY = end[0]-c2[0], end[1]-c2[1]
+		if math.Hypot(arrowDX, arrowDY) < 1e-9 {
+			arrowDX, arrowDY = end[0]-start[0], end[1]-start[1]
+		}
+		length := math.Hypot(arrowDX, arrowDY)
+		arrow = length >= 1e-9
+		if arrow {
+			arrowDX, arrowDY = arrowDX/length, arrowDY/length
+			lineEnd = [2]float64{end[0] - arrowDX*arrowLength*config.Scale, end[1] - arrowDY*arrowLength*config.Scale}
+		}
+	}
+
+	if config.isSketch() {
+		sketchConfig := config.Theme.SketchConfig
+		rng := config.rng
+		roughness := sketchConfig.Roughness * config.Scale
+		jitter := func() float64 { return (rng.Float64() - 0.5) * roughness }
+		for i := 0; i < sketchConfig.Iterations; i++ {
+			dc.Push()
+			dc.Translate((rng.Float64()-0.5)*sketchConfig.LineVariation*config.Scale, (rng.Float64()-0.5)*sketchConfig.LineVariation*config.Scale)
+			dc.MoveTo(start[0], start[1])
+			dc.CubicTo(c1[0]+jitter(), c1[1]+jitter(), c2[0]+jitter(), c2[1]+jitter(), lineEnd[0], lineEnd[1])
+			dc.Stroke()
+			dc.Pop()
+		}
+	} else {
+		dc.MoveTo(start[0], start[1])
+		dc.CubicTo(c1[0], c1[1], c2[0], c2[1], lineEnd[0], lineEnd[1])
+		dc.Stroke()
+	}
+	if arrow {
+		drawArrowhead(dc, end[0], end[1], arrowDX, arrowDY, config.Scale)
+	}
+}
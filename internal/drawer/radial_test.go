@@ -0,0 +1,107 @@
+package drawer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// angleFrom 返回以 -π/2（正上方）为起点顺时针度量的节点方向，范围 [0, 2π)
+func angleFrom(n *types.Node) float64 {
+	a := math.Atan2(n.Y, n.X) + math.Pi/2
+	for a < 0 {
+		a += 2 * math.Pi
+	}
+	return math.Mod(a, 2*math.Pi)
+}
+
+func TestRadialLayout(t *testing.T) {
+	a := &types.Node{Text: "A", Children: []*types.Node{{Text: "A1"}, {Text: "A2"}, {Text: "A3"}}}
+	b := &types.Node{Text: "B"}
+	c := &types.Node{Text: "C"}
+	root := &types.Node{Text: "Root", Children: []*types.Node{a, b, c}}
+
+	l := NewRenderer(WithLayout("radial")).layout(root)
+	if root.X != 0 || root.Y != 0 {
+		t.Fatalf("expected the root at the origin, got (%g, %g)", root.X, root.Y)
+	}
+
+	// A 有 3 个叶子，占整圆的 3/5，方向位于其扇区中央
+	const eps = 1e-9
+	if got, want := angleFrom(a), 0.6*math.Pi; math.Abs(got-want) > eps {
+		t.Errorf("expected A at angle %g, got %g", want, got)
+	}
+	if got, want := angleFrom(b), 1.4*math.Pi; math.Abs(got-want) > eps {
+		t.Errorf("expected B at angle %g, got %g", want, got)
+	}
+	for _, child := range a.Children {
+		if got := angleFrom(child); got < 0 || got > 1.2*math.Pi {
+			t.Errorf("expected %s within A's wedge, got angle %g", child.Text, got)
+		}
+		if math.Hypot(child.X, child.Y) <= math.Hypot(a.X, a.Y) {
+			t.Errorf("expected %s farther from the root than A", child.Text)
+		}
+	}
+
+	// 节点分布在四个象限，边界包含全部节点
+	quadrants := map[[2]bool]bool{}
+	boxes := collectNodeBoxes(root, l.nodeSizes, nil)
+	for _, box := range boxes {
+		if box.node != root {
+			quadrants[[2]bool{box.node.X > 0, box.node.Y > 0}] = true
+		}
+		if box.minX < l.bounds.MinX || box.maxX > l.bounds.MaxX || box.minY < l.bounds.MinY || box.maxY > l.bounds.MaxY {
+			t.Errorf("node %s lies outside the bounds", box.node.Text)
+		}
+	}
+	if len(quadrants) != 4 {
+		t.Errorf("expected nodes in all four quadrants, got %v", quadrants)
+	}
+	for i, p := range boxes {
+		for _, q := range boxes[i+1:] {
+			if p.minX < q.maxX && q.minX < p.maxX && p.minY < q.maxY && q.minY < p.maxY {
+				t.Errorf("nodes %s and %s overlap", p.node.Text, q.node.Text)
+			}
+		}
+	}
+}
+
+func TestRadialConnections(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Root", Children: []*types.Node{
+			{Text: "A", Children: []*types.Node{{Text: "A1"}, {Text: "A2"}}},
+			{Text: "B"}, {Text: "C"}, {Text: "D"},
+		}}
+	}
+	for _, opts := range [][]Option{
+		{WithLayout("radial")},
+		{WithLayout("radial"), WithArrows(true), WithConnectorStyle("straight")},
+		{WithLayout("radial"), WithTheme("sketch"), WithConnectorRouting()},
+		{WithLayout("radial"), WithFormat("svg")},
+	} {
+		var buf bytes.Buffer
+		if err := Draw(newTree(), &buf, opts...); err != nil {
+			t.Fatalf("Draw failed: %v", err)
+		}
+		if buf.Len() == 0 {
+			t.Fatal("expected output")
+		}
+	}
+
+	const eps = 1e-9
+
+	// 连接线起止于父子节点的边框，直线样式的控制点与端点重合
+	root := newTree()
+	r := NewRenderer(WithLayout("radial"), WithConnectorStyle("straight"), WithScale(1))
+	l := r.layout(root)
+	child := root.Children[0]
+	start, c1, c2, end, ok := radialConnectionPoints(root, child, l.nodeSizes[root], l.nodeSizes[child], l.config)
+	if !ok || c1 != start || c2 != end {
+		t.Fatalf("expected a straight connector, got %v %v %v %v", start, c1, c2, end)
+	}
+	if size := l.nodeSizes[root]; math.Abs(start[0]-root.X) > size.Width/2+eps || math.Abs(start[1]-root.Y) > size.Height/2+eps {
+		t.Errorf("expected the connector to start on the root's border, got %v", start)
+	}
+}
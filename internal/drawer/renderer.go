@@ -0,0 +1,280 @@
+package drawer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"math/rand"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+	"golang.org/x/image/font/opentype"
+)
+
+// Renderer draws mind maps with a fixed set of options. The theme is resolved
+// and the font parsed once by NewRenderer, so a long-lived Renderer avoids
+// that work on every call. A Renderer is safe for concurrent use.
+type Renderer struct {
+	opts   drawOptions
+	config *DrawConfig    // 已解析的配置模板，只读；每次渲染使用其副本
+	font   *truetype.Font // 已解析的字体，骨架预览或加载失败时为空
+
+	fontFile *opentype.Font // WithFontFile 指定的外部字体，优先于内嵌字体
+	fontErr  error          // 外部字体加载失败的原因，位图渲染时返回
+
+	engine LayoutEngine // 创建时按布局名解析的布局引擎
+}
+
+// NewRenderer resolves the theme and loads the font for the given options.
+// An unknown theme falls back to the built-in defaults, as Draw does.
+func NewRenderer(options ...Option) *Renderer {
+	opts := newDrawOptions(options)
+	var config *DrawConfig
+	var err error
+	if opts.themeConfig != nil {
+		config = newDrawConfigFromTheme(opts.themeConfig)
+	} else {
+		config, err = NewDrawConfig(opts.theme)
+	}
+	if err != nil {
+		// 如果主题加载失败，使用默认配置
+		config = &DrawConfig{
+			MinNodeWidth:        DefaultMinNodeWidth,
+			MaxNodeWidth:        DefaultMaxNodeWidth,
+			MinNodeHeight:       DefaultMinNodeHeight,
+			LevelSpacing:        DefaultLevelSpacing,
+			NodeSpacing:         DefaultNodeSpacing,
+			CornerRadius:        DefaultCornerRadius,
+			FontSize:            DefaultFontSize,
+			Scale:               DefaultScale,
+			LineHeight:          DefaultLineHeight,
+			TextPadding:         DefaultTextPadding,
+			BackgroundColor:     [3]float64{1.0, 1.0, 1.0},
+			ConnectionLineColor: [3]float64{0.051, 0.043, 0.133},
+			MarkerColor:         defaultMarkerColor,
+		}
+	}
+
+	// 用户指定的缩放需限制在主题推荐的范围内；未指定时使用质量预设的缩放
+	if opts.scale > 0 {
+		config.applyScale(opts.scale)
+	} else if opts.preset != nil && opts.preset.scale > 0 {
+		config.applyScale(opts.preset.scale)
+	}
+	if opts.preset != nil {
+		config.Supersample = opts.preset.supersample
+	}
+
+	// 骨架预览固定以 1 倍缩放绘制
+	if opts.skeleton {
+		config.skeleton = true
+		config.Scale = 1
+	}
+	config.Layout = opts.layout
+	config.Alignment = opts.align
+	config.MinAspectRatio = opts.minAspectRatio
+	config.applySpacing(opts)
+	if opts.rootLevelSpacing > 0 {
+		config.RootLevelSpacing = opts.rootLevelSpacing
+	}
+	config.RouteConnectors = opts.routeConnectors
+	if opts.connectorStyle != "" {
+		config.ConnectorStyle = opts.connectorStyle
+	}
+	if opts.textAlign != "" {
+		config.TextAlign = opts.textAlign
+	}
+	if opts.arrows != nil {
+		config.Arrows = *opts.arrows
+	}
+	config.MetaKeys = opts.metaKeys
+	config.shaper = opts.shaper
+	config.RTL = opts.rtl
+	config.Highlight = opts.highlight
+	config.DimUnmatched = opts.dimUnmatched
+
+	r := &Renderer{opts: opts, config: config}
+	// 布局名已由 WithLayout 校验；引擎在创建时解析，之后的注册不影响已有的 Renderer
+	if engine, ok := lookupLayout(opts.layout); ok {
+		r.engine = engine
+	} else {
+		r.engine, _ = lookupLayout("right")
+	}
+	switch {
+	case config.skeleton:
+	case opts.fontFile != "":
+		r.fontFile, r.fontErr = loadFontFile(opts.fontFile, opts.fontIndex)
+	default:
+		if r.font, err = parseEmbeddedFont(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+	config.hasGlyph = r.hasGlyph
+	return r
+}
+
+// Render draws the mind map to w as a PNG image, or as a JPEG image when the
+// Renderer was created with WithFormat("jpeg").
+func (r *Renderer) Render(rootNode *types.Node, w io.Writer) error {
+	return r.RenderContext(context.Background(), rootNode, w)
+}
+
+// Image draws the mind map and returns the bitmap without encoding it, for
+// callers that composite, crop or re-encode the result. The image is an
+// *image.RGBA.
+func (r *Renderer) Image(rootNode *types.Node) (image.Image, error) {
+	return r.ImageContext(context.Background(), rootNode)
+}
+
+// encode 按选项中的格式编码位图。JPEG 输出不允许透明背景，画布始终不透明，不会丢失透明度。
+func (r *Renderer) encode(w io.Writer, img image.Image) error {
+	if r.opts.format == "jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: r.opts.quality})
+	}
+	encoder := png.Encoder{CompressionLevel: png.DefaultCompression}
+	if r.opts.preset != nil {
+		encoder.CompressionLevel = r.opts.preset.compression
+	}
+	return encoder.Encode(w, img)
+}
+
+// newConfig 返回本次渲染专用的配置副本，渲染过程中对缩放与随机源的修改不影响模板
+func (r *Renderer) newConfig() *DrawConfig {
+	config := *r.config
+	// 如果是手绘风格，按主题种子创建独立的随机源，保证相同输入输出一致
+	if config.isSketch() {
+		config.rng = rand.New(rand.NewSource(config.Theme.SketchConfig.Seed))
+	}
+	return &config
+}
+
+// setFontFace 为绘制上下文设置指定字号的字体。字体对象可共享，字形缓存随 face 独立创建。
+func (r *Renderer) setFontFace(dc *gg.Context, size float64) {
+	if r.fontFile != nil {
+		if face, err := newFontFileFace(r.fontFile, size); err == nil {
+			dc.SetFontFace(face)
+		}
+		return
+	}
+	if r.font == nil {
+		return
+	}
+	dc.SetFontFace(truetype.NewFace(r.font, &truetype.Options{Size: size}))
+}
+
+// layout 测量节点并计算布局与边界
+func (r *Renderer) layout(rootNode *types.Node) *mindmapLayout {
+	l, _ := r.layoutContext(context.Background(), rootNode)
+	return l
+}
+
+// layoutContext 与 layout 相同，在测量与布局之后检查 ctx，已取消时返回 ctx.Err()
+func (r *Renderer) layoutContext(ctx context.Context, rootNode *types.Node) (*mindmapLayout, error) {
+	config := r.newConfig()
+	config.ctx = ctx
+
+	// 按排序键调整兄弟节点顺序；顺序改变的部分使用副本，不修改传入的树
+	rootNode = orderChildren(rootNode)
+
+	// 限制深度时在裁剪后的副本上布局，记录被隐藏的后代数量
+	var hidden map[*types.Node]int
+	var collapsed map[*types.Node]*types.Node
+	if r.opts.maxDepth >= 0 {
+		rootNode, hidden, collapsed = pruneToDepth(rootNode, r.opts.maxDepth)
+	}
+
+	// 计算节点尺寸；骨架预览跳过文本测量
+	// measure 测量节点以外的文本（面包屑、标题与说明）在正文字号下的宽度
+	nodeSizes := make(map[*types.Node]*NodeSize)
+	var measure func(text string) float64
+	if config.skeleton {
+		estimateNodeSizes(rootNode, nodeSizes, config)
+		measure = func(text string) float64 { return estimateTextWidth(text, config.FontSize) }
+	} else {
+		// 创建临时上下文用于文本测量
+		tempDC := gg.NewContext(1, 1)
+		r.setFontFace(tempDC, config.FontSize)
+		measureCache := newTextMeasureCache(config.textShaper())
+		calculateNodeSizes(tempDC, rootNode, 0, nodeSizes, config, measureCache)
+		warnMissingRTLGlyphs(rootNode, config.hasGlyph)
+		measure = func(text string) float64 { return measureStringCached(tempDC, text, measureCache) }
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 由选定的布局引擎计算节点位置
+	r.engine.Position(rootNode, nodeSizes, config)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if config.RTL {
+		mirrorLayout(rootNode)
+	}
+
+	// 计算边界
+	bounds := &Bounds{
+		MinX: math.MaxFloat64,
+		MinY: math.MaxFloat64,
+		MaxX: -math.MaxFloat64,
+		MaxY: -math.MaxFloat64,
+	}
+	calculateBoundsWithSizes(rootNode, nodeSizes, bounds)
+
+	// 子树渲染时在根节点外侧放置父节点标签
+	var crumb *breadcrumb
+	if r.opts.breadcrumb != "" {
+		crumb = placeBreadcrumb(r.opts.breadcrumb, measure(r.opts.breadcrumb), rootNode, nodeSizes[rootNode], config)
+		crumb.extend(bounds)
+	}
+
+	// 扩展边界，确保有足够的边距
+	extraMargin := 50.0
+	bounds.MinX -= extraMargin
+	bounds.MinY -= extraMargin
+	bounds.MaxX += extraMargin
+	bounds.MaxY += extraMargin
+
+	// 图标图例位于导图下方右侧，标题与说明占用边界上下方新增的区域
+	var legend *iconLegend
+	if r.opts.iconLegend {
+		legend = placeIconLegend(rootNode, measure, bounds, config)
+	}
+	title := placeTitleBand(r.opts.title, titleScale, true, measure, bounds, config)
+	caption := placeTitleBand(r.opts.caption, captionScale, false, measure, bounds, config)
+
+	return &mindmapLayout{root: rootNode, config: config, nodeSizes: nodeSizes, bounds: bounds, hidden: hidden, collapsed: collapsed, breadcrumb: crumb, title: title, caption: caption, legend: legend}, nil
+}
+
+// rasterSize 返回位图画布的像素尺寸，超出最大尺寸时先缩小缩放以适应画布
+func (r *Renderer) rasterSize(l *mindmapLayout) (int, int, error) {
+	canvasWidth, canvasHeight := l.size()
+	if err := fitScale(l.config, canvasWidth, canvasHeight, r.opts.maxW, r.opts.maxH); err != nil {
+		return 0, 0, err
+	}
+	return canvasPixels(canvasWidth, canvasHeight, l.config.Scale)
+}
+
+// newRasterContext 创建已设置字体并填充背景的位图绘制上下文
+func (r *Renderer) newRasterContext(config *DrawConfig, pixelWidth, pixelHeight int) *gg.Context {
+	dc := gg.NewContext(pixelWidth, pixelHeight)
+	dc.SetLineWidth(1.0 * config.Scale)
+	dc.SetLineJoin(gg.LineJoinRound)
+	dc.SetLineCap(gg.LineCapButt)
+
+	if !config.skeleton {
+		r.setFontFace(dc, config.FontSize*config.Scale)
+	}
+
+	// 设置背景；透明背景时保留新画布的全透明像素
+	if !r.opts.transparent {
+		dc.SetRGB(config.BackgroundColor[0], config.BackgroundColor[1], config.BackgroundColor[2])
+		dc.Clear()
+	}
+	return dc
+}
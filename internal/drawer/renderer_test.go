@@ -0,0 +1,289 @@
+package drawer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestRendererConcurrent(t *testing.T) {
+	r := NewRenderer(WithTheme("default"), WithScale(1))
+	// 布局会写入节点坐标，每次渲染使用独立的树
+	newTree := func(i int) *types.Node {
+		return &types.Node{Text: fmt.Sprintf("Root %d", i), Children: []*types.Node{
+			{Text: "分支", Children: []*types.Node{{Text: fmt.Sprintf("叶子 %d", i)}}},
+		}}
+	}
+	want := make([][]byte, 4)
+	for i := range want {
+		var buf bytes.Buffer
+		if err := r.Render(newTree(i), &buf); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		want[i] = buf.Bytes()
+	}
+
+	// 并发渲染的结果必须与顺序渲染完全一致
+	var wg sync.WaitGroup
+	for round := 0; round < 3; round++ {
+		for i := range want {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var buf bytes.Buffer
+				if err := r.Render(newTree(i), &buf); err != nil {
+					t.Errorf("Render failed: %v", err)
+					return
+				}
+				if !bytes.Equal(buf.Bytes(), want[i]) {
+					t.Errorf("tree %d: concurrent output differs from sequential output", i)
+				}
+			}(i)
+		}
+	}
+	wg.Wait()
+}
+
+// TestRenderDuringThemeReload 在并发渲染的同时重新加载并注册主题，
+// 每次渲染都必须完整地使用某一版主题；以 go test -race 运行时同时检查数据竞争
+func TestRenderDuringThemeReload(t *testing.T) {
+	manager := theme.GetManager()
+	base, err := manager.GetThemeStrict("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	variants := []*theme.ThemeConfig{base.Clone(), base.Clone()}
+	variants[1].Colors.Background = "#eeeeee"
+	variants[1].NodeStyles.Leaf.FillColor = [3]float64{1, 0.9, 0.9}
+
+	// 布局会写入节点坐标，每次渲染使用独立的树
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Root", Children: []*types.Node{{Text: "Leaf"}}}
+	}
+	want := make([][]byte, len(variants))
+	for i, v := range variants {
+		if err := manager.RegisterTheme("reload-test", v); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := Draw(newTree(), &buf, WithTheme("reload-test"), WithScale(1)); err != nil {
+			t.Fatal(err)
+		}
+		want[i] = buf.Bytes()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if err := manager.RegisterTheme("reload-test", variants[i%len(variants)]); err != nil {
+				t.Errorf("RegisterTheme failed: %v", err)
+				return
+			}
+			if err := manager.Reload(); err != nil {
+				t.Errorf("Reload failed: %v", err)
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				var buf bytes.Buffer
+				if err := Draw(newTree(), &buf, WithTheme("reload-test"), WithScale(1)); err != nil {
+					t.Errorf("Draw failed: %v", err)
+					return
+				}
+				if !bytes.Equal(buf.Bytes(), want[0]) && !bytes.Equal(buf.Bytes(), want[1]) {
+					t.Error("render mixed two versions of the theme")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	<-done
+}
+
+func TestDrawMatchesRenderer(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Child"}}}
+	var viaDraw, viaRenderer bytes.Buffer
+	if err := DrawWithTheme(root, &viaDraw, "dark"); err != nil {
+		t.Fatalf("DrawWithTheme failed: %v", err)
+	}
+	if err := NewRenderer(WithTheme("dark")).Render(root, &viaRenderer); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !bytes.Equal(viaDraw.Bytes(), viaRenderer.Bytes()) {
+		t.Fatal("expected Draw and Renderer to produce identical output")
+	}
+}
+
+func TestDrawJPEG(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Child"}, {Text: "Another child"}}}
+	for _, themeName := range []string{"dark", "sketch"} {
+		t.Run(themeName, func(t *testing.T) {
+			var png, jpg bytes.Buffer
+			if err := Draw(root, &png, WithTheme(themeName), WithScale(1)); err != nil {
+				t.Fatalf("Draw PNG failed: %v", err)
+			}
+			if err := Draw(root, &jpg, WithTheme(themeName), WithScale(1), WithFormat("jpg"), WithJPEGQuality(80)); err != nil {
+				t.Fatalf("Draw JPEG failed: %v", err)
+			}
+			if jpg.Len() >= png.Len() {
+				t.Errorf("expected JPEG (%d bytes) to be smaller than PNG (%d bytes)", jpg.Len(), png.Len())
+			}
+
+			img, err := jpeg.Decode(&jpg)
+			if err != nil {
+				t.Fatalf("output is not a JPEG: %v", err)
+			}
+			// JPEG 没有透明通道，角落像素应为主题背景色而非黑色
+			config, _ := NewDrawConfig(themeName)
+			r, g, b, _ := img.At(0, 0).RGBA()
+			for i, got := range []uint32{r, g, b} {
+				if want := config.BackgroundColor[i] * 0xffff; math.Abs(float64(got)-want) > 0x0800 {
+					t.Fatalf("corner pixel %v does not match background %v", img.At(0, 0), config.BackgroundColor)
+				}
+			}
+		})
+	}
+}
+
+func TestDrawTransparentBackground(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Child"}}}
+	var buf bytes.Buffer
+	if err := Draw(root, &buf, WithTheme("dark"), WithScale(1), WithTransparentBackground()); err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("output is not a PNG: %v", err)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Fatalf("expected transparent corner, got alpha %d", a)
+	}
+	// 画布中线穿过节点，节点填充保持不透明
+	center := image.Pt(img.Bounds().Dx()/2, img.Bounds().Dy()/2)
+	opaque := false
+	for x := 0; x < img.Bounds().Dx(); x++ {
+		if _, _, _, a := img.At(x, center.Y).RGBA(); a == 0xffff {
+			opaque = true
+			break
+		}
+	}
+	if !opaque {
+		t.Fatal("expected nodes to stay opaque")
+	}
+
+	if err := Draw(root, io.Discard, WithTransparentBackground(), WithFormat("jpeg")); !errors.Is(err, ErrTransparentBackground) {
+		t.Fatalf("expected ErrTransparentBackground for JPEG, got %v", err)
+	}
+	if err := DrawReveal(root, io.Discard, WithTransparentBackground()); !errors.Is(err, ErrTransparentBackground) {
+		t.Fatalf("expected ErrTransparentBackground for GIF, got %v", err)
+	}
+}
+
+// 对比每次调用 Draw 与复用 Renderer 的单次渲染开销
+func BenchmarkDrawPerCall(b *testing.B) {
+	root := buildLargeTree(3, 1)
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		if err := Draw(root, &buf, WithScale(1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRendererReuse(b *testing.B) {
+	root := buildLargeTree(3, 1)
+	r := NewRenderer(WithScale(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		if err := r.Render(root, &buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkNewRenderer 衡量每次请求重新解析主题与字体的固定开销
+func BenchmarkNewRenderer(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewRenderer(WithTheme("default"))
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	if err := Warmup(); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+	warmed, err := parseEmbeddedFont()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector, err := loadVectorFont()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 预热后的渲染直接复用已解析的字体，而不是重新解析
+	var buf bytes.Buffer
+	if err := Draw(&types.Node{Text: "Root", Children: []*types.Node{{Text: "Child"}}}, &buf); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	if r := NewRenderer(); r.font != warmed {
+		t.Fatal("expected a renderer created after Warmup to use the warmed font")
+	}
+	if f, _ := parseEmbeddedFont(); f != warmed {
+		t.Fatal("expected the embedded font to stay cached after Draw")
+	}
+	if f, _ := loadVectorFont(); f != vector {
+		t.Fatal("expected the vector font to stay cached after Warmup")
+	}
+}
+
+func TestRenderImage(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Child"}}}
+	img, err := Render(root, WithScale(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := img.(*image.RGBA); !ok {
+		t.Fatalf("expected *image.RGBA, got %T", img)
+	}
+
+	// Draw 的结果即 Render 的图片经 PNG 编码
+	var buf bytes.Buffer
+	if err := Draw(root, &buf, WithScale(1)); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("expected Draw to encode a %v image, got %v", img.Bounds(), decoded.Bounds())
+	}
+	if diff, ok := compareImages(decoded, img); !ok {
+		t.Errorf("expected Draw to encode the rendered image, %s", diff)
+	}
+
+	if _, err := Render(root, WithLayout("diagonal")); err == nil {
+		t.Error("expected an error for an invalid option")
+	}
+}
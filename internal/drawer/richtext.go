@@ -0,0 +1,237 @@
+package drawer
+
+import (
+	"strings"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// 行内样式在换行前以私有区字符的形式嵌入文本，换行后再据此分段绘制。
+// 这些字符不参与测量，也不会被绘制。
+const (
+	markHighlightStart = '\uE000'
+	markHighlightEnd   = '\uE001'
+	markSupStart       = '\uE002'
+	markSupEnd         = '\uE003'
+	markSubStart       = '\uE004'
+	markSubEnd         = '\uE005'
+)
+
+const (
+	scriptScale = 0.7  // 上下标相对正文的字号比例
+	scriptShift = 0.22 // 上下标中心相对行中心的偏移，以行高为单位
+)
+
+var defaultMarkerColor = [3]float64{1.0, 0.898, 0.561}
+
+func isMark(r rune) bool {
+	return r >= markHighlightStart && r <= markSubEnd
+}
+
+func hasMarks(s string) bool {
+	return strings.IndexFunc(s, isMark) >= 0
+}
+
+func stripMarks(s string) string {
+	if !hasMarks(s) {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if isMark(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// markedText 将节点的样式区间以标记字符嵌入文本
+func markedText(node *types.Node) string {
+	if len(node.Spans) == 0 {
+		return node.Text
+	}
+
+	starts := make(map[int][]rune)
+	ends := make(map[int][]rune)
+	for _, span := range node.Spans {
+		if span.Start >= span.End {
+			continue
+		}
+		switch span.Kind {
+		case types.SpanHighlight:
+			starts[span.Start] = append(starts[span.Start], markHighlightStart)
+			ends[span.End] = append(ends[span.End], markHighlightEnd)
+		case types.SpanSuperscript:
+			starts[span.Start] = append(starts[span.Start], markSupStart)
+			ends[span.End] = append(ends[span.End], markSupEnd)
+		case types.SpanSubscript:
+			starts[span.Start] = append(starts[span.Start], markSubStart)
+			ends[span.End] = append(ends[span.End], markSubEnd)
+		}
+	}
+
+	var b strings.Builder
+	i := 0
+	for _, r := range node.Text {
+		b.WriteString(string(ends[i]))
+		b.WriteString(string(starts[i]))
+		b.WriteRune(r)
+		i++
+	}
+	b.WriteString(string(ends[i]))
+	return b.String()
+}
+
+// markState 记录跨行延续的样式状态
+type markState struct {
+	highlight bool
+	sup       bool
+	sub       bool
+}
+
+func (s *markState) active() bool {
+	return s.highlight || s.sup || s.sub
+}
+
+type textSegment struct {
+	text      string
+	highlight bool
+	sup       bool
+	sub       bool
+}
+
+// script 返回片段的字号比例与以行高为单位的纵向偏移（向下为正）
+func (seg textSegment) script() (scale, shift float64) {
+	switch {
+	case seg.sup:
+		return scriptScale, -scriptShift
+	case seg.sub:
+		return scriptScale, scriptShift
+	}
+	return 1, 0
+}
+
+// splitMarkedLine 按标记字符将一行拆分为样式一致的片段，并更新跨行状态
+func splitMarkedLine(line string, state *markState) []textSegment {
+	var segments []textSegment
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			segments = append(segments, textSegment{
+				text:      current.String(),
+				highlight: state.highlight,
+				sup:       state.sup,
+				sub:       state.sub,
+			})
+			current.Reset()
+		}
+	}
+
+	for _, r := range line {
+		switch r {
+		case markHighlightStart, markHighlightEnd:
+			flush()
+			state.highlight = r == markHighlightStart
+		case markSupStart, markSupEnd:
+			flush()
+			state.sup = r == markSupStart
+		case markSubStart, markSubEnd:
+			flush()
+			state.sub = r == markSubStart
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return segments
+}
+
+// measureMarked 测量带标记文本的宽度，上下标按缩小后的字号计算
+func measureMarked(text string, measure func(string) float64) float64 {
+	var state markState
+	total := 0.0
+	for _, seg := range splitMarkedLine(text, &state) {
+		k, _ := seg.script()
+		total += measure(seg.text) * k
+	}
+	return total
+}
+
+// drawMarkedLine 在 [left, right] 内按文本对齐方式、纵向居中于 cy 逐段绘制带行内样式的一行文本
+func drawMarkedLine(dc canvas, line string, left, right, cy, lineHeight float64, state *markState, style *types.NodeStyle, config *DrawConfig) {
+	segments := splitMarkedLine(line, state)
+
+	widths := make([]float64, len(segments))
+	total := 0.0
+	for i, seg := range segments {
+		k, _ := seg.script()
+		widths[i] = measureText(dc, config, seg.text) * k
+		total += widths[i]
+	}
+
+	// 从右到左时首段位于最右侧，各段依次向左排列
+	x := alignedX(config.TextAlign, left, right, total)
+	if config.RTL {
+		x += total
+	}
+	for i, seg := range segments {
+		if config.RTL {
+			x -= widths[i]
+		}
+		if seg.highlight {
+			pad := 2.0 * config.Scale
+			dc.SetRGB(config.MarkerColor[0], config.MarkerColor[1], config.MarkerColor[2])
+			drawRoundedRect(dc, x-pad, cy-lineHeight/2, widths[i]+2*pad, lineHeight, 3.0*config.Scale)
+			dc.Fill()
+		}
+		textColor := style.TextColor
+		if seg.highlight {
+			textColor = contrastTextColor(config.MarkerColor)
+		}
+		dc.SetRGB(textColor[0], textColor[1], textColor[2])
+		k, shift := seg.script()
+		if k == 1 {
+			drawText(dc, config, seg.text, x, cy, 0, 0.5)
+		} else {
+			drawScaledText(dc, config, seg.text, x, cy+shift*lineHeight, k)
+		}
+		if !config.RTL {
+			x += widths[i]
+		}
+	}
+}
+
+// fontScaler 由矢量绘制面实现：临时调整字号，返回恢复原字号的函数
+type fontScaler interface {
+	scaleFont(k float64) (restore func())
+}
+
+// drawScaledText 以 k 倍字号绘制左端位于 x、纵向居中于 y 的文本。
+// 位图通过变换矩阵缩放字形，矢量绘制面直接改用更小的字号。
+func drawScaledText(dc canvas, config *DrawConfig, text string, x, y, k float64) {
+	if fs, ok := dc.(fontScaler); ok {
+		restore := fs.scaleFont(k)
+		drawText(dc, config, text, x, y, 0, 0.5)
+		restore()
+		return
+	}
+	gc, ok := dc.(*gg.Context)
+	if !ok {
+		drawText(dc, config, text, x, y, 0, 0.5)
+		return
+	}
+	gc.Push()
+	gc.Translate(x, y)
+	gc.Scale(k, k)
+	drawText(gc, config, text, 0, 0, 0, 0.5)
+	gc.Pop()
+}
+
+// contrastTextColor 根据背景亮度选择黑色或白色文本
+func contrastTextColor(bg [3]float64) [3]float64 {
+	luminance := 0.299*bg[0] + 0.587*bg[1] + 0.114*bg[2]
+	if luminance > 0.5 {
+		return [3]float64{0, 0, 0}
+	}
+	return [3]float64{1, 1, 1}
+}
@@ -0,0 +1,118 @@
+package drawer
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// countColor 统计图像中与给定颜色完全一致的像素数量
+func countColor(img image.Image, c [3]float64) int {
+	// gg 以截断方式将浮点颜色转换为 8 位
+	want := [3]uint32{uint32(c[0] * 255), uint32(c[1] * 255), uint32(c[2] * 255)}
+	count := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			if r>>8 == want[0] && g>>8 == want[1] && bl>>8 == want[2] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func renderPNG(t *testing.T, root *types.Node, opts ...Option) image.Image {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Draw(root, &buf, opts...); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	return img
+}
+
+func TestDrawHighlightSpan(t *testing.T) {
+	plain := &types.Node{Text: "Root", Children: []*types.Node{{Text: "Deadline Friday"}}}
+	marked := &types.Node{Text: "Root", Children: []*types.Node{{
+		Text:  "Deadline Friday",
+		Spans: []types.TextSpan{{Start: 9, End: 15, Kind: types.SpanHighlight}},
+	}}}
+
+	if n := countColor(renderPNG(t, plain), defaultMarkerColor); n != 0 {
+		t.Fatalf("expected no marker pixels without spans, got %d", n)
+	}
+	if n := countColor(renderPNG(t, marked), defaultMarkerColor); n == 0 {
+		t.Fatalf("expected highlighted span to paint marker-colored pixels")
+	}
+}
+
+func TestMarkedTextWrapsAcrossLines(t *testing.T) {
+	node := &types.Node{
+		Text:  "alpha beta gamma",
+		Spans: []types.TextSpan{{Start: 6, End: 16, Kind: types.SpanHighlight}},
+	}
+	text := markedText(node)
+	if stripMarks(text) != node.Text {
+		t.Fatalf("expected marks to strip back to the original text, got %q", stripMarks(text))
+	}
+
+	var state markState
+	first := splitMarkedLine("alpha \uE000beta", &state)
+	second := splitMarkedLine("gamma\uE001", &state)
+	if len(first) != 2 || first[0].highlight || !first[1].highlight {
+		t.Fatalf("unexpected first line segments: %+v", first)
+	}
+	if len(second) != 1 || !second[0].highlight {
+		t.Fatalf("expected highlight to continue onto the next line: %+v", second)
+	}
+	if state.active() {
+		t.Fatalf("expected highlight to end after the closing mark")
+	}
+}
+
+func TestDrawScriptSpans(t *testing.T) {
+	node := &types.Node{
+		Text: "H2O x2",
+		Spans: []types.TextSpan{
+			{Start: 1, End: 2, Kind: types.SpanSubscript},
+			{Start: 5, End: 6, Kind: types.SpanSuperscript},
+		},
+	}
+	text := markedText(node)
+	if stripMarks(text) != node.Text {
+		t.Fatalf("expected script marks to strip back to the original text, got %q", stripMarks(text))
+	}
+
+	var state markState
+	segments := splitMarkedLine(text, &state)
+	if len(segments) != 4 || !segments[1].sub || !segments[3].sup || segments[2].sub {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+	if state.active() {
+		t.Fatalf("expected all marks to be closed at the end of the line")
+	}
+
+	// 上下标按缩小后的字号测量，整行比未标记时更窄
+	measure := func(s string) float64 { return float64(len([]rune(s))) }
+	if got := measureMarked(text, measure); got >= measure(node.Text) {
+		t.Fatalf("expected scripts to shrink the measured width, got %v", got)
+	}
+
+	root := &types.Node{Text: "Chemistry", Children: []*types.Node{node}}
+	renderPNG(t, root)
+	var buf bytes.Buffer
+	if err := DrawSVG(root, &buf); err != nil {
+		t.Fatalf("svg draw failed: %v", err)
+	}
+	if hasMarks(buf.String()) {
+		t.Fatalf("expected marks to be consumed in SVG output")
+	}
+}
@@ -0,0 +1,88 @@
+package drawer
+
+import (
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// defaultBend 默认的转折位置：控制点位于起止点主轴方向的中点，形成对称的 S 形曲线
+const defaultBend = 0.5
+
+// connectorBends 避让时依次尝试的转折位置，离默认值越近越优先。
+// 转折越靠前，曲线越早完成侧向移动，随后平直地进入子节点；越靠后则相反。
+var connectorBends = []float64{defaultBend, 0.35, 0.65, 0.2, 0.8, 0.05, 0.95}
+
+// nodeBox 节点的矩形框（未缩放）
+type nodeBox struct {
+	node                   *types.Node
+	minX, minY, maxX, maxY float64
+}
+
+// collectNodeBoxes 收集树中所有节点的矩形框
+func collectNodeBoxes(node *types.Node, nodeSizes map[*types.Node]*NodeSize, boxes []nodeBox) []nodeBox {
+	if node == nil {
+		return boxes
+	}
+	if size := nodeSizes[node]; size != nil {
+		boxes = append(boxes, nodeBox{
+			node: node,
+			minX: node.X - size.Width/2,
+			minY: node.Y - size.Height/2,
+			maxX: node.X + size.Width/2,
+			maxY: node.Y + size.Height/2,
+		})
+	}
+	for _, child := range node.Children {
+		boxes = collectNodeBoxes(child, nodeSizes, boxes)
+	}
+	return boxes
+}
+
+// connectorControls 返回转折位置为 bend 时连接线的两个贝塞尔控制点
+func connectorControls(startX, startY, endX, endY, bend float64, vertical bool) (c1x, c1y, c2x, c2y float64) {
+	if vertical {
+		midY := startY + (endY-startY)*bend
+		return startX, midY, endX, midY
+	}
+	midX := startX + (endX-startX)*bend
+	return midX, startY, midX, endY
+}
+
+// routeConnector 选择不穿过其他节点框的转折位置，所有候选都相交时退回默认值。
+// 起止点为已缩放的坐标，节点框按 scale 换算；连接线两端的节点不参与检测。
+func routeConnector(startX, startY, endX, endY float64, vertical bool, style string, parent, child *types.Node, boxes []nodeBox, scale float64) float64 {
+	// 控制点与折线顶点都位于起止点围成的矩形内，连接线同样不会超出该矩形，只需检测与其重叠的节点框
+	minX, maxX := min(startX, endX), max(startX, endX)
+	minY, maxY := min(startY, endY), max(startY, endY)
+	var nearby []nodeBox
+	for _, b := range boxes {
+		if b.node == parent || b.node == child {
+			continue
+		}
+		if b.maxX*scale > minX && b.minX*scale < maxX && b.maxY*scale > minY && b.minY*scale < maxY {
+			nearby = append(nearby, b)
+		}
+	}
+	if len(nearby) == 0 {
+		return defaultBend
+	}
+
+	for _, bend := range connectorBends {
+		if !connectorCrosses(startX, startY, endX, endY, bend, vertical, style, nearby, scale) {
+			return bend
+		}
+	}
+	return defaultBend
+}
+
+// connectorCrosses 判断转折位置为 bend 的连接线是否穿过任一节点框
+func connectorCrosses(startX, startY, endX, endY, bend float64, vertical bool, style string, boxes []nodeBox, scale float64) bool {
+	points := connectorSamples(style, startX, startY, endX, endY, bend, vertical)
+	for _, b := range boxes {
+		for _, p := range points {
+			if p.X > b.minX*scale && p.X < b.maxX*scale && p.Y > b.minY*scale && p.Y < b.maxY*scale {
+				return true
+			}
+		}
+	}
+	return false
+}
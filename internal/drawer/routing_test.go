@@ -0,0 +1,63 @@
+package drawer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestRouteConnectorAvoidsBox(t *testing.T) {
+	parent := &types.Node{Text: "Parent"}
+	child := &types.Node{Text: "Child"}
+	sibling := &types.Node{Text: "Sibling"}
+	// 兄弟节点恰好位于默认 S 形曲线的中段
+	boxes := []nodeBox{
+		{node: parent, minX: -40, minY: -10, maxX: 0, maxY: 10},
+		{node: sibling, minX: 80, minY: 40, maxX: 120, maxY: 60},
+		{node: child, minX: 200, minY: 90, maxX: 240, maxY: 110},
+	}
+
+	if !connectorCrosses(0, 0, 200, 100, defaultBend, false, "", boxes[1:2], 1) {
+		t.Fatal("expected the default connector to cross the sibling box")
+	}
+
+	bend := routeConnector(0, 0, 200, 100, false, "", parent, child, boxes, 1)
+	if bend == defaultBend {
+		t.Fatal("expected the connector bend to be adjusted")
+	}
+	if connectorCrosses(0, 0, 200, 100, bend, false, "", boxes, 1) {
+		t.Fatalf("expected the routed connector (bend %v) to clear every box", bend)
+	}
+
+	// 缩放后的坐标同样生效
+	if bend := routeConnector(0, 0, 400, 200, false, "", parent, child, boxes, 2); connectorCrosses(0, 0, 400, 200, bend, false, "", boxes[1:2], 2) {
+		t.Fatalf("expected the routed connector to clear the box at scale 2, got bend %v", bend)
+	}
+}
+
+func TestRouteConnectorKeepsDefaultWithoutObstacles(t *testing.T) {
+	parent := &types.Node{Text: "Parent"}
+	child := &types.Node{Text: "Child"}
+	boxes := []nodeBox{
+		{node: parent, minX: -40, minY: -10, maxX: 0, maxY: 10},
+		{node: child, minX: 200, minY: 90, maxX: 240, maxY: 110},
+		{node: &types.Node{Text: "Far"}, minX: 300, minY: 300, maxX: 340, maxY: 320},
+	}
+	if bend := routeConnector(0, 0, 200, 100, false, "", parent, child, boxes, 1); bend != defaultBend {
+		t.Fatalf("expected the default bend, got %v", bend)
+	}
+}
+
+func TestDrawWithConnectorRouting(t *testing.T) {
+	root := &types.Node{Text: "Root"}
+	for _, text := range []string{"A", "B", "C", "D", "E"} {
+		root.Children = append(root.Children, &types.Node{Text: text})
+	}
+	for _, layout := range []string{"right", "down"} {
+		var buf bytes.Buffer
+		if err := Draw(root, &buf, WithLayout(layout), WithConnectorRouting()); err != nil {
+			t.Fatalf("layout %s: draw failed: %v", layout, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package drawer
+
+import (
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// WithRTL lays the map out right to left for Hebrew or Arabic outlines: the
+// layout is mirrored horizontally (the default layout puts the root on the
+// right with children growing left), icons move to the right of the text,
+// and each line is reordered with the Unicode bidirectional algorithm for a
+// right-to-left paragraph. SVG output keeps text in logical order and leaves
+// reordering to the viewer; a custom TextShaper receives logical order too.
+//
+// The font must contain the glyphs: the embedded font has no Hebrew or
+// Arabic, so pass one that does with WithFontFile. Missing glyphs are logged
+// and drawn as the font's placeholder. Arabic letters are drawn in their
+// isolated forms unless the TextShaper joins them.
+func WithRTL(enabled bool) Option {
+	return func(opts *drawOptions) {
+		opts.rtl = enabled
+	}
+}
+
+// IsMostlyRTL reports whether the tree's text is predominantly right to left:
+// it has more strong right-to-left letters (Hebrew, Arabic and similar
+// scripts) than strong left-to-right ones. Callers can use it to suggest
+// WithRTL.
+func IsMostlyRTL(root *types.Node) bool {
+	rtl, ltr := 0, 0
+	walkText(root, func(text string) {
+		for _, r := range text {
+			switch {
+			case isRTLRune(r):
+				rtl++
+			case unicode.IsLetter(r):
+				ltr++
+			}
+		}
+	})
+	return rtl > ltr
+}
+
+// walkText 依次对树中每个节点的文本与备注调用 fn
+func walkText(root *types.Node, fn func(text string)) {
+	root.Walk(func(node *types.Node, _ int) bool {
+		fn(node.Text)
+		if node.Note != "" {
+			fn(node.Note)
+		}
+		return true
+	})
+}
+
+// isRTLRune 判断字符是否为强从右到左字符
+func isRTLRune(r rune) bool {
+	props, _ := bidi.LookupRune(r)
+	class := props.Class()
+	return class == bidi.R || class == bidi.AL
+}
+
+// mirrorLayout 沿根节点所在的竖直线水平镜像整棵树
+func mirrorLayout(node *types.Node) {
+	axis := node.X
+	node.Walk(func(n *types.Node, _ int) bool {
+		n.X = 2*axis - n.X
+		return true
+	})
+}
+
+// warnMissingRTLGlyphs 在字体缺少树中从右到左字符的字形时给出一次警告
+func warnMissingRTLGlyphs(root *types.Node, hasGlyph func(rune) bool) {
+	if hasGlyph == nil {
+		return
+	}
+	missing := false
+	walkText(root, func(text string) {
+		for _, r := range text {
+			if !missing && isRTLRune(r) && !hasGlyph(r) {
+				missing = true
+			}
+		}
+	})
+	if missing {
+		log.Printf("font has no glyphs for some right-to-left text; use a font that covers it (-font / WithFontFile)")
+	}
+}
+
+// bidiCanvas 由自行按双向算法排列文本的绘制面实现；SVG 中的文本由查看器排版，无需预先重排
+type bidiCanvas interface {
+	nativeBidi()
+}
+
+// displayText 返回实际绘制的文本：从右到左模式下，位图（使用默认整形器时）与 PDF 按显示顺序重排
+func displayText(dc canvas, config *DrawConfig, text string) string {
+	if !config.RTL {
+		return text
+	}
+	if _, ok := dc.(bidiCanvas); ok {
+		return text
+	}
+	if _, ok := dc.(*gg.Context); ok && config.shaper != nil {
+		return text
+	}
+	return visualOrder(text)
+}
+
+// visualOrder 按 Unicode 双向算法把逻辑顺序的一行文本重排为从左到右的显示顺序，段落方向为从右到左。
+// 不含从右到左字符的文本原样返回
+func visualOrder(text string) string {
+	if !strings.ContainsFunc(text, isRTLRune) {
+		return text
+	}
+	var p bidi.Paragraph
+	if _, err := p.SetString(text, bidi.DefaultDirection(bidi.RightToLeft)); err != nil {
+		return text
+	}
+	o, err := p.Order()
+	if err != nil {
+		return text
+	}
+	// 从右到左的段落中，各段按相反顺序显示，从右到左的段内字符也反转（成对括号随之镜像）
+	var b strings.Builder
+	for i := o.NumRuns() - 1; i >= 0; i-- {
+		run := o.Run(i)
+		if run.Direction() == bidi.RightToLeft {
+			b.WriteString(bidi.ReverseString(run.String()))
+		} else {
+			b.WriteString(run.String())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestVisualOrder(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello world", "Hello world"},
+		{"שלום", "םולש"},
+		{"שלום world", "world םולש"},
+		{"שלום (א)", "(א) םולש"},
+	}
+	for _, tt := range tests {
+		if got := visualOrder(tt.in); got != tt.want {
+			t.Errorf("visualOrder(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsMostlyRTL(t *testing.T) {
+	hebrew := &types.Node{Text: "תוכנית", Children: []*types.Node{{Text: "שלב א"}, {Text: "API"}}}
+	if !IsMostlyRTL(hebrew) {
+		t.Error("expected a Hebrew outline to be detected as right to left")
+	}
+	mixed := &types.Node{Text: "Plan", Children: []*types.Node{{Text: "שלב"}, {Text: "Launch"}}}
+	if IsMostlyRTL(mixed) {
+		t.Error("expected a mostly English outline not to be right to left")
+	}
+}
+
+func TestRTLMirrorsLayout(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}, {Text: "B"}}}
+	}
+
+	ltr := newTree()
+	NewRenderer().layout(ltr)
+	rtl := newTree()
+	l := NewRenderer(WithRTL(true), WithBreadcrumb("Parent")).layout(rtl)
+	for i, child := range rtl.Children {
+		if child.X >= rtl.X {
+			t.Errorf("expected child %s left of the root, got x=%g (root %g)", child.Text, child.X, rtl.X)
+		}
+		if child.X-rtl.X != -(ltr.Children[i].X-ltr.X) || child.Y != ltr.Children[i].Y {
+			t.Errorf("expected child %s to mirror the left-to-right layout", child.Text)
+		}
+	}
+	if l.breadcrumb.x <= rtl.X {
+		t.Errorf("expected the breadcrumb right of the root, got x=%g", l.breadcrumb.x)
+	}
+}
+
+func TestRTLDraw(t *testing.T) {
+	root := &types.Node{Text: "תוכנית", Children: []*types.Node{{Text: "**שלב** א"}, {Text: "✅ סיום"}}}
+	for _, format := range []string{"png", "svg", "pdf"} {
+		var buf bytes.Buffer
+		draw := Draw
+		switch format {
+		case "svg":
+			draw = DrawSVG
+		case "pdf":
+			draw = DrawPDF
+		}
+		if err := draw(root, &buf, WithRTL(true)); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		// SVG 中文本保持逻辑顺序
+		if format == "svg" && !strings.Contains(buf.String(), "תוכנית") {
+			t.Error("expected SVG text in logical order")
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package drawer
+
+import "github.com/hellodeveye/mindmapgen/pkg/types"
+
+// SampleTree returns the small fixed map used for theme previews and golden
+// image tests: a root with a two-level branch, a leaf and a CJK leaf, so a
+// render shows the root, level and leaf styles. Each call builds a new tree,
+// since rendering sets node positions.
+func SampleTree() *types.Node {
+	return &types.Node{Text: "Project", Children: []*types.Node{
+		{Text: "Design", Children: []*types.Node{{Text: "Mockups"}, {Text: "Review"}}},
+		{Text: "Build"},
+		{Text: "发布计划"},
+	}}
+}
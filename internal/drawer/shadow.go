@@ -0,0 +1,48 @@
+package drawer
+
+import (
+	"log"
+
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// 阴影的默认不透明度与颜色
+const defaultShadowAlpha = 0.15
+
+// NodeShadow describes the drop shadow drawn beneath every node, in layout
+// units before scaling.
+type NodeShadow struct {
+	OffsetX, OffsetY float64
+	Blur             float64 // 模糊半径；当前以实心阴影绘制，保留供模糊实现使用
+	Color            [3]float64
+	Alpha            float64
+}
+
+// newNodeShadow 根据主题的阴影配置创建阴影，未配置时返回 nil
+func newNodeShadow(themeConfig *theme.ThemeConfig) *NodeShadow {
+	sc := themeConfig.Shadow
+	if sc == nil {
+		return nil
+	}
+	color, ok := parseHexColor(sc.Color, [3]float64{0, 0, 0})
+	if !ok && sc.Color != "" {
+		log.Printf("theme %q has invalid shadow color %q", themeConfig.Name, sc.Color)
+	}
+	alpha := sc.Alpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = defaultShadowAlpha
+	}
+	return &NodeShadow{OffsetX: sc.OffsetX, OffsetY: sc.OffsetY, Blur: sc.Blur, Color: color, Alpha: alpha}
+}
+
+// drawNodeShadow 在节点背景之下绘制偏移的半透明形状，x、y、w、h、r 为已缩放的节点框。
+// gg 没有模糊滤镜，这里绘制实心阴影；模糊版本可在此处按 Blur 半径改为多层渐隐的形状，
+// 或在位图上对阴影单独做高斯模糊后再合成。
+func drawNodeShadow(dc canvas, shadow *NodeShadow, shape types.Shape, x, y, w, h, r, scale float64) {
+	dc.Push()
+	dc.SetRGBA(shadow.Color[0], shadow.Color[1], shadow.Color[2], shadow.Alpha)
+	drawShapePath(dc, shape, x+shadow.OffsetX*scale, y+shadow.OffsetY*scale, w, h, r)
+	dc.Fill()
+	dc.Pop()
+}
@@ -0,0 +1,55 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/internal/theme"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestNewNodeShadow(t *testing.T) {
+	if s := newNodeShadow(&theme.ThemeConfig{}); s != nil {
+		t.Fatalf("expected no shadow without configuration, got %+v", s)
+	}
+	s := newNodeShadow(&theme.ThemeConfig{Shadow: &theme.ShadowConfig{OffsetX: 2, OffsetY: 3, Color: "#336699"}})
+	if s == nil || s.OffsetX != 2 || s.OffsetY != 3 || s.Alpha != defaultShadowAlpha || s.Color != [3]float64{0.2, 0.4, 0.6} {
+		t.Fatalf("unexpected shadow %+v", s)
+	}
+	if s := newNodeShadow(&theme.ThemeConfig{Shadow: &theme.ShadowConfig{Color: "bogus", Alpha: 0.5}}); s.Color != [3]float64{} || s.Alpha != 0.5 {
+		t.Fatalf("expected an invalid color to fall back to black, got %+v", s)
+	}
+}
+
+func TestDrawShadow(t *testing.T) {
+	cfg, err := theme.GetManager().GetThemeStrict("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shadowed := *cfg
+	shadowed.Shadow = &theme.ShadowConfig{OffsetX: 3, OffsetY: 4, Alpha: 0.25}
+
+	countShadows := func(tc *theme.ThemeConfig) int {
+		root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}, {Text: "B"}}}
+		var buf bytes.Buffer
+		if err := DrawSVG(root, &buf, WithThemeConfig(tc)); err != nil {
+			t.Fatalf("DrawSVG failed: %v", err)
+		}
+		return strings.Count(buf.String(), "fill:#000000;fill-opacity:0.25")
+	}
+	if got := countShadows(cfg); got != 0 {
+		t.Fatalf("expected shadows to be off by default, got %d", got)
+	}
+	// 共享样式只声明一次，被每个节点的阴影引用
+	if got := countShadows(&shadowed); got != 1 {
+		t.Fatalf("expected a shared shadow style, got %d declarations", got)
+	}
+
+	// 阴影偏移随缩放放大
+	sc := newSVGCanvas(true)
+	drawNodeShadow(sc, newNodeShadow(&shadowed), types.ShapeSquare, 10, 10, 100, 40, 0, 2)
+	if !strings.Contains(sc.body.String(), `d="M16,18 `) {
+		t.Fatalf("expected the shadow offset to be scaled, got %s", sc.body.String())
+	}
+}
@@ -0,0 +1,46 @@
+package drawer
+
+import "github.com/fogleman/gg"
+
+// TextShaper measures and draws text runs. Implementations can wrap a real
+// shaping engine (e.g. HarfBuzz) so that Indic, Thai or Arabic scripts get
+// correct glyph clustering and ligatures; the default measures and draws rune
+// by rune with the font face loaded into the gg context.
+type TextShaper interface {
+	// MeasureString returns the advance width of text in the context's units.
+	MeasureString(dc *gg.Context, text string) float64
+	// DrawString draws text anchored at (x, y); ax and ay follow the
+	// semantics of gg.Context.DrawStringAnchored.
+	DrawString(dc *gg.Context, text string, x, y, ax, ay float64)
+}
+
+// defaultShaper 直接使用 gg 的测量与绘制
+type defaultShaper struct{}
+
+func (defaultShaper) MeasureString(dc *gg.Context, text string) float64 {
+	w, _ := dc.MeasureString(text)
+	return w
+}
+
+func (defaultShaper) DrawString(dc *gg.Context, text string, x, y, ax, ay float64) {
+	dc.DrawStringAnchored(text, x, y, ax, ay)
+}
+
+// measureText 测量绘制面上的文本宽度；位图使用配置的 TextShaper，其他绘制面使用自身的字体度量
+func measureText(dc canvas, config *DrawConfig, text string) float64 {
+	if gc, ok := dc.(*gg.Context); ok {
+		return config.textShaper().MeasureString(gc, text)
+	}
+	w, _ := dc.MeasureString(text)
+	return w
+}
+
+// drawText 在绘制面上绘制文本，锚点语义与 gg.Context.DrawStringAnchored 一致
+func drawText(dc canvas, config *DrawConfig, text string, x, y, ax, ay float64) {
+	text = displayText(dc, config, text)
+	if gc, ok := dc.(*gg.Context); ok {
+		config.textShaper().DrawString(gc, text, x, y, ax, ay)
+		return
+	}
+	dc.DrawStringAnchored(text, x, y, ax, ay)
+}
@@ -0,0 +1,44 @@
+package drawer
+
+import (
+	"io"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+type stubShaper struct {
+	measured map[string]int
+	drawn    map[string]int
+}
+
+func (s *stubShaper) MeasureString(dc *gg.Context, text string) float64 {
+	s.measured[text]++
+	return float64(len([]rune(text))) * 10
+}
+
+func (s *stubShaper) DrawString(dc *gg.Context, text string, x, y, ax, ay float64) {
+	s.drawn[text]++
+}
+
+func TestDrawUsesTextShaper(t *testing.T) {
+	shaper := &stubShaper{measured: map[string]int{}, drawn: map[string]int{}}
+	root := &types.Node{
+		Text:     "สวัสดี",
+		Children: []*types.Node{{Text: "नमस्ते"}},
+	}
+
+	if err := Draw(root, io.Discard, WithTextShaper(shaper)); err != nil {
+		t.Fatalf("draw failed: %v", err)
+	}
+
+	for _, text := range []string{"สวัสดี", "नमस्ते"} {
+		if shaper.measured[text] == 0 {
+			t.Errorf("expected shaper to measure %q", text)
+		}
+		if shaper.drawn[text] == 0 {
+			t.Errorf("expected shaper to draw %q", text)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package drawer
+
+import (
+	"math"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// applyShapeSize 为非矩形的节点形状预留额外空间，保证文本落在轮廓内
+func applyShapeSize(size *NodeSize, shape types.Shape, config *DrawConfig) {
+	switch shape {
+	case types.ShapeCircle:
+		// 圆的直径取文本块对角线长度加内边距
+		textHeight := float64(len(size.Lines)) * size.LineHeight
+		d := math.Hypot(size.ActualTextWidth, textHeight) + 2*config.TextPadding
+		d = math.Max(d, math.Max(config.MinNodeHeight, size.Height))
+		size.Width, size.Height = d, d
+	case types.ShapeHexagon:
+		size.Width += size.Height / 2
+	case types.ShapeCloud:
+		size.Width += 2 * config.TextPadding
+		size.Height += config.TextPadding
+	case types.ShapeBang:
+		size.Width += 3 * config.TextPadding
+		size.Height += 2 * config.TextPadding
+	}
+}
+
+// drawShapePath 构建节点轮廓路径；未知形状退回到圆角矩形
+func drawShapePath(dc canvas, shape types.Shape, x, y, w, h, r float64) {
+	switch shape {
+	case types.ShapeSquare:
+		dc.NewSubPath()
+		dc.DrawRectangle(x, y, w, h)
+	case types.ShapeRounded:
+		drawRoundedRect(dc, x, y, w, h, h/2)
+	case types.ShapeCircle:
+		dc.NewSubPath()
+		dc.DrawEllipse(x+w/2, y+h/2, w/2, h/2)
+	case types.ShapeHexagon:
+		drawHexagon(dc, x, y, w, h)
+	case types.ShapeCloud:
+		drawCloud(dc, x, y, w, h)
+	case types.ShapeBang:
+		drawBang(dc, x, y, w, h)
+	default:
+		drawRoundedRect(dc, x, y, w, h, r)
+	}
+}
+
+// drawHexagon 绘制左右两端为尖角的六边形
+func drawHexagon(dc canvas, x, y, w, h float64) {
+	inset := math.Min(h/2, w/4)
+	dc.NewSubPath()
+	dc.MoveTo(x+inset, y)
+	dc.LineTo(x+w-inset, y)
+	dc.LineTo(x+w, y+h/2)
+	dc.LineTo(x+w-inset, y+h)
+	dc.LineTo(x+inset, y+h)
+	dc.LineTo(x, y+h/2)
+	dc.ClosePath()
+}
+
+// drawCloud 沿矩形四边绘制向外凸出的半圆弧，形成云朵轮廓
+func drawCloud(dc canvas, x, y, w, h float64) {
+	bump := math.Max(h/2, 1)
+	nx := int(math.Max(1, math.Round(w/bump)))
+	ny := int(math.Max(1, math.Round(h/bump)))
+	dx := w / float64(nx)
+	dy := h / float64(ny)
+
+	dc.NewSubPath()
+	// 上边，从左到右
+	for i := 0; i < nx; i++ {
+		dc.DrawArc(x+dx/2+float64(i)*dx, y, dx/2, math.Pi, 2*math.Pi)
+	}
+	// 右边，从上到下
+	for i := 0; i < ny; i++ {
+		dc.DrawArc(x+w, y+dy/2+float64(i)*dy, dy/2, -math.Pi/2, math.Pi/2)
+	}
+	// 下边，从右到左
+	for i := 0; i < nx; i++ {
+		dc.DrawArc(x+w-dx/2-float64(i)*dx, y+h, dx/2, 0, math.Pi)
+	}
+	// 左边，从下到上
+	for i := 0; i < ny; i++ {
+		dc.DrawArc(x, y+h-dy/2-float64(i)*dy, dy/2, math.Pi/2, 3*math.Pi/2)
+	}
+	dc.ClosePath()
+}
+
+// drawBang 绘制爆炸形（锯齿状星形）轮廓
+func drawBang(dc canvas, x, y, w, h float64) {
+	const spikes = 12
+	cx, cy := x+w/2, y+h/2
+	dc.NewSubPath()
+	for i := 0; i < spikes*2; i++ {
+		angle := float64(i) * math.Pi / spikes
+		rx, ry := w/2, h/2
+		if i%2 == 1 {
+			rx, ry = rx*0.82, ry*0.72
+		}
+		px := cx + rx*math.Cos(angle)
+		py := cy + ry*math.Sin(angle)
+		if i == 0 {
+			dc.MoveTo(px, py)
+		} else {
+			dc.LineTo(px, py)
+		}
+	}
+	dc.ClosePath()
+}
@@ -0,0 +1,74 @@
+package drawer
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// 骨架预览中每个字符的估算宽度（相对字号）
+const (
+	skeletonNarrowRune = 0.55 // 拉丁字母、数字等
+	skeletonWideRune   = 1.0  // 中日韩等全角字符
+)
+
+// estimateTextWidth 按字符数估算文本宽度，不进行字体测量
+func estimateTextWidth(text string, fontSize float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		if isMark(r) {
+			continue
+		}
+		if r > unicode.MaxLatin1 && !unicode.IsSpace(r) {
+			width += skeletonWideRune * fontSize
+		} else {
+			width += skeletonNarrowRune * fontSize
+		}
+	}
+	return width
+}
+
+// estimateNodeSizes 以估算宽度计算节点尺寸，文本不换行，高度固定为单行
+func estimateNodeSizes(node *types.Node, nodeSizes map[*types.Node]*NodeSize, config *DrawConfig) {
+	if node == nil {
+		return
+	}
+
+	maxTextWidth := config.MaxNodeWidth - 2*config.TextPadding
+	textWidth := math.Min(estimateTextWidth(node.Text, config.FontSize), maxTextWidth)
+	size := &NodeSize{
+		Width:           math.Max(config.MinNodeWidth, textWidth+2*config.TextPadding),
+		Height:          config.MinNodeHeight,
+		Lines:           []string{node.Text},
+		ActualTextWidth: textWidth,
+		LineHeight:      config.LineHeight,
+	}
+	if utf8.RuneCountInString(node.Text) == 0 {
+		size.Lines = nil
+	}
+	size.Width = config.widenToAspectRatio(size.Width, size.Height)
+	applyShapeSize(size, node.Shape, config)
+	nodeSizes[node] = size
+
+	for _, child := range node.Children {
+		estimateNodeSizes(child, nodeSizes, config)
+	}
+}
+
+// drawPlaceholderBar 在文本块的位置绘制代表文本的占位条
+func drawPlaceholderBar(dc canvas, node *types.Node, size *NodeSize, style *types.NodeStyle, scale float64, config *DrawConfig) {
+	if size.ActualTextWidth <= 0 {
+		return
+	}
+	w := size.ActualTextWidth * scale
+	h := config.FontSize * 0.5 * scale
+	left, _ := textSpan(node, size, config)
+	x := left * scale
+	y := node.Y*scale - h/2
+
+	dc.SetRGBA(style.TextColor[0], style.TextColor[1], style.TextColor[2], 0.35)
+	drawRoundedRect(dc, x, y, w, h, h/2)
+	dc.Fill()
+}
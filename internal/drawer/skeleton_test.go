@@ -0,0 +1,84 @@
+package drawer
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// buildLargeTree 构建指定宽度与深度的测试树
+func buildLargeTree(breadth, depth int) *types.Node {
+	var build func(prefix string, level int) *types.Node
+	build = func(prefix string, level int) *types.Node {
+		node := &types.Node{Text: "Topic " + prefix + " with some longer wrapped text"}
+		if level == depth {
+			return node
+		}
+		for i := 0; i < breadth; i++ {
+			node.Children = append(node.Children, build(fmt.Sprintf("%s.%d", prefix, i), level+1))
+		}
+		return node
+	}
+	return build("0", 0)
+}
+
+func TestDrawSkeleton(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "中文节点"}, {Text: ""}}}
+	img := renderPNG(t, root, WithSkeleton())
+	full := renderPNG(t, root)
+	if img.Bounds().Dx() >= full.Bounds().Dx() {
+		t.Fatalf("expected skeleton preview at scale 1 to be smaller than full render: %v vs %v", img.Bounds(), full.Bounds())
+	}
+}
+
+// textRecorder 记录绘制的文本，其余绘制操作交给 gg
+type textRecorder struct {
+	*gg.Context
+	texts []string
+}
+
+func (c *textRecorder) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	c.texts = append(c.texts, s)
+	c.Context.DrawStringAnchored(s, x, y, ax, ay)
+}
+
+// 骨架预览的速度来自跳过文本测量与绘制，检查这一点而不是比较耗时；
+// 耗时对比见 BenchmarkDrawSkeleton 与 BenchmarkDrawFull
+func TestDrawSkeletonSkipsText(t *testing.T) {
+	root := buildLargeTree(3, 2)
+	paint := func(opts ...Option) []string {
+		l := NewRenderer(opts...).layout(root)
+		dc := &textRecorder{Context: gg.NewContext(1, 1)}
+		paintMindmap(dc, l)
+		return dc.texts
+	}
+	if texts := paint(); len(texts) == 0 {
+		t.Fatal("expected the full render to draw node text")
+	}
+	if texts := paint(WithSkeleton()); len(texts) != 0 {
+		t.Fatalf("expected the skeleton preview to draw no text, got %q", texts)
+	}
+}
+
+func BenchmarkDrawFull(b *testing.B) {
+	root := buildLargeTree(6, 3)
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		if err := Draw(root, &buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDrawSkeleton(b *testing.B) {
+	root := buildLargeTree(6, 3)
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		if err := Draw(root, &buf, WithSkeleton()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package drawer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOption is returned, wrapped with details, when an option is
+// given values it cannot use, such as a negative spacing.
+var ErrInvalidOption = errors.New("invalid drawer option")
+
+// invalidOption 记录第一个无效选项的错误，渲染时返回
+func (opts *drawOptions) invalidOption(format string, args ...any) {
+	if opts.err == nil {
+		opts.err = fmt.Errorf("%w: %s", ErrInvalidOption, fmt.Sprintf(format, args...))
+	}
+}
+
+// WithNodeWidth overrides the theme's minimum and maximum node width, in
+// layout units before scaling. Text wraps at the maximum width. Both values
+// must be positive with min ≤ max; otherwise rendering returns an error
+// wrapping ErrInvalidOption.
+func WithNodeWidth(min, max float64) Option {
+	return func(opts *drawOptions) {
+		if min <= 0 || max <= 0 || min > max {
+			opts.invalidOption("node width must satisfy 0 < min ≤ max, got min %g and max %g", min, max)
+			return
+		}
+		opts.minNodeWidth, opts.maxNodeWidth = min, max
+	}
+}
+
+// WithNodeSpacing overrides the theme's gap between sibling subtrees. The
+// gap must be positive; otherwise rendering returns an error wrapping
+// ErrInvalidOption.
+func WithNodeSpacing(gap float64) Option {
+	return func(opts *drawOptions) {
+		if gap <= 0 {
+			opts.invalidOption("node spacing must be positive, got %g", gap)
+			return
+		}
+		opts.nodeSpacing = gap
+	}
+}
+
+// WithLevelSpacing overrides the theme's gap between a node and its
+// children. WithRootLevelSpacing still takes precedence for the root's
+// children. The gap must be positive; otherwise rendering returns an error
+// wrapping ErrInvalidOption.
+func WithLevelSpacing(gap float64) Option {
+	return func(opts *drawOptions) {
+		if gap <= 0 {
+			opts.invalidOption("level spacing must be positive, got %g", gap)
+			return
+		}
+		opts.levelSpacing = gap
+	}
+}
+
+// applySpacing 用选项中的节点宽度与间距覆盖主题配置，未指定的保持不变
+func (c *DrawConfig) applySpacing(opts drawOptions) {
+	if opts.maxNodeWidth > 0 {
+		c.MinNodeWidth, c.MaxNodeWidth = opts.minNodeWidth, opts.maxNodeWidth
+	}
+	if opts.nodeSpacing > 0 {
+		c.NodeSpacing = opts.nodeSpacing
+	}
+	if opts.levelSpacing > 0 {
+		c.LevelSpacing = opts.levelSpacing
+	}
+}
@@ -0,0 +1,65 @@
+package drawer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestSpacingOptionsOverrideTheme(t *testing.T) {
+	r := NewRenderer(WithNodeWidth(60, 400), WithNodeSpacing(5), WithLevelSpacing(80))
+	if r.opts.err != nil {
+		t.Fatalf("unexpected error: %v", r.opts.err)
+	}
+	c := r.config
+	if c.MinNodeWidth != 60 || c.MaxNodeWidth != 400 || c.NodeSpacing != 5 || c.LevelSpacing != 80 {
+		t.Fatalf("options not applied: min %g max %g node %g level %g", c.MinNodeWidth, c.MaxNodeWidth, c.NodeSpacing, c.LevelSpacing)
+	}
+
+	// 未指定时沿用主题设置
+	base := NewRenderer().config
+	c = NewRenderer(WithNodeSpacing(5)).config
+	if c.MinNodeWidth != base.MinNodeWidth || c.MaxNodeWidth != base.MaxNodeWidth || c.LevelSpacing != base.LevelSpacing {
+		t.Fatalf("unset options changed the theme values")
+	}
+
+	// 较小的层级间距使画布更窄
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A", Children: []*types.Node{{Text: "B"}}}}}
+	wide, _ := NewRenderer().layout(root).size()
+	narrow, _ := NewRenderer(WithLevelSpacing(40)).layout(root).size()
+	if narrow >= wide {
+		t.Fatalf("expected a smaller level spacing to narrow the map, got %g >= %g", narrow, wide)
+	}
+}
+
+func TestSpacingOptionsValidation(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}}}
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{"min above max", WithNodeWidth(300, 200)},
+		{"zero min", WithNodeWidth(0, 200)},
+		{"negative max", WithNodeWidth(100, -1)},
+		{"zero node spacing", WithNodeSpacing(0)},
+		{"negative level spacing", WithLevelSpacing(-10)},
+	}
+	draws := map[string]func(*types.Node, io.Writer, ...Option) error{
+		"png": Draw, "svg": DrawSVG, "pdf": DrawPDF, "gif": DrawReveal, "toc": DrawTOC,
+	}
+	for _, tt := range tests {
+		for format, draw := range draws {
+			var buf bytes.Buffer
+			err := draw(root, &buf, tt.opt)
+			if !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("%s (%s): expected ErrInvalidOption, got %v", tt.name, format, err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("%s (%s): expected no output", tt.name, format)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package drawer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// SubtreeUploader stores the image of a subtree collapsed by WithMaxDepth
+// and returns the URL it is served from. contentType is "image/png", or
+// "image/jpeg" with WithFormat("jpeg").
+type SubtreeUploader func(data []byte, contentType string) (string, error)
+
+// WithSubtreeImages makes Layout render the full subtree below each node
+// that WithMaxDepth collapsed, pass the image to upload and report the
+// returned URL in that node's LayoutNode.SubtreeImage, so a client drawing
+// the overview can link each "+k" badge to the hidden branch. Subtree
+// images use the same options without the depth limit, focus, title and
+// caption. It has no effect without WithMaxDepth or when drawing.
+func WithSubtreeImages(upload SubtreeUploader) Option {
+	return func(opts *drawOptions) {
+		opts.subtreeUpload = upload
+	}
+}
+
+// subtreeImage 以完整深度渲染被裁剪节点的原子树并上传，返回图片地址
+func (r *Renderer) subtreeImage(subtree *types.Node) (string, error) {
+	sub := *r
+	sub.opts.maxDepth = -1
+	sub.opts.focus = ""
+	sub.opts.title, sub.opts.caption = "", ""
+	sub.opts.subtreeUpload = nil
+
+	var buf bytes.Buffer
+	if err := sub.RenderContext(context.Background(), subtree, &buf); err != nil {
+		return "", fmt.Errorf("render subtree %q: %w", subtree.Text, err)
+	}
+	contentType := "image/png"
+	if r.opts.format == "jpeg" {
+		contentType = "image/jpeg"
+	}
+	url, err := r.opts.subtreeUpload(buf.Bytes(), contentType)
+	if err != nil {
+		return "", fmt.Errorf("upload subtree %q: %w", subtree.Text, err)
+	}
+	return url, nil
+}
@@ -0,0 +1,220 @@
+package drawer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// DrawSVG renders the mind map as an SVG document using the same layout as
+// Draw. Distinct fill/stroke/text styles are declared once as CSS classes in
+// a <style> block and referenced by class, which keeps large maps compact.
+func DrawSVG(rootNode *types.Node, w io.Writer, options ...Option) error {
+	return drawVector(rootNode, w, options, func(opts drawOptions, _ float64) vectorSurface {
+		return newSVGCanvas(opts.inlineSVGStyles)
+	})
+}
+
+// svgFontFamily SVG 文本使用的字体，与内嵌字体保持一致并提供常见的中文回退字体
+const svgFontFamily = `SimHei,"Microsoft YaHei","PingFang SC","Noto Sans CJK SC",sans-serif`
+
+// svgCanvas 将 canvas 调用记录为 SVG 元素
+type svgCanvas struct {
+	vectorCanvas
+
+	body   bytes.Buffer
+	inline bool // 为 true 时直接在元素上写样式，不生成共享的 CSS 类
+	linked bool // 是否输出过超链接，决定是否声明 xlink 命名空间
+
+	classes    map[string]string // 样式声明 -> 类名
+	classOrder []string          // 按首次出现顺序排列的样式声明
+
+	defs      bytes.Buffer // 渐变等需在 <defs> 中声明的元素
+	gradients int
+}
+
+func newSVGCanvas(inline bool) *svgCanvas {
+	return &svgCanvas{
+		vectorCanvas: newVectorCanvas(),
+		inline:       inline,
+		classes:      make(map[string]string),
+	}
+}
+
+func (sc *svgCanvas) Fill() {
+	decl := "fill:" + svgColor(sc.state.color)
+	if sc.state.alpha < 1 {
+		decl += ";fill-opacity:" + formatNum(sc.state.alpha)
+	}
+	sc.paint(decl)
+}
+
+func (sc *svgCanvas) Stroke() {
+	decl := "fill:none;stroke:" + svgColor(sc.state.color) + ";stroke-width:" + formatNum(sc.state.lineWidth)
+	if sc.state.alpha < 1 {
+		decl += ";stroke-opacity:" + formatNum(sc.state.alpha)
+	}
+	sc.paint(decl)
+}
+
+// fillLinearGradient 以用户坐标系中的 <linearGradient> 填充当前路径
+func (sc *svgCanvas) fillLinearGradient(x0, y0, x1, y1 float64, from, to [3]float64) {
+	if len(sc.path) == 0 {
+		return
+	}
+	p0, p1 := sc.transform(x0, y0), sc.transform(x1, y1)
+	id := fmt.Sprintf("g%d", sc.gradients)
+	sc.gradients++
+	fmt.Fprintf(&sc.defs, "<linearGradient id=\"%s\" gradientUnits=\"userSpaceOnUse\" x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\">"+
+		"<stop offset=\"0\" stop-color=\"%s\"/><stop offset=\"1\" stop-color=\"%s\"/></linearGradient>\n",
+		id, formatNum(p0.X), formatNum(p0.Y), formatNum(p1.X), formatNum(p1.Y), svgColor(from), svgColor(to))
+	decl := "fill:url(#" + id + ")"
+	if sc.state.alpha < 1 {
+		decl += ";fill-opacity:" + formatNum(sc.state.alpha)
+	}
+	sc.paint(decl)
+}
+
+// paint 以给定样式输出并清空待绘制的路径
+func (sc *svgCanvas) paint(decl string) {
+	path := sc.takePath()
+	if len(path) == 0 {
+		return
+	}
+	var d strings.Builder
+	for _, seg := range path {
+		if d.Len() > 0 {
+			d.WriteByte(' ')
+		}
+		d.WriteByte(seg.op)
+		for i, p := range seg.pts {
+			if i > 0 {
+				d.WriteByte(' ')
+			}
+			d.WriteString(formatNum(p.X) + "," + formatNum(p.Y))
+		}
+	}
+	fmt.Fprintf(&sc.body, "<path %s d=\"%s\"/>\n", sc.styleAttr(decl), d.String())
+}
+
+// nativeBidi 表明 SVG 文本保持逻辑顺序，由查看器按双向算法排版
+func (sc *svgCanvas) nativeBidi() {}
+
+func (sc *svgCanvas) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	if sc.font == nil || s == "" {
+		return
+	}
+	// 水平方向交给 text-anchor，避免查看器回退到其他字体时文字偏离节点中心
+	anchor := "start"
+	switch {
+	case ax >= 1:
+		anchor = "end"
+	case ax > 0:
+		anchor = "middle"
+	}
+	_, h := sc.MeasureString(s)
+	p := sc.transform(x, y+ay*h)
+
+	decl := "fill:" + svgColor(sc.state.color) + ";font-size:" + formatNum(sc.fontSize) + "px"
+	if anchor != "start" {
+		decl += ";text-anchor:" + anchor
+	}
+	if sc.state.alpha < 1 {
+		decl += ";fill-opacity:" + formatNum(sc.state.alpha)
+	}
+
+	var text bytes.Buffer
+	xml.EscapeText(&text, []byte(s))
+	fmt.Fprintf(&sc.body, "<text %s x=\"%s\" y=\"%s\">%s</text>\n",
+		sc.styleAttr(decl), formatNum(p.X), formatNum(p.Y), text.String())
+}
+
+// beginLink 之后输出的元素包裹在指向 href 的 <a> 中，直到 endLink。
+// 只接受 http、https、mailto 与相对地址，其他地址（如 javascript:）不生成链接并返回 false。
+func (sc *svgCanvas) beginLink(href string) bool {
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+	default:
+		return false
+	}
+	sc.linked = true
+	var attr bytes.Buffer
+	xml.EscapeText(&attr, []byte(href))
+	fmt.Fprintf(&sc.body, "<a xlink:href=\"%s\">\n", attr.String())
+	return true
+}
+
+func (sc *svgCanvas) endLink() {
+	sc.body.WriteString("</a>\n")
+}
+
+// beginTooltip 之后输出的元素包裹在带 <title> 的 <g> 中，悬停时显示 text，直到 endTooltip
+func (sc *svgCanvas) beginTooltip(text string) {
+	var title bytes.Buffer
+	xml.EscapeText(&title, []byte(text))
+	fmt.Fprintf(&sc.body, "<g><title>%s</title>\n", title.String())
+}
+
+func (sc *svgCanvas) endTooltip() {
+	sc.body.WriteString("</g>\n")
+}
+
+// styleAttr 返回引用共享 CSS 类的 class 属性；内联模式下返回 style 属性
+func (sc *svgCanvas) styleAttr(decl string) string {
+	if sc.inline {
+		return `style="` + decl + `"`
+	}
+	name, ok := sc.classes[decl]
+	if !ok {
+		name = fmt.Sprintf("s%d", len(sc.classOrder))
+		sc.classes[decl] = name
+		sc.classOrder = append(sc.classOrder, decl)
+	}
+	return `class="` + name + `"`
+}
+
+// writeTo 写出完整的 SVG 文档，共享样式集中写在 <style> 中
+func (sc *svgCanvas) writeTo(w io.Writer, width, height float64) error {
+	var buf bytes.Buffer
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	xlink := ""
+	if sc.linked {
+		xlink = ` xmlns:xlink="http://www.w3.org/1999/xlink"`
+	}
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\"%s width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		xlink, formatNum(width), formatNum(height), formatNum(width), formatNum(height))
+	buf.WriteString("<style>\n")
+	fmt.Fprintf(&buf, "path{stroke-linecap:butt;stroke-linejoin:round}\ntext{font-family:%s}\n", svgFontFamily)
+	for _, decl := range sc.classOrder {
+		fmt.Fprintf(&buf, ".%s{%s}\n", sc.classes[decl], decl)
+	}
+	buf.WriteString("</style>\n")
+	if sc.defs.Len() > 0 {
+		buf.WriteString("<defs>\n")
+		buf.Write(sc.defs.Bytes())
+		buf.WriteString("</defs>\n")
+	}
+	buf.Write(sc.body.Bytes())
+	buf.WriteString("</svg>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// svgColor 将 0-1 的颜色分量转换为 #rrggbb
+func svgColor(c [3]float64) string {
+	return fmt.Sprintf("#%02x%02x%02x", colorByte(c[0]), colorByte(c[1]), colorByte(c[2]))
+}
+
+func colorByte(v float64) int {
+	return int(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
@@ -0,0 +1,101 @@
+package drawer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestDrawSVGSharedStyles(t *testing.T) {
+	root := &types.Node{Text: "中文导图 & <SVG>"}
+	for i := 0; i < 10; i++ {
+		root.Children = append(root.Children, &types.Node{Text: "分支", Children: []*types.Node{{Text: "叶子"}}})
+	}
+
+	var buf bytes.Buffer
+	if err := DrawSVG(root, &buf); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	out := buf.String()
+
+	// 输出必须是格式良好的 XML，且文本已转义
+	dec := xml.NewDecoder(strings.NewReader(out))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+	}
+	if !strings.Contains(out, "中文导图 &amp; &lt;SVG&gt;") {
+		t.Error("root text missing or not escaped")
+	}
+
+	if !strings.Contains(out, "<style>") {
+		t.Fatal("expected a <style> block")
+	}
+	if strings.Contains(out, "style=\"") {
+		t.Error("expected no inline style attributes")
+	}
+	elements := strings.Count(out, "<path ") + strings.Count(out, "<text ")
+	refs := strings.Count(out, `class="s`)
+	classes := strings.Count(out, "\n.s")
+	if refs != elements {
+		t.Errorf("expected every element to reference a class, got %d of %d", refs, elements)
+	}
+	if classes == 0 || classes*3 > elements {
+		t.Errorf("expected styles to be shared, got %d classes for %d elements", classes, elements)
+	}
+
+	var inline bytes.Buffer
+	if err := DrawSVG(root, &inline, WithInlineSVGStyles()); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	if strings.Contains(inline.String(), `class="`) {
+		t.Error("expected inline styles only")
+	}
+	if inline.Len() <= buf.Len() {
+		t.Errorf("expected shared styles to be smaller: %d vs %d bytes inline", buf.Len(), inline.Len())
+	}
+}
+
+func TestDrawSVGLinks(t *testing.T) {
+	root := &types.Node{Text: "Docs", Children: []*types.Node{
+		{Text: "Guide", Link: "https://example.com/guide?a=1&b=2"},
+		{Text: "Evil", Link: "javascript:alert(1)"},
+		{Text: "Plain"},
+	}}
+	var buf bytes.Buffer
+	if err := DrawSVG(root, &buf); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	svg := buf.String()
+	if !strings.Contains(svg, `xmlns:xlink="http://www.w3.org/1999/xlink"`) {
+		t.Error("expected the xlink namespace to be declared")
+	}
+	if got := strings.Count(svg, "<a "); got != 1 || strings.Count(svg, "</a>") != 1 {
+		t.Fatalf("expected exactly one link element, got %d", got)
+	}
+	if !strings.Contains(svg, `<a xlink:href="https://example.com/guide?a=1&amp;b=2">`) {
+		t.Error("expected the escaped link URL")
+	}
+	if strings.Contains(svg, "javascript:") {
+		t.Error("expected unsafe URLs not to be linked")
+	}
+	if err := xml.Unmarshal(buf.Bytes(), new(struct{})); err != nil {
+		t.Fatalf("expected well-formed XML: %v", err)
+	}
+
+	// 没有链接时不声明 xlink 命名空间
+	buf.Reset()
+	if err := DrawSVG(&types.Node{Text: "Plain"}, &buf); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "xlink") {
+		t.Error("expected no xlink namespace without links")
+	}
+}
@@ -0,0 +1,82 @@
+package drawer
+
+import (
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// 节点内文本的对齐方式：左对齐、居中（默认）与右对齐
+const (
+	textAlignLeft   = "left"
+	textAlignCenter = "center"
+	textAlignRight  = "right"
+)
+
+// isTextAlign 判断是否为支持的文本对齐方式
+func isTextAlign(align string) bool {
+	switch align {
+	case textAlignLeft, textAlignCenter, textAlignRight:
+		return true
+	}
+	return false
+}
+
+// WithTextAlign sets how the lines of a node's text are aligned, overriding
+// the theme's layout.textAlign: "left", "center" (the default) or "right".
+// In rectangular nodes the text block also moves to that side of the node;
+// other shapes keep the block centered and align the lines within it.
+// Unknown values are ignored.
+func WithTextAlign(align string) Option {
+	return func(opts *drawOptions) {
+		if align = strings.ToLower(strings.TrimSpace(align)); isTextAlign(align) {
+			opts.textAlign = align
+		}
+	}
+}
+
+// textCentered 判断节点文本是否居中对齐
+func (c *DrawConfig) textCentered() bool {
+	return c.TextAlign != textAlignLeft && c.TextAlign != textAlignRight
+}
+
+// textSpan 返回节点文本块（含图标、备注与脚注）左右边缘的 x 坐标（未缩放）。
+// 矩形节点的文本块按对齐方式贴靠左或右内边距；其他形状为容纳轮廓预留了空间，文本块保持居中
+func textSpan(node *types.Node, size *NodeSize, config *DrawConfig) (left, right float64) {
+	left, right = node.X-size.ActualTextWidth/2, node.X+size.ActualTextWidth/2
+	switch node.Shape {
+	case types.ShapeDefault, types.ShapeSquare, types.ShapeRounded:
+	default:
+		return left, right
+	}
+	slack := max(0, size.Width/2-config.TextPadding-size.ActualTextWidth/2)
+	switch config.TextAlign {
+	case textAlignLeft:
+		return left - slack, right - slack
+	case textAlignRight:
+		return left + slack, right + slack
+	}
+	return left, right
+}
+
+// alignedX 返回在 [left, right] 内按对齐方式放置宽度为 width 的文本时的左端坐标
+func alignedX(align string, left, right, width float64) float64 {
+	switch align {
+	case textAlignLeft:
+		return left
+	case textAlignRight:
+		return right - width
+	}
+	return (left + right - width) / 2
+}
+
+// alignedAnchor 返回在 [left, right] 内按对齐方式绘制文本时的锚点 x 坐标及水平锚点比例
+func alignedAnchor(align string, left, right float64) (x, ax float64) {
+	switch align {
+	case textAlignLeft:
+		return left, 0
+	case textAlignRight:
+		return right, 1
+	}
+	return (left + right) / 2, 0.5
+}
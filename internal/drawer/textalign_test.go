@@ -0,0 +1,81 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestTextSpan(t *testing.T) {
+	config := &DrawConfig{TextPadding: 10}
+	size := &NodeSize{Width: 200, ActualTextWidth: 100}
+	node := &types.Node{X: 0}
+
+	for _, tt := range []struct {
+		align       string
+		left, right float64
+	}{
+		{"", -50, 50},
+		{textAlignCenter, -50, 50},
+		{textAlignLeft, -90, 10},
+		{textAlignRight, -10, 90},
+	} {
+		config.TextAlign = tt.align
+		if left, right := textSpan(node, size, config); left != tt.left || right != tt.right {
+			t.Errorf("%q: got [%g, %g], want [%g, %g]", tt.align, left, right, tt.left, tt.right)
+		}
+	}
+
+	// 非矩形形状的文本块保持居中
+	config.TextAlign = textAlignLeft
+	circle := &types.Node{Shape: types.ShapeCircle}
+	if left, right := textSpan(circle, size, config); left != -50 || right != 50 {
+		t.Errorf("expected a centered block in a circle, got [%g, %g]", left, right)
+	}
+}
+
+func TestTextAlignConnectorTrim(t *testing.T) {
+	newTree := func() *types.Node {
+		return &types.Node{Text: "Root", Children: []*types.Node{{Text: "Leaf"}}}
+	}
+	for _, tc := range []struct {
+		align  string
+		layout string
+	}{
+		{"left", "right"}, {"right", "right"}, {"left", "left"}, {"right", "left"},
+	} {
+		root := newTree()
+		// 最小宽度远大于文本，使文本块偏离节点中心
+		l := NewRenderer(WithTextAlign(tc.align), WithLayout(tc.layout), WithNodeWidth(300, 400), WithScale(1)).layout(root)
+		leaf := root.Children[0]
+		leafSize := l.nodeSizes[leaf]
+		_, _, endX, _ := horizontalConnectionPoints(root, leaf, l.nodeSizes[root], leafSize, l.config)
+		left, right := textSpan(leaf, leafSize, l.config)
+		if tc.layout == "right" && (endX >= left || endX < leaf.X-leafSize.Width/2) {
+			t.Errorf("%s/%s: expected the connector to stop before the text at %g, got %g", tc.align, tc.layout, left, endX)
+		}
+		if tc.layout == "left" && (endX <= right || endX > leaf.X+leafSize.Width/2) {
+			t.Errorf("%s/%s: expected the connector to stop after the text at %g, got %g", tc.align, tc.layout, right, endX)
+		}
+	}
+}
+
+func TestWithTextAlign(t *testing.T) {
+	if r := NewRenderer(WithTextAlign(" Left ")); r.config.TextAlign != textAlignLeft {
+		t.Errorf("expected left, got %q", r.config.TextAlign)
+	}
+	if r := NewRenderer(WithTextAlign("justify")); r.config.TextAlign != "" {
+		t.Errorf("expected an unknown alignment to be ignored, got %q", r.config.TextAlign)
+	}
+
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A **bold** line that is long enough to wrap onto a second line", Note: "note"}}}
+	var buf bytes.Buffer
+	if err := DrawSVG(root, &buf, WithTextAlign("right")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "text-anchor:end") {
+		t.Error("expected right-aligned lines to be anchored at their end")
+	}
+}
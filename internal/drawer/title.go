@@ -0,0 +1,84 @@
+package drawer
+
+import (
+	"math"
+	"strings"
+)
+
+// 标题与说明相对正文的字号比例
+const (
+	titleScale   = 1.4
+	captionScale = 0.8
+)
+
+// titleBand 画布顶部的标题或底部的说明（布局单位）
+type titleBand struct {
+	text   string
+	k      float64 // 相对正文的字号比例
+	x, y   float64 // 文本中心
+	width  float64 // 文本宽度
+	height float64 // 文本行高
+}
+
+// WithTitle draws title centered above the map in a larger font, using the
+// theme's connector color. The canvas grows to make room for it and is
+// widened if the title is wider than the map. An empty title draws nothing.
+func WithTitle(title string) Option {
+	return func(opts *drawOptions) {
+		opts.title = strings.TrimSpace(title)
+	}
+}
+
+// WithCaption draws caption, e.g. a generation date or a source note,
+// centered below the map in a smaller font, using the theme's connector
+// color. The canvas grows to make room for it. An empty caption draws
+// nothing.
+func WithCaption(caption string) Option {
+	return func(opts *drawOptions) {
+		opts.caption = strings.TrimSpace(caption)
+	}
+}
+
+// placeTitleBand 在已含边距的边界上方（top 为 true）或下方加入一行文本，并扩展边界：
+// 文本与导图之间保留原有边距，文本外侧再留出一行行高；文本比导图宽时左右对称加宽。
+// text 为空时不修改边界，返回 nil。
+func placeTitleBand(text string, k float64, top bool, measure func(string) float64, bounds *Bounds, config *DrawConfig) *titleBand {
+	if text == "" {
+		return nil
+	}
+	b := &titleBand{
+		text:   text,
+		k:      k,
+		x:      (bounds.MinX + bounds.MaxX) / 2,
+		width:  measure(text) * k,
+		height: config.LineHeight * k,
+	}
+	pad := config.LineHeight
+	if top {
+		b.y = bounds.MinY - b.height/2
+		bounds.MinY -= b.height + pad
+	} else {
+		b.y = bounds.MaxY + b.height/2
+		bounds.MaxY += b.height + pad
+	}
+	if half := b.width/2 + pad; b.x-half < bounds.MinX {
+		bounds.MinX = math.Min(bounds.MinX, b.x-half)
+		bounds.MaxX = math.Max(bounds.MaxX, b.x+half)
+	}
+	return b
+}
+
+// drawTitleBand 以连接线颜色居中绘制标题或说明，骨架预览绘制占位条
+func drawTitleBand(dc canvas, b *titleBand, config *DrawConfig) {
+	scale := config.Scale
+	line := config.ConnectionLineColor
+	dc.SetRGB(line[0], line[1], line[2])
+	if config.skeleton {
+		h := config.FontSize * b.k * 0.5 * scale
+		drawRoundedRect(dc, (b.x-b.width/2)*scale, b.y*scale-h/2, b.width*scale, h, h/2)
+		dc.Fill()
+		return
+	}
+	width := measureText(dc, config, b.text) * b.k
+	drawScaledText(dc, config, b.text, b.x*scale-width/2, b.y*scale, b.k)
+}
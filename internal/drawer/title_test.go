@@ -0,0 +1,61 @@
+package drawer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestTitleAndCaptionExtendBounds(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}, {Text: "B"}}}
+	base := NewRenderer().layout(root)
+	baseW, baseH := base.size()
+
+	// 空标题与说明不改变布局
+	empty := NewRenderer(WithTitle("  "), WithCaption("")).layout(root)
+	if *empty.bounds != *base.bounds || empty.title != nil || empty.caption != nil {
+		t.Fatalf("expected empty title and caption to leave the layout untouched")
+	}
+
+	l := NewRenderer(WithTitle("Plan"), WithCaption("2026-10-16")).layout(root)
+	w, h := l.size()
+	if w != baseW || h <= baseH {
+		t.Fatalf("expected only the height to grow, got %gx%g from %gx%g", w, h, baseW, baseH)
+	}
+	if l.bounds.MinY >= base.bounds.MinY || l.bounds.MaxY <= base.bounds.MaxY {
+		t.Fatalf("expected room above and below the map, got %+v from %+v", *l.bounds, *base.bounds)
+	}
+	// 标题与说明完整位于画布内，且不与原有内容区域重叠
+	if top := l.title.y - l.title.height/2; top < l.bounds.MinY || l.title.y+l.title.height/2 > base.bounds.MinY {
+		t.Errorf("title at %g does not fit between %g and %g", l.title.y, l.bounds.MinY, base.bounds.MinY)
+	}
+	if l.caption.y-l.caption.height/2 < base.bounds.MaxY || l.caption.y+l.caption.height/2 > l.bounds.MaxY {
+		t.Errorf("caption at %g does not fit between %g and %g", l.caption.y, base.bounds.MaxY, l.bounds.MaxY)
+	}
+
+	// 比导图宽的标题使画布左右对称加宽
+	wide := NewRenderer(WithTitle(strings.Repeat("A very long title ", 10))).layout(root)
+	if w, _ := wide.size(); w <= baseW {
+		t.Fatalf("expected a wide title to widen the canvas")
+	}
+	if wide.title.x-wide.title.width/2 < wide.bounds.MinX || wide.title.x+wide.title.width/2 > wide.bounds.MaxX {
+		t.Errorf("expected the title to fit horizontally")
+	}
+}
+
+func TestDrawSVGTitleAndCaption(t *testing.T) {
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A"}}}
+	var buf bytes.Buffer
+	if err := DrawSVG(root, &buf, WithTitle("Quarterly plan"), WithCaption("Draft")); err != nil {
+		t.Fatalf("DrawSVG failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Quarterly plan</text>") || !strings.Contains(buf.String(), "Draft</text>") {
+		t.Fatalf("expected title and caption text in the SVG")
+	}
+	buf.Reset()
+	if err := Draw(root, &buf, WithTitle("Quarterly plan")); err != nil || buf.Len() == 0 {
+		t.Fatalf("Draw failed: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package drawer
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+	"golang.org/x/image/font"
+)
+
+// 目录图的版式（布局单位，按主题缩放输出）
+const (
+	tocPadding     = 20.0
+	tocFontSize    = 14.0
+	tocTitleSize   = 18.0
+	tocLineGap     = 6.0
+	tocNumberGap   = 8.0
+	tocTitleMargin = 10.0
+	tocMaxLevels   = 2 // 列出的分支层数：一级与二级分支
+)
+
+// tocEntry 目录中的一行：编号（如 "2.1"）、分支文本与所在层级（1 起）
+type tocEntry struct {
+	number string
+	text   string
+	level  int
+}
+
+// tocEntries 按先序列出一级与二级分支及其编号
+func tocEntries(root *types.Node) []tocEntry {
+	var entries []tocEntry
+	var visit func(node *types.Node, prefix string, level int)
+	visit = func(node *types.Node, prefix string, level int) {
+		if level > tocMaxLevels {
+			return
+		}
+		for i, child := range node.Children {
+			number := strconv.Itoa(i + 1)
+			if prefix != "" {
+				number = prefix + "." + number
+			}
+			entries = append(entries, tocEntry{number: number, text: child.Text, level: level})
+			visit(child, number, level+1)
+		}
+	}
+	visit(root, "", 1)
+	return entries
+}
+
+// DrawTOC renders a compact table of contents for the mind map as a PNG: the
+// root text as a title, followed by every top-level and second-level branch
+// with its outline number (1, 1.1, ...). It is a navigable companion to the
+// full image of a large map. Branch order and the theme's background and
+// scale follow the same options as Draw.
+func DrawTOC(rootNode *types.Node, w io.Writer, options ...Option) error {
+	r := NewRenderer(options...)
+	if r.opts.err != nil {
+		return r.opts.err
+	}
+	rootNode, err := r.focusRoot(rootNode)
+	if err != nil {
+		return err
+	}
+	config := r.newConfig()
+	rootNode = orderChildren(rootNode)
+	entries := tocEntries(rootNode)
+
+	ttf, err := parseEmbeddedFont()
+	if err != nil {
+		return err
+	}
+	newFace := func(size float64) font.Face {
+		return truetype.NewFace(ttf, &truetype.Options{Size: size})
+	}
+
+	// 在 1 倍缩放下测量；各层编号列按该层最宽的编号对齐
+	measure := gg.NewContext(1, 1)
+	measure.SetFontFace(newFace(tocTitleSize))
+	titleWidth, titleHeight := measure.MeasureString(rootNode.Text)
+	measure.SetFontFace(newFace(tocFontSize))
+	_, lineHeight := measure.MeasureString("M")
+	numberWidths := make([]float64, tocMaxLevels+1)
+	for _, e := range entries {
+		nw, _ := measure.MeasureString(e.number)
+		numberWidths[e.level] = max(numberWidths[e.level], nw)
+	}
+	width := titleWidth
+	for _, e := range entries {
+		tw, _ := measure.MeasureString(e.text)
+		width = max(width, tocTextX(e.level, numberWidths)-tocPadding+tw)
+	}
+	width += 2 * tocPadding
+	height := 2*tocPadding + titleHeight
+	if len(entries) > 0 {
+		height += tocTitleMargin + float64(len(entries))*(lineHeight+tocLineGap) - tocLineGap
+	}
+
+	k := config.Scale
+	pixelWidth, pixelHeight, err := canvasPixels(width, height, k)
+	if err != nil {
+		return err
+	}
+	dc := gg.NewContext(pixelWidth, pixelHeight)
+	bg := config.BackgroundColor
+	if !r.opts.transparent {
+		dc.SetRGB(bg[0], bg[1], bg[2])
+		dc.Clear()
+	}
+	textColor := contrastTextColor(bg)
+
+	// 字体按输出尺寸加载，避免缩放位图字形导致文字模糊
+	dc.SetFontFace(newFace(tocTitleSize * k))
+	dc.SetRGB(textColor[0], textColor[1], textColor[2])
+	dc.DrawStringAnchored(rootNode.Text, tocPadding*k, tocPadding*k, 0, 1)
+
+	dc.SetFontFace(newFace(tocFontSize * k))
+	y := tocPadding + titleHeight + tocTitleMargin
+	for _, e := range entries {
+		x := tocTextX(e.level, numberWidths)
+		// 编号使用连接线颜色弱化显示，右对齐到编号列
+		dc.SetRGB(config.ConnectionLineColor[0], config.ConnectionLineColor[1], config.ConnectionLineColor[2])
+		dc.DrawStringAnchored(e.number, (x-tocNumberGap)*k, y*k, 1, 1)
+		dc.SetRGB(textColor[0], textColor[1], textColor[2])
+		dc.DrawStringAnchored(e.text, x*k, y*k, 0, 1)
+		y += lineHeight + tocLineGap
+	}
+
+	return dc.EncodePNG(w)
+}
+
+// tocTextX 返回某层条目文本的起始位置（未缩放）。下一层的编号从上一层文本的起始位置开始，形成缩进。
+func tocTextX(level int, numberWidths []float64) float64 {
+	x := tocPadding
+	for l := 1; l <= level; l++ {
+		x += numberWidths[l] + tocNumberGap
+	}
+	return x
+}
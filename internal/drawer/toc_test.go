@@ -0,0 +1,54 @@
+package drawer
+
+import (
+	"bytes"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestTOCEntries(t *testing.T) {
+	root := &types.Node{Text: "Project", Children: []*types.Node{
+		{Text: "Plan", Children: []*types.Node{
+			{Text: "Goals", Children: []*types.Node{{Text: "Too deep"}}},
+			{Text: "Risks"},
+		}},
+		{Text: "Build"},
+	}}
+
+	want := []tocEntry{
+		{number: "1", text: "Plan", level: 1},
+		{number: "1.1", text: "Goals", level: 2},
+		{number: "1.2", text: "Risks", level: 2},
+		{number: "2", text: "Build", level: 1},
+	}
+	if got := tocEntries(root); !reflect.DeepEqual(got, want) {
+		t.Fatalf("tocEntries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDrawTOC(t *testing.T) {
+	small := &types.Node{Text: "Project", Children: []*types.Node{{Text: "Plan"}}}
+	large := &types.Node{Text: "Project", Children: []*types.Node{
+		{Text: "Plan", Children: []*types.Node{{Text: "Goals"}, {Text: "Risks"}}},
+		{Text: "Build"},
+	}}
+
+	heights := make([]int, 0, 2)
+	for _, root := range []*types.Node{small, large} {
+		var buf bytes.Buffer
+		if err := DrawTOC(root, &buf, WithTheme("dark")); err != nil {
+			t.Fatalf("DrawTOC failed: %v", err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		heights = append(heights, img.Bounds().Dy())
+	}
+	if heights[1] <= heights[0] {
+		t.Fatalf("expected one row per listed branch, got heights %v", heights)
+	}
+}
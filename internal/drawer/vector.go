@@ -0,0 +1,250 @@
+package drawer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// vectorSurface 矢量输出格式（PDF、SVG）的绘制面
+type vectorSurface interface {
+	canvas
+	setFont(font *vectorFont, size float64)
+	writeTo(w io.Writer, width, height float64) error
+}
+
+// drawVector 以 1 倍缩放（一个布局单位对应一个输出单位）把导图绘制到矢量绘制面
+func drawVector(rootNode *types.Node, w io.Writer, options []Option, surface func(opts drawOptions, height float64) vectorSurface) error {
+	r := NewRenderer(options...)
+	if r.opts.err != nil {
+		return r.opts.err
+	}
+	rootNode, err := r.focusRoot(rootNode)
+	if err != nil {
+		return err
+	}
+	l := r.layout(rootNode)
+	config := l.config
+	config.Scale = 1 // 矢量输出无需放大
+	width, height := l.size()
+
+	vs := surface(r.opts, height)
+	if !config.skeleton {
+		font, err := loadVectorFont()
+		if err != nil {
+			return fmt.Errorf("failed to load font: %w", err)
+		}
+		vs.setFont(font, config.FontSize)
+	}
+
+	// 绘制背景，透明背景时省略
+	if !r.opts.transparent {
+		vs.SetRGB(config.BackgroundColor[0], config.BackgroundColor[1], config.BackgroundColor[2])
+		vs.DrawRectangle(0, 0, width, height)
+		vs.Fill()
+	}
+
+	paintMindmap(vs, l)
+
+	return vs.writeTo(w, width, height)
+}
+
+// vectorState 可由 Push/Pop 保存与恢复的绘制状态
+type vectorState struct {
+	matrix    gg.Matrix
+	color     [3]float64
+	alpha     float64
+	lineWidth float64
+}
+
+// pathSegment 路径片段：op 为 'M'、'L'、'C' 或 'Z'，pts 为已变换的坐标
+type pathSegment struct {
+	op  byte
+	pts []gg.Point
+}
+
+// vectorCanvas 实现 canvas 中与输出格式无关的部分：绘制状态、变换与路径构建。
+// 坐标系与 gg 一致（原点在左上角，y 轴向下）；具体格式负责填充、描边与文本。
+type vectorCanvas struct {
+	state vectorState
+	stack []vectorState
+
+	path       []pathSegment // 尚未填充或描边的路径
+	hasCurrent bool
+	start      gg.Point
+
+	font     *vectorFont
+	fontSize float64
+}
+
+func newVectorCanvas() vectorCanvas {
+	return vectorCanvas{state: vectorState{matrix: gg.Identity(), alpha: 1, lineWidth: 1}}
+}
+
+func (vc *vectorCanvas) setFont(font *vectorFont, size float64) {
+	vc.font = font
+	vc.fontSize = size
+}
+
+// scaleFont 临时按比例调整字号，用于绘制上下标
+func (vc *vectorCanvas) scaleFont(k float64) (restore func()) {
+	size := vc.fontSize
+	vc.fontSize = size * k
+	return func() { vc.fontSize = size }
+}
+
+func (vc *vectorCanvas) SetRGB(r, g, b float64) {
+	vc.SetRGBA(r, g, b, 1)
+}
+
+func (vc *vectorCanvas) SetRGBA(r, g, b, a float64) {
+	vc.state.color = [3]float64{r, g, b}
+	vc.state.alpha = a
+}
+
+func (vc *vectorCanvas) SetLineWidth(lineWidth float64) {
+	vc.state.lineWidth = lineWidth
+}
+
+func (vc *vectorCanvas) Push() {
+	vc.stack = append(vc.stack, vc.state)
+}
+
+func (vc *vectorCanvas) Pop() {
+	if len(vc.stack) == 0 {
+		return
+	}
+	vc.state = vc.stack[len(vc.stack)-1]
+	vc.stack = vc.stack[:len(vc.stack)-1]
+}
+
+func (vc *vectorCanvas) Translate(x, y float64) {
+	vc.state.matrix = vc.state.matrix.Translate(x, y)
+}
+
+func (vc *vectorCanvas) transform(x, y float64) gg.Point {
+	tx, ty := vc.state.matrix.TransformPoint(x, y)
+	return gg.Point{X: tx, Y: ty}
+}
+
+func (vc *vectorCanvas) NewSubPath() {
+	vc.hasCurrent = false
+}
+
+func (vc *vectorCanvas) MoveTo(x, y float64) {
+	p := vc.transform(x, y)
+	vc.path = append(vc.path, pathSegment{op: 'M', pts: []gg.Point{p}})
+	vc.start, vc.hasCurrent = p, true
+}
+
+func (vc *vectorCanvas) LineTo(x, y float64) {
+	if !vc.hasCurrent {
+		vc.MoveTo(x, y)
+		return
+	}
+	vc.path = append(vc.path, pathSegment{op: 'L', pts: []gg.Point{vc.transform(x, y)}})
+}
+
+func (vc *vectorCanvas) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if !vc.hasCurrent {
+		vc.MoveTo(x1, y1)
+	}
+	vc.path = append(vc.path, pathSegment{op: 'C', pts: []gg.Point{
+		vc.transform(x1, y1), vc.transform(x2, y2), vc.transform(x3, y3),
+	}})
+}
+
+func (vc *vectorCanvas) ClosePath() {
+	if vc.hasCurrent {
+		vc.path = append(vc.path, pathSegment{op: 'Z'})
+	}
+}
+
+// DrawArc 与 gg 一致：存在当前点时以直线连接到圆弧起点
+func (vc *vectorCanvas) DrawArc(x, y, r, angle1, angle2 float64) {
+	vc.drawEllipticalArc(x, y, r, r, angle1, angle2)
+}
+
+func (vc *vectorCanvas) DrawCircle(x, y, r float64) {
+	vc.DrawEllipse(x, y, r, r)
+}
+
+func (vc *vectorCanvas) DrawEllipse(x, y, rx, ry float64) {
+	vc.NewSubPath()
+	vc.drawEllipticalArc(x, y, rx, ry, 0, 2*math.Pi)
+	vc.ClosePath()
+}
+
+func (vc *vectorCanvas) DrawRectangle(x, y, w, h float64) {
+	vc.NewSubPath()
+	vc.MoveTo(x, y)
+	vc.LineTo(x+w, y)
+	vc.LineTo(x+w, y+h)
+	vc.LineTo(x, y+h)
+	vc.ClosePath()
+}
+
+// drawEllipticalArc 以不超过 90° 的三次贝塞尔曲线段逼近椭圆弧
+func (vc *vectorCanvas) drawEllipticalArc(x, y, rx, ry, angle1, angle2 float64) {
+	segments := int(math.Ceil(math.Abs(angle2-angle1) / (math.Pi / 2)))
+	if segments < 1 {
+		segments = 1
+	}
+	step := (angle2 - angle1) / float64(segments)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+
+	x0, y0 := x+rx*math.Cos(angle1), y+ry*math.Sin(angle1)
+	if vc.hasCurrent {
+		vc.LineTo(x0, y0)
+	} else {
+		vc.MoveTo(x0, y0)
+	}
+	for i := 0; i < segments; i++ {
+		a1 := angle1 + float64(i)*step
+		a2 := a1 + step
+		cos1, sin1 := math.Cos(a1), math.Sin(a1)
+		cos2, sin2 := math.Cos(a2), math.Sin(a2)
+		vc.CubicTo(
+			x+rx*(cos1-k*sin1), y+ry*(sin1+k*cos1),
+			x+rx*(cos2+k*sin2), y+ry*(sin2-k*cos2),
+			x+rx*cos2, y+ry*sin2,
+		)
+	}
+}
+
+// takePath 返回并清空待绘制的路径
+func (vc *vectorCanvas) takePath() []pathSegment {
+	path := vc.path
+	vc.path = nil
+	vc.hasCurrent = false
+	return path
+}
+
+// MeasureString 返回文本宽度与字体高度，字体高度与 gg 的约定相同（字号 × 72/96）
+func (vc *vectorCanvas) MeasureString(s string) (w, h float64) {
+	if vc.font == nil {
+		return 0, 0
+	}
+	return vc.font.measure(s, vc.fontSize), vc.fontSize * 72 / 96
+}
+
+// textOrigin 返回锚定文本的基线起点（已变换）
+func (vc *vectorCanvas) textOrigin(s string, x, y, ax, ay float64) gg.Point {
+	w, h := vc.MeasureString(s)
+	return vc.transform(x-ax*w, y+ay*h)
+}
+
+// formatNum 以最多三位小数的紧凑形式格式化数值
+func formatNum(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
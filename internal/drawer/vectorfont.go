@@ -0,0 +1,294 @@
+package drawer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// vectorFont 矢量输出（PDF、SVG）使用的内嵌 TrueType 字体，提供字形度量与子集化
+type vectorFont struct {
+	data       []byte
+	font       *truetype.Font
+	unitsPerEm int
+	numGlyphs  int
+}
+
+var (
+	vectorFontOnce sync.Once
+	vectorFontVal  *vectorFont
+	vectorFontErr  error
+)
+
+// loadVectorFont 解析内嵌的中文字体，结果在进程内复用
+func loadVectorFont() (*vectorFont, error) {
+	vectorFontOnce.Do(func() {
+		for _, ef := range embeddedFonts {
+			if len(ef.Data) == 0 {
+				continue
+			}
+			f, err := truetype.Parse(ef.Data)
+			if err != nil {
+				vectorFontErr = fmt.Errorf("failed to parse font %s: %w", ef.Name, err)
+				continue
+			}
+			tables, err := readFontTables(ef.Data)
+			if err != nil {
+				vectorFontErr = fmt.Errorf("failed to read font %s: %w", ef.Name, err)
+				continue
+			}
+			maxp, ok := tables["maxp"]
+			if !ok || len(maxp) < 6 {
+				vectorFontErr = fmt.Errorf("font %s has no maxp table", ef.Name)
+				continue
+			}
+			vectorFontVal = &vectorFont{
+				data:       ef.Data,
+				font:       f,
+				unitsPerEm: int(f.FUnitsPerEm()),
+				numGlyphs:  int(binary.BigEndian.Uint16(maxp[4:6])),
+			}
+			vectorFontErr = nil
+			return
+		}
+		if vectorFontErr == nil {
+			vectorFontErr = errors.New("no embedded font available")
+		}
+	})
+	return vectorFontVal, vectorFontErr
+}
+
+// glyph 返回字符对应的字形编号
+func (f *vectorFont) glyph(r rune) uint16 {
+	return uint16(f.font.Index(r))
+}
+
+// advance 返回字形的前进宽度（字体单位）
+func (f *vectorFont) advance(gid uint16) int {
+	return int(f.font.HMetric(fixed.Int26_6(f.unitsPerEm), truetype.Index(gid)).AdvanceWidth)
+}
+
+// measure 返回文本在指定字号下的宽度
+func (f *vectorFont) measure(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		units += f.advance(f.glyph(r))
+	}
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// toPDFUnits 将字体单位换算为 PDF 字形空间的 1/1000 em
+func (f *vectorFont) toPDFUnits(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+// readFontTables 读取 TrueType 文件的表目录
+func readFontTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errors.New("font data too short")
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:6]))
+	if len(data) < 12+16*numTables {
+		return nil, errors.New("truncated table directory")
+	}
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		rec := data[12+16*i:]
+		tag := string(rec[0:4])
+		offset := int(binary.BigEndian.Uint32(rec[8:12]))
+		length := int(binary.BigEndian.Uint32(rec[12:16]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("table %q out of range", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// subsetTables 子集化时保留的表；GSUB、vmtx 等排版表在 PDF 中用不到
+var subsetTables = []string{"OS/2", "cmap", "cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "name", "post", "prep"}
+
+// subset 生成仅包含已用字形轮廓的字体文件。字形编号保持不变，
+// 未使用的字形置为空轮廓，从而可以继续使用 Identity 映射。
+func (f *vectorFont) subset(used map[uint16]bool) ([]byte, error) {
+	tables, err := readFontTables(f.data)
+	if err != nil {
+		return nil, err
+	}
+	head, glyf, loca := tables["head"], tables["glyf"], tables["loca"]
+	if len(head) < 54 || glyf == nil || loca == nil {
+		return nil, errors.New("font is missing glyf outlines")
+	}
+	longLoca := binary.BigEndian.Uint16(head[50:52]) == 1
+
+	glyphRange := func(gid int) (int, int) {
+		if longLoca {
+			return int(binary.BigEndian.Uint32(loca[gid*4:])), int(binary.BigEndian.Uint32(loca[gid*4+4:]))
+		}
+		return int(binary.BigEndian.Uint16(loca[gid*2:])) * 2, int(binary.BigEndian.Uint16(loca[gid*2+2:])) * 2
+	}
+	locaEntries := len(loca) / 2
+	if longLoca {
+		locaEntries = len(loca) / 4
+	}
+	numGlyphs := min(f.numGlyphs, locaEntries-1)
+
+	// 收集字形，包括复合字形引用的组件
+	keep := make(map[int]bool, len(used)+1)
+	queue := []int{0}
+	for gid := range used {
+		queue = append(queue, int(gid))
+	}
+	for len(queue) > 0 {
+		gid := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if gid >= numGlyphs || keep[gid] {
+			continue
+		}
+		keep[gid] = true
+		start, end := glyphRange(gid)
+		if start >= end || end > len(glyf) {
+			continue
+		}
+		for _, c := range compositeComponents(glyf[start:end]) {
+			if !keep[c] {
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	var newGlyf bytes.Buffer
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for gid := 0; gid < numGlyphs; gid++ {
+		binary.BigEndian.PutUint32(newLoca[gid*4:], uint32(newGlyf.Len()))
+		if !keep[gid] {
+			continue
+		}
+		start, end := glyphRange(gid)
+		if start < end && end <= len(glyf) {
+			newGlyf.Write(glyf[start:end])
+			for newGlyf.Len()%4 != 0 {
+				newGlyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[numGlyphs*4:], uint32(newGlyf.Len()))
+
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint32(newHead[8:12], 0)  // checkSumAdjustment，写出后重新计算
+	binary.BigEndian.PutUint16(newHead[50:52], 1) // indexToLocFormat: long
+
+	out := map[string][]byte{"glyf": newGlyf.Bytes(), "loca": newLoca, "head": newHead}
+	for _, tag := range subsetTables {
+		if _, ok := out[tag]; !ok && tables[tag] != nil {
+			out[tag] = tables[tag]
+		}
+	}
+	return writeFontTables(out), nil
+}
+
+// compositeComponents 返回复合字形引用的组件字形编号
+func compositeComponents(glyph []byte) []int {
+	if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph[0:2])) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+	var components []int
+	p := 10
+	for p+4 <= len(glyph) {
+		flags := binary.BigEndian.Uint16(glyph[p:])
+		components = append(components, int(binary.BigEndian.Uint16(glyph[p+2:])))
+		p += 4
+		if flags&argsAreWords != 0 {
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			p += 2
+		case flags&haveXYScale != 0:
+			p += 4
+		case flags&haveTwoByTwo != 0:
+			p += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return components
+}
+
+// writeFontTables 按 TrueType 格式写出表目录与表数据，并填写校验和
+func writeFontTables(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	var buf bytes.Buffer
+	header := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(header[0:], 0x00010000)
+	binary.BigEndian.PutUint16(header[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[10:], uint16(numTables*16-searchRange))
+
+	offset := len(header)
+	headOffset := -1
+	var body bytes.Buffer
+	for i, tag := range tags {
+		data := tables[tag]
+		rec := header[12+16*i:]
+		copy(rec[0:4], tag)
+		binary.BigEndian.PutUint32(rec[4:], fontChecksum(data))
+		binary.BigEndian.PutUint32(rec[8:], uint32(offset+body.Len()))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(data)))
+		if tag == "head" {
+			headOffset = offset + body.Len()
+		}
+		body.Write(data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+	buf.Write(header)
+	buf.Write(body.Bytes())
+
+	out := buf.Bytes()
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-fontChecksum(out))
+	}
+	return out
+}
+
+// fontChecksum 计算 TrueType 表校验和（按 32 位大端字求和）
+func fontChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,301 @@
+// Package metrics is a minimal Prometheus-compatible metrics registry:
+// counters, histograms and summaries (sum and count only) exposed in the
+// Prometheus text format. It covers what the HTTP server reports without
+// pulling in the Prometheus client library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds the metrics exposed by its ServeHTTP method.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// metric 可写出为文本格式的一组指标
+type metric interface {
+	write(w io.Writer) error
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	r.metrics = append(r.metrics, m)
+	r.mu.Unlock()
+}
+
+// WriteText writes every metric in the Prometheus text exposition format,
+// in registration order.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP serves the metrics for a Prometheus scrape.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = r.WriteText(w)
+}
+
+// desc 指标的名称、说明、类型与标签名
+type desc struct {
+	name, help, kind string
+	labels           []string
+}
+
+func (d desc) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.kind)
+	return err
+}
+
+// CounterVec is a counter partitioned by label values.
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*series
+}
+
+// series 一组标签值及其计数
+type series struct {
+	labels []string
+	value  float64
+}
+
+// NewCounterVec registers a counter with the given label names.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, kind: "counter", labels: labels}, values: map[string]*series{}}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the series with the given label values, which must match
+// the label names in number and order.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v to the series with the given label values.
+func (c *CounterVec) Add(v float64, values ...string) {
+	if len(values) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", c.name, len(c.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &series{labels: append([]string(nil), values...)}
+		c.values[key] = s
+	}
+	s.value += v
+}
+
+// Value returns the current value of the series with the given label values.
+func (c *CounterVec) Value(values ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.values[strings.Join(values, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	if err := c.writeHeader(w); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	if len(c.labels) == 0 && len(keys) == 0 {
+		// 无标签的计数器在首次计数前也输出 0
+		fmt.Fprintf(&b, "%s 0\n", c.name)
+	}
+	for _, k := range keys {
+		s := c.values[k]
+		fmt.Fprintf(&b, "%s%s %s\n", c.name, labelPairs(c.labels, s.labels), formatFloat(s.value))
+	}
+	c.mu.Unlock()
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Counter is a counter without labels.
+type Counter struct {
+	vec *CounterVec
+}
+
+// NewCounter registers a counter without labels.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return &Counter{vec: r.NewCounterVec(name, help)}
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() { c.vec.Inc() }
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() float64 { return c.vec.Value() }
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	desc
+	upper []float64
+
+	mu     sync.Mutex
+	counts []uint64 // 每个桶（不含 +Inf）内的观测数，非累计
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds,
+// which must be sorted in increasing order. The +Inf bucket is implicit.
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic("metrics: histogram buckets for " + name + " are not sorted")
+	}
+	h := &Histogram{
+		desc:   desc{name: name, help: help, kind: "histogram"},
+		upper:  append([]float64(nil), buckets...),
+		counts: make([]uint64, len(buckets)),
+	}
+	r.register(h)
+	return h
+}
+
+// Observe records one observation.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v) // 第一个 >= v 的上界
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	if err := h.writeHeader(w); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	var b strings.Builder
+	var cumulative uint64
+	for i, upper := range h.upper {
+		cumulative += h.counts[i]
+		fmt.Fprintf(&b, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(upper), cumulative)
+	}
+	fmt.Fprintf(&b, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	fmt.Fprintf(&b, "%s_sum %s\n%s_count %d\n", h.name, formatFloat(h.sum), h.name, h.count)
+	h.mu.Unlock()
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Summary tracks the count and sum of observations. Quantiles are not
+// computed; rates and averages can be derived from the two series.
+type Summary struct {
+	desc
+	mu    sync.Mutex
+	count uint64
+	sum   float64
+}
+
+// NewSummary registers a summary.
+func (r *Registry) NewSummary(name, help string) *Summary {
+	s := &Summary{desc: desc{name: name, help: help, kind: "summary"}}
+	r.register(s)
+	return s
+}
+
+// Observe records one observation.
+func (s *Summary) Observe(v float64) {
+	s.mu.Lock()
+	s.count++
+	s.sum += v
+	s.mu.Unlock()
+}
+
+// Sum returns the sum of observations.
+func (s *Summary) Sum() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sum
+}
+
+func (s *Summary) write(w io.Writer) error {
+	if err := s.writeHeader(w); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	line := fmt.Sprintf("%s_sum %s\n%s_count %d\n", s.name, formatFloat(s.sum), s.name, s.count)
+	s.mu.Unlock()
+	_, err := io.WriteString(w, line)
+	return err
+}
+
+// labelPairs 返回 {name="value",...} 形式的标签，没有标签时返回空串
+func labelPairs(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+// formatFloat 按文本格式的约定输出数值
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	reg := NewRegistry()
+	renders := reg.NewCounterVec("renders_total", "Renders.", "theme", "status")
+	duration := reg.NewHistogram("render_seconds", "Render time.", []float64{0.1, 1})
+	size := reg.NewSummary("render_bytes", "Output size.")
+	errs := reg.NewCounter("errors_total", "Errors.\nSecond line.")
+
+	renders.Inc("dark", "ok")
+	renders.Inc("default", "ok")
+	renders.Add(2, "dark", "ok")
+	renders.Inc(`a"b`, "error")
+	duration.Observe(0.05)
+	duration.Observe(0.1)
+	duration.Observe(3)
+	size.Observe(100)
+	size.Observe(50)
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP renders_total Renders.
+# TYPE renders_total counter
+renders_total{theme="a\"b",status="error"} 1
+renders_total{theme="dark",status="ok"} 3
+renders_total{theme="default",status="ok"} 1
+# HELP render_seconds Render time.
+# TYPE render_seconds histogram
+render_seconds_bucket{le="0.1"} 2
+render_seconds_bucket{le="1"} 2
+render_seconds_bucket{le="+Inf"} 3
+render_seconds_sum 3.15
+render_seconds_count 3
+# HELP render_bytes Output size.
+# TYPE render_bytes summary
+render_bytes_sum 150
+render_bytes_count 2
+# HELP errors_total Errors.\nSecond line.
+# TYPE errors_total counter
+errors_total 0
+`
+	if b.String() != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	errs.Inc()
+	if errs.Value() != 1 || renders.Value("dark", "ok") != 3 || duration.Count() != 3 || size.Sum() != 150 {
+		t.Fatal("unexpected metric values")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("up_total", "Up.").Inc()
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "up_total 1\n") {
+		t.Fatalf("expected the counter in the body, got %s", rec.Body.String())
+	}
+}
+
+func TestCounterVecLabelCount(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a missing label value")
+		}
+	}()
+	NewRegistry().NewCounterVec("c_total", "C.", "a", "b").Inc("x")
+}
@@ -0,0 +1,41 @@
+package parser
+
+import "strings"
+
+// stripComments 去除输入中的注释，保持行数不变以便报错行号与原文一致：
+// 以 // 或 # 开头的整行注释替换为空行，正文后以空白分隔的 "// 备注" 被去除。
+// 行首的 \# 与 \// 以及正文中的 \// 表示字面量，去掉反斜杠后保留。
+func stripComments(input string) string {
+	lines := strings.Split(input, "\n")
+	for i, line := range lines {
+		lines[i] = stripLineComment(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripLineComment 去除单行中的注释，保留行首缩进
+func stripLineComment(line string) string {
+	text := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(text)]
+	if strings.HasPrefix(text, "//") || strings.HasPrefix(text, "#") {
+		return ""
+	}
+	if strings.HasPrefix(text, `\#`) || strings.HasPrefix(text, `\//`) {
+		text = text[1:]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if strings.HasPrefix(text[i:], `\//`) {
+			b.WriteString("//")
+			i += 2
+			continue
+		}
+		// 注释需与正文以空白分隔，避免截断 https:// 等地址
+		if strings.HasPrefix(text[i:], "//") && i > 0 && (text[i-1] == ' ' || text[i-1] == '\t') {
+			return indent + strings.TrimRight(b.String(), " \t")
+		}
+		b.WriteByte(text[i])
+	}
+	return indent + b.String()
+}
@@ -0,0 +1,47 @@
+package parser
+
+import "testing"
+
+func TestStripLineComment(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"// header comment", ""},
+		{"  # indented comment", ""},
+		{"  Child // note", "  Child"},
+		{"Child\t// note", "Child"},
+		{"Docs https://example.com/a", "Docs https://example.com/a"},
+		{`\# not a comment`, "# not a comment"},
+		{`\// literal`, "// literal"},
+		{`  a \// b // note`, "  a // b"},
+		{"C#", "C#"},
+	}
+	for _, tt := range tests {
+		if got := stripLineComment(tt.line); got != tt.want {
+			t.Errorf("stripLineComment(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	input := "# Planning notes\nRoot // the topic\n  // skipped\n  A\n    # skipped too\n    A1 // note\n  \\#1 priority\n"
+	root, err := Parse(input, ParseComments())
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if root.Text != "Root" || len(root.Children) != 2 {
+		t.Fatalf("expected Root with 2 children, got %+v", root)
+	}
+	if a := root.Children[0]; a.Text != "A" || len(a.Children) != 1 || a.Children[0].Text != "A1" {
+		t.Errorf("unexpected first child: %+v", a)
+	}
+	if got := root.Children[1].Text; got != "#1 priority" {
+		t.Errorf("expected escaped hash to stay literal, got %q", got)
+	}
+
+	// 未启用时注释行作为普通节点，两行顶格文本构成多个根节点
+	if _, err := Parse(input); err == nil {
+		t.Fatalf("expected comment lines to be parsed as nodes without ParseComments")
+	}
+}
@@ -0,0 +1,23 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// ParseFormat 按输入格式解析内容：text（缩进文本或 Mermaid，默认）、opml、json。
+// options 仅作用于文本格式
+func ParseFormat(content []byte, format string, options ...Option) (*types.Node, error) {
+	switch format {
+	case "", "text":
+		return Parse(string(content), options...)
+	case "opml":
+		return ParseOPML(bytes.NewReader(content))
+	case "json":
+		return ParseJSON(bytes.NewReader(content))
+	default:
+		return nil, fmt.Errorf("unknown input format %q (expected text, opml or json)", format)
+	}
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parseIconDirective 解析 Mermaid 的图标行，如 "::icon(fa fa-rocket)"，返回括号内的图标类名。
+// 图标行修饰其上一行的节点，本身不产生节点。
+func parseIconDirective(line string) (string, bool) {
+	if !strings.HasPrefix(line, "::icon(") || !strings.HasSuffix(line, ")") {
+		return "", false
+	}
+	icon := strings.TrimSpace(line[len("::icon(") : len(line)-1])
+	return icon, icon != ""
+}
+
+// parseIcon 解析行首的 emoji，如 "🚀 发布"。emoji 需与正文以空白分隔且正文非空，
+// 否则原样返回文本，仅含 emoji 的节点仍作为文本显示。
+func parseIcon(text string) (string, string) {
+	n := emojiPrefixLen(text)
+	if n == 0 || n == len(text) {
+		return text, ""
+	}
+	if r, _ := utf8.DecodeRuneInString(text[n:]); r != ' ' && r != '\t' {
+		return text, ""
+	}
+	rest := strings.TrimLeft(text[n:], " \t")
+	if rest == "" {
+		return text, ""
+	}
+	return rest, text[:n]
+}
+
+// emojiPrefixLen 返回文本开头 emoji 序列的字节长度，包括其后的变体选择符、肤色修饰符
+// 与零宽连接的后续字符；开头不是 emoji 时返回 0
+func emojiPrefixLen(text string) int {
+	r, size := utf8.DecodeRuneInString(text)
+	if !isEmoji(r) {
+		return 0
+	}
+	n := size
+	for n < len(text) {
+		r, size = utf8.DecodeRuneInString(text[n:])
+		switch {
+		case r == 0xFE0E || r == 0xFE0F || r == 0x20E3 || (r >= 0x1F3FB && r <= 0x1F3FF):
+			n += size
+		case r == 0x200D:
+			next, nextSize := utf8.DecodeRuneInString(text[n+size:])
+			if !isEmoji(next) {
+				return n
+			}
+			n += size + nextSize
+		case r >= 0x1F1E6 && r <= 0x1F1FF && n == size && r0IsRegional(text):
+			// 两个区域指示符组成一面旗帜
+			n += size
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// r0IsRegional 判断文本的首字符是否为区域指示符
+func r0IsRegional(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isEmoji 判断字符是否可作为图标：杂项符号、装饰符号与补充平面的 emoji 区段中的符号
+func isEmoji(r rune) bool {
+	if !unicode.Is(unicode.So, r) {
+		return false
+	}
+	return (r >= 0x2300 && r <= 0x23FF) || (r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2B00 && r <= 0x2BFF) || (r >= 0x1F000 && r <= 0x1FAFF)
+}
@@ -0,0 +1,61 @@
+package parser
+
+import "testing"
+
+func TestParseIcon(t *testing.T) {
+	tests := []struct {
+		input, wantText, wantIcon string
+	}{
+		{"🚀 Launch", "Launch", "🚀"},
+		{"⭐️ Favorite", "Favorite", "⭐️"},
+		{"👩‍💻 Dev", "Dev", "👩‍💻"},
+		{"🇨🇳 China", "China", "🇨🇳"},
+		{"🚀Launch", "🚀Launch", ""},
+		{"🚀", "🚀", ""},
+		{"Launch 🚀", "Launch 🚀", ""},
+		{"→ next", "→ next", ""},
+	}
+	for _, tt := range tests {
+		text, icon := parseIcon(tt.input)
+		if text != tt.wantText || icon != tt.wantIcon {
+			t.Errorf("parseIcon(%q) = %q, %q; want %q, %q", tt.input, text, icon, tt.wantText, tt.wantIcon)
+		}
+	}
+}
+
+func TestParseIconIntoNode(t *testing.T) {
+	root, err := Parse("🗺 Plan\n  - 🚀 Launch {owner=Ann}\n  Review")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if root.Text != "Plan" || root.Icon != "🗺" {
+		t.Errorf("expected root icon to be split off, got %q / %q", root.Icon, root.Text)
+	}
+	if child := root.Children[0]; child.Text != "Launch" || child.Icon != "🚀" || child.Meta["owner"] != "Ann" {
+		t.Errorf("unexpected child %+v", child)
+	}
+	if root.Children[1].Icon != "" {
+		t.Errorf("expected no icon on plain node, got %q", root.Children[1].Icon)
+	}
+}
+
+func TestParseMermaidIconDirective(t *testing.T) {
+	input := "mindmap\n  root((Project))\n    Launch\n    ::icon(fa fa-rocket)\n    Review\n      ::icon(mdi mdi-book)\n"
+	root, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("expected icon lines not to create nodes, got %d children", len(root.Children))
+	}
+	if got := root.Children[0].Icon; got != "fa fa-rocket" {
+		t.Errorf("expected fa icon on Launch, got %q", got)
+	}
+	if got := root.Children[1].Icon; got != "mdi mdi-book" {
+		t.Errorf("expected mdi icon on Review, got %q", got)
+	}
+
+	if _, err := Parse("mindmap\n  ::icon(fa fa-rocket)\n  root\n"); err == nil {
+		t.Error("expected an icon before any node to be rejected")
+	}
+}
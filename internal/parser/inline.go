@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// inlineMarkers 行内标记及其对应的样式
+var inlineMarkers = []struct {
+	delim   string
+	kind    types.SpanKind
+	noSpace bool // 为 true 时标记内不允许空白，避免把 "a ~ b ~ c" 这类文本误认为标记
+}{
+	{"==", types.SpanHighlight, false},
+	{"^", types.SpanSuperscript, true},
+	{"~", types.SpanSubscript, true},
+}
+
+// parseInlineMarkup 移除文本中成对的行内标记（如 ==高亮==、x^2^、H~2~O），返回清理后的文本
+// 和以 rune 偏移表示的样式区间。未闭合的标记按原样保留。
+func parseInlineMarkup(text string) (string, []types.TextSpan) {
+	var out strings.Builder
+	var spans []types.TextSpan
+	runeCount := 0
+
+	for i := 0; i < len(text); {
+		matched := false
+		for _, marker := range inlineMarkers {
+			if !strings.HasPrefix(text[i:], marker.delim) {
+				continue
+			}
+			rest := text[i+len(marker.delim):]
+			end := strings.Index(rest, marker.delim)
+			if end <= 0 {
+				continue
+			}
+			inner := rest[:end]
+			if marker.noSpace && strings.IndexFunc(inner, unicode.IsSpace) >= 0 {
+				continue
+			}
+			out.WriteString(inner)
+			innerRunes := len([]rune(inner))
+			spans = append(spans, types.TextSpan{Start: runeCount, End: runeCount + innerRunes, Kind: marker.kind})
+			runeCount += innerRunes
+			i += len(marker.delim)*2 + end
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		out.WriteRune(r)
+		runeCount++
+		i += size
+	}
+
+	return out.String(), spans
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestParseInlineMarkup(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantText  string
+		wantSpans []types.TextSpan
+	}{
+		{
+			name:     "highlight",
+			input:    "Ship ==before Friday== please",
+			wantText: "Ship before Friday please",
+			wantSpans: []types.TextSpan{
+				{Start: 5, End: 18, Kind: types.SpanHighlight},
+			},
+		},
+		{
+			name:     "cjk offsets are runes",
+			input:    "核心==专注==习惯",
+			wantText: "核心专注习惯",
+			wantSpans: []types.TextSpan{
+				{Start: 2, End: 4, Kind: types.SpanHighlight},
+			},
+		},
+		{
+			name:     "superscript",
+			input:    "x^2^ + y^2^",
+			wantText: "x2 + y2",
+			wantSpans: []types.TextSpan{
+				{Start: 1, End: 2, Kind: types.SpanSuperscript},
+				{Start: 6, End: 7, Kind: types.SpanSuperscript},
+			},
+		},
+		{
+			name:     "subscript",
+			input:    "H~2~O",
+			wantText: "H2O",
+			wantSpans: []types.TextSpan{
+				{Start: 1, End: 2, Kind: types.SpanSubscript},
+			},
+		},
+		{
+			name:     "script markers must not contain spaces",
+			input:    "a ~ b ~ c and 2^n",
+			wantText: "a ~ b ~ c and 2^n",
+		},
+		{
+			name:     "unclosed marker is literal",
+			input:    "a == b",
+			wantText: "a == b",
+		},
+		{
+			name:     "empty marker is literal",
+			input:    "a ==== b",
+			wantText: "a ==== b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, spans := parseInlineMarkup(tt.input)
+			if text != tt.wantText {
+				t.Errorf("expected text %q, got %q", tt.wantText, text)
+			}
+			if !reflect.DeepEqual(spans, tt.wantSpans) {
+				t.Errorf("expected spans %+v, got %+v", tt.wantSpans, spans)
+			}
+		})
+	}
+}
+
+func TestParseHighlightIntoNode(t *testing.T) {
+	root, err := Parse("Root\n  Deadline ==Friday==")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	child := root.Children[0]
+	if child.Text != "Deadline Friday" {
+		t.Errorf("expected markers to be stripped, got %q", child.Text)
+	}
+	if len(child.Spans) != 1 || child.Spans[0].Kind != types.SpanHighlight {
+		t.Fatalf("expected one highlight span, got %+v", child.Spans)
+	}
+}
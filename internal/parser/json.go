@@ -0,0 +1,22 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// ParseJSON 读取由 types.Node JSON 编码得到的节点树
+func ParseJSON(r io.Reader) (*types.Node, error) {
+	var root *types.Node
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if root == nil {
+		return nil, errors.New("JSON document has no root node")
+	}
+	return root, nil
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONRoundTrip(t *testing.T) {
+	root, err := Parse("Root\n  A ==hot==\n    A1\n  B")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	decoded, err := ParseJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	if decoded.Text != "Root" || len(decoded.Children) != 2 {
+		t.Fatalf("unexpected tree: %+v", decoded)
+	}
+	if got := decoded.Children[0]; got.Text != "A hot" || len(got.Spans) != 1 || len(got.Children) != 1 {
+		t.Errorf("unexpected first child: %+v", got)
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	for _, input := range []string{`{"text": `, `null`} {
+		if _, err := ParseJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// markdownLink 匹配 Markdown 链接 [标签](地址)，地址中不能包含空白与括号
+	markdownLink = regexp.MustCompile(`\[([^\[\]]+)\]\(([^()\s]+)\)`)
+	// bareURL 匹配文本中的 http(s) 地址
+	bareURL = regexp.MustCompile(`https?://[^\s<>()\[\]{}"]+`)
+)
+
+// parseLink 将文本中的 Markdown 链接替换为其标签，返回第一个链接的地址。
+// 没有 Markdown 链接且 bare 为 true 时，文本中的第一个 http(s) 地址作为链接，文本保持不变。
+func parseLink(text string, bare bool) (string, string) {
+	if m := markdownLink.FindStringSubmatch(text); m != nil {
+		return markdownLink.ReplaceAllString(text, "$1"), m[2]
+	}
+	if bare {
+		if url := bareURL.FindString(text); url != "" {
+			// 句末的标点不属于地址
+			return text, strings.TrimRight(url, ".,;:!?'")
+		}
+	}
+	return text, ""
+}
@@ -0,0 +1,52 @@
+package parser
+
+import "testing"
+
+func TestParseLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		bare     bool
+		wantText string
+		wantLink string
+	}{
+		{name: "markdown link", input: "[Docs](https://example.com/docs)", wantText: "Docs", wantLink: "https://example.com/docs"},
+		{name: "link inside text", input: "Read [the guide](https://example.com/g) first", wantText: "Read the guide first", wantLink: "https://example.com/g"},
+		{name: "first of several links", input: "[A](https://a.example) and [B](https://b.example)", wantText: "A and B", wantLink: "https://a.example"},
+		{name: "bare URL ignored by default", input: "See https://example.com", wantText: "See https://example.com"},
+		{name: "bare URL", input: "See https://example.com/x.", bare: true, wantText: "See https://example.com/x.", wantLink: "https://example.com/x"},
+		{name: "markdown wins over bare", input: "[Docs](/docs) https://example.com", bare: true, wantText: "Docs https://example.com", wantLink: "/docs"},
+		{name: "brackets without URL", input: "Array [1](two words)", wantText: "Array [1](two words)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, link := parseLink(tt.input, tt.bare)
+			if text != tt.wantText || link != tt.wantLink {
+				t.Errorf("parseLink(%q) = %q, %q; want %q, %q", tt.input, text, link, tt.wantText, tt.wantLink)
+			}
+		})
+	}
+}
+
+func TestParseLinkIntoNode(t *testing.T) {
+	input := "Project\n  [Docs](https://example.com/docs) @1\n  Site https://example.com\n"
+	root, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	docs, site := root.Children[0], root.Children[1]
+	if docs.Text != "Docs" || docs.Link != "https://example.com/docs" {
+		t.Errorf("expected the Markdown link to be extracted, got %q -> %q", docs.Text, docs.Link)
+	}
+	if site.Link != "" {
+		t.Errorf("expected bare URLs to be ignored by default, got %q", site.Link)
+	}
+
+	root, err = Parse(input, ParseBareURLs())
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if site := root.Children[1]; site.Text != "Site https://example.com" || site.Link != "https://example.com" {
+		t.Errorf("expected the bare URL to become the link, got %q -> %q", site.Text, site.Link)
+	}
+}
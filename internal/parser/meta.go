@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"strings"
+)
+
+// parseMeta 解析行尾的元数据块，如 "发布 {owner=Ann; due=2024-05-01}"。
+// 元数据块需与正文以空白分隔，且每一项都是非空键的 key=value；
+// 否则（如 Mermaid 的 id{{六边形}}）原样返回文本。
+func parseMeta(text string) (string, map[string]string) {
+	if !strings.HasSuffix(text, "}") {
+		return text, nil
+	}
+	open := strings.LastIndex(text, "{")
+	if open <= 0 || (text[open-1] != ' ' && text[open-1] != '\t') {
+		return text, nil
+	}
+
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(text[open+1:len(text)-1], ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return text, nil
+		}
+		meta[key] = strings.TrimSpace(value)
+	}
+	if len(meta) == 0 {
+		return text, nil
+	}
+	return strings.TrimRight(text[:open], " \t"), meta
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMeta(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantText string
+		wantMeta map[string]string
+	}{
+		{
+			name:     "multiple pairs",
+			input:    "Launch {owner=Ann; due=2024-05-01; status = in progress}",
+			wantText: "Launch",
+			wantMeta: map[string]string{"owner": "Ann", "due": "2024-05-01", "status": "in progress"},
+		},
+		{
+			name:     "trailing separator and empty value",
+			input:    "Review {owner=Bob;reviewer=;}",
+			wantText: "Review",
+			wantMeta: map[string]string{"owner": "Bob", "reviewer": ""},
+		},
+		{name: "mermaid hexagon is not metadata", input: "id{{Hexagon}}", wantText: "id{{Hexagon}}"},
+		{name: "braces without pairs", input: "Set {a, b}", wantText: "Set {a, b}"},
+		{name: "empty key", input: "Odd {=x}", wantText: "Odd {=x}"},
+		{name: "not trailing", input: "Use {k=v} here", wantText: "Use {k=v} here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, meta := parseMeta(tt.input)
+			if text != tt.wantText {
+				t.Errorf("expected text %q, got %q", tt.wantText, text)
+			}
+			if !reflect.DeepEqual(meta, tt.wantMeta) {
+				t.Errorf("expected meta %v, got %v", tt.wantMeta, meta)
+			}
+		})
+	}
+}
+
+func TestParseMetaIntoNode(t *testing.T) {
+	root, err := Parse("Project\n  Launch @2 {owner=Ann; status=done}\n  Plan")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	child := root.Children[0]
+	if child.Text != "Launch" {
+		t.Errorf("expected metadata and order key to be stripped, got %q", child.Text)
+	}
+	if child.Order == nil || *child.Order != 2 {
+		t.Errorf("expected order key 2, got %v", child.Order)
+	}
+	want := map[string]string{"owner": "Ann", "status": "done"}
+	if !reflect.DeepEqual(child.Meta, want) {
+		t.Errorf("expected meta %v, got %v", want, child.Meta)
+	}
+	if root.Children[1].Meta != nil {
+		t.Errorf("expected no metadata on a plain node, got %v", root.Children[1].Meta)
+	}
+}
@@ -0,0 +1,13 @@
+package parser
+
+import "strings"
+
+// parseNoteLine 解析备注行，如 "> 下周五前完成"。备注行修饰其上一行的节点，本身不产生节点；
+// ">" 需位于行首，其后为空白或行尾
+func parseNoteLine(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, ">")
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
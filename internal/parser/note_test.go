@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseNotes(t *testing.T) {
+	input := "Root\n  Launch\n    > ship before Friday\n    > needs sign-off\n    Docs\n  >no space is text\n  Plain\n  >\n"
+	root, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(root.Children) != 3 {
+		t.Fatalf("expected note lines not to become children, got %d children", len(root.Children))
+	}
+	launch := root.Children[0]
+	if launch.Note != "ship before Friday\nneeds sign-off" {
+		t.Errorf("expected multi-line note, got %q", launch.Note)
+	}
+	if len(launch.Children) != 1 || launch.Children[0].Text != "Docs" {
+		t.Errorf("expected Docs to stay a child of Launch, got %+v", launch.Children)
+	}
+	if got := root.Children[1].Text; got != ">no space is text" {
+		t.Errorf("expected a marker without space to stay text, got %q", got)
+	}
+	if plain := root.Children[2]; plain.Note != "" {
+		t.Errorf("expected an empty note to be ignored, got %q", plain.Note)
+	}
+
+	var parseErr *ParseError
+	if _, err := Parse("> orphan note\nRoot"); !errors.As(err, &parseErr) || parseErr.Line != 1 {
+		t.Fatalf("expected a note before any node to fail on line 1, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Head    struct {
+		Title string `xml:"title"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Note     string        `xml:"_note,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// ParseOPML 将 OPML 大纲（OmniOutliner、Workflowy 等导出格式）解析为节点树。
+// 只有一个顶层 outline 时以其为根节点，否则创建一个以文档标题命名的根节点。
+func ParseOPML(r io.Reader) (*types.Node, error) {
+	var doc opmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+
+	outlines := doc.Body.Outlines
+	if len(outlines) == 0 {
+		return nil, errors.New("OPML document has no outline elements")
+	}
+
+	if len(outlines) == 1 {
+		return convertOPMLOutline(outlines[0]), nil
+	}
+
+	title := strings.TrimSpace(doc.Head.Title)
+	if title == "" {
+		title = "Root"
+	}
+	root := types.NewNode(title)
+	for _, outline := range outlines {
+		root.AddChild(convertOPMLOutline(outline))
+	}
+	return root, nil
+}
+
+func convertOPMLOutline(outline opmlOutline) *types.Node {
+	// _note 属性作为节点备注；text 为空时退回使用 _note 作为文本
+	text := strings.TrimSpace(outline.Text)
+	note := strings.TrimSpace(outline.Note)
+	if text == "" {
+		text, note = note, ""
+	}
+
+	node := types.NewNode(text)
+	node.Note = note
+	for _, child := range outline.Outlines {
+		node.AddChild(convertOPMLOutline(child))
+	}
+	return node
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOPML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Plan</title></head>
+  <body>
+    <outline text="Project">
+      <outline text="Design &amp; Specs">
+        <outline text="" _note="Wireframes"/>
+      </outline>
+      <outline text="Build" _note="After review"/>
+    </outline>
+  </body>
+</opml>`
+
+	root, err := ParseOPML(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if root.Text != "Project" {
+		t.Errorf("expected root 'Project', got %q", root.Text)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(root.Children))
+	}
+	if got := root.Children[0].Text; got != "Design & Specs" {
+		t.Errorf("expected unescaped text, got %q", got)
+	}
+	if got := root.Children[0].Children[0].Text; got != "Wireframes" {
+		t.Errorf("expected _note fallback 'Wireframes', got %q", got)
+	}
+	if got := root.Children[0].Children[0].Note; got != "" {
+		t.Errorf("expected a note used as text not to repeat as note, got %q", got)
+	}
+	if got := root.Children[1].Note; got != "After review" {
+		t.Errorf("expected _note as the node note, got %q", got)
+	}
+}
+
+func TestParseOPMLMultipleTopLevel(t *testing.T) {
+	input := `<opml version="2.0"><head><title>Ideas</title></head><body>
+<outline text="A"/><outline text="B"/></body></opml>`
+
+	root, err := ParseOPML(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if root.Text != "Ideas" || len(root.Children) != 2 {
+		t.Fatalf("expected synthetic root 'Ideas' with 2 children, got %q with %d", root.Text, len(root.Children))
+	}
+}
+
+func TestParseOPMLMalformed(t *testing.T) {
+	_, err := ParseOPML(strings.NewReader(`<opml><body><outline text="A"></body>`))
+	if err == nil || !strings.Contains(err.Error(), "failed to parse OPML") {
+		t.Fatalf("expected wrapped parse error, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseOrderKey 解析行尾的排序键标记，如 "介绍 @2"。标记需与正文以空白分隔，
+// 无法识别时原样返回文本。
+func parseOrderKey(text string) (string, *int) {
+	at := strings.LastIndex(text, "@")
+	if at <= 0 || (text[at-1] != ' ' && text[at-1] != '\t') {
+		return text, nil
+	}
+	order, err := strconv.Atoi(text[at+1:])
+	if err != nil {
+		return text, nil
+	}
+	return strings.TrimRight(text[:at], " \t"), &order
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestParseOrderKey(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantText  string
+		wantOrder int
+		wantKey   bool
+	}{
+		{"Intro @2", "Intro", 2, true},
+		{"Outro\t@-1", "Outro", -1, true},
+		{"mail user@example", "mail user@example", 0, false},
+		{"@3", "@3", 0, false},
+		{"Price @ 3", "Price @ 3", 0, false},
+		{"Tag @v2", "Tag @v2", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			text, order := parseOrderKey(tt.input)
+			if text != tt.wantText || (order != nil) != tt.wantKey || (order != nil && *order != tt.wantOrder) {
+				t.Fatalf("parseOrderKey(%q) = (%q, %v), want (%q, %d)", tt.input, text, order, tt.wantText, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestParseOrderKeys(t *testing.T) {
+	root, err := Parse("mindmap\n  root((Center))\n    b[Second] @2\n    First @1")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	second, first := root.Children[0], root.Children[1]
+	if second.Text != "Second" || second.Order == nil || *second.Order != 2 {
+		t.Fatalf("unexpected node %+v", second)
+	}
+	if first.Text != "First" || first.Order == nil || *first.Order != 1 {
+		t.Fatalf("unexpected node %+v", first)
+	}
+
+	fromJSON, err := ParseJSON(strings.NewReader(`{"text":"Root","children":[{"text":"A","order":5}]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	if got := fromJSON.Children[0].Order; got == nil || *got != 5 {
+		t.Fatalf("expected JSON order 5, got %v", got)
+	}
+}
+
+func TestOrderJSONRoundTrip(t *testing.T) {
+	five := 5
+	root := &types.Node{Text: "Root", Children: []*types.Node{{Text: "A", Order: &five}, {Text: "B"}}}
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	decoded, err := ParseJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	if got := decoded.Children[0].Order; got == nil || *got != 5 {
+		t.Fatalf("expected order 5 to survive %s, got %v", data, got)
+	}
+	if decoded.Children[1].Order != nil {
+		t.Errorf("expected no order for B, got %v", *decoded.Children[1].Order)
+	}
+}
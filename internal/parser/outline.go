@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// bulletGlyphs 从 Notion、Google Docs 等复制的大纲中常见的项目符号，各层级依次使用 • ◦ ▪ 等
+const bulletGlyphs = "•◦▪▫●○■□‣⁃∙*"
+
+// stripBullet 移除行首的一个项目符号及其后的空白；符号后必须紧跟空白，避免误删 *强调* 等正文内容
+func stripBullet(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if size == 0 || !strings.ContainsRune(bulletGlyphs, r) {
+		return text
+	}
+	rest := text[size:]
+	if trimmed := strings.TrimLeft(rest, " \t"); trimmed != rest {
+		return trimmed
+	}
+	return text
+}
+
+// hasBullet 判断行（已去除首尾空白）是否以项目符号开头
+func hasBullet(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "- ") || stripBullet(trimmed) != trimmed
+}
+
+// isTitledOutline 判断输入是否为“标题 + 顶格项目列表”的大纲：首行是不带项目符号的标题，
+// 其后有顶格的项目符号行。Notion 与 Google Docs 复制页面时常见这种结构，
+// 此时顶格的项目应作为标题的子节点，而不是替换根节点。
+func isTitledOutline(input string) bool {
+	first := true
+	for _, line := range strings.Split(input, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if first {
+				return false
+			}
+			continue
+		}
+		if first {
+			if trimmed == "mindmap" || hasBullet(trimmed) {
+				return false
+			}
+			first = false
+			continue
+		}
+		if hasBullet(trimmed) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectIndentUnit 返回每一级缩进的宽度：制表符按 tabWidth 展开后所有缩进宽度的最大公约数。
+// Notion 与 Google Docs 的导出常以 4 个空格为一级；公约数为奇数（含 1）时
+// 按默认的 2 个空格处理，由调用方报告缩进不一致。explicitTab 表示 tabWidth 由调用方指定，
+// 此时含制表符缩进且公约数为 tabWidth 的倍数时，奇数宽度来自制表位，照常使用。
+func detectIndentUnit(input string, tabWidth int, explicitTab bool) int {
+	unit := 0
+	tabbed := false
+	for _, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if w := leadingWidth(line, tabWidth); w > 0 {
+			unit = gcd(unit, w)
+			tabbed = tabbed || strings.ContainsRune(line[:len(line)-len(strings.TrimLeft(line, " \t"))], '\t')
+		}
+	}
+	if explicitTab && tabbed && unit%tabWidth == 0 {
+		return unit
+	}
+	if unit < 2 || unit%2 != 0 {
+		return 2
+	}
+	return unit
+}
+
+// detectTabWidth 返回制表符默认展开的列宽：仅用空格缩进的行的层级宽度，
+// 使一个制表符恰好为一级缩进
+func detectTabWidth(input string) int {
+	var spaced []string
+	for _, line := range strings.Split(input, "\n") {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !strings.ContainsRune(indent, '\t') {
+			spaced = append(spaced, line)
+		}
+	}
+	return detectIndentUnit(strings.Join(spaced, "\n"), 1, false)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// outlineString 以缩进文本描述树结构，便于与期望值比较
+func outlineString(node *types.Node, depth int, b *strings.Builder) {
+	b.WriteString(strings.Repeat("  ", depth) + node.Text + "\n")
+	for _, child := range node.Children {
+		outlineString(child, depth+1, b)
+	}
+}
+
+func TestParseExportedOutlines(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{
+			fixture: "notion.txt",
+			want: `Product Roadmap
+  Q1 Goals
+    Launch beta
+      Invite 50 testers
+      Collect feedback
+    Hire a designer
+  Q2 Goals
+    Public release
+    Pricing page
+`,
+		},
+		{
+			fixture: "gdocs.txt",
+			want: `Team Offsite
+  Agenda
+    Welcome & intros
+    Planning
+      Budget review
+      Hiring plan
+  Logistics
+    Venue
+    Travel
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			input, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			root, err := Parse(string(input))
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			var b strings.Builder
+			outlineString(root, 0, &b)
+			if b.String() != tt.want {
+				t.Errorf("unexpected tree:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStripBullet(t *testing.T) {
+	tests := map[string]string{
+		"• Item":       "Item",
+		"◦\tItem":      "Item",
+		"* Item":       "Item",
+		"*emphasis*":   "*emphasis*",
+		"•":            "•",
+		"Plain text":   "Plain text",
+		"■ ◦ Two deep": "◦ Two deep",
+	}
+	for input, want := range tests {
+		if got := stripBullet(input); got != want {
+			t.Errorf("stripBullet(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDetectIndentUnit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"Root\n  A\n    B", 2},
+		{"Root\n    A\n        B", 4},
+		{"Root\n    A\n      B", 2},
+		{"Root\n   A", 2},
+		{"Root", 2},
+	}
+	for _, tt := range tests {
+		if got := detectIndentUnit(tt.input, 2, false); got != tt.want {
+			t.Errorf("detectIndentUnit(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+
+	// 显式的奇数制表符宽度：纯制表符缩进以制表位为一级，纯空格缩进仍按默认处理
+	if got := detectIndentUnit("Root\n\tA\n\t\tB", 3, true); got != 3 {
+		t.Errorf("expected tab stops of 3 columns to be the unit, got %d", got)
+	}
+	if got := detectIndentUnit("Root\n   A", 3, true); got != 2 {
+		t.Errorf("expected odd space indentation to fall back to 2, got %d", got)
+	}
+}
@@ -2,20 +2,160 @@ package parser
 
 import (
 	"bufio"
+	"fmt"
 	"regexp"
 	"strings"
 
 	"github.com/hellodeveye/mindmapgen/pkg/types"
 )
 
-func Parse(input string) (*types.Node, error) {
+// ParseError 描述无法映射到树结构的输入行
+type ParseError struct {
+	Line   int    // 1-based 行号，0 表示与具体行无关
+	Column int    // 1-based 列号，指向首个非空白字符
+	Text   string // 出错行的内容（已去除首尾空白）
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Reason
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %q", e.Line, e.Column, e.Reason, e.Text)
+}
+
+// RootLine is one top-level line reported by MultipleRootsError.
+type RootLine struct {
+	Line int    // 1-based line number
+	Text string // line content, trimmed
+}
+
+// MultipleRootsError is returned when the input has more than one top-level
+// node. A mind map has a single root, so this usually means a paste or
+// indentation mistake; Roots lists every top-level line in input order.
+type MultipleRootsError struct {
+	Roots []RootLine
+}
+
+func (e *MultipleRootsError) Error() string {
+	lines := make([]string, len(e.Roots))
+	for i, r := range e.Roots {
+		lines[i] = fmt.Sprintf("line %d %q", r.Line, r.Text)
+	}
+	return fmt.Sprintf("found %d root nodes, expected 1 (%s); indent the others under a single root", len(e.Roots), strings.Join(lines, ", "))
+}
+
+type parseOptions struct {
+	lenient     bool
+	bareURLs    bool
+	comments    bool
+	tabWidth    int // 制表符展开的列宽，0 表示等于一级缩进
+	indentWidth int // 每一级缩进的列宽，0 表示自动检测
+}
+
+// Option configures parse behavior.
+type Option func(*parseOptions)
+
+// ParseLenient restores the historical behavior: lines that cannot be placed
+// are dropped silently and empty input yields a "Root" node instead of an error.
+func ParseLenient() Option {
+	return func(opts *parseOptions) {
+		opts.lenient = true
+	}
+}
+
+// ParseBareURLs makes a plain http(s) URL in node text the node's link when
+// the node has no Markdown [label](url) link. The text keeps the URL.
+func ParseBareURLs() Option {
+	return func(opts *parseOptions) {
+		opts.bareURLs = true
+	}
+}
+
+// ParseComments skips lines starting with // or # and strips trailing
+// "// note" fragments that are separated from the node text by whitespace.
+// A leading \# or \// keeps the hash or slashes as literal text, as does \//
+// inside a line. Off by default so that outlines with #-prefixed labels parse
+// unchanged.
+func ParseComments() Option {
+	return func(opts *parseOptions) {
+		opts.comments = true
+	}
+}
+
+// ParseIndentWidth sets how many columns of leading whitespace make one
+// level instead of detecting it from the input. By default the width is the
+// greatest common divisor of all indentation widths, falling back to 2 when
+// that is odd; an explicit width also accepts odd steps such as 3 spaces.
+// Lines that do not align to the width are reported as errors. Values below
+// 1 are ignored.
+func ParseIndentWidth(n int) Option {
+	return func(opts *parseOptions) {
+		if n >= 1 {
+			opts.indentWidth = n
+		}
+	}
+}
+
+// ParseTabWidth expands each tab in leading indentation to the next
+// multiple of n columns before levels are counted. By default a tab is as
+// wide as one level: the ParseIndentWidth width if set, otherwise the input's
+// space indentation (2 columns when the input has none), so tab-indented
+// lines mix with space-indented ones. Values below 1 are ignored.
+func ParseTabWidth(n int) Option {
+	return func(opts *parseOptions) {
+		if n >= 1 {
+			opts.tabWidth = n
+		}
+	}
+}
+
+func Parse(input string, options ...Option) (*types.Node, error) {
+	var opts parseOptions
+	for _, opt := range options {
+		if opt != nil {
+			opt(&opts)
+		}
+	}
+
+	if opts.comments {
+		input = stripComments(input)
+	}
+
 	scanner := bufio.NewScanner(strings.NewReader(input))
 	var stack []*types.Node
 	var root *types.Node
 	foundMindmap := false
+	mermaid := false // 是否为 Mermaid mindmap 语法，决定是否解析节点形状
+	lineNo := 0
 
-	// 检测使用的缩进方式
-	indentType := detectIndentationType(input)
+	// 非宽松模式下记录遇到的第一个错误
+	var parseErr *ParseError
+	fail := func(line, trimmed, reason string) {
+		if parseErr == nil && !opts.lenient {
+			parseErr = &ParseError{
+				Line:   lineNo,
+				Column: len(line) - len(strings.TrimLeft(line, " \t")) + 1,
+				Text:   trimmed,
+				Reason: reason,
+			}
+		}
+	}
+
+	// 检测制表符展开的列宽与每一级缩进的宽度，指定缩进宽度时制表符默认等于一级
+	tabWidth := opts.tabWidth
+	if tabWidth == 0 {
+		tabWidth = opts.indentWidth
+	}
+	if tabWidth == 0 {
+		tabWidth = detectTabWidth(input)
+	}
+	indentUnit := opts.indentWidth
+	if indentUnit == 0 {
+		indentUnit = detectIndentUnit(input, tabWidth, opts.tabWidth != 0)
+	}
+	// 标题后紧跟顶格项目列表时，标题以下的所有行下移一级
+	titled := isTitledOutline(input)
 
 	// 记录每个层级的最后一个节点
 	levelLastNodes := make(map[int]*types.Node)
@@ -23,8 +163,18 @@ func Parse(input string) (*types.Node, error) {
 	// 记录上一行的缩进级别，用于检测层级变化
 	prevLevel := -1
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	// 最近创建的节点，Mermaid 的 ::icon() 行作用于该节点
+	var lastNode *types.Node
+
+	// 根节点所在的层级（Mermaid 语法中根节点位于 mindmap 之下一级），以及所有顶层行。
+	// 出现第二个顶层行后不再构建节点，只继续收集顶层行用于报错
+	rootLevel := 0
+	var roots []RootLine
+
+	for scanner.Scan() && parseErr == nil {
+		lineNo++
+		// 去除行尾空白，避免仅含空白的行或行尾空格干扰缩进计算
+		line := strings.TrimRight(scanner.Text(), " \t")
 		trimmed := strings.TrimSpace(line)
 
 		if trimmed == "" {
@@ -33,29 +183,102 @@ func Parse(input string) (*types.Node, error) {
 
 		if trimmed == "mindmap" {
 			foundMindmap = true
+			mermaid = true
+			rootLevel = 1
+			continue
+		}
+
+		// Mermaid 图标行不产生节点，缩进不参与层级计算
+		if icon, ok := parseIconDirective(trimmed); ok {
+			if len(roots) > 1 {
+				continue
+			}
+			if lastNode == nil {
+				fail(line, trimmed, "icon before any node")
+				continue
+			}
+			lastNode.Icon = icon
+			continue
+		}
+
+		// 备注行同样作用于上一节点，连续多行备注按行拼接
+		if note, ok := parseNoteLine(trimmed); ok {
+			if len(roots) > 1 {
+				continue
+			}
+			if lastNode == nil {
+				fail(line, trimmed, "note before any node")
+				continue
+			}
+			if note == "" {
+				continue
+			}
+			if lastNode.Note != "" {
+				lastNode.Note += "\n"
+			}
+			lastNode.Note += note
 			continue
 		}
 
-		level := getIndentationLevel(line, indentType)
+		if leadingWidth(line, tabWidth)%indentUnit != 0 && len(roots) <= 1 {
+			if mixedIndent(line) {
+				fail(line, trimmed, fmt.Sprintf("mixed tabs and spaces do not align to the %d-column indentation (tab width %d)", indentUnit, tabWidth))
+			} else {
+				fail(line, trimmed, "inconsistent indentation width")
+			}
+		}
+
+		level := getIndentationLevel(line, tabWidth, indentUnit)
+		if titled && root != nil {
+			level++
+		}
+		if root != nil && level <= rootLevel {
+			roots = append(roots, RootLine{Line: lineNo, Text: trimmed})
+		}
+		if len(roots) > 1 {
+			continue
+		}
 
 		// 清理文本，对根节点做特殊处理
-		cleanedText := cleanText(trimmed)
+		// 行尾的元数据块与样式指令可按任意顺序出现
+		cleanedText, meta := parseMeta(cleanText(trimmed))
+		cleanedText, style := parseStyle(cleanedText)
+		if meta == nil {
+			cleanedText, meta = parseMeta(cleanedText)
+		}
+		cleanedText, order := parseOrderKey(cleanedText)
+		shape := types.ShapeDefault
 		if (level == 0 && !foundMindmap) || (level == 1 && foundMindmap) {
 			// 根节点特殊处理，移除"root"和双括号
 			cleanedText = cleanRootText(cleanedText)
+		} else if mermaid {
+			cleanedText, shape = parseShape(cleanedText)
 		}
 
+		cleanedText, icon := parseIcon(cleanedText)
+		cleanedText, link := parseLink(cleanedText, opts.bareURLs)
+		cleanedText, spans := parseInlineMarkup(cleanedText)
 		node := &types.Node{
 			Text:     cleanedText,
 			Children: []*types.Node{},
+			Spans:    spans,
+			Shape:    shape,
+			Order:    order,
+			Meta:     meta,
+			Icon:     icon,
+			Link:     link,
+			Style:    style,
 		}
+		lastNode = node
 
 		if !foundMindmap && level == 0 {
+			roots = append(roots, RootLine{Line: lineNo, Text: trimmed})
 			root = node
 			stack = []*types.Node{node}
 			levelLastNodes[level] = node
 			prevLevel = level
 		} else if foundMindmap && level == 1 { // First node after mindmap is root
+			roots = append(roots, RootLine{Line: lineNo, Text: trimmed})
 			root = node
 			stack = []*types.Node{node}
 			levelLastNodes[level] = node
@@ -97,82 +320,72 @@ func Parse(input string) (*types.Node, error) {
 					}
 
 					levelLastNodes[level] = node
+				} else {
+					fail(line, trimmed, "orphaned dedent: no parent at this indentation level")
 				}
 			}
 
 			prevLevel = level
+		} else {
+			fail(line, trimmed, "content before root node")
 		}
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	// 宽松模式下保留第一棵树，其余顶层行及其子树被丢弃
+	if len(roots) > 1 && !opts.lenient {
+		return nil, &MultipleRootsError{Roots: roots}
+	}
+
 	if root == nil {
+		if !opts.lenient {
+			return nil, &ParseError{Reason: "no root node found"}
+		}
 		root = &types.Node{
 			Text:     "Root",
 			Children: []*types.Node{},
 		}
 	}
 
-	return root, scanner.Err()
+	return root, nil
 }
 
-// 检测使用的缩进类型
-func detectIndentationType(input string) string {
-	lines := strings.Split(input, "\n")
-	tabCount := 0
-	spaceCount := 0
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "\t") {
-			tabCount++
-		} else if strings.HasPrefix(line, "  ") {
-			spaceCount++
-		}
-	}
-
-	if tabCount > spaceCount {
-		return "tab"
-	}
-	return "space"
-}
-
-// 根据缩进类型获取缩进级别
-func getIndentationLevel(line string, indentType string) int {
-	if indentType == "tab" {
-		// 计算开头的制表符数量
-		tabCount := 0
-		for _, c := range line {
-			if c == '\t' {
-				tabCount++
-			} else {
-				break
-			}
-		}
-		return tabCount
-	} else {
-		// 使用原始的空格计数方法
-		return countIndentation(line)
-	}
+// 根据行首空白的展开宽度获取缩进级别，每 unit 列为一级
+func getIndentationLevel(line string, tabWidth, unit int) int {
+	return leadingWidth(line, tabWidth) / unit
 }
 
-func countIndentation(line string) int {
+// leadingWidth 计算行首空白的宽度，制表符展开到下一个 tabWidth 的整数倍列，与编辑器显示一致
+func leadingWidth(line string, tabWidth int) int {
 	count := 0
 	for _, c := range line {
 		if c == ' ' {
 			count++
 		} else if c == '\t' {
-			// 每个tab算作一个层级
-			count += 2
+			count += tabWidth - count%tabWidth
 		} else {
 			break
 		}
 	}
-	return count / 2 // 每两个空格为一个层级，tab已经转换为相应空格数
+	return count
+}
+
+// mixedIndent 判断行首空白是否同时包含制表符与空格
+func mixedIndent(line string) bool {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	return strings.ContainsRune(indent, '\t') && strings.ContainsRune(indent, ' ')
 }
 
 // 清理普通节点文本
 func cleanText(text string) string {
-	// 删除前缀的空格、制表符和破折号
+	// 删除前缀的空格、制表符和破折号，以及复制大纲时带入的项目符号
 	text = strings.TrimLeft(text, " \t-")
-	return strings.TrimSpace(text)
+	return strings.TrimSpace(stripBullet(text))
 }
 
 // 专门处理根节点文本，移除"root"和双括号
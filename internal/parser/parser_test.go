@@ -1,7 +1,12 @@
 package parser
 
 import (
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
 )
 
 func TestSimpleParse(t *testing.T) {
@@ -23,3 +28,305 @@ mindmap
 		t.Errorf("expected 2 children, got %d", len(root.Children))
 	}
 }
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantLine   int
+		wantReason string
+	}{
+		{
+			name:       "odd indentation",
+			input:      "Root\n   Child",
+			wantLine:   2,
+			wantReason: "inconsistent indentation width",
+		},
+		{
+			name:       "orphaned dedent",
+			input:      "Root\n      Deep\n    Orphan",
+			wantLine:   3,
+			wantReason: "orphaned dedent",
+		},
+		{
+			name:       "content before root",
+			input:      "mindmap\nStray\n  root((Topic))",
+			wantLine:   2,
+			wantReason: "content before root",
+		},
+		{
+			name:       "no root",
+			input:      "mindmap\n",
+			wantLine:   0,
+			wantReason: "no root",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if parseErr.Line != tt.wantLine {
+				t.Errorf("expected line %d, got %d", tt.wantLine, parseErr.Line)
+			}
+			if !strings.Contains(parseErr.Reason, tt.wantReason) {
+				t.Errorf("expected reason containing %q, got %q", tt.wantReason, parseErr.Reason)
+			}
+		})
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	root, err := Parse("Root\n      Deep\n    Orphan", ParseLenient())
+	if err != nil {
+		t.Fatalf("lenient parse failed: %v", err)
+	}
+	if root.Text != "Root" || len(root.Children) != 1 {
+		t.Fatalf("expected orphan to be dropped, got %+v", root)
+	}
+
+	root, err = Parse("", ParseLenient())
+	if err != nil {
+		t.Fatalf("lenient parse of empty input failed: %v", err)
+	}
+	if root.Text != "Root" {
+		t.Errorf("expected fallback root 'Root', got %q", root.Text)
+	}
+}
+
+func TestParseMultipleRoots(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []RootLine
+	}{
+		{
+			name:  "indented text",
+			input: "First\n  A\n    A1\nSecond\n  B\n\nThird",
+			want:  []RootLine{{1, "First"}, {4, "Second"}, {7, "Third"}},
+		},
+		{
+			name:  "mermaid",
+			input: "mindmap\n  root((One))\n    A\n  Two\n    B",
+			want:  []RootLine{{2, "root((One))"}, {4, "Two"}},
+		},
+		{
+			name:  "mermaid unindented",
+			input: "mindmap\n  root((One))\n    A\nTwo\n::icon(fa fa-book)",
+			want:  []RootLine{{2, "root((One))"}, {4, "Two"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var rootsErr *MultipleRootsError
+			if !errors.As(err, &rootsErr) {
+				t.Fatalf("expected *MultipleRootsError, got %v", err)
+			}
+			if len(rootsErr.Roots) != len(tt.want) {
+				t.Fatalf("expected roots %v, got %v", tt.want, rootsErr.Roots)
+			}
+			for i, r := range tt.want {
+				if rootsErr.Roots[i] != r {
+					t.Errorf("root %d: expected %v, got %v", i, r, rootsErr.Roots[i])
+				}
+			}
+			if !strings.Contains(err.Error(), "line "+strconv.Itoa(tt.want[1].Line)) {
+				t.Errorf("expected the error to name each root line, got %q", err)
+			}
+		})
+	}
+
+	// 宽松模式保留第一棵树
+	root, err := Parse("First\n  A\nSecond\n  B", ParseLenient())
+	if err != nil {
+		t.Fatalf("lenient parse failed: %v", err)
+	}
+	if root.Text != "First" || len(root.Children) != 1 || root.Children[0].Text != "A" {
+		t.Fatalf("expected only the first tree to be kept, got %+v", root)
+	}
+}
+
+func TestParseTrailingWhitespace(t *testing.T) {
+	input := "Root   \n  Child1  \t\n    Leaf \n  Child2\t"
+	root, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if root.Text != "Root" {
+		t.Errorf("expected root 'Root', got %q", root.Text)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(root.Children))
+	}
+	if root.Children[0].Text != "Child1" || len(root.Children[0].Children) != 1 {
+		t.Errorf("unexpected first child: %+v", root.Children[0])
+	}
+}
+
+func TestParseWhitespaceOnlyLines(t *testing.T) {
+	// 制表符缩进的大纲中夹杂着仅含空格的行，不应影响缩进类型的判断
+	input := "Root\n\tChild1\n          \n\t\tLeaf\n        \n\tChild2\n   \n"
+	root, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(root.Children))
+	}
+	if len(root.Children[0].Children) != 1 || root.Children[0].Children[0].Text != "Leaf" {
+		t.Errorf("expected Leaf under Child1, got %+v", root.Children[0].Children)
+	}
+}
+
+func TestParseMixedIndentation(t *testing.T) {
+	// 返回每个节点的父节点文本
+	parents := func(t *testing.T, input string, options ...Option) map[string]string {
+		t.Helper()
+		root, err := Parse(input, options...)
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		got := map[string]string{}
+		var walk func(n *types.Node)
+		walk = func(n *types.Node) {
+			for _, c := range n.Children {
+				got[c.Text] = n.Text
+				walk(c)
+			}
+		}
+		walk(root)
+		return got
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		options []Option
+		want    map[string]string
+	}{
+		{
+			// 制表符等于一级空格缩进，其后的空格再深一级
+			name:  "tab then spaces",
+			input: "Root\n  A\n\t  child\n  B",
+			want:  map[string]string{"A": "Root", "child": "A", "B": "Root"},
+		},
+		{
+			// 空格后的制表符展开到下一个制表位
+			name:  "spaces then tab",
+			input: "Root\n  A\n  \tchild\n    \tgrandchild\n  B",
+			want:  map[string]string{"A": "Root", "child": "A", "grandchild": "child", "B": "Root"},
+		},
+		{
+			name:  "spaces then tab within a tab stop",
+			input: "Root\n    A\n  \tB",
+			want:  map[string]string{"A": "Root", "B": "Root"},
+		},
+		{
+			name:  "tabs and spaces as siblings",
+			input: "Root\n    A\n\tB\n\t\tB1\n        B2",
+			want:  map[string]string{"A": "Root", "B": "Root", "B1": "B", "B2": "B"},
+		},
+		{
+			name:  "pure tabs",
+			input: "Root\n\tA\n\t\tA1\n\tB",
+			want:  map[string]string{"A": "Root", "A1": "A", "B": "Root"},
+		},
+		{
+			name:    "explicit tab width",
+			input:   "Root\n  A\n\tchild",
+			options: []Option{ParseTabWidth(4)},
+			want:    map[string]string{"A": "Root", "child": "A"},
+		},
+		{
+			// 奇数的制表符宽度下，纯制表符缩进每个制表符为一级
+			name:    "odd explicit tab width",
+			input:   "Root\n\tchild\n\t\tgrand\n\tsibling",
+			options: []Option{ParseTabWidth(3)},
+			want:    map[string]string{"child": "Root", "grand": "child", "sibling": "Root"},
+		},
+		{
+			name:    "odd tab width with matching spaces",
+			input:   "Root\n\tchild\n      grand",
+			options: []Option{ParseTabWidth(3)},
+			want:    map[string]string{"child": "Root", "grand": "child"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parents(t, tt.input, tt.options...)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected parents %v, got %v", tt.want, got)
+			}
+			for child, parent := range tt.want {
+				if got[child] != parent {
+					t.Errorf("expected %q under %q, got %q", child, parent, got[child])
+				}
+			}
+		})
+	}
+
+	// 兄弟节点缩进宽度不一致时报告出错的行
+	errTests := []struct {
+		name       string
+		input      string
+		wantLine   int
+		wantReason string
+	}{
+		{"inconsistent sibling widths", "Root\n  A\n    A1\n   B", 4, "inconsistent indentation width"},
+		{"misaligned tab and space", "Root\n\tA\n\t B", 3, "mixed tabs and spaces"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if parseErr.Line != tt.wantLine || !strings.Contains(parseErr.Reason, tt.wantReason) {
+				t.Errorf("expected line %d with %q, got line %d: %q", tt.wantLine, tt.wantReason, parseErr.Line, parseErr.Reason)
+			}
+		})
+	}
+}
+
+func TestParseIndentWidth(t *testing.T) {
+	// 4 个空格一级，自动检测与显式指定结果相同
+	four := "Root\n    A\n        A1\n            A1a\n    B\n        B1"
+	for _, options := range [][]Option{nil, {ParseIndentWidth(4)}} {
+		root, err := Parse(four, options...)
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		if len(root.Children) != 2 || root.Children[0].Children[0].Children[0].Text != "A1a" || root.Children[1].Children[0].Text != "B1" {
+			t.Fatalf("expected 4-space levels, got %+v", root)
+		}
+	}
+
+	// 4 个空格与制表符混用，但每行都对齐到同一缩进宽度
+	root, err := Parse("Root\n    A\n\tB\n\t    B1\n        B2")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(root.Children) != 2 || len(root.Children[1].Children) != 2 {
+		t.Fatalf("expected A and B with two children under B, got %+v", root)
+	}
+
+	// 显式指定奇数宽度
+	root, err = Parse("Root\n   A\n      A1\n   B", ParseIndentWidth(3))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(root.Children) != 2 || len(root.Children[0].Children) != 1 {
+		t.Fatalf("expected 3-space levels, got %+v", root)
+	}
+
+	// 显式宽度下未对齐的行报错
+	var parseErr *ParseError
+	if _, err := Parse("Root\n    A\n      B", ParseIndentWidth(4)); !errors.As(err, &parseErr) || parseErr.Line != 3 {
+		t.Fatalf("expected an error on line 3, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// mermaidShapes Mermaid 思维导图的形状定界符，较长的定界符需排在前面
+var mermaidShapes = []struct {
+	open, close string
+	shape       types.Shape
+}{
+	{"((", "))", types.ShapeCircle},
+	{"))", "((", types.ShapeBang},
+	{"{{", "}}", types.ShapeHexagon},
+	{"[", "]", types.ShapeSquare},
+	{"(", ")", types.ShapeRounded},
+	{")", "(", types.ShapeCloud},
+}
+
+// parseShape 解析 Mermaid 节点形状语法，如 id[方形]、id((圆形))、id)云朵(。
+// 可选的 id 中不能包含空白；无法识别的写法原样返回并使用默认形状。
+func parseShape(text string) (string, types.Shape) {
+	for _, s := range mermaidShapes {
+		start := strings.Index(text, s.open)
+		if start < 0 || strings.ContainsAny(text[:start], " \t") {
+			continue
+		}
+		// 定界符之前的 id 不能再包含其他定界字符
+		if strings.ContainsAny(text[:start], "()[]{}") {
+			continue
+		}
+		rest := text[start+len(s.open):]
+		if !strings.HasSuffix(rest, s.close) || len(rest) < len(s.close) {
+			continue
+		}
+		inner := strings.TrimSpace(rest[:len(rest)-len(s.close)])
+		if inner == "" || strings.Contains(inner, s.close) {
+			continue
+		}
+		return inner, s.shape
+	}
+	return text, types.ShapeDefault
+}
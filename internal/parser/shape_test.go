@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestParseShape(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantText  string
+		wantShape types.Shape
+	}{
+		{"id[Square]", "Square", types.ShapeSquare},
+		{"id(Rounded)", "Rounded", types.ShapeRounded},
+		{"id((Circle))", "Circle", types.ShapeCircle},
+		{"id)Cloud(", "Cloud", types.ShapeCloud},
+		{"id{{Hexagon}}", "Hexagon", types.ShapeHexagon},
+		{"id))Bang((", "Bang", types.ShapeBang},
+		{"[No id]", "No id", types.ShapeSquare},
+		{"Plain text", "Plain text", types.ShapeDefault},
+		{"f(x) and g(y)", "f(x) and g(y)", types.ShapeDefault},
+		{"call foo(bar)", "call foo(bar)", types.ShapeDefault},
+		{"id[]", "id[]", types.ShapeDefault},
+		{"id[unclosed", "id[unclosed", types.ShapeDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			text, shape := parseShape(tt.input)
+			if text != tt.wantText || shape != tt.wantShape {
+				t.Fatalf("parseShape(%q) = (%q, %q), want (%q, %q)", tt.input, text, shape, tt.wantText, tt.wantShape)
+			}
+		})
+	}
+}
+
+func TestParseMermaidShapes(t *testing.T) {
+	input := "mindmap\n  root((Center))\n    a[Square]\n    b)Cloud(\n    Plain"
+	root, err := Parse(input)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if root.Text != "Center" || root.Shape != types.ShapeDefault {
+		t.Fatalf("unexpected root: %q shape %q", root.Text, root.Shape)
+	}
+	want := []struct {
+		text  string
+		shape types.Shape
+	}{
+		{"Square", types.ShapeSquare},
+		{"Cloud", types.ShapeCloud},
+		{"Plain", types.ShapeDefault},
+	}
+	if len(root.Children) != len(want) {
+		t.Fatalf("expected %d children, got %d", len(want), len(root.Children))
+	}
+	for i, w := range want {
+		if c := root.Children[i]; c.Text != w.text || c.Shape != w.shape {
+			t.Errorf("child %d = (%q, %q), want (%q, %q)", i, c.Text, c.Shape, w.text, w.shape)
+		}
+	}
+}
+
+func TestParseShapeIgnoredOutsideMermaid(t *testing.T) {
+	root, err := Parse("Root\n  id[Square]")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if c := root.Children[0]; c.Text != "id[Square]" || c.Shape != types.ShapeDefault {
+		t.Fatalf("expected plain text outside mermaid, got (%q, %q)", c.Text, c.Shape)
+	}
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+// styleKeys 样式指令中可用的键：color（或 fill）为填充色，text 为文字色，stroke（或 border）为边框色
+var styleKeys = map[string]string{
+	"color":  "fill",
+	"fill":   "fill",
+	"text":   "text",
+	"stroke": "stroke",
+	"border": "stroke",
+}
+
+// namedColors 样式指令中可直接使用的颜色名
+var namedColors = map[string]string{
+	"black":  "#000000",
+	"white":  "#ffffff",
+	"gray":   "#808080",
+	"grey":   "#808080",
+	"red":    "#ff0000",
+	"orange": "#ffa500",
+	"yellow": "#ffff00",
+	"green":  "#008000",
+	"blue":   "#0000ff",
+	"purple": "#800080",
+	"pink":   "#ffc0cb",
+}
+
+// parseStyle 解析行尾的样式指令，如 "重要 {color:#ff0000; text:#fff}"。
+// 指令需与正文以空白分隔，且每一项都是已知键的 key:value，否则原样返回文本。
+// 无法识别的颜色记录日志后忽略；缺少填充色时不设置样式，节点沿用主题样式。
+func parseStyle(text string) (string, *types.NodeStyle) {
+	if !strings.HasSuffix(text, "}") {
+		return text, nil
+	}
+	open := strings.LastIndex(text, "{")
+	if open <= 0 || (text[open-1] != ' ' && text[open-1] != '\t') {
+		return text, nil
+	}
+
+	colors := make(map[string][3]float64)
+	found := false
+	for _, decl := range strings.Split(text[open+1:len(text)-1], ";") {
+		if strings.TrimSpace(decl) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(decl, ":")
+		field, known := styleKeys[strings.ToLower(strings.TrimSpace(key))]
+		if !ok || !known {
+			return text, nil
+		}
+		found = true
+		value = strings.TrimSpace(value)
+		c, ok := parseColor(value)
+		if !ok {
+			log.Printf("ignoring invalid %s color %q in %q", strings.TrimSpace(key), value, text)
+			continue
+		}
+		colors[field] = c
+	}
+	if !found {
+		return text, nil
+	}
+	text = strings.TrimRight(text[:open], " \t")
+
+	fill, ok := colors["fill"]
+	if !ok {
+		if len(colors) > 0 {
+			log.Printf("ignoring style without a fill color in %q", text)
+		}
+		return text, nil
+	}
+	style := &types.NodeStyle{FillColor: fill, StrokeColor: fill, TextColor: contrastColor(fill)}
+	if c, ok := colors["stroke"]; ok {
+		style.StrokeColor = c
+	}
+	if c, ok := colors["text"]; ok {
+		style.TextColor = c
+	}
+	return text, style
+}
+
+// parseColor 解析 #rrggbb、简写 #rgb 或颜色名，分量为 0-1
+func parseColor(value string) ([3]float64, bool) {
+	if named, ok := namedColors[strings.ToLower(value)]; ok {
+		value = named
+	}
+	if len(value) == 4 && value[0] == '#' {
+		value = string([]byte{'#', value[1], value[1], value[2], value[2], value[3], value[3]})
+	}
+	if len(value) != 7 || value[0] != '#' {
+		return [3]float64{}, false
+	}
+	var c [3]float64
+	for i := range c {
+		v, err := strconv.ParseUint(value[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return [3]float64{}, false
+		}
+		c[i] = float64(v) / 255
+	}
+	return c, true
+}
+
+// contrastColor 根据填充色亮度选择黑色或白色文字，与绘制时的规则一致
+func contrastColor(bg [3]float64) [3]float64 {
+	if 0.299*bg[0]+0.587*bg[1]+0.114*bg[2] > 0.5 {
+		return [3]float64{0, 0, 0}
+	}
+	return [3]float64{1, 1, 1}
+}
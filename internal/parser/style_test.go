@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
+
+func TestParseStyle(t *testing.T) {
+	red := [3]float64{1, 0, 0}
+	white := [3]float64{1, 1, 1}
+	tests := []struct {
+		name      string
+		input     string
+		wantText  string
+		wantStyle *types.NodeStyle
+	}{
+		{
+			name:      "fill color",
+			input:     "Important {color:#ff0000}",
+			wantText:  "Important",
+			wantStyle: &types.NodeStyle{FillColor: red, StrokeColor: red, TextColor: white},
+		},
+		{
+			name:      "light fill gets dark text",
+			input:     "Note {fill: yellow}",
+			wantText:  "Note",
+			wantStyle: &types.NodeStyle{FillColor: [3]float64{1, 1, 0}, StrokeColor: [3]float64{1, 1, 0}, TextColor: [3]float64{0, 0, 0}},
+		},
+		{
+			name:      "all colors",
+			input:     "Risk {color:#f00; text:#000; border:#fff}",
+			wantText:  "Risk",
+			wantStyle: &types.NodeStyle{FillColor: red, StrokeColor: white, TextColor: [3]float64{0, 0, 0}},
+		},
+		{name: "invalid color is stripped and ignored", input: "Odd {color:#zzzzzz}", wantText: "Odd"},
+		{name: "text color alone is ignored", input: "Odd {text:#fff}", wantText: "Odd"},
+		{name: "unknown key is not a directive", input: "Time {at:10}", wantText: "Time {at:10}"},
+		{name: "metadata is not a directive", input: "Launch {owner=Ann}", wantText: "Launch {owner=Ann}"},
+		{name: "mermaid hexagon", input: "id{{Hexagon}}", wantText: "id{{Hexagon}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, style := parseStyle(tt.input)
+			if text != tt.wantText {
+				t.Errorf("expected text %q, got %q", tt.wantText, text)
+			}
+			if (style == nil) != (tt.wantStyle == nil) || (style != nil && *style != *tt.wantStyle) {
+				t.Errorf("expected style %+v, got %+v", tt.wantStyle, style)
+			}
+		})
+	}
+}
+
+func TestParseStyleWithMeta(t *testing.T) {
+	root, err := Parse("Project\n  Launch {color:red} {owner=Ann}\n  Review {owner=Bob} {color:blue}\n  Plain")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	for i, owner := range []string{"Ann", "Bob"} {
+		child := root.Children[i]
+		if child.Style == nil || child.Meta["owner"] != owner {
+			t.Errorf("expected style and metadata on %q, got %+v / %v", child.Text, child.Style, child.Meta)
+		}
+		if child.Text != "Launch" && child.Text != "Review" {
+			t.Errorf("expected directives to be stripped, got %q", child.Text)
+		}
+	}
+	if root.Children[2].Style != nil {
+		t.Errorf("expected plain nodes to keep the theme style, got %+v", root.Children[2].Style)
+	}
+}
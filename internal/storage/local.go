@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ImageStore stores a rendered mind map and returns the URL it is served at.
+// Both S3Client and LocalStore implement it; tests can substitute a fake.
+type ImageStore interface {
+	UploadImage(ctx context.Context, imageData []byte, contentType string) (string, error)
+}
+
+var ErrMissingLocalConfig = errors.New("missing local storage configuration")
+
+// DefaultLocalPublicPrefix is the URL path local files are served under when
+// LOCAL_STORAGE_PREFIX is not set.
+const DefaultLocalPublicPrefix = "/files/"
+
+// LocalStore saves uploads to a directory on disk, for self-hosting without
+// object storage. Files are served by Handler under PublicPrefix, and
+// UploadImage returns a relative URL below that prefix.
+type LocalStore struct {
+	Dir           string
+	PublicPrefix  string // URL path prefix such as "/files/"
+	TimestampKeys bool   // name files by upload time instead of content hash
+}
+
+// LoadLocalStoreFromEnv reads LOCAL_STORAGE_DIR and the optional
+// LOCAL_STORAGE_PREFIX and LOCAL_STORAGE_TIMESTAMP_KEYS. ErrMissingLocalConfig
+// is returned when no directory is configured.
+func LoadLocalStoreFromEnv() (*LocalStore, error) {
+	dir := strings.TrimSpace(os.Getenv("LOCAL_STORAGE_DIR"))
+	if dir == "" {
+		return nil, ErrMissingLocalConfig
+	}
+	timestampKeys, err := boolEnv("LOCAL_STORAGE_TIMESTAMP_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	store, err := NewLocalStore(dir, os.Getenv("LOCAL_STORAGE_PREFIX"))
+	if err != nil {
+		return nil, err
+	}
+	store.TimestampKeys = timestampKeys
+	return store, nil
+}
+
+// NewLocalStore creates dir if needed and returns a store serving it under
+// prefix (DefaultLocalPublicPrefix when empty).
+func NewLocalStore(dir, prefix string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create storage directory: %v", err)
+	}
+	trimmed := strings.Trim(strings.TrimSpace(prefix), "/")
+	if trimmed == "" && strings.TrimSpace(prefix) != "" {
+		return nil, fmt.Errorf("invalid public prefix %q: must not be the site root", prefix)
+	}
+	// 前缀规范为 "/xxx/" 形式，便于挂载到 ServeMux 与拼接 URL
+	publicPrefix := DefaultLocalPublicPrefix
+	if trimmed != "" {
+		publicPrefix = "/" + trimmed + "/"
+	}
+	return &LocalStore{Dir: dir, PublicPrefix: publicPrefix}, nil
+}
+
+func (s *LocalStore) UploadImage(ctx context.Context, imageData []byte, contentType string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	key := objectKey(imageData, contentType, s.TimestampKeys)
+	name := filepath.Join(s.Dir, filepath.FromSlash(key))
+	// 内容寻址的文件已存在时无需重复写入
+	if !s.TimestampKeys {
+		if _, err := os.Stat(name); err == nil {
+			return path.Join(s.PublicPrefix, key), nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return "", fmt.Errorf("failed to upload image: %v", err)
+	}
+
+	// 先写入临时文件再重命名，避免静态服务读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to upload image: %v", err)
+	}
+	_, err = tmp.Write(imageData)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to upload image: %v", err)
+	}
+
+	return path.Join(s.PublicPrefix, key), nil
+}
+
+// Handler serves the stored files below PublicPrefix. Directory listings and
+// dot files (such as in-progress uploads) are not served.
+func (s *LocalStore) Handler() http.Handler {
+	files := http.StripPrefix(strings.TrimSuffix(s.PublicPrefix, "/"), http.FileServer(http.Dir(s.Dir)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") || strings.HasPrefix(path.Base(r.URL.Path), ".") {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
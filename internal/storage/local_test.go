@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+	store, err := NewLocalStore(dir, "public")
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	if store.PublicPrefix != "/public/" {
+		t.Fatalf("expected a normalized prefix, got %q", store.PublicPrefix)
+	}
+
+	url, err := store.UploadImage(context.Background(), []byte("data"), "image/svg+xml")
+	if err != nil {
+		t.Fatalf("UploadImage failed: %v", err)
+	}
+	if !strings.HasPrefix(url, "/public/mindmaps/") || !strings.HasSuffix(url, ".svg") {
+		t.Fatalf("unexpected URL %q", url)
+	}
+	if again, _ := store.UploadImage(context.Background(), []byte("data"), "image/svg+xml"); again != url {
+		t.Fatalf("expected identical content to reuse %q, got %q", url, again)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(url, "/public/")))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("expected the file on disk, got %q, %v", data, err)
+	}
+
+	for target, status := range map[string]int{
+		url:                 http.StatusOK,
+		"/public/mindmaps/": http.StatusNotFound,
+		"/public/missing":   http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		store.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != status {
+			t.Errorf("GET %s: expected status %d, got %d", target, status, rec.Code)
+		}
+	}
+
+	if _, err := NewLocalStore(dir, "/"); err == nil {
+		t.Error("expected an error for a site-root prefix")
+	}
+}
@@ -1,18 +1,9 @@
 package storage
 
 import (
-	"bytes"
-	"context"
 	"errors"
 	"fmt"
 	"os"
-	"time"
-
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/google/uuid"
 )
 
 var ErrMissingR2Config = errors.New("missing R2 storage configuration")
@@ -23,12 +14,24 @@ type R2Config struct {
 	AccessKeySecret string
 	BucketName      string
 	Domain          string
+	TimestampKeys   bool // key objects by upload time instead of content hash
 }
 
-type R2Client struct {
-	client     *s3.Client
-	bucketName string
-	domain     string
+// R2Client is an S3Client configured for Cloudflare R2.
+type R2Client = S3Client
+
+// S3Config returns the S3-compatible configuration for the R2 account: the
+// account's R2 endpoint, region "auto", and Domain as the public base URL.
+func (cfg R2Config) S3Config() S3Config {
+	return S3Config{
+		Endpoint:      fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID),
+		Region:        "auto",
+		Bucket:        cfg.BucketName,
+		AccessKey:     cfg.AccessKeyID,
+		Secret:        cfg.AccessKeySecret,
+		PublicBaseURL: cfg.Domain,
+		TimestampKeys: cfg.TimestampKeys,
+	}
 }
 
 // LoadR2ConfigFromEnv reads the standard R2_* environment variables and returns
@@ -47,59 +50,23 @@ func LoadR2ConfigFromEnv() (R2Config, error) {
 		return R2Config{}, ErrMissingR2Config
 	}
 
+	var err error
+	if cfg.TimestampKeys, err = boolEnv("R2_TIMESTAMP_KEYS"); err != nil {
+		return R2Config{}, err
+	}
 	return cfg, nil
 }
 
-// NewR2ClientFromEnv constructs an R2 client using environment variables.
+// NewR2ClientFromEnv constructs a storage client from the R2_* environment
+// variables, or from the S3_* variables when R2 is not configured.
 func NewR2ClientFromEnv() (*R2Client, error) {
-	cfg, err := LoadR2ConfigFromEnv()
+	cfg, err := LoadStorageConfigFromEnv()
 	if err != nil {
 		return nil, err
 	}
-	return NewR2Client(cfg)
+	return NewS3Client(cfg)
 }
 
 func NewR2Client(cfg R2Config) (*R2Client, error) {
-	r2Resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
-		return aws.Endpoint{
-			URL: fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID),
-		}, nil
-	})
-
-	awsCfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithEndpointResolverWithOptions(r2Resolver),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			cfg.AccessKeyID,
-			cfg.AccessKeySecret,
-			"",
-		)),
-		config.WithRegion("auto"),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load AWS SDK config: %v", err)
-	}
-
-	client := s3.NewFromConfig(awsCfg)
-	return &R2Client{
-		client:     client,
-		bucketName: cfg.BucketName,
-		domain:     cfg.Domain,
-	}, nil
-}
-
-func (c *R2Client) UploadImage(ctx context.Context, imageData []byte, contentType string) (string, error) {
-	key := fmt.Sprintf("mindmaps/%s_%s.png", time.Now().Format("20060102150405"), uuid.New().String()[:8])
-
-	_, err := c.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(c.bucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(imageData),
-		ContentType: aws.String(contentType),
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload image: %v", err)
-	}
-
-	// Return public URL
-	return fmt.Sprintf("%s/%s", c.domain, key), nil
+	return NewS3Client(cfg.S3Config())
 }
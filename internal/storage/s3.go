@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+)
+
+var ErrMissingS3Config = errors.New("missing S3 storage configuration")
+
+// defaultS3Region is used when S3Config.Region is empty.
+const defaultS3Region = "us-east-1"
+
+// S3Config configures any S3-compatible object store: AWS S3, Cloudflare R2,
+// MinIO, Backblaze B2 and the like.
+type S3Config struct {
+	Endpoint      string // API endpoint URL; empty uses the AWS S3 endpoint for Region
+	Region        string // empty defaults to us-east-1
+	Bucket        string
+	AccessKey     string
+	Secret        string
+	PublicBaseURL string // uploaded objects are served at PublicBaseURL/<key>
+	UsePathStyle  bool   // address buckets as <endpoint>/<bucket>, as MinIO requires
+	TimestampKeys bool   // key objects by upload time instead of content hash
+}
+
+// LoadS3ConfigFromEnv reads the S3_* environment variables. If any required
+// value is missing, ErrMissingS3Config is returned.
+func LoadS3ConfigFromEnv() (S3Config, error) {
+	cfg := S3Config{
+		Endpoint:      os.Getenv("S3_ENDPOINT"),
+		Region:        os.Getenv("S3_REGION"),
+		Bucket:        os.Getenv("S3_BUCKET"),
+		AccessKey:     os.Getenv("S3_ACCESS_KEY"),
+		Secret:        os.Getenv("S3_SECRET"),
+		PublicBaseURL: os.Getenv("S3_PUBLIC_BASE_URL"),
+	}
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.Secret == "" || cfg.PublicBaseURL == "" {
+		return S3Config{}, ErrMissingS3Config
+	}
+	var err error
+	if cfg.UsePathStyle, err = boolEnv("S3_USE_PATH_STYLE"); err != nil {
+		return S3Config{}, err
+	}
+	if cfg.TimestampKeys, err = boolEnv("S3_TIMESTAMP_KEYS"); err != nil {
+		return S3Config{}, err
+	}
+	return cfg, nil
+}
+
+// boolEnv 读取布尔型环境变量，未设置时为 false
+func boolEnv(key string) (bool, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %v", key, raw, err)
+	}
+	return v, nil
+}
+
+// LoadStorageConfigFromEnv reads the R2_* environment variables, falling back
+// to the S3_* variables when R2 is not configured. ErrMissingR2Config is
+// returned when neither set is complete.
+func LoadStorageConfigFromEnv() (S3Config, error) {
+	r2, err := LoadR2ConfigFromEnv()
+	if err == nil {
+		return r2.S3Config(), nil
+	}
+	cfg, err := LoadS3ConfigFromEnv()
+	if errors.Is(err, ErrMissingS3Config) {
+		return S3Config{}, ErrMissingR2Config
+	}
+	return cfg, err
+}
+
+// S3Client uploads rendered mind maps to an S3-compatible bucket.
+type S3Client struct {
+	client        *s3.Client
+	bucket        string
+	publicBaseURL string
+	timestampKeys bool
+}
+
+func NewS3Client(cfg S3Config) (*S3Client, error) {
+	region := cfg.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.TODO(),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			cfg.AccessKey,
+			cfg.Secret,
+			"",
+		)),
+		config.WithRegion(region),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS SDK config: %v", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+	return &S3Client{
+		client:        client,
+		bucket:        cfg.Bucket,
+		publicBaseURL: strings.TrimRight(cfg.PublicBaseURL, "/"),
+		timestampKeys: cfg.TimestampKeys,
+	}, nil
+}
+
+// UploadImage stores imageData and returns its public URL. By default objects
+// are keyed by the SHA-256 of their content, so an image that was uploaded
+// before is not uploaded again.
+func (c *S3Client) UploadImage(ctx context.Context, imageData []byte, contentType string) (string, error) {
+	key := objectKey(imageData, contentType, c.timestampKeys)
+
+	// 内容寻址的对象已存在时跳过上传；HeadObject 的其他错误（如无权限）不影响上传
+	if !c.timestampKeys {
+		_, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(c.bucket),
+			Key:    aws.String(key),
+		})
+		if err == nil {
+			return c.publicURL(key), nil
+		}
+	}
+
+	_, err := c.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(imageData),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload image: %v", err)
+	}
+
+	// Return public URL
+	return c.publicURL(key), nil
+}
+
+func (c *S3Client) publicURL(key string) string {
+	return fmt.Sprintf("%s/%s", c.publicBaseURL, key)
+}
+
+// objectKey 生成对象键：默认为内容的 SHA-256，相同内容得到相同的键；
+// timestamped 时为上传时间加 UUID，不会冲突。扩展名按内容类型选择
+func objectKey(data []byte, contentType string, timestamped bool) string {
+	ext := "png"
+	switch contentType {
+	case "application/pdf":
+		ext = "pdf"
+	case "image/svg+xml":
+		ext = "svg"
+	case "image/gif":
+		ext = "gif"
+	case "image/jpeg":
+		ext = "jpg"
+	case "application/zip":
+		ext = "mmz"
+	}
+	if timestamped {
+		return fmt.Sprintf("mindmaps/%s_%s.%s", time.Now().Format("20060102150405"), uuid.New().String(), ext)
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("mindmaps/%s.%s", hex.EncodeToString(sum[:]), ext)
+}
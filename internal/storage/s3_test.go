@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func clearStorageEnv(t *testing.T) {
+	for _, key := range []string{
+		"R2_ACCOUNT_ID", "R2_ACCESS_KEY_ID", "R2_ACCESS_KEY_SECRET", "R2_BUCKET_NAME", "R2_DOMAIN",
+		"S3_ENDPOINT", "S3_REGION", "S3_BUCKET", "S3_ACCESS_KEY", "S3_SECRET", "S3_PUBLIC_BASE_URL", "S3_USE_PATH_STYLE",
+		"S3_TIMESTAMP_KEYS", "R2_TIMESTAMP_KEYS",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoadStorageConfigFromEnv(t *testing.T) {
+	clearStorageEnv(t)
+	if _, err := LoadStorageConfigFromEnv(); !errors.Is(err, ErrMissingR2Config) {
+		t.Fatalf("expected ErrMissingR2Config without any configuration, got %v", err)
+	}
+
+	t.Setenv("S3_ENDPOINT", "http://localhost:9000")
+	t.Setenv("S3_BUCKET", "maps")
+	t.Setenv("S3_ACCESS_KEY", "minio")
+	t.Setenv("S3_SECRET", "secret")
+	t.Setenv("S3_PUBLIC_BASE_URL", "http://localhost:9000/maps")
+	t.Setenv("S3_USE_PATH_STYLE", "true")
+	cfg, err := LoadStorageConfigFromEnv()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Endpoint != "http://localhost:9000" || cfg.Bucket != "maps" || !cfg.UsePathStyle {
+		t.Fatalf("unexpected S3 config %+v", cfg)
+	}
+
+	t.Setenv("S3_USE_PATH_STYLE", "sometimes")
+	if _, err := LoadStorageConfigFromEnv(); err == nil || errors.Is(err, ErrMissingR2Config) {
+		t.Fatalf("expected an invalid S3_USE_PATH_STYLE error, got %v", err)
+	}
+
+	// R2_* 优先于 S3_*
+	t.Setenv("R2_ACCOUNT_ID", "acct")
+	t.Setenv("R2_ACCESS_KEY_ID", "key")
+	t.Setenv("R2_ACCESS_KEY_SECRET", "secret")
+	t.Setenv("R2_BUCKET_NAME", "bucket")
+	t.Setenv("R2_DOMAIN", "https://cdn.example.com")
+	cfg, err = LoadStorageConfigFromEnv()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Endpoint != "https://acct.r2.cloudflarestorage.com" || cfg.Region != "auto" || cfg.PublicBaseURL != "https://cdn.example.com" {
+		t.Fatalf("unexpected R2 preset %+v", cfg)
+	}
+}
+
+func TestS3ClientPublicURL(t *testing.T) {
+	client, err := NewS3Client(S3Config{Bucket: "maps", AccessKey: "k", Secret: "s", PublicBaseURL: "https://cdn.example.com/"})
+	if err != nil {
+		t.Fatalf("NewS3Client failed: %v", err)
+	}
+	if got := client.publicURL("mindmaps/a.png"); got != "https://cdn.example.com/mindmaps/a.png" {
+		t.Fatalf("unexpected public URL %q", got)
+	}
+}
+
+// fakeS3 以路径风格寻址的最小 S3 服务：支持 HEAD 与 PUT，记录每次上传
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodHead:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = data
+		f.puts++
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3ClientContentAddressedUpload(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	newClient := func(timestamped bool) *S3Client {
+		client, err := NewS3Client(S3Config{
+			Endpoint: srv.URL, Bucket: "maps", AccessKey: "k", Secret: "s",
+			PublicBaseURL: "https://cdn.example.com", UsePathStyle: true, TimestampKeys: timestamped,
+		})
+		if err != nil {
+			t.Fatalf("NewS3Client failed: %v", err)
+		}
+		return client
+	}
+
+	client := newClient(false)
+	ctx := context.Background()
+	first, err := client.UploadImage(ctx, []byte("same"), "image/png")
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	second, err := client.UploadImage(ctx, []byte("same"), "image/png")
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if first != second || fake.puts != 1 {
+		t.Fatalf("expected identical content to be uploaded once, got %q, %q after %d puts", first, second, fake.puts)
+	}
+	// SHA-256("same")
+	if want := "https://cdn.example.com/mindmaps/0967115f2813a3541eaef77de9d9d5773f1c0c04314b0bbfe4ff3b3b1c55b5d5.png"; first != want {
+		t.Fatalf("expected a content-hash key, got %q", first)
+	}
+	if other, _ := client.UploadImage(ctx, []byte("other"), "image/png"); other == first || fake.puts != 2 {
+		t.Fatalf("expected different content to get its own key, got %q after %d puts", other, fake.puts)
+	}
+
+	timestamped := newClient(true)
+	a, _ := timestamped.UploadImage(ctx, []byte("same"), "image/png")
+	b, _ := timestamped.UploadImage(ctx, []byte("same"), "image/png")
+	if a == b || fake.puts != 4 || !strings.HasSuffix(a, ".png") {
+		t.Fatalf("expected distinct timestamped keys for each upload, got %q, %q after %d puts", a, b, fake.puts)
+	}
+}
@@ -1,11 +1,16 @@
 package theme
 
-import "github.com/hellodeveye/mindmapgen/pkg/types"
+import (
+	"fmt"
+
+	"github.com/hellodeveye/mindmapgen/pkg/types"
+)
 
 // ColorConfig 颜色配置
 type ColorConfig struct {
 	Background     string `yaml:"background"`
 	ConnectionLine string `yaml:"connectionLine"`
+	Marker         string `yaml:"marker,omitempty"` // ==高亮== 文本的背景色
 }
 
 // NodeStyleConfig 节点样式配置
@@ -13,14 +18,41 @@ type NodeStyleConfig struct {
 	FillColor   [3]float64 `yaml:"fillColor"`
 	StrokeColor [3]float64 `yaml:"strokeColor"`
 	TextColor   [3]float64 `yaml:"textColor"`
+	LineHeight  float64    `yaml:"lineHeight,omitempty"` // 该层级的行高，0 表示使用 layout.lineHeight
+	// FillGradient 可选的线性渐变填充，设置后代替 fillColor；不支持渐变的输出仍使用 fillColor
+	FillGradient *GradientConfig `yaml:"fillGradient,omitempty"`
+}
+
+// GradientConfig 线性渐变配置，颜色分量 0-1；angle 为渐变方向（度），0 为从左到右，90 为从上到下
+type GradientConfig struct {
+	From  [3]float64 `yaml:"from"`
+	To    [3]float64 `yaml:"to"`
+	Angle float64    `yaml:"angle,omitempty"`
 }
 
 // NodeStylesConfig 所有节点类型的样式配置
 type NodeStylesConfig struct {
-	Root   NodeStyleConfig `yaml:"root"`
-	Level1 NodeStyleConfig `yaml:"level1"`
-	Level2 NodeStyleConfig `yaml:"level2"`
-	Leaf   NodeStyleConfig `yaml:"leaf"`
+	Root NodeStyleConfig `yaml:"root"`
+	// Levels 按距根节点的深度依次取样式（第一项为根节点的子节点），更深的节点沿用最后一项
+	Levels []NodeStyleConfig `yaml:"levels,omitempty"`
+	Level1 NodeStyleConfig   `yaml:"level1"` // 兼容旧配置，未设置 levels 时作为第一层
+	Level2 NodeStyleConfig   `yaml:"level2"` // 兼容旧配置，未设置 levels 时作为第二层
+	Leaf   NodeStyleConfig   `yaml:"leaf"`
+	// Highlight 匹配高亮搜索词的节点样式，未设置时由 colors.marker 生成
+	Highlight *NodeStyleConfig `yaml:"highlight,omitempty"`
+}
+
+// LevelStyles 返回按深度排列的层级样式。未设置 levels 时由 level1/level2 组成，
+// 未配置的层级会被忽略。
+func (nsc NodeStylesConfig) LevelStyles() []NodeStyleConfig {
+	if len(nsc.Levels) > 0 {
+		return nsc.Levels
+	}
+	levels := []NodeStyleConfig{nsc.Level1, nsc.Level2}
+	for len(levels) > 0 && levels[len(levels)-1] == (NodeStyleConfig{}) {
+		levels = levels[:len(levels)-1]
+	}
+	return levels
 }
 
 // SketchConfig 手绘风格配置
@@ -32,18 +64,44 @@ type SketchConfig struct {
 	Seed          int64   `yaml:"seed"`          // 随机种子，确保一致性
 }
 
+// ShadowConfig 节点阴影配置，偏移与模糊半径为未缩放的布局单位
+type ShadowConfig struct {
+	OffsetX float64 `yaml:"offsetX"`
+	OffsetY float64 `yaml:"offsetY"`
+	Blur    float64 `yaml:"blur,omitempty"`  // 模糊半径，当前以实心阴影绘制
+	Color   string  `yaml:"color,omitempty"` // 阴影颜色（十六进制），默认黑色
+	Alpha   float64 `yaml:"alpha,omitempty"` // 不透明度 (0-1]，默认 0.15
+}
+
 // LayoutConfig 布局配置
 type LayoutConfig struct {
-	MinNodeWidth  float64 `yaml:"minNodeWidth"`
-	MaxNodeWidth  float64 `yaml:"maxNodeWidth"`
-	MinNodeHeight float64 `yaml:"minNodeHeight"`
-	LevelSpacing  float64 `yaml:"levelSpacing"`
-	NodeSpacing   float64 `yaml:"nodeSpacing"`
-	CornerRadius  float64 `yaml:"cornerRadius"`
-	FontSize      float64 `yaml:"fontSize"`
-	Scale         float64 `yaml:"scale"`
-	LineHeight    float64 `yaml:"lineHeight"`
-	TextPadding   float64 `yaml:"textPadding"`
+	MinNodeWidth     float64 `yaml:"minNodeWidth"`
+	MaxNodeWidth     float64 `yaml:"maxNodeWidth"`
+	MinNodeHeight    float64 `yaml:"minNodeHeight"`
+	LevelSpacing     float64 `yaml:"levelSpacing"`
+	RootLevelSpacing float64 `yaml:"rootLevelSpacing,omitempty"` // 根节点与一级节点的间距，0 表示使用 levelSpacing
+	NodeSpacing      float64 `yaml:"nodeSpacing"`
+	CornerRadius     float64 `yaml:"cornerRadius"`
+	FontSize         float64 `yaml:"fontSize"`
+	Scale            float64 `yaml:"scale"`
+	LineHeight       float64 `yaml:"lineHeight"`
+	TextPadding      float64 `yaml:"textPadding"`
+	MinScale         float64 `yaml:"minScale,omitempty"`       // 允许的最小缩放，0 表示不限制
+	MaxScale         float64 `yaml:"maxScale,omitempty"`       // 允许的最大缩放，0 表示不限制
+	Arrows           bool    `yaml:"arrows,omitempty"`         // 在连接线的子节点一端绘制箭头
+	ConnectorStyle   string  `yaml:"connectorStyle,omitempty"` // 连接线样式: bezier（默认）, straight, elbow
+	TextAlign        string  `yaml:"textAlign,omitempty"`      // 节点内文本的对齐方式: left, center（默认）, right
+}
+
+// ClampScale 将缩放值限制在主题推荐的范围内，返回结果以及是否发生了截断
+func (lc LayoutConfig) ClampScale(scale float64) (float64, bool) {
+	if lc.MinScale > 0 && scale < lc.MinScale {
+		return lc.MinScale, true
+	}
+	if lc.MaxScale > 0 && scale > lc.MaxScale {
+		return lc.MaxScale, true
+	}
+	return scale, false
 }
 
 // ThemeConfig 主题配置
@@ -54,25 +112,102 @@ type ThemeConfig struct {
 	NodeStyles   NodeStylesConfig `yaml:"nodeStyles"`
 	Layout       LayoutConfig     `yaml:"layout"`
 	SketchConfig *SketchConfig    `yaml:"sketchConfig,omitempty"` // 仅手绘风格需要
+	Shadow       *ShadowConfig    `yaml:"shadow,omitempty"`       // 节点阴影，未设置时不绘制
+	// BranchPalette 可选的分支配色（十六进制颜色），根节点的每个子分支轮流取色，整棵子树沿用该颜色
+	BranchPalette []string `yaml:"branchPalette,omitempty"`
+}
+
+// Clone 返回主题配置的深拷贝，修改副本不影响原配置
+func (tc *ThemeConfig) Clone() *ThemeConfig {
+	if tc == nil {
+		return nil
+	}
+	out := *tc
+	out.NodeStyles.Levels = append([]NodeStyleConfig(nil), tc.NodeStyles.Levels...)
+	if tc.NodeStyles.Highlight != nil {
+		highlight := *tc.NodeStyles.Highlight
+		out.NodeStyles.Highlight = &highlight
+	}
+	styles := []*NodeStyleConfig{&out.NodeStyles.Root, &out.NodeStyles.Level1, &out.NodeStyles.Level2, &out.NodeStyles.Leaf}
+	for i := range out.NodeStyles.Levels {
+		styles = append(styles, &out.NodeStyles.Levels[i])
+	}
+	if out.NodeStyles.Highlight != nil {
+		styles = append(styles, out.NodeStyles.Highlight)
+	}
+	for _, style := range styles {
+		style.cloneGradient()
+	}
+	if tc.SketchConfig != nil {
+		sketch := *tc.SketchConfig
+		out.SketchConfig = &sketch
+	}
+	if tc.Shadow != nil {
+		shadow := *tc.Shadow
+		out.Shadow = &shadow
+	}
+	out.BranchPalette = append([]string(nil), tc.BranchPalette...)
+	return &out
+}
+
+// cloneGradient 以副本替换渐变配置，使克隆的样式不与原样式共享
+func (nsc *NodeStyleConfig) cloneGradient() {
+	if nsc.FillGradient != nil {
+		gradient := *nsc.FillGradient
+		nsc.FillGradient = &gradient
+	}
 }
 
 // ToNodeStyle 将配置转换为NodeStyle结构
 func (nsc NodeStyleConfig) ToNodeStyle() *types.NodeStyle {
-	return &types.NodeStyle{
+	style := &types.NodeStyle{
 		FillColor:   nsc.FillColor,
 		StrokeColor: nsc.StrokeColor,
 		TextColor:   nsc.TextColor,
 	}
+	if g := nsc.FillGradient; g != nil {
+		style.FillGradient = &types.Gradient{From: g.From, To: g.To, Angle: g.Angle}
+	}
+	return style
 }
 
-// GetNodeStyles 获取所有节点样式
+// GetNodeStyles 获取所有节点样式，层级样式以 level1、level2…… 为键
 func (tc *ThemeConfig) GetNodeStyles() map[string]*types.NodeStyle {
-	return map[string]*types.NodeStyle{
-		"root":   tc.NodeStyles.Root.ToNodeStyle(),
-		"level1": tc.NodeStyles.Level1.ToNodeStyle(),
-		"level2": tc.NodeStyles.Level2.ToNodeStyle(),
-		"leaf":   tc.NodeStyles.Leaf.ToNodeStyle(),
+	styles := map[string]*types.NodeStyle{
+		"root": tc.NodeStyles.Root.ToNodeStyle(),
+		"leaf": tc.NodeStyles.Leaf.ToNodeStyle(),
+	}
+	for i, level := range tc.NodeStyles.LevelStyles() {
+		styles[fmt.Sprintf("level%d", i+1)] = level.ToNodeStyle()
+	}
+	return styles
+}
+
+// LevelStyle 返回深度为 depth（根节点的子节点为 1）的非叶子节点样式，
+// 超出已定义层级时使用最后一层；未定义任何层级时返回 nil。
+func (tc *ThemeConfig) LevelStyle(depth int) *types.NodeStyle {
+	levels := tc.NodeStyles.LevelStyles()
+	if len(levels) == 0 {
+		return nil
+	}
+	index := min(max(depth, 1), len(levels)) - 1
+	return levels[index].ToNodeStyle()
+}
+
+// LineHeightFor 返回节点所在层级的行高，层级划分与节点样式一致：根节点、叶子节点、
+// 其余节点按深度取 levels。该层级未设置行高时返回 0。
+func (tc *ThemeConfig) LineHeightFor(depth int, leaf bool) float64 {
+	switch {
+	case depth == 0:
+		return tc.NodeStyles.Root.LineHeight
+	case leaf:
+		return tc.NodeStyles.Leaf.LineHeight
+	}
+	levels := tc.NodeStyles.LevelStyles()
+	if len(levels) == 0 {
+		return 0
 	}
+	return levels[min(depth, len(levels))-1].LineHeight
 }
 
 // IsSketchStyle 判断是否为手绘风格
@@ -0,0 +1,111 @@
+package theme
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLevelStyle(t *testing.T) {
+	var tc ThemeConfig
+	data := `
+nodeStyles:
+  levels:
+    - fillColor: [0.1, 0.1, 0.1]
+    - fillColor: [0.2, 0.2, 0.2]
+    - fillColor: [0.3, 0.3, 0.3]
+  level1:
+    fillColor: [0.9, 0.9, 0.9]
+`
+	if err := yaml.Unmarshal([]byte(data), &tc); err != nil {
+		t.Fatal(err)
+	}
+
+	// levels 优先于旧的 level1/level2，超出的深度沿用最后一层
+	for depth, want := range map[int]float64{1: 0.1, 2: 0.2, 3: 0.3, 6: 0.3} {
+		if got := tc.LevelStyle(depth).FillColor[0]; got != want {
+			t.Errorf("depth %d: fill %v, want %v", depth, got, want)
+		}
+	}
+	if _, ok := tc.GetNodeStyles()["level3"]; !ok {
+		t.Error("expected GetNodeStyles to expose level3")
+	}
+}
+
+func TestLevelStyleLegacyKeys(t *testing.T) {
+	legacy := ThemeConfig{NodeStyles: NodeStylesConfig{
+		Level1: NodeStyleConfig{FillColor: [3]float64{0.1, 0.1, 0.1}},
+		Level2: NodeStyleConfig{FillColor: [3]float64{0.2, 0.2, 0.2}},
+	}}
+	if got := legacy.LevelStyle(1).FillColor[0]; got != 0.1 {
+		t.Errorf("depth 1: fill %v, want level1", got)
+	}
+	if got := legacy.LevelStyle(4).FillColor[0]; got != 0.2 {
+		t.Errorf("depth 4: fill %v, want level2", got)
+	}
+
+	onlyLevel1 := ThemeConfig{NodeStyles: NodeStylesConfig{Level1: legacy.NodeStyles.Level1}}
+	if got := onlyLevel1.LevelStyle(2).FillColor[0]; got != 0.1 {
+		t.Errorf("expected missing level2 to fall back to level1, got %v", got)
+	}
+	if (&ThemeConfig{}).LevelStyle(1) != nil {
+		t.Error("expected nil style when no levels are defined")
+	}
+}
+
+func TestLineHeightFor(t *testing.T) {
+	tc := ThemeConfig{NodeStyles: NodeStylesConfig{
+		Root:   NodeStyleConfig{LineHeight: 30},
+		Levels: []NodeStyleConfig{{LineHeight: 24}, {}},
+		Leaf:   NodeStyleConfig{LineHeight: 16},
+	}}
+	cases := []struct {
+		depth int
+		leaf  bool
+		want  float64
+	}{
+		{0, false, 30},
+		{0, true, 30},
+		{1, false, 24},
+		{2, false, 0}, // 未设置时交由调用方使用全局行高
+		{3, true, 16},
+	}
+	for _, c := range cases {
+		if got := tc.LineHeightFor(c.depth, c.leaf); got != c.want {
+			t.Errorf("depth %d leaf %v: got %v, want %v", c.depth, c.leaf, got, c.want)
+		}
+	}
+}
+
+func TestFillGradient(t *testing.T) {
+	var tc ThemeConfig
+	data := `
+name: gradient
+nodeStyles:
+  root:
+    fillColor: [0.1, 0.1, 0.1]
+    fillGradient:
+      from: [1.0, 0.0, 0.0]
+      to: [0.0, 0.0, 1.0]
+      angle: 45
+  leaf:
+    fillColor: [1.0, 1.0, 1.0]
+`
+	if err := yaml.Unmarshal([]byte(data), &tc); err != nil {
+		t.Fatal(err)
+	}
+	root := tc.NodeStyles.Root.ToNodeStyle()
+	if root.FillGradient == nil || root.FillGradient.From != [3]float64{1, 0, 0} || root.FillGradient.Angle != 45 {
+		t.Fatalf("expected the root gradient, got %+v", root.FillGradient)
+	}
+	// 只有 fillColor 的样式保持纯色填充
+	if leaf := tc.NodeStyles.Leaf.ToNodeStyle(); leaf.FillGradient != nil || leaf.FillColor != [3]float64{1, 1, 1} {
+		t.Fatalf("expected a solid leaf fill, got %+v", leaf)
+	}
+
+	clone := tc.Clone()
+	clone.NodeStyles.Root.FillGradient.Angle = 90
+	if tc.NodeStyles.Root.FillGradient.Angle != 45 {
+		t.Error("expected the clone to copy the gradient")
+	}
+}
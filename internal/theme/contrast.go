@@ -0,0 +1,89 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DefaultMinContrast WCAG AA 对正文文本要求的最小对比度 4.5:1
+const DefaultMinContrast = 4.5
+
+// ContrastRatio 返回两种 RGB 颜色（分量 0-1）的 WCAG 对比度，范围 1-21
+func ContrastRatio(a, b [3]float64) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// relativeLuminance 按 WCAG 定义计算 sRGB 颜色的相对亮度
+func relativeLuminance(c [3]float64) float64 {
+	linear := func(v float64) float64 {
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c[0]) + 0.7152*linear(c[1]) + 0.0722*linear(c[2])
+}
+
+// namedNodeStyle 主题中的一个节点样式，名称与 GetNodeStyles 的键一致
+type namedNodeStyle struct {
+	name  string
+	style *NodeStyleConfig
+}
+
+// nodeStyleRefs 按根节点、各层级、叶子节点的顺序返回可修改的节点样式。
+// 未设置 levels 时返回 level1/level2，与 LevelStyles 的取值一致。
+func (tc *ThemeConfig) nodeStyleRefs() []namedNodeStyle {
+	refs := []namedNodeStyle{{"root", &tc.NodeStyles.Root}}
+	if len(tc.NodeStyles.Levels) > 0 {
+		for i := range tc.NodeStyles.Levels {
+			refs = append(refs, namedNodeStyle{fmt.Sprintf("level%d", i+1), &tc.NodeStyles.Levels[i]})
+		}
+	} else {
+		for i, level := range []*NodeStyleConfig{&tc.NodeStyles.Level1, &tc.NodeStyles.Level2}[:len(tc.NodeStyles.LevelStyles())] {
+			refs = append(refs, namedNodeStyle{fmt.Sprintf("level%d", i+1), level})
+		}
+	}
+	return append(refs, namedNodeStyle{"leaf", &tc.NodeStyles.Leaf})
+}
+
+// ValidateTheme 检查主题中每个节点样式的文本色与填充色的对比度是否达到 minContrast，
+// minContrast <= 0 时使用 DefaultMinContrast。所有不达标的样式合并为一个错误返回。
+func ValidateTheme(tc *ThemeConfig, minContrast float64) error {
+	if minContrast <= 0 {
+		minContrast = DefaultMinContrast
+	}
+	var errs []error
+	for _, ref := range tc.nodeStyleRefs() {
+		if ratio := ContrastRatio(ref.style.TextColor, ref.style.FillColor); ratio < minContrast {
+			errs = append(errs, fmt.Errorf("theme %q: %s text contrast %.2f:1 is below %.2f:1", tc.Name, ref.name, ratio, minContrast))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FixContrast 将对比度低于 minContrast 的节点样式的文本色改为黑色或白色中与填充色对比度更高的一个，
+// 返回被修改的样式名称。minContrast <= 0 时使用 DefaultMinContrast。
+func (tc *ThemeConfig) FixContrast(minContrast float64) []string {
+	if minContrast <= 0 {
+		minContrast = DefaultMinContrast
+	}
+	var fixed []string
+	for _, ref := range tc.nodeStyleRefs() {
+		if ContrastRatio(ref.style.TextColor, ref.style.FillColor) >= minContrast {
+			continue
+		}
+		black, white := [3]float64{0, 0, 0}, [3]float64{1, 1, 1}
+		if ContrastRatio(black, ref.style.FillColor) >= ContrastRatio(white, ref.style.FillColor) {
+			ref.style.TextColor = black
+		} else {
+			ref.style.TextColor = white
+		}
+		fixed = append(fixed, ref.name)
+	}
+	return fixed
+}
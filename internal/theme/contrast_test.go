@@ -0,0 +1,95 @@
+package theme
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestContrastRatio(t *testing.T) {
+	if got := ContrastRatio([3]float64{0, 0, 0}, [3]float64{1, 1, 1}); math.Abs(got-21) > 1e-9 {
+		t.Errorf("black on white: got %.2f, want 21", got)
+	}
+	if got := ContrastRatio([3]float64{0.5, 0.5, 0.5}, [3]float64{0.5, 0.5, 0.5}); got != 1 {
+		t.Errorf("identical colors: got %.2f, want 1", got)
+	}
+}
+
+func TestValidateTheme(t *testing.T) {
+	var low ThemeConfig
+	data := `
+name: low
+nodeStyles:
+  root:
+    fillColor: [0.2, 0.2, 0.2]
+    textColor: [1.0, 1.0, 1.0]
+  levels:
+    - fillColor: [0.9, 0.9, 0.9]
+      textColor: [0.0, 0.0, 0.0]
+    - fillColor: [0.6, 0.6, 0.6]
+      textColor: [0.7, 0.7, 0.7]
+  leaf:
+    fillColor: [1.0, 1.0, 1.0]
+    textColor: [0.75, 0.75, 0.75]
+`
+	if err := yaml.Unmarshal([]byte(data), &low); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ValidateTheme(&low, 0)
+	if err == nil {
+		t.Fatal("expected the low-contrast theme to fail validation")
+	}
+	for _, name := range []string{"level2", "leaf"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected the error to report %s, got: %v", name, err)
+		}
+	}
+	if strings.Contains(err.Error(), "root") || strings.Contains(err.Error(), "level1") {
+		t.Errorf("expected passing styles not to be reported, got: %v", err)
+	}
+
+	// 阈值可配置：放宽到 1.1:1 后 level2 与 leaf 均达标
+	if err := ValidateTheme(&low, 1.1); err != nil {
+		t.Errorf("expected the theme to pass a 1.1:1 threshold, got: %v", err)
+	}
+
+	fixed := low.FixContrast(0)
+	if strings.Join(fixed, ",") != "level2,leaf" {
+		t.Errorf("expected level2 and leaf to be fixed, got %v", fixed)
+	}
+	if err := ValidateTheme(&low, 0); err != nil {
+		t.Errorf("expected the fixed theme to pass, got: %v", err)
+	}
+	if low.NodeStyles.Leaf.TextColor != [3]float64{0, 0, 0} {
+		t.Errorf("expected black text on the white leaf, got %v", low.NodeStyles.Leaf.TextColor)
+	}
+}
+
+func TestValidateThemeHighContrast(t *testing.T) {
+	var high ThemeConfig
+	data := `
+name: high
+nodeStyles:
+  root:
+    fillColor: [0.0, 0.0, 0.0]
+    textColor: [1.0, 1.0, 1.0]
+  level1:
+    fillColor: [0.96, 0.97, 0.98]
+    textColor: [0.0, 0.0, 0.0]
+  leaf:
+    fillColor: [1.0, 1.0, 1.0]
+    textColor: [0.1, 0.1, 0.1]
+`
+	if err := yaml.Unmarshal([]byte(data), &high); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateTheme(&high, DefaultMinContrast); err != nil {
+		t.Errorf("expected the high-contrast theme to pass, got: %v", err)
+	}
+	if fixed := high.FixContrast(0); len(fixed) != 0 {
+		t.Errorf("expected nothing to fix, got %v", fixed)
+	}
+}
@@ -3,7 +3,11 @@ package theme
 import (
 	"embed"
 	"fmt"
+	"io/fs"
+	"log"
+	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -13,10 +17,21 @@ import (
 //go:embed themes/*.yaml
 var themesFS embed.FS
 
+// DefaultMaxExternalThemes 从外部目录加载主题的默认数量上限
+const DefaultMaxExternalThemes = 100
+
 // Manager 主题管理器
 type Manager struct {
 	themes map[string]*ThemeConfig
 	mu     sync.RWMutex
+
+	maxExternal int // 外部目录主题数量上限，<= 0 表示不限制
+	external    int // 已从外部目录加载的主题数量
+
+	dirs       []string                // 已加载的外部主题目录，按加载顺序，供 Reload 重新读取
+	registered map[string]*ThemeConfig // 以代码注册的主题，Reload 后保留
+	reloadMu   sync.Mutex              // 串行化 Reload，读取文件期间不持有 mu
+	generation uint64                  // 主题表每次变化时递增
 }
 
 var (
@@ -39,47 +54,226 @@ func GetManager() *Manager {
 // NewManager 创建新的主题管理器
 func NewManager() *Manager {
 	return &Manager{
-		themes: make(map[string]*ThemeConfig),
+		themes:      make(map[string]*ThemeConfig),
+		maxExternal: DefaultMaxExternalThemes,
 	}
 }
 
+// SetMaxExternalThemes 设置 LoadThemesFromDir 累计加载的主题数量上限，
+// 防止超大目录占用过多内存。n <= 0 表示不限制；内嵌主题与 RegisterTheme 不计入。
+func (m *Manager) SetMaxExternalThemes(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxExternal = n
+}
+
 // LoadEmbeddedThemes 加载嵌入的主题文件
 func (m *Manager) LoadEmbeddedThemes() error {
+	themes, err := readThemes(themesFS, "themes", 0)
+	if err != nil {
+		return fmt.Errorf("failed to read themes directory: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, theme := range themes {
+		m.themes[id] = theme
+	}
+
+	// 如果没有加载到任何主题，设置默认主题
+	if len(m.themes) == 0 {
+		m.setDefaultTheme()
+	}
+
+	return nil
+}
+
+// LoadThemesFromDir 从文件系统目录加载 *.yaml 主题并合并到已有主题中，
+// 与已有主题 ID 相同的文件会覆盖原主题（例如覆盖内嵌的 default 主题）。
+// 累计加载数量达到上限后，其余文件会被跳过并记录警告，见 SetMaxExternalThemes。
+func (m *Manager) LoadThemesFromDir(dir string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	entries, err := themesFS.ReadDir("themes")
+	limit := 0
+	if m.maxExternal > 0 {
+		limit = m.maxExternal - m.external
+		if limit <= 0 {
+			log.Printf("warning: theme limit of %d reached, skipping themes in %s", m.maxExternal, dir)
+			return nil
+		}
+	}
+
+	themes, err := readThemes(os.DirFS(dir), ".", limit)
 	if err != nil {
-		return fmt.Errorf("failed to read themes directory: %w", err)
+		return fmt.Errorf("failed to read themes directory %s: %w", dir, err)
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") {
-			data, err := themesFS.ReadFile(path.Join("themes", entry.Name()))
-			if err != nil {
-				continue // 跳过无法读取的文件
-			}
+	for id, theme := range themes {
+		m.themes[id] = theme
+	}
+	m.external += len(themes)
+	m.dirs = append(m.dirs, dir)
+	m.generation++
+	return nil
+}
 
-			var theme ThemeConfig
-			if err := yaml.Unmarshal(data, &theme); err != nil {
-				continue // 跳过无法解析的文件
-			}
+// Reload 重新读取内嵌主题与所有已加载过的外部目录，并以代码注册的主题覆盖同名主题，
+// 然后在写锁下整体替换主题表。已有的 *ThemeConfig 不会被修改，
+// 正在使用旧配置渲染的请求不受影响。读取内嵌主题失败时保留原主题表并返回错误；
+// 外部目录读取失败时跳过该目录并记录警告。
+func (m *Manager) Reload() error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
 
-			// 使用文件名（不包含扩展名）作为主题ID
-			themeID := entry.Name()[:len(entry.Name())-5] // 移除.yaml扩展名
-			m.themes[themeID] = &theme
+	m.mu.RLock()
+	dirs := append([]string(nil), m.dirs...)
+	maxExternal := m.maxExternal
+	m.mu.RUnlock()
+
+	themes, err := readThemes(themesFS, "themes", 0)
+	if err != nil {
+		return fmt.Errorf("failed to read themes directory: %w", err)
+	}
+	external := 0
+	for _, dir := range dirs {
+		limit := 0
+		if maxExternal > 0 {
+			limit = maxExternal - external
+			if limit <= 0 {
+				log.Printf("warning: theme limit of %d reached, skipping themes in %s", maxExternal, dir)
+				continue
+			}
+		}
+		loaded, err := readThemes(os.DirFS(dir), ".", limit)
+		if err != nil {
+			log.Printf("warning: failed to reload themes directory %s: %v", dir, err)
+			continue
 		}
+		for id, theme := range loaded {
+			themes[id] = theme
+		}
+		external += len(loaded)
 	}
 
-	// 如果没有加载到任何主题，设置默认主题
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, theme := range m.registered {
+		themes[id] = theme
+	}
+	m.themes = themes
+	m.external = external
+	m.generation++
 	if len(m.themes) == 0 {
 		m.setDefaultTheme()
 	}
+	return nil
+}
+
+// Generation 返回主题表的版本号，加载、注册或重新加载主题后递增。
+// 调用方可将其纳入缓存键，使主题变化后旧的渲染结果失效。
+func (m *Manager) Generation() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.generation
+}
 
+// 服务端读取用户主题目录及其数量上限的环境变量
+const (
+	ThemesDirEnv = "MINDMAP_THEMES_DIR"
+	MaxThemesEnv = "MINDMAP_MAX_THEMES"
+)
+
+// LoadThemesFromEnv 在设置了 MINDMAP_THEMES_DIR 时，将该目录中的主题加载到全局管理器。
+// MINDMAP_MAX_THEMES 可覆盖外部主题数量上限。
+func LoadThemesFromEnv() error {
+	dir := strings.TrimSpace(os.Getenv(ThemesDirEnv))
+	if dir == "" {
+		return nil
+	}
+	manager := GetManager()
+	if raw := strings.TrimSpace(os.Getenv(MaxThemesEnv)); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", MaxThemesEnv, raw, err)
+		}
+		manager.SetMaxExternalThemes(n)
+	}
+	return manager.LoadThemesFromDir(dir)
+}
+
+// readThemes 读取目录中的 *.yaml 主题，以文件名（不含扩展名）作为主题 ID。
+// 无法读取、解析或未通过 Validate 校验的文件会被跳过并记录警告。limit > 0 时最多读取 limit 个主题。
+func readThemes(fsys fs.FS, dir string, limit int) (map[string]*ThemeConfig, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	themes := make(map[string]*ThemeConfig)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		if limit > 0 && len(themes) >= limit {
+			log.Printf("warning: theme limit reached, skipping %s and remaining files", entry.Name())
+			break
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			log.Printf("skipping theme %s: %v", entry.Name(), err)
+			continue
+		}
+
+		var theme ThemeConfig
+		if err := yaml.Unmarshal(data, &theme); err != nil {
+			log.Printf("skipping theme %s: %v", entry.Name(), err)
+			continue
+		}
+		if err := theme.Validate(); err != nil {
+			log.Printf("warning: rejecting theme %s: %v", entry.Name(), err)
+			continue
+		}
+
+		themeID := strings.TrimSuffix(entry.Name(), ".yaml")
+		themes[themeID] = &theme
+	}
+	return themes, nil
+}
+
+// RegisterTheme 以代码注册主题，ID 已存在时覆盖原主题。可在服务运行期间调用。
+// 管理器保存 cfg 的副本，注册后再修改 cfg 不影响已注册的主题。
+func (m *Manager) RegisterTheme(id string, cfg *ThemeConfig) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("theme id must not be empty")
+	}
+	if cfg == nil {
+		return fmt.Errorf("theme %q has no config", id)
+	}
+
+	cfg = cfg.Clone()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.themes[id] = cfg
+	if m.registered == nil {
+		m.registered = make(map[string]*ThemeConfig)
+	}
+	m.registered[id] = cfg
+	m.generation++
 	return nil
 }
 
-// GetTheme 获取指定主题
+// Register 在全局主题管理器中注册主题，见 Manager.RegisterTheme
+func Register(id string, cfg *ThemeConfig) error {
+	return GetManager().RegisterTheme(id, cfg)
+}
+
+// GetTheme 获取指定主题的副本。主题不存在时回退到 default 主题且不返回错误，
+// 适用于内部兜底；需要校验用户输入时使用 GetThemeStrict。
+// 返回的副本归调用方所有：渲染期间重新加载或注册主题不会改变它，修改它也不影响管理器中的主题。
 func (m *Manager) GetTheme(name string) (*ThemeConfig, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -88,12 +282,24 @@ func (m *Manager) GetTheme(name string) (*ThemeConfig, error) {
 	if !exists {
 		// 如果主题不存在，返回默认主题
 		if defaultTheme, hasDefault := m.themes["default"]; hasDefault {
-			return defaultTheme, nil
+			return defaultTheme.Clone(), nil
 		}
 		return nil, fmt.Errorf("theme '%s' not found", name)
 	}
 
-	return theme, nil
+	return theme.Clone(), nil
+}
+
+// GetThemeStrict 获取指定主题的副本，主题不存在时返回错误而不回退到 default 主题
+func (m *Manager) GetThemeStrict(name string) (*ThemeConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	theme, exists := m.themes[name]
+	if !exists {
+		return nil, fmt.Errorf("theme '%s' not found", name)
+	}
+	return theme.Clone(), nil
 }
 
 // ListThemes 列出所有可用主题
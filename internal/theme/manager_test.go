@@ -0,0 +1,226 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// themeYAML 返回以内嵌 default 主题为基础、名称为 name 的完整主题文件内容
+func themeYAML(t *testing.T, name string) string {
+	t.Helper()
+	data, err := themesFS.ReadFile("themes/default.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Replace(string(data), `name: "Default Theme"`, fmt.Sprintf("name: %q", name), 1)
+}
+
+func TestLoadThemesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ocean.yaml":   strings.Replace(themeYAML(t, "Ocean"), `"#FFFFFF"`, `"#003366"`, 1),
+		"default.yaml": themeYAML(t, "Custom Default"),
+		"broken.yaml":  "name: [unterminated\n",
+		"invalid.yaml": strings.Replace(themeYAML(t, "Invalid"), "scale: 3.0", "scale: -1", 1),
+		"notes.txt":    "not a theme",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewManager()
+	if err := m.LoadEmbeddedThemes(); err != nil {
+		t.Fatalf("LoadEmbeddedThemes failed: %v", err)
+	}
+	if err := m.LoadThemesFromDir(dir); err != nil {
+		t.Fatalf("LoadThemesFromDir failed: %v", err)
+	}
+
+	ocean, err := m.GetThemeStrict("ocean")
+	if err != nil || ocean.Colors.Background != "#003366" {
+		t.Fatalf("expected theme loaded from disk, got %+v, %v", ocean, err)
+	}
+	if def, _ := m.GetThemeStrict("default"); def.Name != "Custom Default" {
+		t.Errorf("expected disk theme to override the embedded default, got %q", def.Name)
+	}
+	if _, err := m.GetThemeStrict("broken"); err == nil {
+		t.Error("expected invalid theme file to be skipped")
+	}
+	if _, err := m.GetThemeStrict("invalid"); err == nil {
+		t.Error("expected a theme that fails validation to be rejected")
+	}
+	if _, err := m.GetThemeStrict("dark"); err != nil {
+		t.Errorf("expected embedded themes to be kept: %v", err)
+	}
+
+	if err := m.LoadThemesFromDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestLoadThemesFromDirLimit(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("extra%d.yaml", i))
+		if err := os.WriteFile(name, []byte(themeYAML(t, fmt.Sprintf("Extra %d", i))), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewManager()
+	if err := m.LoadEmbeddedThemes(); err != nil {
+		t.Fatalf("LoadEmbeddedThemes failed: %v", err)
+	}
+	embedded := len(m.ListThemes())
+
+	m.SetMaxExternalThemes(3)
+	if err := m.LoadThemesFromDir(dir); err != nil {
+		t.Fatalf("LoadThemesFromDir failed: %v", err)
+	}
+	if got := len(m.ListThemes()) - embedded; got != 3 {
+		t.Fatalf("expected 3 external themes, got %d", got)
+	}
+
+	// 上限按累计数量计算，再次加载不会超出
+	if err := m.LoadThemesFromDir(dir); err != nil {
+		t.Fatalf("LoadThemesFromDir failed: %v", err)
+	}
+	if got := len(m.ListThemes()) - embedded; got != 3 {
+		t.Fatalf("expected the cap to hold across loads, got %d external themes", got)
+	}
+	if _, err := m.GetThemeStrict("default"); err != nil {
+		t.Errorf("expected embedded themes to be kept: %v", err)
+	}
+}
+
+func TestRegisterTheme(t *testing.T) {
+	m := NewManager()
+	if err := m.RegisterTheme("", &ThemeConfig{}); err == nil {
+		t.Error("expected an error for an empty id")
+	}
+	if err := m.RegisterTheme("brand", nil); err == nil {
+		t.Error("expected an error for a nil config")
+	}
+
+	cfg := &ThemeConfig{Name: "Brand", Colors: ColorConfig{Background: "#fafafa"}}
+	if err := m.RegisterTheme("brand", cfg); err != nil {
+		t.Fatalf("RegisterTheme failed: %v", err)
+	}
+	if got, err := m.GetThemeStrict("brand"); err != nil || !reflect.DeepEqual(got, cfg) {
+		t.Fatalf("expected registered theme, got %+v, %v", got, err)
+	}
+
+	// 管理器保存副本：注册后修改原配置或修改取得的主题都不影响已注册的主题
+	cfg.Name = "Changed"
+	got, _ := m.GetThemeStrict("brand")
+	got.Colors.Background = "#000000"
+	if again, _ := m.GetThemeStrict("brand"); again.Name != "Brand" || again.Colors.Background != "#fafafa" {
+		t.Errorf("expected the registered theme to stay unchanged, got %+v", again)
+	}
+}
+
+func TestThemeConfigClone(t *testing.T) {
+	original := &ThemeConfig{
+		Name:          "Full",
+		NodeStyles:    NodeStylesConfig{Levels: []NodeStyleConfig{{LineHeight: 20}}, Highlight: &NodeStyleConfig{LineHeight: 18}},
+		SketchConfig:  &SketchConfig{Seed: 7},
+		Shadow:        &ShadowConfig{OffsetX: 2},
+		BranchPalette: []string{"#ff0000"},
+	}
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("clone differs from the original: %+v", clone)
+	}
+
+	clone.NodeStyles.Levels[0].LineHeight = 30
+	clone.NodeStyles.Highlight.LineHeight = 30
+	clone.SketchConfig.Seed = 8
+	clone.Shadow.OffsetX = 4
+	clone.BranchPalette[0] = "#00ff00"
+	if original.NodeStyles.Levels[0].LineHeight != 20 || original.NodeStyles.Highlight.LineHeight != 18 ||
+		original.SketchConfig.Seed != 7 || original.Shadow.OffsetX != 2 || original.BranchPalette[0] != "#ff0000" {
+		t.Errorf("expected changes to the clone to leave the original untouched, got %+v", original)
+	}
+	if (*ThemeConfig)(nil).Clone() != nil {
+		t.Error("expected a nil clone of a nil config")
+	}
+}
+
+func TestReloadPicksUpNewThemes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ocean.yaml", themeYAML(t, "Ocean"))
+
+	m := NewManager()
+	if err := m.LoadEmbeddedThemes(); err != nil {
+		t.Fatalf("LoadEmbeddedThemes failed: %v", err)
+	}
+	if err := m.LoadThemesFromDir(dir); err != nil {
+		t.Fatalf("LoadThemesFromDir failed: %v", err)
+	}
+	if err := m.RegisterTheme("brand", &ThemeConfig{Name: "Brand"}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := m.GetThemeStrict("ocean")
+	generation := m.Generation()
+
+	write("forest.yaml", themeYAML(t, "Forest"))
+	write("ocean.yaml", themeYAML(t, "Deep Ocean"))
+	if err := m.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if m.Generation() == generation {
+		t.Errorf("expected the generation to change after reload")
+	}
+	if forest, err := m.GetThemeStrict("forest"); err != nil || forest.Name != "Forest" {
+		t.Fatalf("expected the new theme after reload, got %+v, %v", forest, err)
+	}
+	if ocean, _ := m.GetThemeStrict("ocean"); ocean.Name != "Deep Ocean" {
+		t.Errorf("expected the edited theme after reload, got %q", ocean.Name)
+	}
+	// 重新加载替换主题表，不修改渲染中可能仍在使用的旧配置
+	if before.Name != "Ocean" {
+		t.Errorf("expected the previous config to stay untouched, got %q", before.Name)
+	}
+	if _, err := m.GetThemeStrict("brand"); err != nil {
+		t.Errorf("expected registered themes to survive a reload: %v", err)
+	}
+	if _, err := m.GetThemeStrict("dark"); err != nil {
+		t.Errorf("expected embedded themes after reload: %v", err)
+	}
+}
+
+func TestReloadConcurrentReads(t *testing.T) {
+	m := NewManager()
+	if err := m.LoadEmbeddedThemes(); err != nil {
+		t.Fatalf("LoadEmbeddedThemes failed: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if err := m.Reload(); err != nil {
+				t.Errorf("Reload failed: %v", err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		if _, err := m.GetThemeStrict("default"); err != nil {
+			t.Fatalf("default theme missing during reload: %v", err)
+		}
+		m.ListThemes()
+	}
+	<-done
+}
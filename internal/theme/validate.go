@@ -0,0 +1,195 @@
+package theme
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ValidationError lists every problem Validate found in a theme.
+type ValidationError struct {
+	Theme    string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("theme %q is invalid: %s", e.Theme, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks that the theme can be rendered as written instead of
+// silently falling back to defaults: colors are hex strings (#RRGGBB or the
+// #RGB shorthand), node style colors have components in [0,1], layout sizes
+// are positive with minNodeWidth <= maxNodeWidth, and enumerated settings
+// such as style, connectorStyle and textAlign hold known values. All
+// problems are reported together as a *ValidationError; nil means the theme
+// is valid. Contrast is checked separately by ValidateTheme.
+func (tc *ThemeConfig) Validate() error {
+	var v validator
+
+	if strings.TrimSpace(tc.Name) == "" {
+		v.addf("name must not be empty")
+	}
+
+	v.requiredColor("colors.background", tc.Colors.Background)
+	v.requiredColor("colors.connectionLine", tc.Colors.ConnectionLine)
+	v.optionalColor("colors.marker", tc.Colors.Marker)
+	for i, hex := range tc.BranchPalette {
+		v.requiredColor(fmt.Sprintf("branchPalette[%d]", i), hex)
+	}
+
+	for _, ref := range tc.nodeStyleRefs() {
+		v.nodeStyle("nodeStyles."+ref.name, ref.style)
+	}
+	if tc.NodeStyles.Highlight != nil {
+		v.nodeStyle("nodeStyles.highlight", tc.NodeStyles.Highlight)
+	}
+
+	v.layout(tc.Layout)
+
+	switch tc.Style {
+	case "", "standard":
+	case "sketch":
+		if tc.SketchConfig == nil {
+			v.addf("style %q requires sketchConfig", tc.Style)
+		}
+	default:
+		v.addf("style %q must be standard or sketch", tc.Style)
+	}
+	if sc := tc.SketchConfig; sc != nil {
+		if sc.Iterations < 1 {
+			v.addf("sketchConfig.iterations must be at least 1, got %d", sc.Iterations)
+		}
+		v.nonNegative("sketchConfig.roughness", sc.Roughness)
+		v.nonNegative("sketchConfig.lineVariation", sc.LineVariation)
+		switch sc.FillPattern {
+		case "", "none", "dots", "crosshatch":
+		default:
+			v.addf("sketchConfig.fillPattern %q must be none, dots or crosshatch", sc.FillPattern)
+		}
+	}
+
+	if s := tc.Shadow; s != nil {
+		v.optionalColor("shadow.color", s.Color)
+		v.nonNegative("shadow.blur", s.Blur)
+		if s.Alpha < 0 || s.Alpha > 1 || math.IsNaN(s.Alpha) {
+			v.addf("shadow.alpha must be between 0 and 1, got %g", s.Alpha)
+		}
+	}
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Theme: tc.Name, Problems: v.problems}
+}
+
+// validator 收集主题校验中发现的问题
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// requiredColor 检查必填的十六进制颜色
+func (v *validator) requiredColor(field, hex string) {
+	if hex == "" {
+		v.addf("%s must be set", field)
+		return
+	}
+	v.optionalColor(field, hex)
+}
+
+// optionalColor 检查可留空的十六进制颜色
+func (v *validator) optionalColor(field, hex string) {
+	if hex != "" && !isHexColor(hex) {
+		v.addf("%s %q must be a #RRGGBB color", field, hex)
+	}
+}
+
+// nodeStyle 检查节点样式（含渐变填充）的颜色分量与行高
+func (v *validator) nodeStyle(field string, style *NodeStyleConfig) {
+	v.unitColor(field+".fillColor", style.FillColor)
+	v.unitColor(field+".strokeColor", style.StrokeColor)
+	v.unitColor(field+".textColor", style.TextColor)
+	if g := style.FillGradient; g != nil {
+		v.unitColor(field+".fillGradient.from", g.From)
+		v.unitColor(field+".fillGradient.to", g.To)
+	}
+	v.nonNegative(field+".lineHeight", style.LineHeight)
+}
+
+// unitColor 检查分量为 0-1 的颜色
+func (v *validator) unitColor(field string, color [3]float64) {
+	for _, component := range color {
+		if component < 0 || component > 1 || math.IsNaN(component) {
+			v.addf("%s %v must have components between 0 and 1", field, color)
+			return
+		}
+	}
+}
+
+// layout 检查布局尺寸是否为正数且相互一致
+func (v *validator) layout(lc LayoutConfig) {
+	positive := []struct {
+		name  string
+		value float64
+	}{
+		{"minNodeWidth", lc.MinNodeWidth},
+		{"maxNodeWidth", lc.MaxNodeWidth},
+		{"minNodeHeight", lc.MinNodeHeight},
+		{"levelSpacing", lc.LevelSpacing},
+		{"fontSize", lc.FontSize},
+		{"scale", lc.Scale},
+		{"lineHeight", lc.LineHeight},
+	}
+	for _, p := range positive {
+		if !(p.value > 0) {
+			v.addf("layout.%s must be positive, got %g", p.name, p.value)
+		}
+	}
+	v.nonNegative("layout.rootLevelSpacing", lc.RootLevelSpacing)
+	v.nonNegative("layout.nodeSpacing", lc.NodeSpacing)
+	v.nonNegative("layout.cornerRadius", lc.CornerRadius)
+	v.nonNegative("layout.textPadding", lc.TextPadding)
+	v.nonNegative("layout.minScale", lc.MinScale)
+	v.nonNegative("layout.maxScale", lc.MaxScale)
+
+	if lc.MinNodeWidth > lc.MaxNodeWidth {
+		v.addf("layout.minNodeWidth %g must not exceed maxNodeWidth %g", lc.MinNodeWidth, lc.MaxNodeWidth)
+	}
+	if lc.MinScale > 0 && lc.MaxScale > 0 && lc.MinScale > lc.MaxScale {
+		v.addf("layout.minScale %g must not exceed maxScale %g", lc.MinScale, lc.MaxScale)
+	}
+
+	switch lc.ConnectorStyle {
+	case "", "bezier", "straight", "elbow":
+	default:
+		v.addf("layout.connectorStyle %q must be bezier, straight or elbow", lc.ConnectorStyle)
+	}
+	switch lc.TextAlign {
+	case "", "left", "center", "right":
+	default:
+		v.addf("layout.textAlign %q must be left, center or right", lc.TextAlign)
+	}
+}
+
+// nonNegative 检查可为 0 的数值
+func (v *validator) nonNegative(field string, value float64) {
+	if value < 0 || math.IsNaN(value) {
+		v.addf("%s must not be negative, got %g", field, value)
+	}
+}
+
+// isHexColor 判断是否为 #rrggbb 或简写 #rgb 形式的十六进制颜色
+func isHexColor(hex string) bool {
+	if len(hex) != 7 && len(hex) != 4 || hex[0] != '#' {
+		return false
+	}
+	for _, c := range hex[1:] {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
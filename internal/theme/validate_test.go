@@ -0,0 +1,85 @@
+package theme
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedThemesValidate(t *testing.T) {
+	themes, err := readThemes(themesFS, "themes", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := themesFS.ReadDir("themes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(themes) != len(entries) {
+		t.Fatalf("expected every embedded theme to pass validation, loaded %d of %d", len(themes), len(entries))
+	}
+	for id, tc := range themes {
+		if err := tc.Validate(); err != nil {
+			t.Errorf("embedded theme %s: %v", id, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	base := func() *ThemeConfig {
+		tc, err := GetManager().GetThemeStrict("default")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tc
+	}
+
+	tests := []struct {
+		name   string
+		modify func(tc *ThemeConfig)
+		want   []string
+	}{
+		{"bad background", func(tc *ThemeConfig) { tc.Colors.Background = "#ggg000" }, []string{"colors.background"}},
+		{"missing line color", func(tc *ThemeConfig) { tc.Colors.ConnectionLine = "" }, []string{"colors.connectionLine must be set"}},
+		{"unprefixed marker", func(tc *ThemeConfig) { tc.Colors.Marker = "ffcc00" }, []string{"colors.marker"}},
+		{"bad palette entry", func(tc *ThemeConfig) { tc.BranchPalette = []string{"#ff0000", "red"} }, []string{"branchPalette[1]"}},
+		{"component above one", func(tc *ThemeConfig) { tc.NodeStyles.Root.FillColor = [3]float64{255, 0, 0} }, []string{"nodeStyles.root.fillColor"}},
+		{"negative component", func(tc *ThemeConfig) { tc.NodeStyles.Leaf.TextColor[1] = -0.1 }, []string{"nodeStyles.leaf.textColor"}},
+		{"gradient out of range", func(tc *ThemeConfig) {
+			tc.NodeStyles.Root.FillGradient = &GradientConfig{From: [3]float64{0, 0, 0}, To: [3]float64{0, 2, 0}}
+		}, []string{"nodeStyles.root.fillGradient.to"}},
+		{"zero scale", func(tc *ThemeConfig) { tc.Layout.Scale = 0 }, []string{"layout.scale must be positive"}},
+		{"inverted widths", func(tc *ThemeConfig) { tc.Layout.MinNodeWidth = 300 }, []string{"layout.minNodeWidth 300 must not exceed maxNodeWidth 250"}},
+		{"inverted scale range", func(tc *ThemeConfig) { tc.Layout.MinScale, tc.Layout.MaxScale = 4, 2 }, []string{"layout.minScale"}},
+		{"unknown connector", func(tc *ThemeConfig) { tc.Layout.ConnectorStyle = "zigzag" }, []string{"layout.connectorStyle"}},
+		{"sketch without config", func(tc *ThemeConfig) { tc.Style = "sketch" }, []string{"requires sketchConfig"}},
+		{"shadow alpha", func(tc *ThemeConfig) { tc.Shadow = &ShadowConfig{Alpha: 1.5} }, []string{"shadow.alpha"}},
+		{"several problems", func(tc *ThemeConfig) {
+			tc.Colors.Background = "white"
+			tc.Layout.FontSize = -12
+		}, []string{"colors.background", "layout.fontSize"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := base()
+			tt.modify(tc)
+			err := tc.Validate()
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a *ValidationError, got %v", err)
+			}
+			if len(verr.Problems) != len(tt.want) {
+				t.Fatalf("expected %d problems, got %q", len(tt.want), verr.Problems)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(verr.Problems[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, verr.Problems[i], want)
+				}
+			}
+		})
+	}
+
+	if err := base().Validate(); err != nil {
+		t.Errorf("expected the default theme to be valid, got %v", err)
+	}
+}
@@ -6,10 +6,11 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 
 	"github.com/hellodeveye/mindmapgen/api"
+	"github.com/hellodeveye/mindmapgen/internal/drawer"
 	"github.com/hellodeveye/mindmapgen/internal/storage"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
 	"github.com/hellodeveye/mindmapgen/pkg/server"
 )
 
@@ -18,23 +19,54 @@ var staticFiles embed.FS
 
 func main() {
 	port := flag.Int("port", 8080, "HTTP server port")
+	timeouts := server.DefaultTimeouts
+	flag.DurationVar(&timeouts.Read, "read-timeout", timeouts.Read, "Maximum time to read a request, including the body (0 = no limit)")
+	flag.DurationVar(&timeouts.Write, "write-timeout", timeouts.Write, "Maximum time to write a response; keep it above -render-timeout (0 = no limit)")
+	flag.DurationVar(&timeouts.Handler, "render-timeout", timeouts.Handler, "Maximum time to render a mind map before responding 503 (0 = no limit)")
+	// 限流参数的默认值取自 MINDMAP_RATE_LIMIT、MINDMAP_RATE_BURST 与 MINDMAP_TRUST_PROXY
+	rateLimit, err := server.RateLimitFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	flag.Float64Var(&rateLimit.Rate, "rate-limit", rateLimit.Rate, "Requests per second allowed per client IP on /api/ endpoints (0 = unlimited)")
+	flag.IntVar(&rateLimit.Burst, "rate-burst", rateLimit.Burst, "Maximum requests a client may make at once before -rate-limit applies (0 = max(1, rate))")
+	flag.BoolVar(&rateLimit.TrustProxy, "trust-proxy", rateLimit.TrustProxy, "Identify clients by X-Forwarded-For when rate limiting (only behind a trusted reverse proxy)")
+	enableMetrics := flag.Bool("metrics", false, "Record render metrics and serve them for Prometheus at /metrics")
 	flag.Parse()
 	addr := fmt.Sprintf(":%d", *port)
 
-	// Create the server mux with all handlers configured
-	handler := server.NewServer(staticFiles)
-	if cfg, err := storage.LoadR2ConfigFromEnv(); err != nil {
+	if err := theme.LoadThemesFromEnv(); err != nil {
+		log.Printf("failed to load user themes: %v", err)
+	}
+	// 启动时预先解析字体与主题，避免首个请求承担冷启动开销
+	if err := drawer.Warmup(); err != nil {
+		log.Printf("failed to warm up renderer: %v", err)
+	}
+
+	// 对象存储使用 R2_* 环境变量，未配置时尝试通用的 S3_* 环境变量，
+	// 都未配置时可用 LOCAL_STORAGE_DIR 保存到本地目录
+	if cfg, err := storage.LoadStorageConfigFromEnv(); err != nil {
 		if !errors.Is(err, storage.ErrMissingR2Config) {
-			log.Printf("failed to load R2 config: %v", err)
+			log.Printf("failed to load storage config: %v", err)
+		} else if local, err := storage.LoadLocalStoreFromEnv(); err == nil {
+			api.InitLocalStore(local)
+			log.Printf("storing media=url images in %s, served at %s", local.Dir, local.PublicPrefix)
+		} else if !errors.Is(err, storage.ErrMissingLocalConfig) {
+			log.Printf("failed to initialize local storage: %v", err)
 		}
-	} else if err := api.InitR2Client(cfg); err != nil {
-		log.Printf("failed to initialize R2 client: %v", err)
+	} else if err := api.InitS3Client(cfg); err != nil {
+		log.Printf("failed to initialize storage client: %v", err)
 	}
 
+	if *enableMetrics {
+		api.EnableMetrics()
+	}
+
+	// 创建带超时与限流设置的 HTTP 服务，渲染接口的请求 context 在 -render-timeout 后超时
+	srv := server.NewHTTPServer(addr, staticFiles, timeouts, server.WithRateLimit(rateLimit))
+
 	log.Printf("Starting server on %s", addr)
-	// Use the handler returned by NewServer
-	err := http.ListenAndServe(addr, handler)
-	if err != nil {
-		log.Fatal("ListenAndServe: ", err) // Slightly improved error logging
+	if err := srv.ListenAndServe(); err != nil {
+		log.Fatal("ListenAndServe: ", err)
 	}
 }
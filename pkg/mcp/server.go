@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -29,38 +30,61 @@ const (
 
 	maxContentSize    = 1 << 20 // 1 MiB
 	maxConcurrentDraw = 3
+
+	// Bounds for the optional scale argument.
+	minScale = 1.0
+	maxScale = 6.0
 )
 
 var (
-	r2Once      sync.Once
-	r2Client    *storage.R2Client
-	r2ClientErr error
+	storeOnce     sync.Once
+	imageStore    storage.ImageStore // 上传渲染结果的存储，为空时仅返回 base64
+	imageStoreErr error
 
-	validLayouts = map[string]bool{"right": true, "left": true, "both": true}
+	formats = []string{"png", "svg"}
 
 	renderSem = make(chan struct{}, maxConcurrentDraw)
 )
 
-func initR2() {
-	r2Once.Do(func() {
-		var err error
-		r2Client, err = storage.NewR2ClientFromEnv()
+func initStore() {
+	storeOnce.Do(func() {
+		client, err := storage.NewR2ClientFromEnv()
 		if err != nil {
 			if errors.Is(err, storage.ErrMissingR2Config) {
-				r2ClientErr = fmt.Errorf("missing R2 storage configuration; ensure R2_* environment variables are set")
+				imageStoreErr = fmt.Errorf("missing R2 storage configuration; ensure R2_* or S3_* environment variables are set")
 			} else {
-				r2ClientErr = fmt.Errorf("failed to initialize R2 client: %w", err)
+				imageStoreErr = fmt.Errorf("failed to initialize R2 client: %w", err)
 			}
+			return
 		}
+		imageStore = client
 	})
 }
 
+// setImageStore 替换上传使用的存储，跳过从环境变量初始化
+func setImageStore(store storage.ImageStore) {
+	storeOnce.Do(func() {})
+	imageStore, imageStoreErr = store, nil
+}
+
 // NewMindmapServer constructs an MCP server instance exposing mind map tooling.
 func NewMindmapServer() *sdk.MCPServer {
-	initR2()
-	if r2ClientErr != nil {
-		log.Printf("mindmap MCP server storage init: %v (will use base64 fallback)", r2ClientErr)
+	initStore()
+	if imageStoreErr != nil {
+		log.Printf("mindmap MCP server storage init: %v (will use base64 fallback)", imageStoreErr)
 	}
+	return newMindmapServer()
+}
+
+// NewMindmapServerWithStore is like NewMindmapServer but uploads rendered
+// images to store instead of the storage configured from the environment;
+// a nil store returns base64 images only.
+func NewMindmapServerWithStore(store storage.ImageStore) *sdk.MCPServer {
+	setImageStore(store)
+	return newMindmapServer()
+}
+
+func newMindmapServer() *sdk.MCPServer {
 
 	themeNames := theme.GetManager().ListThemes()
 	sort.Strings(themeNames)
@@ -83,7 +107,7 @@ func NewMindmapServer() *sdk.MCPServer {
 }
 
 func buildGenerateTool(themeNames []string) protocol.Tool {
-	description := "Generates a PNG mind map image from indented text or Mermaid mindmap syntax. The tool parses the provided text, converts it into a visual mind map, and returns the generated PNG image."
+	description := fmt.Sprintf("Generates a mind map image from indented text or Mermaid mindmap syntax. The tool parses the provided text, converts it into a visual mind map, and returns the generated PNG image, or the SVG source when format is svg. The optional scale (%g-%g) controls PNG resolution.", minScale, maxScale)
 	opts := []protocol.ToolOption{
 		protocol.WithDescription(description),
 		protocol.WithToolAnnotation(protocol.ToolAnnotation{
@@ -119,11 +143,25 @@ func buildGenerateTool(themeNames []string) protocol.Tool {
 
 	opts = append(opts, protocol.WithString(
 		"layout",
-		protocol.Description("Layout direction. Defaults to 'right'."),
-		protocol.Enum("right", "left", "both"),
+		protocol.Description("Layout direction: right, left, both (branches on both sides), down or up (vertical tree), or radial (root at the center). Defaults to 'right'."),
+		protocol.Enum(drawer.Layouts()...),
 		protocol.DefaultString("right"),
 	))
 
+	opts = append(opts, protocol.WithString(
+		"format",
+		protocol.Description("Output format: png returns an image, svg returns the SVG source as text. Defaults to 'png'."),
+		protocol.Enum(formats...),
+		protocol.DefaultString("png"),
+	))
+
+	opts = append(opts, protocol.WithNumber(
+		"scale",
+		protocol.Description(fmt.Sprintf("Output resolution multiplier between %g and %g, overriding the theme's scale. Lower values give smaller PNGs.", minScale, maxScale)),
+		protocol.Min(minScale),
+		protocol.Max(maxScale),
+	))
+
 	return protocol.NewTool(ToolGenerateMindmap, opts...)
 }
 
@@ -169,8 +207,27 @@ func generateMindmapHandler(themeNames []string) sdk.ToolHandlerFunc {
 				layout = value
 			}
 		}
-		if !validLayouts[layout] {
-			return protocol.NewToolResultError(fmt.Sprintf("invalid layout %q; must be one of: right, left, both", layout)), nil
+		if !drawer.IsLayout(layout) {
+			return protocol.NewToolResultError(fmt.Sprintf("invalid layout %q; must be one of: %s", layout, strings.Join(drawer.Layouts(), ", "))), nil
+		}
+
+		format := "png"
+		if rawFormat, ok := args["format"]; ok {
+			if value, ok := rawFormat.(string); ok && strings.TrimSpace(value) != "" {
+				format = value
+			}
+		}
+		if !slices.Contains(formats, format) {
+			return protocol.NewToolResultError(fmt.Sprintf("invalid format %q; must be one of: %s", format, strings.Join(formats, ", "))), nil
+		}
+
+		var scale float64
+		if rawScale, ok := args["scale"]; ok && rawScale != nil {
+			value, ok := rawScale.(float64)
+			if !ok || value < minScale || value > maxScale {
+				return protocol.NewToolResultError(fmt.Sprintf("argument 'scale' must be a number between %g and %g", minScale, maxScale)), nil
+			}
+			scale = value
 		}
 
 		root, err := parser.Parse(content)
@@ -186,8 +243,18 @@ func generateMindmapHandler(themeNames []string) sdk.ToolHandlerFunc {
 		}
 		defer func() { <-renderSem }()
 
+		drawOpts := []drawer.Option{drawer.WithTheme(themeName), drawer.WithLayout(layout)}
+		if scale > 0 {
+			drawOpts = append(drawOpts, drawer.WithScale(scale))
+		}
 		var buffer bytes.Buffer
-		if err := drawer.Draw(root, &buffer, drawer.WithTheme(themeName), drawer.WithLayout(layout)); err != nil {
+		if format == "svg" {
+			if err := drawer.DrawSVG(root, &buffer, drawOpts...); err != nil {
+				return protocol.NewToolResultErrorFromErr("failed to render mind map", err), nil
+			}
+			return svgResult(ctx, buffer.Bytes()), nil
+		}
+		if err := drawer.Draw(root, &buffer, drawOpts...); err != nil {
 			return protocol.NewToolResultErrorFromErr("failed to render mind map", err), nil
 		}
 
@@ -195,9 +262,9 @@ func generateMindmapHandler(themeNames []string) sdk.ToolHandlerFunc {
 		b64Data := base64.StdEncoding.EncodeToString(imgBytes)
 
 		// Try R2 upload; fall back to base64-only on failure.
-		initR2()
-		if r2Client != nil {
-			url, err := r2Client.UploadImage(ctx, imgBytes, "image/png")
+		initStore()
+		if imageStore != nil {
+			url, err := imageStore.UploadImage(ctx, imgBytes, "image/png")
 			if err != nil {
 				log.Printf("R2 upload failed, falling back to base64: %v", err)
 			} else {
@@ -234,6 +301,37 @@ func generateMindmapHandler(themeNames []string) sdk.ToolHandlerFunc {
 	}
 }
 
+// svgResult returns the SVG source as text content. When R2 is configured the
+// SVG is uploaded as well and its URL is listed first.
+func svgResult(ctx context.Context, svgBytes []byte) *protocol.CallToolResult {
+	source := protocol.TextContent{
+		Annotated: protocol.Annotated{},
+		Type:      "text",
+		Text:      string(svgBytes),
+	}
+
+	initStore()
+	if imageStore != nil {
+		url, err := imageStore.UploadImage(ctx, svgBytes, "image/svg+xml")
+		if err != nil {
+			log.Printf("R2 upload failed, returning inline SVG only: %v", err)
+		} else {
+			return &protocol.CallToolResult{
+				Content: []protocol.Content{
+					protocol.TextContent{
+						Annotated: protocol.Annotated{},
+						Type:      "text",
+						Text:      fmt.Sprintf("Mind map uploaded: %s", url),
+					},
+					source,
+				},
+			}
+		}
+	}
+
+	return &protocol.CallToolResult{Content: []protocol.Content{source}}
+}
+
 func buildThemesResource() protocol.Resource {
 	return protocol.NewResource(
 		themesResourceURI,
@@ -267,7 +365,7 @@ func themesResourceHandler(ctx context.Context, request protocol.ReadResourceReq
 
 func buildThemeDetailTemplate() protocol.ResourceTemplate {
 	return protocol.NewResourceTemplate(
-		"mindmapgen://themes/{name}",
+		themesResourceURI+"/{name}",
 		"Theme Detail",
 		protocol.WithTemplateDescription("Returns the full configuration for a specific theme."),
 		protocol.WithTemplateMIMEType("application/json"),
@@ -277,7 +375,7 @@ func buildThemeDetailTemplate() protocol.ResourceTemplate {
 func themeDetailHandler(ctx context.Context, request protocol.ReadResourceRequest) ([]protocol.ResourceContents, error) {
 	uri := request.Params.URI
 	// Extract theme name from URI: "mindmapgen://themes/{name}"
-	const prefix = "mindmapgen://themes/"
+	const prefix = themesResourceURI + "/"
 	if !strings.HasPrefix(uri, prefix) {
 		return nil, fmt.Errorf("invalid resource URI: %s", uri)
 	}
@@ -286,22 +384,10 @@ func themeDetailHandler(ctx context.Context, request protocol.ReadResourceReques
 		return nil, fmt.Errorf("theme name is required")
 	}
 
-	mgr := theme.GetManager()
-	themeNames := mgr.ListThemes()
-	found := false
-	for _, t := range themeNames {
-		if t == name {
-			found = true
-			break
-		}
-	}
-	if !found {
-		return nil, fmt.Errorf("theme %q not found", name)
-	}
-
-	cfg, err := mgr.GetTheme(name)
+	// 使用严格查找：未知主题应报告 not found，而不是返回 default 主题
+	cfg, err := theme.GetManager().GetThemeStrict(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load theme %q: %w", name, err)
+		return nil, err
 	}
 
 	data, err := json.Marshal(cfg)
@@ -98,6 +98,36 @@ func TestGenerateMindmap_InvalidLayout(t *testing.T) {
 	}
 }
 
+func TestGenerateMindmap_MultipleRoots(t *testing.T) {
+	handler := generateMindmapHandler(nil)
+	result := callTool(t, handler, map[string]any{"content": "Root\n  Child\nOther\n  Child"})
+	if !result.IsError {
+		t.Fatal("expected error result for multiple roots")
+	}
+	if text := resultText(result); !strings.Contains(text, "2 root nodes") || !strings.Contains(text, `line 3 "Other"`) {
+		t.Errorf("error message should list the root lines, got: %s", text)
+	}
+}
+
+func TestGenerateMindmap_VerticalLayout(t *testing.T) {
+	handler := generateMindmapHandler(nil)
+	for _, layout := range []string{"down", "up"} {
+		result := callTool(t, handler, map[string]any{"content": "Root\n  Child", "layout": layout})
+		if result.IsError {
+			t.Fatalf("layout %q: expected success, got error: %s", layout, resultText(result))
+		}
+	}
+
+	tool := buildGenerateTool(nil)
+	data, err := json.Marshal(tool.InputSchema.Properties["layout"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"down"`) || !strings.Contains(string(data), `"up"`) {
+		t.Errorf("expected the layout schema to list vertical layouts, got %s", data)
+	}
+}
+
 func TestGenerateMindmap_ValidInput_Base64Fallback(t *testing.T) {
 	// Without R2 configured, the handler should return a base64 image.
 	handler := generateMindmapHandler(nil)
@@ -201,3 +231,77 @@ func TestThemeDetailResource_NotFound(t *testing.T) {
 		t.Errorf("error should mention 'not found', got: %v", err)
 	}
 }
+
+func TestGenerateMindmap_SVGFormat(t *testing.T) {
+	handler := generateMindmapHandler(nil)
+	result := callTool(t, handler, map[string]any{"content": "Root\n  Child", "format": "svg"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", resultText(result))
+	}
+	if hasImageContent(result) {
+		t.Error("expected no ImageContent for svg format")
+	}
+	if !strings.Contains(resultText(result), "<svg") {
+		t.Errorf("expected SVG source in text content, got: %.80s", resultText(result))
+	}
+}
+
+func TestGenerateMindmap_InvalidFormat(t *testing.T) {
+	handler := generateMindmapHandler(nil)
+	result := callTool(t, handler, map[string]any{"content": "Root\n  Child", "format": "bmp"})
+	if !result.IsError {
+		t.Fatal("expected error result for invalid format")
+	}
+	if !strings.Contains(resultText(result), "invalid format") {
+		t.Errorf("error message should mention 'invalid format', got: %s", resultText(result))
+	}
+}
+
+func TestGenerateMindmap_Scale(t *testing.T) {
+	handler := generateMindmapHandler(nil)
+	result := callTool(t, handler, map[string]any{"content": "Root\n  Child", "scale": 1.0})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", resultText(result))
+	}
+
+	for _, scale := range []any{0.5, 7.0, "2"} {
+		result := callTool(t, handler, map[string]any{"content": "Root\n  Child", "scale": scale})
+		if !result.IsError {
+			t.Fatalf("scale %v: expected error result", scale)
+		}
+		if !strings.Contains(resultText(result), "scale") {
+			t.Errorf("scale %v: error message should mention 'scale', got: %s", scale, resultText(result))
+		}
+	}
+}
+
+// fakeStore 记录上传内容的 ImageStore
+type fakeStore struct {
+	data        []byte
+	contentType string
+}
+
+func (f *fakeStore) UploadImage(_ context.Context, data []byte, contentType string) (string, error) {
+	f.data, f.contentType = data, contentType
+	return "https://cdn.example.com/mindmap.png", nil
+}
+
+func TestGenerateMindmap_UploadsToStore(t *testing.T) {
+	t.Cleanup(func() { setImageStore(nil) })
+	fake := &fakeStore{}
+	if NewMindmapServerWithStore(fake) == nil {
+		t.Fatal("expected a server")
+	}
+
+	handler := generateMindmapHandler(nil)
+	result := callTool(t, handler, map[string]any{"content": "Root\n  Child"})
+	if result.IsError {
+		t.Fatalf("expected success, got error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "Mind map uploaded: https://cdn.example.com/mindmap.png") {
+		t.Errorf("expected the uploaded URL in the result, got %q", resultText(result))
+	}
+	if fake.contentType != "image/png" || !strings.HasPrefix(string(fake.data), "\x89PNG") {
+		t.Errorf("expected PNG bytes to be uploaded, got %q with %d bytes", fake.contentType, len(fake.data))
+	}
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables read by RateLimitFromEnv.
+const (
+	RateLimitEnv  = "MINDMAP_RATE_LIMIT"
+	RateBurstEnv  = "MINDMAP_RATE_BURST"
+	TrustProxyEnv = "MINDMAP_TRUST_PROXY"
+)
+
+// RateLimit configures the per-client token bucket applied to the /api/
+// endpoints. Health probes, /metrics and static files are never limited.
+type RateLimit struct {
+	// Rate is the sustained number of requests per second allowed for one
+	// client IP. Zero disables rate limiting.
+	Rate float64
+	// Burst is the number of requests a client can make at once before it
+	// is held to Rate. Zero means max(1, Rate).
+	Burst int
+	// TrustProxy identifies clients by the last address in X-Forwarded-For,
+	// the one appended by the reverse proxy in front of the server, instead
+	// of the connection's remote address. Enable it only behind a proxy that
+	// sets the header, otherwise clients can pick their own key.
+	TrustProxy bool
+}
+
+// RateLimitFromEnv reads the rate limit from MINDMAP_RATE_LIMIT,
+// MINDMAP_RATE_BURST and MINDMAP_TRUST_PROXY. Unset variables leave the
+// corresponding field at its zero value.
+func RateLimitFromEnv() (RateLimit, error) {
+	var rl RateLimit
+	if v := os.Getenv(RateLimitEnv); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || math.IsInf(rate, 0) {
+			return RateLimit{}, fmt.Errorf("%s must be a non-negative number of requests per second, got %q", RateLimitEnv, v)
+		}
+		rl.Rate = rate
+	}
+	if v := os.Getenv(RateBurstEnv); v != "" {
+		burst, err := strconv.Atoi(v)
+		if err != nil || burst < 0 {
+			return RateLimit{}, fmt.Errorf("%s must be a non-negative integer, got %q", RateBurstEnv, v)
+		}
+		rl.Burst = burst
+	}
+	if v := os.Getenv(TrustProxyEnv); v != "" {
+		trust, err := strconv.ParseBool(v)
+		if err != nil {
+			return RateLimit{}, fmt.Errorf("%s must be true or false, got %q", TrustProxyEnv, v)
+		}
+		rl.TrustProxy = trust
+	}
+	return rl, nil
+}
+
+// rateLimiter 按客户端 IP 维护令牌桶
+type rateLimiter struct {
+	rate       float64
+	burst      float64
+	trustProxy bool
+	now        func() time.Time // 测试中可替换
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter 返回 rl 对应的限流器；rl.Rate <= 0 时返回 nil，表示不限流
+func newRateLimiter(rl RateLimit) *rateLimiter {
+	if !(rl.Rate > 0) {
+		return nil
+	}
+	burst := float64(rl.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(rl.Rate))
+	}
+	return &rateLimiter{
+		rate:       rl.Rate,
+		burst:      burst,
+		trustProxy: rl.TrustProxy,
+		now:        time.Now,
+		buckets:    map[string]*tokenBucket{},
+	}
+}
+
+// allow 从 key 的令牌桶取出一个令牌；令牌不足时返回 false 及下一个令牌可用前的等待时间
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep 定期删除已回满的令牌桶，它们与新建的桶等价，避免大量一次性客户端占用内存
+func (l *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	interval := max(refill, time.Minute)
+	if now.Sub(l.lastSweep) < interval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientKey 返回限流使用的客户端标识：信任代理时取 X-Forwarded-For 的最后一个地址，否则取连接的远端 IP
+func (l *rateLimiter) clientKey(r *http.Request) string {
+	if l.trustProxy {
+		if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+			hops := strings.Split(fwd[len(fwd)-1], ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// middleware 对 /api/ 下的请求限流，超出时返回 429 及 Retry-After；
+// 探针、指标与静态文件不受限制。l 为 nil 时原样返回 next
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ok, wait := l.allow(l.clientKey(r))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(struct {
+				Error string `json:"error"`
+			}{Error: "Too many requests"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
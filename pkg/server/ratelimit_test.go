@@ -0,0 +1,136 @@
+package server
+
+import (
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(rl RateLimit) (*rateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := newRateLimiter(rl)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	l, clock := newTestLimiter(RateLimit{Rate: 2, Burst: 3})
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatalf("request %d within the burst was rejected", i+1)
+		}
+	}
+	ok, wait := l.allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected a rejection with a 500ms wait, got %v, %v", ok, wait)
+	}
+	if ok, _ := l.allow("b"); !ok {
+		t.Fatal("expected a separate bucket per client")
+	}
+
+	// 每秒补充 2 个令牌，且不超过突发上限
+	clock.t = clock.t.Add(500 * time.Millisecond)
+	if ok, _ := l.allow("a"); !ok {
+		t.Fatal("expected a token after 500ms")
+	}
+	clock.t = clock.t.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatalf("request %d after refilling was rejected", i+1)
+		}
+	}
+	if ok, _ := l.allow("a"); ok {
+		t.Fatal("expected the refill to be capped at the burst")
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l, clock := newTestLimiter(RateLimit{Rate: 1})
+	l.allow("a")
+	l.allow("b")
+	clock.t = clock.t.Add(2 * time.Minute)
+	l.allow("c")
+	if len(l.buckets) != 1 {
+		t.Fatalf("expected idle buckets to be dropped, have %d", len(l.buckets))
+	}
+}
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	if newRateLimiter(RateLimit{}) != nil || newRateLimiter(RateLimit{Rate: -1, Burst: 5}) != nil {
+		t.Fatal("expected no limiter without a positive rate")
+	}
+	if l := newRateLimiter(RateLimit{Rate: 0.5}); l.burst != 1 {
+		t.Fatalf("expected a default burst of 1, got %v", l.burst)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/gen", nil)
+	req.RemoteAddr = "10.0.0.1:5123"
+	req.Header.Add("X-Forwarded-For", "1.1.1.1, 203.0.113.7")
+
+	if got := newRateLimiter(RateLimit{Rate: 1}).clientKey(req); got != "10.0.0.1" {
+		t.Errorf("expected the remote IP without a trusted proxy, got %q", got)
+	}
+	trusted := newRateLimiter(RateLimit{Rate: 1, TrustProxy: true})
+	if got := trusted.clientKey(req); got != "203.0.113.7" {
+		t.Errorf("expected the address appended by the proxy, got %q", got)
+	}
+	req.Header.Del("X-Forwarded-For")
+	if got := trusted.clientKey(req); got != "10.0.0.1" {
+		t.Errorf("expected the remote IP without the header, got %q", got)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	srv := NewHTTPServer(":0", embed.FS{}, Timeouts{}, WithRateLimit(RateLimit{Rate: 0.1, Burst: 1}))
+	serve := func(path, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/api/themes", "192.0.2.1:1000"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the first request to pass, got %d", rec.Code)
+	}
+	rec := serve("/api/themes", "192.0.2.1:1001")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "10" {
+		t.Fatalf("expected 429 with Retry-After 10, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := serve("/api/themes", "192.0.2.2:1000"); rec.Code != http.StatusOK {
+		t.Fatalf("expected another client to pass, got %d", rec.Code)
+	}
+	// 探针与静态文件不受限流影响
+	for i := 0; i < 3; i++ {
+		if rec := serve("/healthz", "192.0.2.1:1002"); rec.Code != http.StatusOK {
+			t.Fatalf("expected /healthz to be exempt, got %d", rec.Code)
+		}
+		if rec := serve("/favicon.ico", "192.0.2.1:1002"); rec.Code == http.StatusTooManyRequests {
+			t.Fatal("expected static files to be exempt")
+		}
+	}
+}
+
+func TestRateLimitFromEnv(t *testing.T) {
+	t.Setenv(RateLimitEnv, "2.5")
+	t.Setenv(RateBurstEnv, "10")
+	t.Setenv(TrustProxyEnv, "true")
+	rl, err := RateLimitFromEnv()
+	if err != nil || rl != (RateLimit{Rate: 2.5, Burst: 10, TrustProxy: true}) {
+		t.Fatalf("unexpected rate limit %+v, %v", rl, err)
+	}
+
+	t.Setenv(RateBurstEnv, "-1")
+	if _, err := RateLimitFromEnv(); err == nil {
+		t.Fatal("expected an error for a negative burst")
+	}
+}
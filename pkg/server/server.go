@@ -2,16 +2,51 @@ package server
 
 import (
 	"embed"
+	"encoding/json"
 	"io/fs"
 	"log"
 	"net/http"
 	"path"
+	"time"
 
 	"github.com/hellodeveye/mindmapgen/api"
+	"github.com/hellodeveye/mindmapgen/internal/storage"
+	"github.com/hellodeveye/mindmapgen/internal/theme"
 )
 
-// NewServer creates and configures a new HTTP server multiplexer.
+// NewServerWithStore is like NewServer but uploads media=url images to store
+// instead of the storage configured from the environment.
+func NewServerWithStore(staticFS embed.FS, store storage.ImageStore) http.Handler {
+	api.SetImageStore(store)
+	return NewServer(staticFS)
+}
+
+// NewServer creates and configures a new HTTP server multiplexer. Rendering
+// is bounded only by the request context and requests are not rate limited;
+// use NewHTTPServer for timeouts and options.
 func NewServer(staticFS embed.FS) http.Handler {
+	return newServer(staticFS, 0, nil)
+}
+
+// Option configures the handler built by NewHTTPServer.
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	rateLimit RateLimit
+}
+
+// WithRateLimit limits requests to the /api/ endpoints per client IP with a
+// token bucket; clients over the limit get 429 Too Many Requests with a
+// Retry-After header. A zero Rate leaves requests unlimited.
+func WithRateLimit(rl RateLimit) Option {
+	return func(o *serverOptions) {
+		o.rateLimit = rl
+	}
+}
+
+// newServer 创建路由；renderTimeout > 0 时渲染接口的请求 context 在该时长后超时，
+// limiter 不为 nil 时对 /api/ 下的请求按客户端限流
+func newServer(staticFS embed.FS, renderTimeout time.Duration, limiter *rateLimiter) http.Handler {
 	mux := http.NewServeMux()
 
 	// Create a sub-filesystem rooted at "static"
@@ -25,11 +60,30 @@ func NewServer(staticFS embed.FS) http.Handler {
 	staticHandler := http.FileServer(http.FS(contentStatic))
 
 	// API endpoints
-	mux.HandleFunc("/api/gen", api.GenerateMindmapHandler)
+	// 设置 MINDMAP_API_KEYS 后，生成（含 media=url 上传）与批量接口需要 API 密钥
+	mux.HandleFunc("/api/gen", api.RequireAPIKey(withTimeout(api.GenerateMindmapHandler, renderTimeout)))
+	mux.HandleFunc("/api/batch", api.RequireAPIKey(withTimeout(api.BatchHandler, renderTimeout)))
 	mux.HandleFunc("/api/themes", api.ListThemesHandler)
+	mux.HandleFunc("/api/themes/reload", api.ReloadThemesHandler)
+	mux.HandleFunc("/api/themes/validate", api.ValidateThemeHandler)
+	mux.HandleFunc("/api/themes/{name}/preview", withTimeout(api.ThemePreviewHandler, renderTimeout))
+
+	// 负载均衡器的存活与就绪探针
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", handleReadyz)
+
+	// 启用指标时提供 Prometheus 抓取接口
+	if metricsHandler := api.MetricsHandler(); metricsHandler != nil {
+		mux.Handle("/metrics", metricsHandler)
+	}
+
+	// 本地存储模式下提供 media=url 保存的图片
+	if local := api.LocalFileStore(); local != nil {
+		mux.Handle(local.PublicPrefix, local.Handler())
+	}
 
 	mux.HandleFunc("/", handleIndex(contentStatic, staticHandler))
-	return mux
+	return limiter.middleware(mux)
 }
 
 func handleIndex(contentStatic fs.FS, staticHandler http.Handler) http.HandlerFunc {
@@ -50,3 +104,36 @@ func handleIndex(contentStatic fs.FS, staticHandler http.Handler) http.HandlerFu
 		staticHandler.ServeHTTP(w, r)
 	}
 }
+
+// r2InitError 返回 R2 初始化失败的原因，测试中可替换
+var r2InitError = api.R2InitError
+
+type probeResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func writeProbe(w http.ResponseWriter, status int, body probeResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// handleHealthz 存活探针：进程能处理请求即返回 200
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, probeResponse{Status: "ok"})
+}
+
+// handleReadyz 就绪探针：至少加载了一个主题，且已配置的 R2 客户端初始化成功时返回 200，否则返回 503 及原因
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if len(theme.GetManager().ListThemes()) == 0 {
+		writeProbe(w, http.StatusServiceUnavailable, probeResponse{Status: "unavailable", Reason: "no themes loaded"})
+		return
+	}
+	if err := r2InitError(); err != nil {
+		writeProbe(w, http.StatusServiceUnavailable, probeResponse{Status: "unavailable", Reason: "R2 client failed to initialize: " + err.Error()})
+		return
+	}
+	writeProbe(w, http.StatusOK, probeResponse{Status: "ready"})
+}
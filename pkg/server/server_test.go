@@ -0,0 +1,125 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hellodeveye/mindmapgen/api"
+)
+
+func probe(t *testing.T, path string) (int, probeResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	NewServer(embed.FS{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body probeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("%s: failed to decode response: %v", path, err)
+	}
+	return rec.Code, body
+}
+
+func TestHealthz(t *testing.T) {
+	if code, body := probe(t, "/healthz"); code != http.StatusOK || body.Status != "ok" {
+		t.Fatalf("expected 200 ok, got %d %+v", code, body)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	if code, body := probe(t, "/readyz"); code != http.StatusOK || body.Status != "ready" {
+		t.Fatalf("expected 200 ready, got %d %+v", code, body)
+	}
+
+	orig := r2InitError
+	defer func() { r2InitError = orig }()
+	r2InitError = func() error { return errors.New("bad endpoint") }
+
+	code, body := probe(t, "/readyz")
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 when R2 failed, got %d", code)
+	}
+	if !strings.Contains(body.Reason, "R2") || !strings.Contains(body.Reason, "bad endpoint") {
+		t.Errorf("expected an R2 reason, got %q", body.Reason)
+	}
+}
+
+func TestThemePreviewRoute(t *testing.T) {
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewServer(embed.FS{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	if rec := serve("/api/themes/default/preview?media=svg"); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("expected an SVG preview, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec := serve("/api/themes/missing/preview"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown theme, got %d", rec.Code)
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	srv := NewHTTPServer(":0", embed.FS{}, Timeouts{Read: time.Second, Write: 3 * time.Second, Handler: 2 * time.Second})
+	if srv.ReadTimeout != time.Second || srv.WriteTimeout != 3*time.Second {
+		t.Fatalf("unexpected timeouts: read %v, write %v", srv.ReadTimeout, srv.WriteTimeout)
+	}
+
+	var deadline time.Time
+	var ok bool
+	h := withTimeout(func(w http.ResponseWriter, r *http.Request) { deadline, ok = r.Context().Deadline() }, 2*time.Second)
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/gen", nil))
+	if !ok || time.Until(deadline) > 2*time.Second {
+		t.Fatalf("expected a request deadline within 2s, got %v, %v", deadline, ok)
+	}
+	withTimeout(func(w http.ResponseWriter, r *http.Request) { _, ok = r.Context().Deadline() }, 0)(
+		httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/gen", nil))
+	if ok {
+		t.Error("expected no deadline when the handler timeout is zero")
+	}
+}
+
+func TestMetricsRoute(t *testing.T) {
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewServer(embed.FS{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return rec
+	}
+	if rec := serve(); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected /metrics to be unmounted by default, got %d", rec.Code)
+	}
+
+	api.EnableMetrics()
+	rec := serve()
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "# TYPE mindmap_render_total counter") {
+		t.Fatalf("expected the metrics exposition, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAPIKeyRoutes(t *testing.T) {
+	t.Setenv(api.APIKeysEnv, "secret")
+	serve := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader("Plan\n  Goal"))
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		NewServer(embed.FS{}).ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := serve(http.MethodPost, "/api/gen?format=svg", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for /api/gen without a key, got %d", code)
+	}
+	if code := serve(http.MethodPost, "/api/batch", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for /api/batch without a key, got %d", code)
+	}
+	if code := serve(http.MethodPost, "/api/gen?format=svg", "secret"); code != http.StatusOK {
+		t.Fatalf("expected 200 with the key, got %d", code)
+	}
+	if code := serve(http.MethodGet, "/api/themes", ""); code != http.StatusOK {
+		t.Fatalf("expected other endpoints to stay open, got %d", code)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"embed"
+	"net/http"
+	"time"
+)
+
+// Timeouts bounds how long the HTTP server spends on one request.
+type Timeouts struct {
+	// Read limits reading the whole request, body included.
+	Read time.Duration
+	// Write limits the time from the end of the request headers to the end
+	// of the response. Keep it above Handler so a timed-out render can
+	// still send its 503.
+	Write time.Duration
+	// Handler is the deadline of the request context in the rendering
+	// endpoints (/api/gen, /api/batch and theme previews); a render that
+	// runs past it stops and the client gets a 503. Zero means no deadline.
+	Handler time.Duration
+}
+
+// DefaultTimeouts are the timeouts the server command uses unless
+// overridden by flags.
+var DefaultTimeouts = Timeouts{
+	Read:    30 * time.Second,
+	Write:   90 * time.Second,
+	Handler: 60 * time.Second,
+}
+
+// NewHTTPServer returns an *http.Server listening on addr that serves
+// NewServer's routes with the given timeouts and options.
+func NewHTTPServer(addr string, staticFS embed.FS, t Timeouts, opts ...Option) *http.Server {
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           newServer(staticFS, t.Handler, newRateLimiter(o.rateLimit)),
+		ReadHeaderTimeout: t.Read,
+		ReadTimeout:       t.Read,
+		WriteTimeout:      t.Write,
+	}
+}
+
+// withTimeout 为请求的 context 设置截止时间，渲染在超时后停止并由处理函数返回 503。
+// d <= 0 时原样返回 h
+func withTimeout(h http.HandlerFunc, d time.Duration) http.HandlerFunc {
+	if d <= 0 {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		h(w, r.WithContext(ctx))
+	}
+}
@@ -0,0 +1,52 @@
+package types
+
+import "strings"
+
+// Find returns the nodes in the tree rooted at n for which pred returns true,
+// in pre-order.
+func (n *Node) Find(pred func(*Node) bool) []*Node {
+	var found []*Node
+	n.Walk(func(node *Node, _ int) bool {
+		if pred(node) {
+			found = append(found, node)
+		}
+		return true
+	})
+	return found
+}
+
+// Filter returns a pruned copy of the tree rooted at n that keeps the nodes
+// for which keep returns true together with their ancestors; branches
+// without a kept node are dropped. It returns nil if no node is kept. The
+// copies share Style, Spans, Order and Meta with the originals, and n itself
+// is left unchanged.
+func (n *Node) Filter(keep func(*Node) bool) *Node {
+	if n == nil {
+		return nil
+	}
+	children := []*Node{}
+	for _, child := range n.Children {
+		if kept := child.Filter(keep); kept != nil {
+			children = append(children, kept)
+		}
+	}
+	if len(children) == 0 && !keep(n) {
+		return nil
+	}
+	out := *n
+	out.Children = children
+	return &out
+}
+
+// FilterText is Filter keeping the nodes whose Text or Note contains term,
+// ignoring case and the term's surrounding whitespace. An empty term keeps
+// the whole tree and returns n itself.
+func (n *Node) FilterText(term string) *Node {
+	term = strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return n
+	}
+	return n.Filter(func(node *Node) bool {
+		return strings.Contains(strings.ToLower(node.Text), term) || strings.Contains(strings.ToLower(node.Note), term)
+	})
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindAndFilter(t *testing.T) {
+	root := NewNode("Plan")
+	design := NewNode("Design")
+	design.AddChild(NewNode("Mockups"))
+	design.AddChild(NewNode("Review design"))
+	build := NewNode("Build")
+	build.AddChild(NewNode("API"))
+	root.AddChild(design)
+	root.AddChild(build)
+
+	matches := func(n *Node) bool { return strings.Contains(strings.ToLower(n.Text), "design") }
+
+	var texts []string
+	for _, n := range root.Find(matches) {
+		texts = append(texts, n.Text)
+	}
+	if want := []string{"Design", "Review design"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("Find() = %v, want %v", texts, want)
+	}
+
+	filtered := root.Filter(func(n *Node) bool { return n.Text == "Review design" })
+	if filtered == nil || filtered == root {
+		t.Fatalf("expected a pruned copy, got %v", filtered)
+	}
+	texts = nil
+	filtered.Walk(func(n *Node, depth int) bool {
+		texts = append(texts, strings.Repeat("-", depth)+n.Text)
+		return true
+	})
+	if want := []string{"Plan", "-Design", "--Review design"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("Filter() kept %v, want %v", texts, want)
+	}
+	if root.Count() != 6 || len(design.Children) != 2 {
+		t.Errorf("Filter() changed the input tree: %d nodes, %d design children", root.Count(), len(design.Children))
+	}
+
+	if got := root.Filter(func(*Node) bool { return false }); got != nil {
+		t.Errorf("expected nil when nothing is kept, got %+v", got)
+	}
+}
+
+func TestFilterText(t *testing.T) {
+	root := NewNode("Plan")
+	design := NewNode("Design")
+	design.AddChild(&Node{Text: "Mockups", Note: "due FRIDAY"})
+	root.AddChild(design)
+	root.AddChild(NewNode("Build"))
+
+	if root.FilterText("  ") != root {
+		t.Fatal("expected an empty term to keep the tree")
+	}
+	filtered := root.FilterText(" friday ")
+	if filtered == nil || len(filtered.Children) != 1 || filtered.Children[0].Children[0].Text != "Mockups" {
+		t.Fatalf("expected the note match to keep Design > Mockups, got %+v", filtered)
+	}
+	if root.FilterText("missing") != nil {
+		t.Fatal("expected nil when nothing matches")
+	}
+}
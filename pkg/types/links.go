@@ -0,0 +1,28 @@
+package types
+
+// NodeLink describes a linked node and the path of node texts leading to it.
+type NodeLink struct {
+	Path []string `json:"path"`
+	URL  string   `json:"url"`
+}
+
+// CollectLinks lists every node carrying a Link in pre-order. Path starts at
+// root and ends with the linked node's own text.
+func CollectLinks(root *Node) []NodeLink {
+	links := []NodeLink{}
+	var visit func(n *Node, path []string)
+	visit = func(n *Node, path []string) {
+		if n == nil {
+			return
+		}
+		path = append(path, n.Text)
+		if n.Link != "" {
+			links = append(links, NodeLink{Path: append([]string(nil), path...), URL: n.Link})
+		}
+		for _, child := range n.Children {
+			visit(child, path)
+		}
+	}
+	visit(root, nil)
+	return links
+}
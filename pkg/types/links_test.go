@@ -0,0 +1,33 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectLinks(t *testing.T) {
+	root := NewNode("Docs")
+	root.Link = "https://example.com"
+	api := NewNode("API")
+	ref := NewNode("Reference")
+	ref.Link = "https://example.com/api/ref"
+	api.AddChild(ref)
+	api.AddChild(NewNode("Unlinked"))
+	guide := NewNode("Guide")
+	guide.Link = "https://example.com/guide"
+	root.AddChild(api)
+	root.AddChild(guide)
+
+	want := []NodeLink{
+		{Path: []string{"Docs"}, URL: "https://example.com"},
+		{Path: []string{"Docs", "API", "Reference"}, URL: "https://example.com/api/ref"},
+		{Path: []string{"Docs", "Guide"}, URL: "https://example.com/guide"},
+	}
+	if got := CollectLinks(root); !reflect.DeepEqual(got, want) {
+		t.Fatalf("CollectLinks() = %+v, want %+v", got, want)
+	}
+
+	if got := CollectLinks(NewNode("plain")); got == nil || len(got) != 0 {
+		t.Fatalf("expected empty non-nil slice, got %#v", got)
+	}
+}
@@ -1,16 +1,76 @@
 package types
 
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrCycle is returned when a node tree references one of its own ancestors.
+var ErrCycle = errors.New("node tree contains a cycle")
+
 type NodeStyle struct {
-	FillColor   [3]float64
-	StrokeColor [3]float64
-	TextColor   [3]float64
+	FillColor   [3]float64 `json:"fillColor"`
+	StrokeColor [3]float64 `json:"strokeColor"`
+	TextColor   [3]float64 `json:"textColor"`
+	// FillGradient, when set, fills the node instead of FillColor; outputs
+	// that cannot draw gradients keep using FillColor.
+	FillGradient *Gradient `json:"fillGradient,omitempty"`
+}
+
+// Gradient is a linear gradient fill spanning the node. From and To are RGB
+// colors with components in [0,1]; Angle is the direction in degrees, 0
+// running left to right and 90 top to bottom.
+type Gradient struct {
+	From  [3]float64 `json:"from"`
+	To    [3]float64 `json:"to"`
+	Angle float64    `json:"angle,omitempty"`
+}
+
+// SpanKind identifies how a span of node text is styled.
+type SpanKind string
+
+const (
+	// SpanHighlight renders the span with a colored marker background.
+	SpanHighlight SpanKind = "highlight"
+	// SpanSuperscript renders the span smaller and raised above the baseline.
+	SpanSuperscript SpanKind = "superscript"
+	// SpanSubscript renders the span smaller and lowered below the baseline.
+	SpanSubscript SpanKind = "subscript"
+)
+
+// TextSpan marks a styled range of Node.Text using rune offsets.
+type TextSpan struct {
+	Start int      `json:"start"` // inclusive
+	End   int      `json:"end"`   // exclusive
+	Kind  SpanKind `json:"kind"`
 }
 
+// Shape selects the outline drawn around a node, following Mermaid mindmap
+// shape syntax. The zero value is the theme's default rounded rectangle.
+type Shape string
+
+const (
+	ShapeDefault Shape = ""
+	ShapeSquare  Shape = "square"
+	ShapeRounded Shape = "rounded"
+	ShapeCircle  Shape = "circle"
+	ShapeCloud   Shape = "cloud"
+	ShapeHexagon Shape = "hexagon"
+	ShapeBang    Shape = "bang"
+)
+
 type Node struct {
-	Text     string
-	Children []*Node
-	X, Y     float64
-	Style    *NodeStyle // Optional custom style for this node
+	Text     string            `json:"text"`
+	Children []*Node           `json:"children,omitempty"`
+	X, Y     float64           `json:"-"`               // Layout-internal coordinates
+	Style    *NodeStyle        `json:"style,omitempty"` // Optional custom style for this node
+	Spans    []TextSpan        `json:"spans,omitempty"` // Optional inline styling parsed from markup
+	Link     string            `json:"link,omitempty"`  // Optional URL the node points to
+	Shape    Shape             `json:"shape,omitempty"` // Optional outline shape
+	Order    *int              `json:"order,omitempty"` // Optional sort key among siblings
+	Meta     map[string]string `json:"meta,omitempty"`  // Optional structured metadata, e.g. owner or status
+	Icon     string            `json:"icon,omitempty"`  // Optional emoji or icon class drawn before the text, e.g. "🚀" or "fa fa-rocket"
+	Note     string            `json:"note,omitempty"`  // Optional annotation drawn in smaller text below the label
 }
 
 // NewNode creates a new node with default style
@@ -25,3 +85,107 @@ func NewNode(text string) *Node {
 func (n *Node) AddChild(child *Node) {
 	n.Children = append(n.Children, child)
 }
+
+// Walk calls fn for n and each of its descendants in pre-order, passing the
+// node's depth below n (0 for n itself). The walk stops as soon as fn returns
+// false. Nil nodes are skipped. The tree must not contain cycles; see
+// CheckCycles.
+func (n *Node) Walk(fn func(n *Node, depth int) bool) {
+	var visit func(node *Node, depth int) bool
+	visit = func(node *Node, depth int) bool {
+		if node == nil {
+			return true
+		}
+		if !fn(node, depth) {
+			return false
+		}
+		for _, child := range node.Children {
+			if !visit(child, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	visit(n, 0)
+}
+
+// Depth returns the depth of the deepest node below n: 0 for a node without
+// children (or a nil node), 1 if n has only children, and so on.
+func (n *Node) Depth() int {
+	deepest := 0
+	n.Walk(func(_ *Node, depth int) bool {
+		deepest = max(deepest, depth)
+		return true
+	})
+	return deepest
+}
+
+// Count returns the number of nodes in the tree rooted at n, including n
+// itself; a nil node counts as an empty tree.
+func (n *Node) Count() int {
+	count := 0
+	n.Walk(func(*Node, int) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// CheckCycles reports ErrCycle if any node is reachable from itself.
+// Subtrees shared between several parents are allowed.
+func (n *Node) CheckCycles() error {
+	onPath := make(map[*Node]bool)
+	var visit func(*Node) error
+	visit = func(node *Node) error {
+		if node == nil {
+			return nil
+		}
+		if onPath[node] {
+			return ErrCycle
+		}
+		onPath[node] = true
+		for _, child := range node.Children {
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		delete(onPath, node)
+		return nil
+	}
+	return visit(n)
+}
+
+// jsonNode mirrors Node without its MarshalJSON method so that nested nodes
+// don't repeat the cycle check.
+type jsonNode struct {
+	Text     string            `json:"text"`
+	Children []*jsonNode       `json:"children,omitempty"`
+	Style    *NodeStyle        `json:"style,omitempty"`
+	Spans    []TextSpan        `json:"spans,omitempty"`
+	Link     string            `json:"link,omitempty"`
+	Shape    Shape             `json:"shape,omitempty"`
+	Order    *int              `json:"order,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Icon     string            `json:"icon,omitempty"`
+	Note     string            `json:"note,omitempty"`
+}
+
+func toJSONNode(n *Node) *jsonNode {
+	if n == nil {
+		return nil
+	}
+	out := &jsonNode{Text: n.Text, Style: n.Style, Spans: n.Spans, Link: n.Link, Shape: n.Shape, Order: n.Order, Meta: n.Meta, Icon: n.Icon, Note: n.Note}
+	for _, child := range n.Children {
+		out.Children = append(out.Children, toJSONNode(child))
+	}
+	return out
+}
+
+// MarshalJSON encodes the tree, rejecting cyclic references instead of
+// recursing forever. Layout coordinates are not serialized.
+func (n *Node) MarshalJSON() ([]byte, error) {
+	if err := n.CheckCycles(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(toJSONNode(n))
+}
@@ -1,6 +1,12 @@
 package types
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestNewNode(t *testing.T) {
 	root := NewNode("root")
@@ -12,3 +18,107 @@ func TestNewNode(t *testing.T) {
 		t.Errorf("expected initialized children slice")
 	}
 }
+
+func TestNodeJSONRoundTrip(t *testing.T) {
+	root := NewNode("root")
+	root.X, root.Y = 12, 34
+	child := NewNode("child")
+	child.Style = &NodeStyle{FillColor: [3]float64{1, 0, 0}}
+	child.Meta = map[string]string{"owner": "Ann"}
+	child.Icon = "🚀"
+	child.Note = "due Friday"
+	root.AddChild(child)
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "34") || strings.Contains(string(data), `"X"`) {
+		t.Errorf("expected layout coordinates to be omitted, got %s", data)
+	}
+
+	var decoded Node
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if decoded.Text != "root" || len(decoded.Children) != 1 {
+		t.Fatalf("unexpected decoded tree: %+v", decoded)
+	}
+	if decoded.Children[0].Style == nil || decoded.Children[0].Style.FillColor[0] != 1 {
+		t.Errorf("expected child style to round-trip, got %+v", decoded.Children[0].Style)
+	}
+	if decoded.Children[0].Meta["owner"] != "Ann" {
+		t.Errorf("expected child metadata to round-trip, got %v", decoded.Children[0].Meta)
+	}
+	if decoded.Children[0].Icon != "🚀" {
+		t.Errorf("expected child icon to round-trip, got %q", decoded.Children[0].Icon)
+	}
+	if decoded.Children[0].Note != "due Friday" {
+		t.Errorf("expected child note to round-trip, got %q", decoded.Children[0].Note)
+	}
+}
+
+func TestNodeJSONRejectsCycles(t *testing.T) {
+	root := NewNode("root")
+	child := NewNode("child")
+	root.AddChild(child)
+	child.AddChild(root)
+
+	if _, err := json.Marshal(root); !errors.Is(err, ErrCycle) {
+		t.Fatalf("expected ErrCycle, got %v", err)
+	}
+
+	shared := NewNode("shared")
+	dag := NewNode("root")
+	dag.AddChild(shared)
+	dag.AddChild(shared)
+	if err := dag.CheckCycles(); err != nil {
+		t.Fatalf("expected shared subtrees to be allowed, got %v", err)
+	}
+}
+
+func TestNodeWalk(t *testing.T) {
+	var empty *Node
+	empty.Walk(func(*Node, int) bool {
+		t.Fatal("expected no visits for a nil tree")
+		return true
+	})
+	if empty.Depth() != 0 || empty.Count() != 0 {
+		t.Errorf("nil tree: Depth() = %d, Count() = %d, want 0, 0", empty.Depth(), empty.Count())
+	}
+
+	single := NewNode("Only")
+	if single.Depth() != 0 || single.Count() != 1 {
+		t.Errorf("single node: Depth() = %d, Count() = %d, want 0, 1", single.Depth(), single.Count())
+	}
+
+	root := NewNode("Root")
+	a := NewNode("A")
+	a.AddChild(NewNode("A1"))
+	a.AddChild(NewNode("A2"))
+	root.AddChild(a)
+	root.AddChild(NewNode("B"))
+	root.AddChild(nil)
+
+	var visited []string
+	root.Walk(func(n *Node, depth int) bool {
+		visited = append(visited, strings.Repeat("-", depth)+n.Text)
+		return true
+	})
+	want := []string{"Root", "-A", "--A1", "--A2", "-B"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("Walk visited %v, want %v", visited, want)
+	}
+	if root.Depth() != 2 || root.Count() != 5 {
+		t.Errorf("Depth() = %d, Count() = %d, want 2, 5", root.Depth(), root.Count())
+	}
+
+	visited = nil
+	root.Walk(func(n *Node, _ int) bool {
+		visited = append(visited, n.Text)
+		return n.Text != "A1"
+	})
+	if want := []string{"Root", "A", "A1"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("Walk after early stop visited %v, want %v", visited, want)
+	}
+}